@@ -4,7 +4,13 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
 	"net"
+	"os"
+	"strconv"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/grpcapi"
@@ -13,6 +19,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -21,22 +28,73 @@ var grpcServerInstance *grpc.Server
 // startGRPCServer initializes and starts the gRPC server
 func startGRPCServer(pool *pgxpool.Pool, srv *httpapi.Server, jwtCfg auth.JWTCfg) {
 	grpcAddr := env("GRPC_ADDR", ":8082")
+
+	// Check credentials before listening so a misconfigured replica never accepts a connection
+	creds, err := grpcCredentials(jwtCfg.DevMode || env("GRPC_ALLOW_INSECURE", "") == "true")
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid gRPC TLS configuration")
+	}
+
 	lis, err := net.Listen("tcp", grpcAddr)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to listen for gRPC")
 	}
 
 	// Chain interceptors (executed in order)
-	grpcServerInstance = grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			grpcapi.RecoveryInterceptor(),         // Recover from panics
-			grpcapi.CorrelationIDInterceptor(),    // Add correlation ID
-			grpcapi.LoggingInterceptor(),          // Log requests
-			grpcapi.AuthInterceptor(pool, jwtCfg), // Validate JWT
-			grpcapi.SessionInterceptor(),          // Validate session
-			grpcapi.EpochInterceptor(pool),        // Validate epoch
-		),
-	)
+	interceptors := []grpc.UnaryServerInterceptor{
+		grpcapi.RecoveryInterceptor(),         // Recover from panics
+		grpcapi.CorrelationIDInterceptor(),    // Add correlation ID
+		grpcapi.LoggingInterceptor(srv.AccessLog.Level), // Access log line per RPC
+		grpcapi.ServerMetadataInterceptor(),   // Server time / API version headers
+		grpcapi.AuthInterceptor(pool, jwtCfg), // Validate JWT
+		grpcapi.ScopeInterceptor(srv.Scopes),  // Check token scopes (when enforced)
+		grpcapi.ForceWriteInterceptor(srv.Scopes.ForceWriteScope), // Gate PushRequest.force
+		grpcapi.SessionInterceptor(),          // Validate session
+		grpcapi.EpochInterceptor(pool),        // Validate epoch
+	}
+	if srv.SessionLimiter != nil {
+		// Runs last so AuthInterceptor has set the user ID
+		interceptors = append(interceptors, grpcapi.SessionRateLimitInterceptor(srv.SessionLimiter))
+	}
+	if srv.MaxPushItems > 0 {
+		interceptors = append(interceptors, grpcapi.PushItemLimitInterceptor(srv.MaxPushItems))
+	}
+	if srv.Load != nil {
+		// Count RPCs toward the same load estimate as HTTP requests
+		interceptors = append([]grpc.UnaryServerInterceptor{grpcapi.LoadInterceptor(srv.Load)}, interceptors...)
+	}
+	// Streaming RPCs (PushStream) run through the same chain
+	// (the force check and item cap need the request, so PushStream gets its own stream interceptors)
+	streamInterceptors := make([]grpc.StreamServerInterceptor, len(interceptors))
+	for i, interceptor := range interceptors {
+		streamInterceptors[i] = grpcapi.StreamServerInterceptor(interceptor)
+	}
+	streamInterceptors = append(streamInterceptors, grpcapi.ForceWriteStreamInterceptor(srv.Scopes.ForceWriteScope))
+	if srv.MaxPushItems > 0 {
+		streamInterceptors = append(streamInterceptors, grpcapi.PushItemLimitStreamInterceptor(srv.MaxPushItems))
+	}
+
+	// Message size limits (gRPC defaults: 4MB received, unlimited sent)
+	recvMB, err := strconv.Atoi(env("GRPC_MAX_RECV_MSG_MB", "4"))
+	if err != nil || recvMB <= 0 {
+		log.Fatal().Str("value", env("GRPC_MAX_RECV_MSG_MB", "")).Msg("FATAL: GRPC_MAX_RECV_MSG_MB must be a positive integer")
+	}
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+		grpc.MaxRecvMsgSize(recvMB << 20),
+	}
+	if creds != nil {
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+	if v := env("GRPC_MAX_SEND_MSG_MB", ""); v != "" {
+		sendMB, err := strconv.Atoi(v)
+		if err != nil || sendMB <= 0 {
+			log.Fatal().Str("value", v).Msg("FATAL: GRPC_MAX_SEND_MSG_MB must be a positive integer")
+		}
+		serverOpts = append(serverOpts, grpc.MaxSendMsgSize(sendMB<<20))
+	}
+	grpcServerInstance = grpc.NewServer(serverOpts...)
 
 	// Create main gRPC server with all services
 	grpcApiServer := grpcapi.NewServer(
@@ -49,24 +107,48 @@ func startGRPCServer(pool *pgxpool.Pool, srv *httpapi.Server, jwtCfg auth.JWTCfg
 		srv.TaskListSvc,
 		srv.TaskListCategorySvc,
 	)
+	grpcApiServer.Load = srv.Load
+	grpcApiServer.DisabledEntities = srv.DisabledEntities
+	grpcApiServer.MaxPushItems = srv.MaxPushItems
+	grpcApiServer.SyncCfg = srv.SyncCfg
+	grpcApiServer.RateLimit = &syncv1.RateLimitInfo{
+		WindowSeconds: int32(srv.RateLimitConfig.WindowSeconds),
+		MaxRequests:   int32(srv.RateLimitConfig.MaxRequests),
+		Burst:         int32(srv.RateLimitConfig.Burst),
+	}
 
 	// Register core sync service (sessions, info, wipe, state)
 	syncv1.RegisterSyncServiceServer(grpcServerInstance, grpcApiServer)
 
 	// Register entity sync services using wrappers
-	syncv1.RegisterNoteSyncServiceServer(grpcServerInstance, grpcApiServer)
-	syncv1.RegisterTaskSyncServiceServer(grpcServerInstance, &grpcapi.TaskServer{Server: grpcApiServer})
-	syncv1.RegisterCommentSyncServiceServer(grpcServerInstance, &grpcapi.CommentServer{Server: grpcApiServer})
-	syncv1.RegisterChatSyncServiceServer(grpcServerInstance, &grpcapi.ChatServer{Server: grpcApiServer})
-	syncv1.RegisterChatMessageSyncServiceServer(grpcServerInstance, &grpcapi.ChatMessageServer{Server: grpcApiServer})
-	syncv1.RegisterTaskListSyncServiceServer(grpcServerInstance, &grpcapi.TaskListServer{Server: grpcApiServer})
-	syncv1.RegisterTaskListCategorySyncServiceServer(grpcServerInstance, &grpcapi.TaskListCategoryServer{Server: grpcApiServer})
+	// Disabled entities are left unregistered, so their RPCs return codes.Unimplemented
+	if srv.EntityEnabled("notes") {
+		syncv1.RegisterNoteSyncServiceServer(grpcServerInstance, grpcApiServer)
+	}
+	if srv.EntityEnabled("tasks") {
+		syncv1.RegisterTaskSyncServiceServer(grpcServerInstance, &grpcapi.TaskServer{Server: grpcApiServer})
+	}
+	if srv.EntityEnabled("comments") {
+		syncv1.RegisterCommentSyncServiceServer(grpcServerInstance, &grpcapi.CommentServer{Server: grpcApiServer})
+	}
+	if srv.EntityEnabled("chats") {
+		syncv1.RegisterChatSyncServiceServer(grpcServerInstance, &grpcapi.ChatServer{Server: grpcApiServer})
+	}
+	if srv.EntityEnabled("chat_messages") {
+		syncv1.RegisterChatMessageSyncServiceServer(grpcServerInstance, &grpcapi.ChatMessageServer{Server: grpcApiServer})
+	}
+	if srv.EntityEnabled("task_lists") {
+		syncv1.RegisterTaskListSyncServiceServer(grpcServerInstance, &grpcapi.TaskListServer{Server: grpcApiServer})
+	}
+	if srv.EntityEnabled("task_list_categories") {
+		syncv1.RegisterTaskListCategorySyncServiceServer(grpcServerInstance, &grpcapi.TaskListCategoryServer{Server: grpcApiServer})
+	}
 
 	reflection.Register(grpcServerInstance) // Enable reflection for grpcurl testing
 
 	// Start gRPC server in goroutine
 	go func() {
-		log.Info().Str("addr", grpcAddr).Msg("starting gRPC server")
+		log.Info().Str("addr", grpcAddr).Bool("tls", creds != nil).Msg("starting gRPC server")
 		if err := grpcServerInstance.Serve(lis); err != nil {
 			log.Fatal().Err(err).Msg("gRPC server failed")
 		}
@@ -82,3 +164,47 @@ func stopGRPCServer() {
 		log.Info().Msg("gRPC server stopped")
 	}
 }
+
+// grpcCredentials builds the gRPC listener's TLS credentials from GRPC_TLS_CERT_FILE and
+// GRPC_TLS_KEY_FILE, verifying client certificates against GRPC_TLS_CLIENT_CA_FILE when
+// set (mTLS). Without a certificate it returns nil (plaintext), which only allowInsecure
+// (dev mode, or TLS terminated in front of the server) permits.
+func grpcCredentials(allowInsecure bool) (credentials.TransportCredentials, error) {
+	certFile, keyFile := env("GRPC_TLS_CERT_FILE", ""), env("GRPC_TLS_KEY_FILE", "")
+	clientCAFile := env("GRPC_TLS_CLIENT_CA_FILE", "")
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together")
+	}
+	if certFile == "" {
+		if clientCAFile != "" {
+			return nil, errors.New("GRPC_TLS_CLIENT_CA_FILE requires GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE")
+		}
+		if !allowInsecure {
+			return nil, errors.New("gRPC needs GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE outside dev mode (GRPC_ALLOW_INSECURE=true when TLS terminates in front of the server)")
+		}
+		log.Warn().Msg("gRPC server is running without TLS")
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load GRPC_TLS_CERT_FILE/GRPC_TLS_KEY_FILE: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read GRPC_TLS_CLIENT_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("GRPC_TLS_CLIENT_CA_FILE contains no PEM certificates")
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(cfg), nil
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -12,7 +15,11 @@ import (
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/erauner12/toolbridge-api/internal/httpapi"
+	"github.com/erauner12/toolbridge-api/internal/loadest"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/workos/workos-go/v6/pkg/usermanagement"
@@ -73,6 +80,12 @@ func main() {
 				"Setting only issuer would have no JWKS to validate signatures.")
 	}
 
+	// Clock skew tolerated on exp/nbf/iat so IdPs with slightly skewed clocks don't cause 401s
+	jwtLeewaySeconds, err := strconv.Atoi(env("JWT_LEEWAY_SECONDS", "60"))
+	if err != nil || jwtLeewaySeconds < 0 {
+		log.Fatal().Str("value", env("JWT_LEEWAY_SECONDS", "")).Msg("FATAL: JWT_LEEWAY_SECONDS must be a non-negative integer")
+	}
+
 	// Additional accepted audiences (for MCP OAuth tokens, token exchange, etc.)
 	// These are in addition to the primary JWT_AUDIENCE
 	//
@@ -105,6 +118,7 @@ func main() {
 		Audience:          jwtAudience,
 		AcceptedAudiences: acceptedAudiences,
 		TenantClaim:       env("TENANT_CLAIM", ""),
+		Leeway:            time.Duration(jwtLeewaySeconds) * time.Second,
 
 		BackendRSAPrivateKeyPEM: backendRSAPrivateKeyPEM,
 		BackendKeyID:            backendKeyID,
@@ -131,6 +145,189 @@ func main() {
 	tenantAuthCache := auth.NewTenantAuthCache()
 	log.Info().Msg("Tenant authorization cache initialized (5-minute TTL)")
 
+	// Admin token for support diagnostics endpoints (optional)
+	// When unset, /v1/admin/* routes are not registered
+	adminToken := env("ADMIN_TOKEN", "")
+	if adminToken != "" {
+		log.Info().Msg("Admin endpoints enabled (ADMIN_TOKEN set)")
+	}
+
+	// Per-user concurrent session cap (optional, 0 = unlimited)
+	if v := env("SESSION_MAX_PER_USER", "0"); v != "0" {
+		maxSessions, err := strconv.Atoi(v)
+		if err != nil || maxSessions < 0 {
+			log.Fatal().Str("value", v).Msg("FATAL: SESSION_MAX_PER_USER must be a non-negative integer")
+		}
+		policy, err := session.ParseLimitPolicy(env("SESSION_LIMIT_POLICY", string(session.LimitPolicyEvictOldest)))
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid SESSION_LIMIT_POLICY")
+		}
+		session.GetStore().SetUserSessionLimit(maxSessions, policy)
+		log.Info().Int("maxPerUser", maxSessions).Str("policy", string(policy)).Msg("Per-user session limit enabled")
+	}
+
+	// Where sync sessions live: "memory" (per process) or "postgres" (shared by all replicas)
+	sessionBackend, err := session.ParseStoreBackend(env("SESSION_STORE", string(session.StoreMemory)))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid SESSION_STORE")
+	}
+	if sessionBackend == session.StorePostgres {
+		session.GetStore().UsePostgres(pool)
+		log.Info().Msg("Sync sessions stored in Postgres (shared across replicas)")
+	}
+
+	// Sync settings of the entity services: storage, validation, paging, logging (see sync_config.go)
+	syncCfg := loadSyncConfig()
+
+	// Zone for pushed timestamps that carry no UTC offset; all timestamps are stored and served in UTC
+	if tz := env("TIMESTAMP_DEFAULT_TZ", ""); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid TIMESTAMP_DEFAULT_TZ")
+		}
+		syncx.SetDefaultLocation(loc)
+		log.Info().Str("zone", loc.String()).Msg("Offset-less timestamps read in configured zone")
+	}
+
+	// Scheduled note deletion sweeper (soft-deletes notes whose deleteAfter has passed); 0 disables
+	sweepInterval, err := time.ParseDuration(env("SCHEDULED_DELETE_SWEEP_INTERVAL", "1m"))
+	if err != nil || sweepInterval < 0 {
+		log.Fatal().Str("value", env("SCHEDULED_DELETE_SWEEP_INTERVAL", "")).Msg("FATAL: SCHEDULED_DELETE_SWEEP_INTERVAL must be a non-negative duration (e.g., 1m)")
+	}
+
+	// Per-request deadline (cancels slow handlers and their DB queries with a 504)
+	// Keep below the http.Server WriteTimeout so the 504 can still be written; 0 disables
+	requestTimeout, err := time.ParseDuration(env("REQUEST_TIMEOUT", "20s"))
+	if err != nil || requestTimeout < 0 {
+		log.Fatal().Str("value", env("REQUEST_TIMEOUT", "")).Msg("FATAL: REQUEST_TIMEOUT must be a non-negative duration (e.g., 20s)")
+	}
+
+	// Long-polling pulls (?wait=): longest hold; waits also end before REQUEST_TIMEOUT. 0 disables
+	// Must stay below the http.Server WriteTimeout, which no handler can extend
+	maxPullWait, err := time.ParseDuration(env("PULL_MAX_WAIT", "25s"))
+	if err != nil || maxPullWait < 0 || maxPullWait >= 30*time.Second {
+		log.Fatal().Str("value", env("PULL_MAX_WAIT", "")).Msg("FATAL: PULL_MAX_WAIT must be a non-negative duration under 30s (e.g., 25s)")
+	}
+
+	// Load estimate behind the sync info hints (recommendedBatch/recommendedBackoffMs/currentLoad)
+	// Capacity is the per-replica request volume per minute treated as full load
+	loadCapacity, err := strconv.Atoi(env("LOAD_CAPACITY_PER_MINUTE", "6000"))
+	if err != nil || loadCapacity <= 0 {
+		log.Fatal().Str("value", env("LOAD_CAPACITY_PER_MINUTE", "")).Msg("FATAL: LOAD_CAPACITY_PER_MINUTE must be a positive integer")
+	}
+
+	// Pull hot-loop detection: a session re-pulling one cursor this many times in a row while
+	// newer data exists is logged, and refused with 429 when PULL_LOOP_THROTTLE=true; 0 disables
+	pullLoopRepeats, err := strconv.Atoi(env("PULL_LOOP_REPEATS", "20"))
+	if err != nil || pullLoopRepeats < 0 {
+		log.Fatal().Str("value", env("PULL_LOOP_REPEATS", "")).Msg("FATAL: PULL_LOOP_REPEATS must be a non-negative integer")
+	}
+	var pullLoop *httpapi.PullLoopGuard
+	if pullLoopRepeats > 0 {
+		pullLoop = httpapi.NewPullLoopGuard(pullLoopRepeats, env("PULL_LOOP_THROTTLE", "") == "true")
+	}
+
+	// Cold pull depth: pulls without a cursor only go back this many days unless full=true; 0 = no limit
+	coldPullMaxDays, err := strconv.Atoi(env("COLD_PULL_MAX_DAYS", "0"))
+	if err != nil || coldPullMaxDays < 0 {
+		log.Fatal().Str("value", env("COLD_PULL_MAX_DAYS", "")).Msg("FATAL: COLD_PULL_MAX_DAYS must be a non-negative integer")
+	}
+
+	// Session-scoped undo: record each session's pre-session item state; logs are pruned after the retention
+	sessionUndo := env("SESSION_UNDO", "") == "true"
+	sessionUndoRetention, err := time.ParseDuration(env("SESSION_UNDO_RETENTION", "24h"))
+	if err != nil || sessionUndoRetention <= 0 {
+		log.Fatal().Str("value", env("SESSION_UNDO_RETENTION", "")).Msg("FATAL: SESSION_UNDO_RETENTION must be a positive duration (e.g., 24h)")
+	}
+
+	// REST ETags: "version" (default) or "content" (hash of the payload; changes ETag values)
+	etagMode, err := httpapi.ParseETagMode(env("ETAG_MODE", string(httpapi.ETagVersion)))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid ETAG_MODE")
+	}
+
+	// Session creation limit per user (HTTP and gRPC share the budget); 0 = no dedicated limit
+	sessionRate := httpapi.DefaultSessionRateLimitConfig
+	if sessionRate.MaxRequests, err = strconv.Atoi(env("SESSION_CREATE_PER_MINUTE", strconv.Itoa(sessionRate.MaxRequests))); err != nil || sessionRate.MaxRequests < 0 {
+		log.Fatal().Str("value", env("SESSION_CREATE_PER_MINUTE", "")).Msg("FATAL: SESSION_CREATE_PER_MINUTE must be a non-negative integer")
+	}
+	if sessionRate.Burst, err = strconv.Atoi(env("SESSION_CREATE_BURST", strconv.Itoa(sessionRate.Burst))); err != nil || sessionRate.Burst < 1 {
+		log.Fatal().Str("value", env("SESSION_CREATE_BURST", "")).Msg("FATAL: SESSION_CREATE_BURST must be a positive integer")
+	}
+	// Rate limit budgets: shared across replicas through Redis, or in-process per replica when unset
+	var rateLimitBackend httpapi.LimiterBackend = httpapi.InProcessLimiters
+	if v := env("RATE_LIMIT_REDIS_URL", ""); v != "" {
+		redisOpts, err := redis.ParseURL(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid RATE_LIMIT_REDIS_URL")
+		}
+		redisClient := redis.NewClient(redisOpts)
+		pingCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := redisClient.Ping(pingCtx).Err(); err != nil {
+			log.Warn().Err(err).Msg("rate limit redis unreachable; requests are allowed until it recovers")
+		}
+		cancel()
+		rateLimitBackend = httpapi.RedisLimiters(redisClient)
+		log.Info().Str("addr", redisOpts.Addr).Msg("rate limits shared through redis")
+	}
+
+	var sessionLimiter httpapi.Limiter
+	if sessionRate.MaxRequests > 0 {
+		sessionLimiter = rateLimitBackend("session", sessionRate)
+	}
+
+	// Access log: one line per request/RPC at ACCESS_LOG_LEVEL ("off" disables); health probes opt-in
+	accessLog := httpapi.DefaultAccessLogCfg
+	if accessLog.Level, err = httpapi.ParseAccessLogLevel(env("ACCESS_LOG_LEVEL", "info")); err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid ACCESS_LOG_LEVEL")
+	}
+	accessLog.Probes = env("ACCESS_LOG_PROBES", "false") == "true"
+
+	// Request ID in X-Request-ID and error bodies, for support tickets (on unless set to "false")
+	echoRequestID := env("ERROR_REQUEST_ID", "true") != "false"
+
+	// Process action metadata keys the action doesn't declare: ignore (default) or reject with 400
+	unknownProcessMetadata, err := httpapi.ParseUnknownMetadataMode(env("PROCESS_METADATA_UNKNOWN", string(httpapi.UnknownMetadataIgnore)))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid PROCESS_METADATA_UNKNOWN")
+	}
+
+	// Entity types shipped dark (comma-separated URL names, e.g. task_list_categories)
+	disabledEntities, err := httpapi.ParseDisabledEntities(env("DISABLED_ENTITIES", ""))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid DISABLED_ENTITIES")
+	}
+
+	// Status for sync routes naming an unknown entity type (404, or 400 for strict clients)
+	unknownEntityStatus, err := httpapi.ParseUnknownEntityStatus(env("UNKNOWN_ENTITY_STATUS", "404"))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid UNKNOWN_ENTITY_STATUS")
+	}
+
+	// Compressed request bodies: cap on the decompressed size (zip-bomb protection)
+	maxDecompressedMB, err := strconv.Atoi(env("MAX_DECOMPRESSED_BODY_MB", "32"))
+	if err != nil || maxDecompressedMB <= 0 {
+		log.Fatal().Str("value", env("MAX_DECOMPRESSED_BODY_MB", "")).Msg("FATAL: MAX_DECOMPRESSED_BODY_MB must be a positive integer")
+	}
+
+	// Items per push request (HTTP 413 / gRPC ResourceExhausted beyond it); 0 = unlimited
+	maxPushItems, err := strconv.Atoi(env("MAX_PUSH_ITEMS", "0"))
+	if err != nil || maxPushItems < 0 {
+		log.Fatal().Str("value", env("MAX_PUSH_ITEMS", "")).Msg("FATAL: MAX_PUSH_ITEMS must be a non-negative integer")
+	}
+
+	// OAuth scope enforcement: reads need SCOPE_READ, mutations SCOPE_WRITE (off by default);
+	// forced pushes always need SCOPE_FORCE_WRITE
+	scopeCfg := auth.ScopeCfg{
+		Enforce:         env("SCOPE_ENFORCEMENT", "") == "true",
+		ReadScope:       env("SCOPE_READ", auth.DefaultReadScope),
+		WriteScope:      env("SCOPE_WRITE", auth.DefaultWriteScope),
+		ForceWriteScope: env("SCOPE_FORCE_WRITE", auth.DefaultForceWriteScope),
+	}
+	if scopeCfg.Enforce {
+		log.Info().Str("read", scopeCfg.ReadScope).Str("write", scopeCfg.WriteScope).Msg("Token scope enforcement enabled")
+	}
+
 	// HTTP server setup
 	srv := &httpapi.Server{
 		DB:                  pool,
@@ -140,6 +337,24 @@ func main() {
 		WorkOSClient:    workosClient,
 		DefaultTenantID: defaultTenantID,
 		TenantAuthCache: tenantAuthCache,
+		AdminToken:      adminToken,
+		RequestTimeout:  requestTimeout,
+		Load:            loadest.New(loadCapacity),
+		ColdPullMaxAge:  time.Duration(coldPullMaxDays) * 24 * time.Hour,
+		MaxPullWait:     maxPullWait,
+		SessionUndo:     sessionUndo,
+		PullLoop:        pullLoop,
+		ETagMode:        etagMode,
+		Scopes:          scopeCfg,
+		SessionLimiter:  sessionLimiter,
+		RateLimitBackend: rateLimitBackend,
+		MaxDecompressedBytes: int64(maxDecompressedMB) << 20,
+		MaxPushItems:         maxPushItems,
+		AccessLog:       accessLog,
+		EchoRequestID:   echoRequestID,
+		UnknownProcessMetadata: unknownProcessMetadata,
+		DisabledEntities: disabledEntities,
+		UnknownEntityStatus: unknownEntityStatus,
 		// Initialize services
 		NoteSvc:             syncservice.NewNoteService(pool),
 		TaskSvc:             syncservice.NewTaskService(pool),
@@ -148,7 +363,9 @@ func main() {
 		ChatMessageSvc:      syncservice.NewChatMessageService(pool),
 		TaskListSvc:         syncservice.NewTaskListService(pool),
 		TaskListCategorySvc: syncservice.NewTaskListCategoryService(pool),
+		SearchSvc:           syncservice.NewSearchService(pool),
 	}
+	useSyncConfig(srv, syncCfg)
 
 	// Security validation: Always require a strong HS256 secret in production mode
 	// This provides defense-in-depth even when upstream OIDC is configured, since the middleware
@@ -222,13 +439,68 @@ func main() {
 		IdleTimeout:  120 * time.Second,
 	}
 
+	// Built-in TLS is optional: by default the server speaks plain HTTP behind a TLS-terminating proxy
+	tlsCertFile, tlsKeyFile := env("TLS_CERT_FILE", ""), env("TLS_KEY_FILE", "")
+	if (tlsCertFile == "") != (tlsKeyFile == "") {
+		log.Fatal().Msg("FATAL: TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	var redirectServer *http.Server
+	if tlsCertFile != "" {
+		// Fail at startup, not on the first handshake, if the pair is unreadable
+		if _, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile); err != nil {
+			log.Fatal().Err(err).Msg("FATAL: cannot load TLS_CERT_FILE/TLS_KEY_FILE")
+		}
+		httpServer.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+		// Optional plain-HTTP listener that only redirects to HTTPS
+		if redirectAddr := env("HTTP_REDIRECT_ADDR", ""); redirectAddr != "" {
+			_, httpsPort, err := net.SplitHostPort(httpAddr)
+			if err != nil {
+				log.Fatal().Err(err).Str("value", httpAddr).Msg("FATAL: HTTP_ADDR must be host:port to redirect to it")
+			}
+			redirectServer = &http.Server{
+				Addr:         redirectAddr,
+				Handler:      httpapi.HTTPSRedirect(httpsPort),
+				ReadTimeout:  5 * time.Second,
+				WriteTimeout: 5 * time.Second,
+			}
+		}
+	} else if env("HTTP_REDIRECT_ADDR", "") != "" {
+		log.Fatal().Msg("FATAL: HTTP_REDIRECT_ADDR requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	// Background workers stop when workerCtx is cancelled at shutdown
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	if sweepInterval > 0 {
+		go srv.NoteSvc.RunScheduledDeleteSweeper(workerCtx, sweepInterval)
+	}
+	if sessionUndo {
+		go syncservice.RunSessionChangePruner(workerCtx, pool, time.Hour, sessionUndoRetention)
+	}
+
 	// Start server in goroutine
 	go func() {
-		log.Info().Str("addr", httpAddr).Msg("starting HTTP server")
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsCertFile != "" {
+			log.Info().Str("addr", httpAddr).Msg("starting HTTPS server")
+			err = httpServer.ListenAndServeTLS(tlsCertFile, tlsKeyFile)
+		} else {
+			log.Info().Str("addr", httpAddr).Msg("starting HTTP server")
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatal().Err(err).Msg("HTTP server failed")
 		}
 	}()
+	if redirectServer != nil {
+		go func() {
+			log.Info().Str("addr", redirectServer.Addr).Msg("starting HTTP to HTTPS redirect server")
+			if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatal().Err(err).Msg("HTTP redirect server failed")
+			}
+		}()
+	}
 
 	// ===================================================================
 	// gRPC Server Setup (Conditionally compiled with -tags grpc)
@@ -243,6 +515,7 @@ func main() {
 	<-sigChan
 
 	log.Info().Msg("shutting down gracefully...")
+	stopWorkers()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -251,6 +524,11 @@ func main() {
 	if err := httpServer.Shutdown(shutdownCtx); err != nil {
 		log.Error().Err(err).Msg("HTTP server shutdown error")
 	}
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(shutdownCtx); err != nil {
+			log.Error().Err(err).Msg("HTTP redirect server shutdown error")
+		}
+	}
 
 	// Shutdown gRPC server (no-op without grpc tag)
 	stopGRPCServer()
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/httpapi"
+	"github.com/erauner12/toolbridge-api/internal/payloadcrypt"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/rs/zerolog/log"
+)
+
+// loadSyncConfig builds the sync settings of the entity services from the environment
+// Unset variables keep the syncservice.DefaultConfig value; invalid ones are fatal.
+func loadSyncConfig() *syncservice.Config {
+	cfg := syncservice.DefaultConfig()
+
+	// Payload encryption at rest (optional)
+	// PAYLOAD_ENCRYPTION_KEY is a base64-encoded 32-byte key-encryption key
+	if encKey := env("PAYLOAD_ENCRYPTION_KEY", ""); encKey != "" {
+		key, err := payloadcrypt.ParseKey(encKey)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid PAYLOAD_ENCRYPTION_KEY")
+		}
+		payloadCipher, err := payloadcrypt.NewCipher(key)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid PAYLOAD_ENCRYPTION_KEY")
+		}
+		cfg.PayloadCipher = payloadCipher
+		log.Info().Str("keyId", payloadCipher.KeyID()).Msg("Payload encryption at rest enabled")
+	}
+
+	// Sync push field semantics: "keep" (omitted = unchanged, null = cleared, default) or "clear" (legacy: push replaces payload)
+	absentFields, err := syncservice.ParseAbsentFieldMode(env("SYNC_PUSH_ABSENT_FIELDS", string(syncservice.AbsentFieldsKeep)))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid SYNC_PUSH_ABSENT_FIELDS")
+	}
+	cfg.AbsentFields = absentFields
+
+	// Top-level payload keys shadowing sync metadata in REST writes: strip (default), reject (422) or allow
+	reservedKeys, err := syncservice.ParseReservedKeyMode(env("RESERVED_PAYLOAD_KEYS", string(syncservice.ReservedKeysStrip)))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid RESERVED_PAYLOAD_KEYS")
+	}
+	cfg.ReservedKeys = reservedKeys
+
+	// Allowed chat_message roles (comma-separated); other roles are rejected on write
+	chatRoles, err := syncservice.ParseChatMessageRoles(env("CHAT_MESSAGE_ROLES", strings.Join(syncservice.DefaultChatMessageRoles, ",")))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid CHAT_MESSAGE_ROLES")
+	}
+	cfg.ChatMessageRoles = chatRoles
+
+	// Log privacy: payload keys masked wherever payloads are logged, or no payload logging at all
+	cfg.RedactedLogKeys = syncservice.ParseRedactedLogKeys(env("LOG_REDACT_KEYS", strings.Join(syncservice.DefaultRedactedLogKeys, ",")))
+	cfg.PayloadLogging = env("LOG_PAYLOADS", "true") != "false"
+
+	// Per-entity templates for fields REST creates leave out (unset = built-in task/chat defaults)
+	if v := env("DEFAULT_PAYLOADS", ""); v != "" {
+		templates, err := syncservice.ParseDefaultPayloads(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid DEFAULT_PAYLOADS")
+		}
+		cfg.DefaultPayloads = templates
+	}
+
+	// Per-entity field formats checked on write (unset = built-in note sourceUrl / comment authorEmail rules)
+	if v := env("FIELD_FORMATS", ""); v != "" {
+		formats, err := syncservice.ParseFieldFormats(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid FIELD_FORMATS")
+		}
+		cfg.FieldFormats = formats
+	}
+
+	// Per-entity caps on array field sizes (unset = built-in 64 tags per note/task)
+	if v := env("ARRAY_LIMITS", ""); v != "" {
+		limits, err := syncservice.ParseArrayLimits(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid ARRAY_LIMITS")
+		}
+		cfg.ArrayLimits = limits
+	}
+
+	// Per-entity caps on free-text field length (unset = built-in note/comment/message content caps)
+	if v := env("TEXT_LIMITS", ""); v != "" {
+		limits, err := syncservice.ParseTextLimits(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid TEXT_LIMITS")
+		}
+		cfg.TextLimits = limits
+	}
+
+	// Entities whose existing items can be deleted but not edited, e.g. "chat_message"
+	if v := env("APPEND_ONLY_ENTITIES", ""); v != "" {
+		tables, err := syncservice.ParseAppendOnlyEntities(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid APPEND_ONLY_ENTITIES")
+		}
+		cfg.AppendOnlyEntities = tables
+	}
+
+	// Payload fields GET /v1/{entity}/export.csv may contain (unset = built-in task columns)
+	if v := env("EXPORT_COLUMNS", ""); v != "" {
+		columns, err := syncservice.ParseExportColumns(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid EXPORT_COLUMNS")
+		}
+		cfg.ExportColumns = columns
+	}
+
+	// Per-entity payload field unique among a user's live items, e.g. {"note":"externalId"}
+	if v := env("UNIQUE_FIELDS", ""); v != "" {
+		fields, err := syncservice.ParseUniqueFields(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid UNIQUE_FIELDS")
+		}
+		cfg.UniqueFields = fields
+	}
+
+	// Per-user sequential task display IDs (seqId, GET /v1/tasks/seq/{n})
+	cfg.SeqIDs = env("SEQ_IDS", "") == "true"
+
+	// Single-item read cache (GetNote, GetTask, ...), invalidated by every write; 0 disables
+	itemCache := syncservice.ItemCacheCfg{}
+	if itemCache.Size, err = strconv.Atoi(env("ITEM_CACHE_SIZE", "0")); err != nil || itemCache.Size < 0 {
+		log.Fatal().Str("value", env("ITEM_CACHE_SIZE", "")).Msg("FATAL: ITEM_CACHE_SIZE must be a non-negative integer")
+	}
+	if itemCache.TTL, err = time.ParseDuration(env("ITEM_CACHE_TTL", "30s")); err != nil || itemCache.TTL <= 0 {
+		log.Fatal().Str("value", env("ITEM_CACHE_TTL", "")).Msg("FATAL: ITEM_CACHE_TTL must be a positive duration (e.g., 30s)")
+	}
+	cfg.ItemCache = syncservice.NewItemCache(itemCache)
+
+	// Byte cap per pull/list page; pages stop short of limit to stay under it (0 = unlimited)
+	maxPageBytes, err := strconv.Atoi(env("MAX_PAGE_BYTES", "0"))
+	if err != nil || maxPageBytes < 0 {
+		log.Fatal().Str("value", env("MAX_PAGE_BYTES", "")).Msg("FATAL: MAX_PAGE_BYTES must be a non-negative integer")
+	}
+	cfg.MaxPageBytes = maxPageBytes
+
+	// Per-entity default and max page size for pulls and lists (unset = 500/1000, chat messages 100/1000)
+	if v := env("PAGE_LIMITS", ""); v != "" {
+		limits, err := syncservice.ParsePageLimits(v)
+		if err != nil {
+			log.Fatal().Err(err).Msg("FATAL: invalid PAGE_LIMITS")
+		}
+		cfg.PageLimits = limits
+	}
+
+	// Per-user storage cap: writes that would grow a user's live payload bytes past it are refused
+	maxPayloadBytes, err := strconv.ParseInt(env("MAX_PAYLOAD_BYTES", "0"), 10, 64)
+	if err != nil || maxPayloadBytes < 0 {
+		log.Fatal().Str("value", env("MAX_PAYLOAD_BYTES", "")).Msg("FATAL: MAX_PAYLOAD_BYTES must be a non-negative integer")
+	}
+	cfg.MaxPayloadBytes = maxPayloadBytes
+
+	// UID version for new items: "any" (default), "4" or "7" (existing items are unaffected)
+	uidVersion, err := syncservice.ParseUIDVersion(env("UID_VERSION", ""))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid UID_VERSION")
+	}
+	cfg.RequiredUIDVersion = uidVersion
+
+	// Oldest updatedTs a push may create an item with, in days (0 = unchecked); older creates
+	// are rejected or, with MAX_CREATE_AGE_MODE=flag, stored and logged
+	maxCreateAgeDays, err := strconv.Atoi(env("MAX_CREATE_AGE_DAYS", "0"))
+	if err != nil || maxCreateAgeDays < 0 {
+		log.Fatal().Str("value", env("MAX_CREATE_AGE_DAYS", "")).Msg("FATAL: MAX_CREATE_AGE_DAYS must be a non-negative integer")
+	}
+	createAgeMode, err := syncservice.ParseCreateAgeMode(env("MAX_CREATE_AGE_MODE", ""))
+	if err != nil {
+		log.Fatal().Err(err).Msg("FATAL: invalid MAX_CREATE_AGE_MODE")
+	}
+	cfg.MaxCreateAgeDays, cfg.CreateAgeMode = maxCreateAgeDays, createAgeMode
+
+	// Server-computed note fields (see syncservice.Config.RegisterPayloadTransform)
+	if env("NOTE_WORD_COUNT", "") == "true" {
+		cfg.RegisterPayloadTransform("note", syncservice.WordCount("content", "wordCount"))
+	}
+
+	return cfg
+}
+
+// useSyncConfig gives the HTTP server and every entity service of srv the same sync settings
+func useSyncConfig(srv *httpapi.Server, cfg *syncservice.Config) {
+	srv.SyncCfg = cfg
+	srv.NoteSvc.Cfg = cfg
+	srv.TaskSvc.Cfg = cfg
+	srv.CommentSvc.Cfg = cfg
+	srv.ChatSvc.Cfg = cfg
+	srv.ChatMessageSvc.Cfg = cfg
+	srv.TaskListSvc.Cfg = cfg
+	srv.TaskListCategorySvc.Cfg = cfg
+	srv.SearchSvc.Cfg = cfg
+}
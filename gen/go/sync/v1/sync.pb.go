@@ -29,7 +29,10 @@ const (
 type PushRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// A batch of items, each a JSON-like object.
-	Items         []*structpb.Struct `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Items []*structpb.Struct `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// Apply every item regardless of LWW timestamps (data migrations). Requires the
+	// force-write scope (sync:force by default); PermissionDenied otherwise.
+	Force         bool `protobuf:"varint,2,opt,name=force,proto3" json:"force,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -71,6 +74,13 @@ func (x *PushRequest) GetItems() []*structpb.Struct {
 	return nil
 }
 
+func (x *PushRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
 type PushResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Acks          []*PushAck             `protobuf:"bytes,1,rep,name=acks,proto3" json:"acks,omitempty"`
@@ -425,9 +435,10 @@ func (x *ServerInfo) GetHints() *SyncHints {
 
 type EntityCapability struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	MaxLimit      int32                  `protobuf:"varint,1,opt,name=max_limit,json=maxLimit,proto3" json:"max_limit,omitempty"`
+	MaxLimit      int32                  `protobuf:"varint,1,opt,name=max_limit,json=maxLimit,proto3" json:"max_limit,omitempty"` // larger pull limits are clamped to it (x-limit-clamped header)
 	Push          bool                   `protobuf:"varint,2,opt,name=push,proto3" json:"push,omitempty"`
 	Pull          bool                   `protobuf:"varint,3,opt,name=pull,proto3" json:"pull,omitempty"`
+	DefaultLimit  int32                  `protobuf:"varint,4,opt,name=default_limit,json=defaultLimit,proto3" json:"default_limit,omitempty"` // page size when a pull sends no limit
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -483,6 +494,13 @@ func (x *EntityCapability) GetPull() bool {
 	return false
 }
 
+func (x *EntityCapability) GetDefaultLimit() int32 {
+	if x != nil {
+		return x.DefaultLimit
+	}
+	return 0
+}
+
 type LockingCapability struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Supported     bool                   `protobuf:"varint,1,opt,name=supported,proto3" json:"supported,omitempty"`
@@ -596,11 +614,13 @@ func (x *RateLimitInfo) GetBurst() int32 {
 }
 
 type SyncHints struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	RecommendedBatch int32                  `protobuf:"varint,1,opt,name=recommended_batch,json=recommendedBatch,proto3" json:"recommended_batch,omitempty"`
-	BackoffMsOn_429  int32                  `protobuf:"varint,2,opt,name=backoff_ms_on_429,json=backoffMsOn429,proto3" json:"backoff_ms_on_429,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	RecommendedBatch     int32                  `protobuf:"varint,1,opt,name=recommended_batch,json=recommendedBatch,proto3" json:"recommended_batch,omitempty"`
+	BackoffMsOn_429      int32                  `protobuf:"varint,2,opt,name=backoff_ms_on_429,json=backoffMsOn429,proto3" json:"backoff_ms_on_429,omitempty"`
+	RecommendedBackoffMs int32                  `protobuf:"varint,3,opt,name=recommended_backoff_ms,json=recommendedBackoffMs,proto3" json:"recommended_backoff_ms,omitempty"` // suggested delay between batches at current load (0 = none)
+	CurrentLoad          float64                `protobuf:"fixed64,4,opt,name=current_load,json=currentLoad,proto3" json:"current_load,omitempty"`                             // recent request volume relative to capacity (1.0 = at capacity)
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *SyncHints) Reset() {
@@ -647,6 +667,20 @@ func (x *SyncHints) GetBackoffMsOn_429() int32 {
 	return 0
 }
 
+func (x *SyncHints) GetRecommendedBackoffMs() int32 {
+	if x != nil {
+		return x.RecommendedBackoffMs
+	}
+	return 0
+}
+
+func (x *SyncHints) GetCurrentLoad() float64 {
+	if x != nil {
+		return x.CurrentLoad
+	}
+	return 0
+}
+
 type BeginSessionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -1043,9 +1077,10 @@ var File_sync_v1_sync_proto protoreflect.FileDescriptor
 
 const file_sync_v1_sync_proto_rawDesc = "" +
 	"\n" +
-	"\x12sync/v1/sync.proto\x12\x12toolbridge.sync.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"<\n" +
+	"\x12sync/v1/sync.proto\x12\x12toolbridge.sync.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"R\n" +
 	"\vPushRequest\x12-\n" +
-	"\x05items\x18\x01 \x03(\v2\x17.google.protobuf.StructR\x05items\"?\n" +
+	"\x05items\x18\x01 \x03(\v2\x17.google.protobuf.StructR\x05items\x12\x14\n" +
+	"\x05force\x18\x02 \x01(\bR\x05force\"?\n" +
 	"\fPushResponse\x12/\n" +
 	"\x04acks\x18\x01 \x03(\v2\x1b.toolbridge.sync.v1.PushAckR\x04acks\"\x86\x01\n" +
 	"\aPushAck\x12\x10\n" +
@@ -1077,21 +1112,24 @@ const file_sync_v1_sync_proto_rawDesc = "" +
 	"\x05hints\x18\a \x01(\v2\x1d.toolbridge.sync.v1.SyncHintsR\x05hints\x1aa\n" +
 	"\rEntitiesEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12:\n" +
-	"\x05value\x18\x02 \x01(\v2$.toolbridge.sync.v1.EntityCapabilityR\x05value:\x028\x01\"W\n" +
+	"\x05value\x18\x02 \x01(\v2$.toolbridge.sync.v1.EntityCapabilityR\x05value:\x028\x01\"|\n" +
 	"\x10EntityCapability\x12\x1b\n" +
 	"\tmax_limit\x18\x01 \x01(\x05R\bmaxLimit\x12\x12\n" +
 	"\x04push\x18\x02 \x01(\bR\x04push\x12\x12\n" +
-	"\x04pull\x18\x03 \x01(\bR\x04pull\"E\n" +
+	"\x04pull\x18\x03 \x01(\bR\x04pull\x12#\n" +
+	"\rdefault_limit\x18\x04 \x01(\x05R\fdefaultLimit\"E\n" +
 	"\x11LockingCapability\x12\x1c\n" +
 	"\tsupported\x18\x01 \x01(\bR\tsupported\x12\x12\n" +
 	"\x04mode\x18\x02 \x01(\tR\x04mode\"o\n" +
 	"\rRateLimitInfo\x12%\n" +
 	"\x0ewindow_seconds\x18\x01 \x01(\x05R\rwindowSeconds\x12!\n" +
 	"\fmax_requests\x18\x02 \x01(\x05R\vmaxRequests\x12\x14\n" +
-	"\x05burst\x18\x03 \x01(\x05R\x05burst\"c\n" +
+	"\x05burst\x18\x03 \x01(\x05R\x05burst\"\xbc\x01\n" +
 	"\tSyncHints\x12+\n" +
 	"\x11recommended_batch\x18\x01 \x01(\x05R\x10recommendedBatch\x12)\n" +
-	"\x11backoff_ms_on_429\x18\x02 \x01(\x05R\x0ebackoffMsOn429\"\x15\n" +
+	"\x11backoff_ms_on_429\x18\x02 \x01(\x05R\x0ebackoffMsOn429\x124\n" +
+	"\x16recommended_backoff_ms\x18\x03 \x01(\x05R\x14recommendedBackoffMs\x12!\n" +
+	"\fcurrent_load\x18\x04 \x01(\x01R\vcurrentLoad\"\x15\n" +
 	"\x13BeginSessionRequest\"\xc2\x01\n" +
 	"\vSyncSession\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
@@ -1128,27 +1166,41 @@ const file_sync_v1_sync_proto_rawDesc = "" +
 	"\n" +
 	"EndSession\x12%.toolbridge.sync.v1.EndSessionRequest\x1a&.toolbridge.sync.v1.EndSessionResponse\"\x00\x12W\n" +
 	"\vWipeAccount\x12&.toolbridge.sync.v1.WipeAccountRequest\x1a\x1e.toolbridge.sync.v1.WipeResult\"\x00\x12\\\n" +
-	"\fGetSyncState\x12'.toolbridge.sync.v1.GetSyncStateRequest\x1a!.toolbridge.sync.v1.UserSyncState\"\x002\xab\x01\n" +
+	"\fGetSyncState\x12'.toolbridge.sync.v1.GetSyncStateRequest\x1a!.toolbridge.sync.v1.UserSyncState\"\x002\xfb\x01\n" +
 	"\x0fNoteSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xab\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12N\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a\x1b.toolbridge.sync.v1.PushAck\"\x000\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xfb\x01\n" +
 	"\x0fTaskSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xae\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12N\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a\x1b.toolbridge.sync.v1.PushAck\"\x000\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xfe\x01\n" +
 	"\x12CommentSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xab\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12N\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a\x1b.toolbridge.sync.v1.PushAck\"\x000\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xfb\x01\n" +
 	"\x0fChatSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xb2\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12N\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a\x1b.toolbridge.sync.v1.PushAck\"\x000\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x82\x02\n" +
 	"\x16ChatMessageSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xaf\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12N\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a\x1b.toolbridge.sync.v1.PushAck\"\x000\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xff\x01\n" +
 	"\x13TaskListSyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
-	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\xb7\x01\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12N\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a\x1b.toolbridge.sync.v1.PushAck\"\x000\x01\x12K\n" +
+	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x002\x87\x02\n" +
 	"\x1bTaskListCategorySyncService\x12K\n" +
-	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12K\n" +
+	"\x04Push\x12\x1f.toolbridge.sync.v1.PushRequest\x1a .toolbridge.sync.v1.PushResponse\"\x00\x12N\n" +
+	"\n" +
+	"PushStream\x12\x1f.toolbridge.sync.v1.PushRequest\x1a\x1b.toolbridge.sync.v1.PushAck\"\x000\x01\x12K\n" +
 	"\x04Pull\x12\x1f.toolbridge.sync.v1.PullRequest\x1a .toolbridge.sync.v1.PullResponse\"\x00B;Z9github.com/erauner12/toolbridge-api/gen/go/sync/v1;syncv1b\x06proto3"
 
 var (
@@ -1211,40 +1263,54 @@ var file_sync_v1_sync_proto_depIdxs = []int32{
 	15, // 18: toolbridge.sync.v1.SyncService.WipeAccount:input_type -> toolbridge.sync.v1.WipeAccountRequest
 	17, // 19: toolbridge.sync.v1.SyncService.GetSyncState:input_type -> toolbridge.sync.v1.GetSyncStateRequest
 	0,  // 20: toolbridge.sync.v1.NoteSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 21: toolbridge.sync.v1.NoteSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 22: toolbridge.sync.v1.TaskSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 23: toolbridge.sync.v1.TaskSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 24: toolbridge.sync.v1.CommentSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 25: toolbridge.sync.v1.CommentSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 26: toolbridge.sync.v1.ChatSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 27: toolbridge.sync.v1.ChatSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 28: toolbridge.sync.v1.ChatMessageSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 29: toolbridge.sync.v1.ChatMessageSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 30: toolbridge.sync.v1.TaskListSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 31: toolbridge.sync.v1.TaskListSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	0,  // 32: toolbridge.sync.v1.TaskListCategorySyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
-	3,  // 33: toolbridge.sync.v1.TaskListCategorySyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
-	6,  // 34: toolbridge.sync.v1.SyncService.GetServerInfo:output_type -> toolbridge.sync.v1.ServerInfo
-	12, // 35: toolbridge.sync.v1.SyncService.BeginSession:output_type -> toolbridge.sync.v1.SyncSession
-	14, // 36: toolbridge.sync.v1.SyncService.EndSession:output_type -> toolbridge.sync.v1.EndSessionResponse
-	16, // 37: toolbridge.sync.v1.SyncService.WipeAccount:output_type -> toolbridge.sync.v1.WipeResult
-	18, // 38: toolbridge.sync.v1.SyncService.GetSyncState:output_type -> toolbridge.sync.v1.UserSyncState
-	1,  // 39: toolbridge.sync.v1.NoteSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 40: toolbridge.sync.v1.NoteSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 41: toolbridge.sync.v1.TaskSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 42: toolbridge.sync.v1.TaskSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 43: toolbridge.sync.v1.CommentSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 44: toolbridge.sync.v1.CommentSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 45: toolbridge.sync.v1.ChatSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 46: toolbridge.sync.v1.ChatSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 47: toolbridge.sync.v1.ChatMessageSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 48: toolbridge.sync.v1.ChatMessageSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 49: toolbridge.sync.v1.TaskListSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 50: toolbridge.sync.v1.TaskListSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	1,  // 51: toolbridge.sync.v1.TaskListCategorySyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
-	4,  // 52: toolbridge.sync.v1.TaskListCategorySyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
-	34, // [34:53] is the sub-list for method output_type
-	15, // [15:34] is the sub-list for method input_type
+	0,  // 21: toolbridge.sync.v1.NoteSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 22: toolbridge.sync.v1.NoteSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 23: toolbridge.sync.v1.TaskSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 24: toolbridge.sync.v1.TaskSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 25: toolbridge.sync.v1.TaskSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 26: toolbridge.sync.v1.CommentSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 27: toolbridge.sync.v1.CommentSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 28: toolbridge.sync.v1.CommentSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 29: toolbridge.sync.v1.ChatSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 30: toolbridge.sync.v1.ChatSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 31: toolbridge.sync.v1.ChatSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 32: toolbridge.sync.v1.ChatMessageSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 33: toolbridge.sync.v1.ChatMessageSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 34: toolbridge.sync.v1.ChatMessageSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 35: toolbridge.sync.v1.TaskListSyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 36: toolbridge.sync.v1.TaskListSyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 37: toolbridge.sync.v1.TaskListSyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	0,  // 38: toolbridge.sync.v1.TaskListCategorySyncService.Push:input_type -> toolbridge.sync.v1.PushRequest
+	0,  // 39: toolbridge.sync.v1.TaskListCategorySyncService.PushStream:input_type -> toolbridge.sync.v1.PushRequest
+	3,  // 40: toolbridge.sync.v1.TaskListCategorySyncService.Pull:input_type -> toolbridge.sync.v1.PullRequest
+	6,  // 41: toolbridge.sync.v1.SyncService.GetServerInfo:output_type -> toolbridge.sync.v1.ServerInfo
+	12, // 42: toolbridge.sync.v1.SyncService.BeginSession:output_type -> toolbridge.sync.v1.SyncSession
+	14, // 43: toolbridge.sync.v1.SyncService.EndSession:output_type -> toolbridge.sync.v1.EndSessionResponse
+	16, // 44: toolbridge.sync.v1.SyncService.WipeAccount:output_type -> toolbridge.sync.v1.WipeResult
+	18, // 45: toolbridge.sync.v1.SyncService.GetSyncState:output_type -> toolbridge.sync.v1.UserSyncState
+	1,  // 46: toolbridge.sync.v1.NoteSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	2,  // 47: toolbridge.sync.v1.NoteSyncService.PushStream:output_type -> toolbridge.sync.v1.PushAck
+	4,  // 48: toolbridge.sync.v1.NoteSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 49: toolbridge.sync.v1.TaskSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	2,  // 50: toolbridge.sync.v1.TaskSyncService.PushStream:output_type -> toolbridge.sync.v1.PushAck
+	4,  // 51: toolbridge.sync.v1.TaskSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 52: toolbridge.sync.v1.CommentSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	2,  // 53: toolbridge.sync.v1.CommentSyncService.PushStream:output_type -> toolbridge.sync.v1.PushAck
+	4,  // 54: toolbridge.sync.v1.CommentSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 55: toolbridge.sync.v1.ChatSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	2,  // 56: toolbridge.sync.v1.ChatSyncService.PushStream:output_type -> toolbridge.sync.v1.PushAck
+	4,  // 57: toolbridge.sync.v1.ChatSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 58: toolbridge.sync.v1.ChatMessageSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	2,  // 59: toolbridge.sync.v1.ChatMessageSyncService.PushStream:output_type -> toolbridge.sync.v1.PushAck
+	4,  // 60: toolbridge.sync.v1.ChatMessageSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 61: toolbridge.sync.v1.TaskListSyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	2,  // 62: toolbridge.sync.v1.TaskListSyncService.PushStream:output_type -> toolbridge.sync.v1.PushAck
+	4,  // 63: toolbridge.sync.v1.TaskListSyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	1,  // 64: toolbridge.sync.v1.TaskListCategorySyncService.Push:output_type -> toolbridge.sync.v1.PushResponse
+	2,  // 65: toolbridge.sync.v1.TaskListCategorySyncService.PushStream:output_type -> toolbridge.sync.v1.PushAck
+	4,  // 66: toolbridge.sync.v1.TaskListCategorySyncService.Pull:output_type -> toolbridge.sync.v1.PullResponse
+	41, // [41:67] is the sub-list for method output_type
+	15, // [15:41] is the sub-list for method input_type
 	15, // [15:15] is the sub-list for extension type_name
 	15, // [15:15] is the sub-list for extension extendee
 	0,  // [0:15] is the sub-list for field type_name
@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
@@ -59,6 +60,11 @@ type JWTCfg struct {
 	// This enables secure distribution of the public key to downstream services for validation.
 	BackendRSAPrivateKeyPEM string // PEM-encoded RSA private key for backend tokens (optional)
 	BackendKeyID            string // kid used for backend tokens (must be non-empty if private key is set)
+
+	// Leeway is the clock skew tolerated between the token issuer and this server when
+	// checking exp, nbf and iat (0 = none). It applies to every token type ValidateToken
+	// accepts: HS256, backend and IdP RS256, and MCP OAuth tokens.
+	Leeway time.Duration
 }
 
 // JWKS caching for upstream IdP public keys
@@ -264,7 +270,7 @@ func ValidateToken(tokenString string, cfg JWTCfg) (string, jwt.MapClaims, error
 		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
 		}
-	})
+	}, jwt.WithLeeway(cfg.Leeway), jwt.WithIssuedAt())
 
 	if err != nil || !t.Valid {
 		return "", nil, fmt.Errorf("jwt validation failed: %w", err)
@@ -339,7 +345,7 @@ func ValidateToken(tokenString string, cfg JWTCfg) (string, jwt.MapClaims, error
 				}
 			}
 			if !audValid {
-				return "", nil, fmt.Errorf("invalid audience: expected one of %v, got %v", acceptedAuds, claims["aud"])
+				return "", nil, fmt.Errorf("%w: expected one of %v, got %v", ErrInvalidAudience, acceptedAuds, claims["aud"])
 			}
 		}
 	}
@@ -459,12 +465,34 @@ func SignBackendToken(claims jwt.MapClaims, cfg JWTCfg) (string, error) {
 	return token.SignedString([]byte(cfg.HS256Secret))
 }
 
+// ErrInvalidAudience marks a token that is valid but not issued for this API.
+// HTTP answers it with 403 rather than 401: signing in again will not help.
+var ErrInvalidAudience = errors.New("invalid audience")
+
+// writeUnauthorized answers 401 with an RFC 6750 Bearer challenge so clients know to
+// (re)authenticate. errCode is empty when no credentials were sent, otherwise "invalid_token".
+func writeUnauthorized(w http.ResponseWriter, errCode, description string) {
+	challenge := `Bearer realm="toolbridge"`
+	if errCode != "" {
+		challenge += fmt.Sprintf(`, error=%q, error_description=%q`, errCode, description)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// writeForbidden answers 403 for an authenticated token that lacks the required
+// audience or scope (RFC 6750 insufficient_scope); re-authenticating will not help
+func writeForbidden(w http.ResponseWriter, description string) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="toolbridge", error="insufficient_scope", error_description=%q`, description))
+	http.Error(w, "forbidden", http.StatusForbidden)
+}
+
 // Middleware creates HTTP middleware for JWT authentication
 // Supports three modes:
 // 1. Production RS256: Upstream IdP Bearer tokens with RS256 signature validation
 // 2. Development HS256: Bearer tokens with HMAC secret (for testing)
 // 3. Development X-Debug-Sub: Bypass JWT validation (ONLY when DevMode=true)
-func Middleware(db *pgxpool.Pool, cfg JWTCfg) func(http.Handler) http.Handler {
+func Middleware(pool *pgxpool.Pool, cfg JWTCfg) func(http.Handler) http.Handler {
 	// SECURITY GUARD: Prevent DevMode from being enabled in production
 	// This is a hard fail to prevent accidental auth bypass in production deployments
 	// Matches common production ENV values: "prod", "production", "prd"
@@ -506,7 +534,11 @@ func Middleware(db *pgxpool.Pool, cfg JWTCfg) func(http.Handler) http.Handler {
 				sub, claims, err = ValidateToken(tok, cfg)
 				if err != nil {
 					log.Warn().Err(err).Msg("jwt validation failed")
-					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					if errors.Is(err, ErrInvalidAudience) {
+						writeForbidden(w, "token audience not accepted")
+						return
+					}
+					writeUnauthorized(w, "invalid_token", "token is missing, expired or invalid")
 					return
 				}
 			}
@@ -514,24 +546,32 @@ func Middleware(db *pgxpool.Pool, cfg JWTCfg) func(http.Handler) http.Handler {
 			// Require subject (either from JWT or debug header)
 			if sub == "" {
 				log.Warn().Msg("missing subject (no JWT sub or X-Debug-Sub header)")
-				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				writeUnauthorized(w, "", "")
 				return
 			}
 
 			// Upsert app_user by subject (creates user on first auth)
+			// Idempotent, so it is retried across brief connection loss (see db.Retry)
 			var userID string
-			if err := db.QueryRow(r.Context(),
-				`INSERT INTO app_user (sub) VALUES ($1)
-				 ON CONFLICT (sub) DO UPDATE SET sub = excluded.sub
-				 RETURNING id`, sub).Scan(&userID); err != nil {
+			if err := db.Retry(r.Context(), db.DefaultRetry, func() error {
+				return pool.QueryRow(r.Context(),
+					`INSERT INTO app_user (sub) VALUES ($1)
+					 ON CONFLICT (sub) DO UPDATE SET sub = excluded.sub
+					 RETURNING id`, sub).Scan(&userID)
+			}); err != nil {
 				log.Error().Err(err).Str("sub", sub).Msg("failed to upsert user")
 				http.Error(w, "server error", http.StatusInternalServerError)
 				return
 			}
 
 			// Add user ID and subject to request context
-			ctx := context.WithValue(r.Context(), CtxUserID, userID)
+			ctx := WithUserID(r.Context(), userID)
 			ctx = context.WithValue(ctx, CtxSubject, sub)
+			if claims != nil {
+				ctx = WithScopes(ctx, TokenScopes(claims))
+			} else {
+				ctx = WithAllScopes(ctx)
+			}
 
 			// Extract tenant from JWT claims if configured and not already set by header middleware
 			// Precedence: X-TB-Tenant-ID header (if present) > JWT tenant claim > no tenant
@@ -577,6 +617,28 @@ func UserID(ctx context.Context) string {
 	return ""
 }
 
+// userSlot is filled in with the authenticated user ID (see TrackUserID)
+type userSlot struct{ id string }
+
+const ctxUserSlot ctxKey = "user_slot"
+
+// WithUserID records the authenticated user ID in ctx
+// Also fills in the slot of an enclosing TrackUserID, so outer middleware sees the user.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	if slot, ok := ctx.Value(ctxUserSlot).(*userSlot); ok {
+		slot.id = userID
+	}
+	return context.WithValue(ctx, CtxUserID, userID)
+}
+
+// TrackUserID lets middleware that runs before authentication learn who a request ran as
+// The returned func reports the user ID authentication recorded ("" if none), and is
+// meant to be called once the handler has returned (e.g. for access logs).
+func TrackUserID(ctx context.Context) (context.Context, func() string) {
+	slot := &userSlot{}
+	return context.WithValue(ctx, ctxUserSlot, slot), func() string { return slot.id }
+}
+
 // Subject extracts the OIDC subject claim (JWT sub, WorkOS user ID) from request context
 // Returns empty string if not authenticated (should never happen after middleware)
 // Use this for WorkOS API calls, not UserID() which returns the database primary key
@@ -5,6 +5,9 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -841,3 +844,123 @@ func pemEncode(data []byte, blockType string) string {
 	}
 	return string(pem.EncodeToMemory(block))
 }
+
+// TestMiddleware_AuthErrors tests RFC 6750 responses: 401 + challenge for missing or
+// invalid tokens, 403 for valid tokens issued for another audience
+func TestMiddleware_AuthErrors(t *testing.T) {
+	cfg := JWTCfg{HS256Secret: "test-secret", Audience: "https://api.example.com"}
+	sign := func(claims jwt.MapClaims) string {
+		tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.HS256Secret))
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return tok
+	}
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantChallenge string // substring of WWW-Authenticate
+	}{
+		{"missing token", "", http.StatusUnauthorized, `Bearer realm="toolbridge"`},
+		{"malformed token", "Bearer not-a-jwt", http.StatusUnauthorized, `error="invalid_token"`},
+		{"expired token", "Bearer " + sign(jwt.MapClaims{"sub": "u1", "aud": "https://api.example.com", "exp": time.Now().Add(-time.Hour).Unix()}), http.StatusUnauthorized, `error="invalid_token"`},
+		{"wrong audience", "Bearer " + sign(jwt.MapClaims{"sub": "u1", "aud": "https://other.example.com", "exp": exp}), http.StatusForbidden, `error="insufficient_scope"`},
+	}
+
+	handler := Middleware(nil, cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler should not be reached")
+	}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/notes", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); !strings.Contains(got, tt.wantChallenge) {
+				t.Errorf("WWW-Authenticate = %q, want it to contain %q", got, tt.wantChallenge)
+			}
+		})
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		expected []string
+	}{
+		{"scope string", jwt.MapClaims{"scope": "sync:read  sync:write"}, []string{"sync:read", "sync:write"}},
+		{"scp array", jwt.MapClaims{"scp": []any{"sync:read", 42}}, []string{"sync:read"}},
+		{"scp string", jwt.MapClaims{"scp": "sync:write"}, []string{"sync:write"}},
+		{"no scopes", jwt.MapClaims{"sub": "user"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TokenScopes(tt.claims)
+			if strings.Join(got, " ") != strings.Join(tt.expected, " ") {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+
+			ctx := WithScopes(httptest.NewRequest("GET", "/", nil).Context(), got)
+			for _, s := range tt.expected {
+				if !HasScope(ctx, s) {
+					t.Errorf("Expected HasScope(%q) to be true", s)
+				}
+			}
+			if HasScope(ctx, "admin") {
+				t.Error("Expected HasScope(admin) to be false")
+			}
+		})
+	}
+}
+
+// TestValidateToken_Leeway checks exp/nbf/iat are compared with the configured clock skew allowance.
+func TestValidateToken_Leeway(t *testing.T) {
+	secret := "test-hmac-secret"
+	now := time.Now()
+
+	sign := func(claims jwt.MapClaims) string {
+		t.Helper()
+		claims["sub"] = "user_123"
+		claims["token_type"] = "backend"
+		tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return tokenString
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		leeway time.Duration
+		wantOK bool
+	}{
+		{"expired within leeway", jwt.MapClaims{"exp": now.Add(-30 * time.Second).Unix()}, time.Minute, true},
+		{"expired without leeway", jwt.MapClaims{"exp": now.Add(-30 * time.Second).Unix()}, 0, false},
+		{"expired beyond leeway", jwt.MapClaims{"exp": now.Add(-2 * time.Minute).Unix()}, time.Minute, false},
+		{"not yet valid within leeway", jwt.MapClaims{"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(30 * time.Second).Unix()}, time.Minute, true},
+		{"not yet valid beyond leeway", jwt.MapClaims{"exp": now.Add(time.Hour).Unix(), "nbf": now.Add(2 * time.Minute).Unix()}, time.Minute, false},
+		{"issued in the future within leeway", jwt.MapClaims{"exp": now.Add(time.Hour).Unix(), "iat": now.Add(30 * time.Second).Unix()}, time.Minute, true},
+		{"issued in the future beyond leeway", jwt.MapClaims{"exp": now.Add(time.Hour).Unix(), "iat": now.Add(2 * time.Minute).Unix()}, time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ValidateToken(sign(tt.claims), JWTCfg{HS256Secret: secret, Leeway: tt.leeway})
+			if (err == nil) != tt.wantOK {
+				t.Errorf("err = %v, want ok=%v", err, tt.wantOK)
+			}
+		})
+	}
+}
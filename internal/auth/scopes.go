@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Default OAuth scopes for sync reads and writes, and for forced (LWW-bypassing) pushes
+const (
+	DefaultReadScope       = "sync:read"
+	DefaultWriteScope      = "sync:write"
+	DefaultForceWriteScope = "sync:force"
+)
+
+// CtxScopes holds the authenticated token's scopes (see WithScopes)
+const CtxScopes ctxKey = "scopes"
+
+// ScopeCfg configures scope-based authorization
+// When Enforce is false every authenticated token may read and write. ForceWriteScope
+// is checked either way: forced pushes can undo convergence, so they are never open.
+type ScopeCfg struct {
+	Enforce         bool
+	ReadScope       string // Required for reads (pulls, GETs)
+	WriteScope      string // Required for mutations (pushes, REST writes, wipes)
+	ForceWriteScope string // Required for forced pushes (?force=true, PushRequest.force); "" = disabled
+}
+
+// grantedScopes is the scope set stored in the request context
+type grantedScopes struct {
+	all bool // dev-mode X-Debug-Sub requests carry no token and are not scope-restricted
+	set map[string]bool
+}
+
+// TokenScopes returns the scopes granted by a token: the space-separated "scope" claim
+// (RFC 8693/9068) or the "scp" claim (a string or an array, as some IdPs issue it)
+func TokenScopes(claims jwt.MapClaims) []string {
+	var scopes []string
+	for _, key := range []string{"scope", "scp"} {
+		switch v := claims[key].(type) {
+		case string:
+			scopes = append(scopes, strings.Fields(v)...)
+		case []any:
+			for _, s := range v {
+				if str, ok := s.(string); ok {
+					scopes = append(scopes, str)
+				}
+			}
+		}
+	}
+	return scopes
+}
+
+// WithScopes records the authenticated token's scopes in ctx
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	set := make(map[string]bool, len(scopes))
+	for _, s := range scopes {
+		set[s] = true
+	}
+	return context.WithValue(ctx, CtxScopes, grantedScopes{set: set})
+}
+
+// WithAllScopes marks a request that authenticated without a token (dev-mode debug subject)
+func WithAllScopes(ctx context.Context) context.Context {
+	return context.WithValue(ctx, CtxScopes, grantedScopes{all: true})
+}
+
+// HasScope reports whether the authenticated request was granted scope
+// Requests without recorded scopes (not authenticated) have none.
+func HasScope(ctx context.Context, scope string) bool {
+	granted, ok := ctx.Value(CtxScopes).(grantedScopes)
+	return ok && (granted.all || granted.set[scope])
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// RetryPolicy bounds how transient connection errors are retried
+type RetryPolicy struct {
+	Attempts  int           // total attempts including the first (1 = no retry)
+	BaseDelay time.Duration // delay before the first retry; doubles after each attempt
+}
+
+// DefaultRetry rides out brief connection loss (e.g. a managed Postgres failover or
+// restart) without holding requests for long: 3 attempts over ~150ms.
+var DefaultRetry = RetryPolicy{Attempts: 3, BaseDelay: 50 * time.Millisecond}
+
+// IsTransient reports whether err is a connection-level failure that is safe to retry:
+// the connection could not be established, the statement never reached the server, or
+// the server rejected it because it is shutting down or starting up. Query errors
+// (constraint violations, syntax, no rows) and context cancellation are not transient.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case strings.HasPrefix(pgErr.Code, "08"): // connection_exception class
+			return true
+		case pgErr.Code == "57P01", pgErr.Code == "57P02", pgErr.Code == "57P03": // admin/crash shutdown, cannot_connect_now
+			return true
+		}
+		return false
+	}
+
+	// Failed before any bytes were sent, so the statement cannot have run
+	return pgconn.SafeToRetry(err)
+}
+
+// Retry runs fn, retrying transient connection errors (see IsTransient) with
+// exponential backoff. fn must be safe to repeat: a whole transaction or an
+// idempotent statement. The last error is returned when attempts run out.
+func Retry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	delay := policy.BaseDelay
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || !IsTransient(err) || attempt >= policy.Attempts {
+			return err
+		}
+
+		log.Ctx(ctx).Warn().Err(err).Int("attempt", attempt).Dur("backoff", delay).Msg("transient database error, retrying")
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// Begin starts a transaction, retrying transient connection errors.
+// Safe because nothing has run yet; use it in place of pool.Begin.
+// The transaction supports AfterCommit callbacks.
+func Begin(ctx context.Context, pool *pgxpool.Pool) (pgx.Tx, error) {
+	var tx pgx.Tx
+	err := Retry(ctx, DefaultRetry, func() error {
+		var err error
+		tx, err = pool.Begin(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &hookTx{Tx: tx}, nil
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// hookTx is the transaction returned by Begin: it runs the callbacks registered with
+// AfterCommit once its commit succeeds. Rollbacks drop them.
+type hookTx struct {
+	pgx.Tx
+	afterCommit []func()
+}
+
+// Commit commits the transaction, then runs its AfterCommit callbacks in order
+func (t *hookTx) Commit(ctx context.Context) error {
+	if err := t.Tx.Commit(ctx); err != nil {
+		return err
+	}
+	for _, fn := range t.afterCommit {
+		fn()
+	}
+	t.afterCommit = nil
+	return nil
+}
+
+// AfterCommit registers fn to run once tx commits, for side effects that must not be
+// observed before the write is (e.g. cache invalidation). Transactions not started by
+// Begin, savepoints and a nil tx (autocommit writes) run fn immediately.
+func AfterCommit(tx pgx.Tx, fn func()) {
+	if ht, ok := tx.(*hookTx); ok {
+		ht.afterCommit = append(ht.afterCommit, fn)
+		return
+	}
+	fn()
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// commitTx is a pgx.Tx whose Commit returns err (other methods are not used)
+type commitTx struct {
+	pgx.Tx
+	err error
+}
+
+func (t *commitTx) Commit(context.Context) error { return t.err }
+
+func TestAfterCommit(t *testing.T) {
+	ctx := context.Background()
+
+	var ran []string
+	tx := &hookTx{Tx: &commitTx{}}
+	AfterCommit(tx, func() { ran = append(ran, "a") })
+	AfterCommit(tx, func() { ran = append(ran, "b") })
+	if len(ran) != 0 {
+		t.Fatalf("Callbacks ran before commit: %v", ran)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+	if len(ran) != 2 || ran[0] != "a" || ran[1] != "b" {
+		t.Errorf("Expected callbacks a, b after commit, got %v", ran)
+	}
+
+	// A failed commit drops them
+	ran = nil
+	failed := &hookTx{Tx: &commitTx{err: errors.New("boom")}}
+	AfterCommit(failed, func() { ran = append(ran, "c") })
+	if err := failed.Commit(ctx); err == nil {
+		t.Fatal("Expected commit error")
+	}
+	if len(ran) != 0 {
+		t.Errorf("Callbacks ran after a failed commit: %v", ran)
+	}
+
+	// Without a hook-capable transaction they run immediately
+	AfterCommit(nil, func() { ran = append(ran, "d") })
+	if len(ran) != 1 || ran[0] != "d" {
+		t.Errorf("Expected immediate callback for nil tx, got %v", ran)
+	}
+}
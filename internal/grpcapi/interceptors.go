@@ -5,17 +5,25 @@ package grpcapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/loadest"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	"github.com/erauner12/toolbridge-api/internal/session"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
@@ -72,6 +80,7 @@ func AuthInterceptor(db *pgxpool.Pool, cfg auth.JWTCfg) grpc.UnaryServerIntercep
 		}
 
 		var subject string
+		var claims jwt.MapClaims
 
 		// 2. Check for debug mode (X-Debug-Sub header)
 		if cfg.DevMode {
@@ -98,9 +107,12 @@ func AuthInterceptor(db *pgxpool.Pool, cfg auth.JWTCfg) grpc.UnaryServerIntercep
 
 			// Validate token using shared validation logic (supports RS256 and HS256)
 			var err error
-			subject, _, err = auth.ValidateToken(tokenString, cfg)
+			subject, claims, err = auth.ValidateToken(tokenString, cfg)
 			if err != nil {
 				logger.Warn().Err(err).Msg("jwt validation failed")
+				if errors.Is(err, auth.ErrInvalidAudience) {
+					return nil, status.Error(codes.PermissionDenied, "token audience not accepted")
+				}
 				return nil, status.Error(codes.Unauthenticated, "invalid token")
 			}
 		}
@@ -120,8 +132,13 @@ func AuthInterceptor(db *pgxpool.Pool, cfg auth.JWTCfg) grpc.UnaryServerIntercep
 			return nil, status.Error(codes.Internal, "user lookup failed")
 		}
 
-		// 5. Add userID to context
-		ctx = context.WithValue(ctx, auth.CtxUserID, userID)
+		// 5. Add userID and token scopes to context
+		ctx = auth.WithUserID(ctx, userID)
+		if claims != nil {
+			ctx = auth.WithScopes(ctx, auth.TokenScopes(claims))
+		} else {
+			ctx = auth.WithAllScopes(ctx)
+		}
 
 		logger.Debug().Str("user_id", userID).Str("subject", subject).Msg("authenticated")
 
@@ -129,6 +146,33 @@ func AuthInterceptor(db *pgxpool.Pool, cfg auth.JWTCfg) grpc.UnaryServerIntercep
 	}
 }
 
+// ScopeInterceptor requires the read scope for Pull/GetSyncState and the write scope for
+// Push/PushStream/WipeAccount (PermissionDenied otherwise). Mirrors HTTP ScopeRequired; other RPCs
+// (server info, sessions) are allowed for any authenticated token.
+func ScopeInterceptor(cfg auth.ScopeCfg) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !cfg.Enforce {
+			return handler(ctx, req)
+		}
+
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+
+		scope := ""
+		switch method {
+		case "Pull", "GetSyncState":
+			scope = cfg.ReadScope
+		case "Push", "PushStream", "WipeAccount":
+			scope = cfg.WriteScope
+		}
+		if scope != "" && !auth.HasScope(ctx, scope) {
+			log.Ctx(ctx).Warn().Str("method", info.FullMethod).Str("scope", scope).Msg("token lacks required scope")
+			return nil, status.Errorf(codes.PermissionDenied, "token lacks required scope %q", scope)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
 // SessionInterceptor validates X-Sync-Session header
 // Mirrors HTTP SessionRequired middleware behavior
 func SessionInterceptor() grpc.UnaryServerInterceptor {
@@ -144,8 +188,12 @@ func SessionInterceptor() grpc.UnaryServerInterceptor {
 
 		// 1. Read X-Sync-Session from metadata
 		md, _ := metadata.FromIncomingContext(ctx)
-		sessionHeaders := md.Get("x-sync-session")
-		if len(sessionHeaders) == 0 || sessionHeaders[0] == "" {
+		sessionID, err := singleMetadataValue(md, "x-sync-session", maxSessionIDLen)
+		if err != nil {
+			logger.Warn().Err(err).Str("method", info.FullMethod).Msg("malformed X-Sync-Session header")
+			return nil, err
+		}
+		if sessionID == "" {
 			logger.Warn().
 				Str("method", info.FullMethod).
 				Msg("missing X-Sync-Session header")
@@ -153,8 +201,6 @@ func SessionInterceptor() grpc.UnaryServerInterceptor {
 				"X-Sync-Session header required. Call BeginSession first.")
 		}
 
-		sessionID := sessionHeaders[0]
-
 		// 2. Validate session exists and is not expired
 		sess, ok := sessionStore.GetSession(sessionID)
 		if !ok {
@@ -199,16 +245,20 @@ func EpochInterceptor(db *pgxpool.Pool) grpc.UnaryServerInterceptor {
 
 		// 1. Read X-Sync-Epoch from metadata
 		md, _ := metadata.FromIncomingContext(ctx)
-		epochHeaders := md.Get("x-sync-epoch")
-		if len(epochHeaders) == 0 || epochHeaders[0] == "" {
+		epochHeader, err := singleMetadataValue(md, "x-sync-epoch", maxEpochLen)
+		if err != nil {
+			logger.Warn().Err(err).Msg("malformed X-Sync-Epoch header")
+			return nil, err
+		}
+		if epochHeader == "" {
 			logger.Warn().Msg("missing X-Sync-Epoch header")
 			return nil, status.Error(codes.FailedPrecondition, "X-Sync-Epoch header required")
 		}
 
-		clientEpoch, err := strconv.Atoi(epochHeaders[0])
-		if err != nil {
-			logger.Warn().Str("epoch_header", epochHeaders[0]).Msg("invalid epoch format")
-			return nil, status.Error(codes.InvalidArgument, "X-Sync-Epoch must be an integer")
+		clientEpoch, err := strconv.Atoi(epochHeader)
+		if err != nil || clientEpoch < 0 {
+			logger.Warn().Str("epoch_header", epochHeader).Msg("invalid epoch format")
+			return nil, status.Error(codes.InvalidArgument, "X-Sync-Epoch must be a non-negative integer")
 		}
 
 		// 2. Query server epoch
@@ -247,16 +297,56 @@ func EpochInterceptor(db *pgxpool.Pool) grpc.UnaryServerInterceptor {
 
 			// Return error with server epoch in message
 			// Client must detect this and trigger full reset
-			return nil, status.Error(codes.FailedPrecondition,
-				fmt.Sprintf("Epoch mismatch: server=%d, client=%d. Local data must be reset.", serverEpoch, clientEpoch))
+			return nil, resyncRequiredError(serverEpoch, clientEpoch)
 		}
 
 		logger.Debug().Int("epoch", serverEpoch).Msg("epoch validated")
 
+		// Echo the server epoch in response headers (mirrors HTTP X-Sync-Epoch)
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-sync-epoch", strconv.Itoa(serverEpoch)))
+
 		return handler(ctx, req)
 	}
 }
 
+// resyncRequiredError is the FailedPrecondition for a stale epoch, carrying an ErrorInfo
+// with reason session.ResyncRequiredCode (the HTTP 409 body's "code") and the server epoch
+func resyncRequiredError(serverEpoch, clientEpoch int) error {
+	st := status.New(codes.FailedPrecondition,
+		fmt.Sprintf("Epoch mismatch: server=%d, client=%d. Local data must be reset.", serverEpoch, clientEpoch))
+	withInfo, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   session.ResyncRequiredCode,
+		Domain:   "toolbridge",
+		Metadata: map[string]string{"epoch": strconv.Itoa(serverEpoch)},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return withInfo.Err()
+}
+
+// Upper bounds for sync metadata values; anything longer is not a value we issued
+const (
+	maxSessionIDLen = 128
+	maxEpochLen     = 19 // digits in math.MaxInt64
+)
+
+// singleMetadataValue returns the value of a metadata key that must be sent at most once
+// Returns "" if the key is absent, and InvalidArgument if it is repeated or oversized,
+// so an ambiguous header can't select a session or epoch by accident.
+func singleMetadataValue(md metadata.MD, key string, maxLen int) (string, error) {
+	values := md.Get(key)
+	switch {
+	case len(values) == 0:
+		return "", nil
+	case len(values) > 1:
+		return "", status.Errorf(codes.InvalidArgument, "%s must be sent once, got %d values", key, len(values))
+	case len(values[0]) > maxLen:
+		return "", status.Errorf(codes.InvalidArgument, "%s exceeds %d bytes", key, maxLen)
+	}
+	return values[0], nil
+}
+
 // ChainUnaryServer creates a single interceptor from a chain of interceptors
 // Interceptors are executed in the order they are provided
 func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
@@ -274,6 +364,30 @@ func ChainUnaryServer(interceptors ...grpc.UnaryServerInterceptor) grpc.UnarySer
 	}
 }
 
+// StreamServerInterceptor adapts a unary interceptor to server-streaming RPCs (PushStream)
+// The interceptor runs with a nil request against the stream's context, and the context
+// it passes on becomes the stream's, so auth, session and epoch checks apply unchanged.
+// Only interceptors that never inspect the request or response can be adapted.
+func StreamServerInterceptor(unary grpc.UnaryServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		unaryInfo := &grpc.UnaryServerInfo{Server: srv, FullMethod: info.FullMethod}
+		_, err := unary(ss.Context(), nil, unaryInfo, func(ctx context.Context, _ interface{}) (interface{}, error) {
+			return nil, handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+		})
+		return err
+	}
+}
+
+// contextServerStream overrides a stream's context with one derived by an interceptor
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
+
 // isSessionExempt returns true if the method does not require a session
 func isSessionExempt(method string) bool {
 	exempt := []string{
@@ -315,20 +429,194 @@ func RecoveryInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
-// LoggingInterceptor logs request details (simple version)
-func LoggingInterceptor() grpc.UnaryServerInterceptor {
+// apiVersion is the sync API version (kept in sync with httpapi.APIVersion)
+const apiVersion = "1.1"
+
+// ServerMetadataInterceptor sends server time and API version as response headers
+// gRPC counterpart of the HTTP {data, meta} envelope: clients read these from the
+// header metadata to detect clock skew without an extra GetServerInfo call.
+// The epoch is added by EpochInterceptor on epoch-validated RPCs.
+func ServerMetadataInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		logger := log.Ctx(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(
+			"x-server-time-ms", strconv.FormatInt(syncx.NowMs(), 10),
+			"x-api-version", apiVersion,
+		))
+		return handler(ctx, req)
+	}
+}
+
+// LoadInterceptor counts every RPC toward the server load estimate reported in
+// GetServerInfo hints. Share the estimator with the HTTP server so both transports
+// contribute to the same load figure.
+func LoadInterceptor(est *loadest.Estimator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		est.Record()
+		return handler(ctx, req)
+	}
+}
+
+// UserRateLimiter is a per-user rate limiter (implemented by httpapi.RateLimiter and httpapi.RedisLimiter)
+type UserRateLimiter interface {
+	Allow(userID string) (allowed bool, remaining int, nextTokenTime, fullResetTime time.Time)
+}
+
+// SessionRateLimitInterceptor limits BeginSession per user (ResourceExhausted when exceeded)
+// Mirrors HTTP SessionRateLimitMiddleware; pass the same limiter so both transports share
+// one budget. Must run after AuthInterceptor.
+func SessionRateLimitInterceptor(limiter UserRateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod != "/toolbridge.sync.v1.SyncService/BeginSession" {
+			return handler(ctx, req)
+		}
 
-		// Get user ID from context if available
 		userID := auth.UserID(ctx)
+		allowed, _, nextTokenTime, _ := limiter.Allow(userID)
+		if !allowed {
+			retryAfter := int(time.Until(nextTokenTime).Seconds())
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+			log.Ctx(ctx).Warn().Str("userId", userID).Int("retryAfter", retryAfter).Msg("session creation rate limit exceeded")
+			return nil, status.Errorf(codes.ResourceExhausted, "too many new sessions; retry after %d seconds", retryAfter)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// PushItemLimitInterceptor rejects a Push carrying more than maxItems items with
+// ResourceExhausted before any item is applied. Mirrors the HTTP push 413; the message
+// tells the client what batch size to split into. Must run after AuthInterceptor.
+func PushItemLimitInterceptor(maxItems int) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := checkPushItems(ctx, req, maxItems); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// PushItemLimitStreamInterceptor applies the same cap to PushStream, whose batch
+// arrives as the stream's single request message
+func PushItemLimitStreamInterceptor(maxItems int) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &pushLimitServerStream{ServerStream: ss, maxItems: maxItems})
+	}
+}
 
-		logger.Info().
+// pushLimitServerStream checks each received push request against the item cap
+type pushLimitServerStream struct {
+	grpc.ServerStream
+	maxItems int
+}
+
+func (s *pushLimitServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return checkPushItems(s.Context(), m, s.maxItems)
+}
+
+// checkPushItems returns ResourceExhausted for a PushRequest over maxItems items (0 = unlimited)
+func checkPushItems(ctx context.Context, req interface{}, maxItems int) error {
+	push, ok := req.(*syncv1.PushRequest)
+	if !ok || maxItems <= 0 || len(push.Items) <= maxItems {
+		return nil
+	}
+	log.Ctx(ctx).Warn().
+		Str("user_id", auth.UserID(ctx)).
+		Int("item_count", len(push.Items)).
+		Int("max_items", maxItems).
+		Msg("push rejected: too many items")
+	return status.Errorf(codes.ResourceExhausted,
+		"push has %d items, max %d per request; split it into batches of at most %d", len(push.Items), maxItems, maxItems)
+}
+
+// ForceWriteInterceptor handles PushRequest.force: the token must hold scope
+// (PermissionDenied otherwise, whether or not scope enforcement is on), and the push
+// then replaces stored items regardless of timestamps. Mirrors HTTP ForceWriteRequired.
+// Must run after AuthInterceptor.
+func ForceWriteInterceptor(scope string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := forceWriteContext(ctx, req, scope)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ForceWriteStreamInterceptor applies the same check to PushStream, whose request
+// arrives after the stream starts; the handler sees the forced context once it is received
+func ForceWriteStreamInterceptor(scope string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &forceWriteServerStream{ServerStream: ss, ctx: ss.Context(), scope: scope})
+	}
+}
+
+// forceWriteServerStream switches its context to the forced one when it receives a forced push
+type forceWriteServerStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	scope string
+}
+
+func (s *forceWriteServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *forceWriteServerStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	ctx, err := forceWriteContext(s.ctx, m, s.scope)
+	if err != nil {
+		return err
+	}
+	s.ctx = ctx
+	return nil
+}
+
+// forceWriteContext returns ctx marked for forced pushes when req is a PushRequest with
+// force set and the token holds scope ("" = forced pushes disabled)
+func forceWriteContext(ctx context.Context, req interface{}, scope string) (context.Context, error) {
+	push, ok := req.(*syncv1.PushRequest)
+	if !ok || !push.Force {
+		return ctx, nil
+	}
+	if scope == "" || !auth.HasScope(ctx, scope) {
+		log.Ctx(ctx).Warn().Str("scope", scope).Msg("forced push rejected: token lacks force-write scope")
+		return ctx, status.Errorf(codes.PermissionDenied, "forced pushes need scope %q", scope)
+	}
+	log.Ctx(ctx).Warn().Str("user_id", auth.UserID(ctx)).Msg("forced push: LWW bypassed")
+	return syncservice.WithForceWrite(ctx), nil
+}
+
+// LoggingInterceptor emits one structured access log line per RPC once it completes:
+// method, status code, latency and the authenticated user. Mirrors HTTP AccessLog.
+// Must run after CorrelationIDInterceptor so log.Ctx carries the correlation ID;
+// zerolog.Disabled turns it off.
+func LoggingInterceptor(level zerolog.Level) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if level == zerolog.Disabled {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		ctx, userID := auth.TrackUserID(ctx)
+
+		resp, err := handler(ctx, req)
+
+		log.Ctx(ctx).WithLevel(level).
 			Str("method", info.FullMethod).
-			Str("user_id", userID).
+			Str("code", status.Code(err).String()).
+			Float64("latency_ms", float64(time.Since(start).Microseconds())/1000).
+			Str("user_id", userID()).
 			Msg("grpc_call")
 
-		return handler(ctx, req)
+		return resp, err
 	}
 }
 
@@ -0,0 +1,234 @@
+//go:build grpc
+// +build grpc
+
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/session"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestSyncMetadataValidation(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/toolbridge.sync.v1.NoteSyncService/Push"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler should not be called for malformed metadata")
+		return nil, nil
+	}
+
+	tests := []struct {
+		name        string
+		interceptor grpc.UnaryServerInterceptor
+		md          metadata.MD
+	}{
+		{
+			name:        "duplicate session",
+			interceptor: SessionInterceptor(),
+			md:          metadata.Pairs("x-sync-session", "a", "x-sync-session", "b"),
+		},
+		{
+			name:        "oversized session",
+			interceptor: SessionInterceptor(),
+			md:          metadata.Pairs("x-sync-session", string(make([]byte, maxSessionIDLen+1))),
+		},
+		{
+			name:        "duplicate epoch",
+			interceptor: EpochInterceptor(nil),
+			md:          metadata.Pairs("x-sync-epoch", "1", "x-sync-epoch", "2"),
+		},
+		{
+			name:        "non-numeric epoch",
+			interceptor: EpochInterceptor(nil),
+			md:          metadata.Pairs("x-sync-epoch", "one"),
+		},
+		{
+			name:        "negative epoch",
+			interceptor: EpochInterceptor(nil),
+			md:          metadata.Pairs("x-sync-epoch", "-1"),
+		},
+		{
+			name:        "oversized epoch",
+			interceptor: EpochInterceptor(nil),
+			md:          metadata.Pairs("x-sync-epoch", "12345678901234567890"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), tt.md)
+			_, err := tt.interceptor(ctx, nil, info, handler)
+			if st, _ := status.FromError(err); st.Code() != codes.InvalidArgument {
+				t.Errorf("Expected InvalidArgument, got %v", err)
+			}
+		})
+	}
+}
+
+// fakeServerStream is a grpc.ServerStream carrying only a context
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	info := &grpc.StreamServerInfo{FullMethod: "/toolbridge.sync.v1.NoteSyncService/PushStream", IsServerStream: true}
+	ss := &fakeServerStream{ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-sync-session", "a", "x-sync-session", "b"))}
+
+	// Rejections from the unary interceptor end the stream before the handler runs
+	err := StreamServerInterceptor(SessionInterceptor())(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+		t.Fatal("handler should not be called for malformed metadata")
+		return nil
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+
+	// The context the unary interceptor passes on becomes the stream's context
+	type ctxKey struct{}
+	setValue := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if info.FullMethod != "/toolbridge.sync.v1.NoteSyncService/PushStream" {
+			t.Errorf("Expected stream method in info, got %s", info.FullMethod)
+		}
+		return handler(context.WithValue(ctx, ctxKey{}, "user-1"), req)
+	}
+	called := false
+	err = StreamServerInterceptor(setValue)(nil, ss, info, func(srv interface{}, stream grpc.ServerStream) error {
+		called = true
+		if got := stream.Context().Value(ctxKey{}); got != "user-1" {
+			t.Errorf("Expected stream context to carry interceptor value, got %v", got)
+		}
+		return status.Error(codes.Aborted, "handler error")
+	})
+	if !called {
+		t.Fatal("Expected handler to be called")
+	}
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("Expected handler error to propagate, got %v", err)
+	}
+}
+
+func TestPushItemLimitInterceptor(t *testing.T) {
+	items := func(n int) []*structpb.Struct {
+		out := make([]*structpb.Struct, n)
+		for i := range out {
+			out[i] = &structpb.Struct{}
+		}
+		return out
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/toolbridge.sync.v1.NoteSyncService/Push"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	limit := PushItemLimitInterceptor(2)
+
+	if _, err := limit(context.Background(), &syncv1.PushRequest{Items: items(2)}, info, handler); err != nil {
+		t.Errorf("Push at the cap should pass, got %v", err)
+	}
+	_, err := limit(context.Background(), &syncv1.PushRequest{Items: items(3)}, info, handler)
+	if status.Code(err) != codes.ResourceExhausted || !strings.Contains(status.Convert(err).Message(), "batches of at most 2") {
+		t.Errorf("Expected ResourceExhausted with batch guidance, got %v", err)
+	}
+	// Other requests are not inspected
+	if _, err := limit(context.Background(), &syncv1.PullRequest{}, info, handler); err != nil {
+		t.Errorf("Non-push request should pass, got %v", err)
+	}
+
+	// PushStream: the cap applies when the handler receives the request
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/toolbridge.sync.v1.NoteSyncService/PushStream", IsServerStream: true}
+	ss := &fakePushStream{fakeServerStream: fakeServerStream{ctx: context.Background()}, items: items(3)}
+	err = PushItemLimitStreamInterceptor(2)(nil, ss, streamInfo, func(srv interface{}, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&syncv1.PushRequest{})
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted for PushStream, got %v", err)
+	}
+}
+
+// fakePushStream delivers one PushRequest with the given items
+type fakePushStream struct {
+	fakeServerStream
+	items []*structpb.Struct
+	force bool
+}
+
+func (s *fakePushStream) RecvMsg(m interface{}) error {
+	m.(*syncv1.PushRequest).Items = s.items
+	m.(*syncv1.PushRequest).Force = s.force
+	return nil
+}
+
+func TestForceWriteInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/toolbridge.sync.v1.NoteSyncService/Push"}
+	var forced bool
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		forced = syncservice.IsForceWrite(ctx)
+		return "ok", nil
+	}
+	intercept := ForceWriteInterceptor(auth.DefaultForceWriteScope)
+	writer := auth.WithScopes(context.Background(), []string{auth.DefaultWriteScope})
+	admin := auth.WithScopes(context.Background(), []string{auth.DefaultWriteScope, auth.DefaultForceWriteScope})
+
+	if _, err := intercept(writer, &syncv1.PushRequest{}, info, handler); err != nil || forced {
+		t.Errorf("Ordinary push should pass unforced, got forced=%v err=%v", forced, err)
+	}
+	if _, err := intercept(writer, &syncv1.PushRequest{Force: true}, info, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Forced push without the scope should be PermissionDenied, got %v", err)
+	}
+	if _, err := intercept(admin, &syncv1.PushRequest{Force: true}, info, handler); err != nil || !forced {
+		t.Errorf("Forced push with the scope should run forced, got forced=%v err=%v", forced, err)
+	}
+	if _, err := ForceWriteInterceptor("")(admin, &syncv1.PushRequest{Force: true}, info, handler); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Forced pushes should be disabled without a scope, got %v", err)
+	}
+
+	// PushStream: the handler sees the forced context once it has received the request
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/toolbridge.sync.v1.NoteSyncService/PushStream", IsServerStream: true}
+	for _, tc := range []struct {
+		ctx      context.Context
+		wantCode codes.Code
+	}{{writer, codes.PermissionDenied}, {admin, codes.OK}} {
+		forced = false
+		ss := &fakePushStream{fakeServerStream: fakeServerStream{ctx: tc.ctx}, force: true}
+		err := ForceWriteStreamInterceptor(auth.DefaultForceWriteScope)(nil, ss, streamInfo, func(srv interface{}, stream grpc.ServerStream) error {
+			if err := stream.RecvMsg(&syncv1.PushRequest{}); err != nil {
+				return err
+			}
+			forced = syncservice.IsForceWrite(stream.Context())
+			return nil
+		})
+		if status.Code(err) != tc.wantCode || forced != (tc.wantCode == codes.OK) {
+			t.Errorf("PushStream: got forced=%v err=%v, want code %v", forced, err, tc.wantCode)
+		}
+	}
+}
+
+func TestResyncRequiredError(t *testing.T) {
+	st, _ := status.FromError(resyncRequiredError(3, 2))
+	if st.Code() != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition, got %v", st.Code())
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("Expected one detail, got %v", details)
+	}
+	info, ok := details[0].(*errdetails.ErrorInfo)
+	if !ok {
+		t.Fatalf("Expected ErrorInfo, got %T", details[0])
+	}
+	if info.Reason != session.ResyncRequiredCode || info.Metadata["epoch"] != "3" {
+		t.Errorf("Got reason %q epoch %q, want %q and 3", info.Reason, info.Metadata["epoch"], session.ResyncRequiredCode)
+	}
+}
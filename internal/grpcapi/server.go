@@ -6,17 +6,22 @@ package grpcapi
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
 
 	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/loadest"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	"github.com/erauner12/toolbridge-api/internal/session"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
@@ -41,6 +46,19 @@ type Server struct {
 	ChatMessageSvc      *syncservice.ChatMessageService
 	TaskListSvc         *syncservice.TaskListService
 	TaskListCategorySvc *syncservice.TaskListCategoryService
+	Load                *loadest.Estimator    // Recent request volume for load-aware hints (nil = static hints)
+	DisabledEntities    map[string]bool       // Entity types shipped dark: services not registered, left out of GetServerInfo
+	MaxPushItems        int                   // Cap on items per push (see PushItemLimitInterceptor); caps recommendedBatch (0 = unlimited)
+	RateLimit           *syncv1.RateLimitInfo // Sync rate limit reported by GetServerInfo, as in /v1/sync/info (nil = defaultRateLimit)
+	SyncCfg             *syncservice.Config   // Sync settings shared with the services (nil = syncservice.DefaultConfig)
+}
+
+// syncCfg returns the sync settings RPCs read page limits and the item cache from
+func (s *Server) syncCfg() *syncservice.Config {
+	if s.SyncCfg == nil {
+		return syncservice.DefaultConfig()
+	}
+	return s.SyncCfg
 }
 
 // NewServer creates a new gRPC server instance
@@ -86,7 +104,7 @@ func (s *Server) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.Pus
 		Msg("grpc_notes_push_started")
 
 	// 2. Begin transaction
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		return nil, status.Error(codes.Internal, "db error")
@@ -132,6 +150,63 @@ func (s *Server) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv1.Pus
 	return &syncv1.PushResponse{Acks: acks}, nil
 }
 
+// PushStream implements NoteSyncService.PushStream
+func (s *Server) PushStream(req *syncv1.PushRequest, stream grpc.ServerStreamingServer[syncv1.PushAck]) error {
+	return s.pushStream("notes", req, stream, s.NoteSvc.PushNoteItem)
+}
+
+// pushStream commits each pushed item in its own transaction and streams its ack as
+// soon as it commits, so a client sees progress and per-item errors before the batch
+// ends. Item errors are reported in the ack as in Push; only database and transport
+// failures end the stream early, leaving the items already acked applied.
+func (s *Server) pushStream(
+	entity string,
+	req *syncv1.PushRequest,
+	stream grpc.ServerStreamingServer[syncv1.PushAck],
+	push func(ctx context.Context, tx pgx.Tx, userID string, item map[string]any) syncservice.PushAck,
+) error {
+	ctx := stream.Context()
+	logger := log.Ctx(ctx)
+	userID := auth.UserID(ctx)
+	if userID == "" {
+		return status.Error(codes.Unauthenticated, "missing user")
+	}
+
+	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msgf("grpc_%s_push_stream_started", entity)
+
+	sent := 0
+	for _, itemStruct := range req.Items {
+		tx, err := db.Begin(ctx, s.DB)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to begin transaction")
+			return status.Error(codes.Internal, "db error")
+		}
+		svcAck := push(ctx, tx, userID, itemStruct.AsMap())
+		// A failed statement aborts the item's transaction; its ack already carries the error
+		if err := tx.Commit(ctx); err != nil && !(errors.Is(err, pgx.ErrTxCommitRollback) && svcAck.Error != "") {
+			logger.Error().Err(err).Msg("failed to commit transaction")
+			return status.Error(codes.Internal, "commit error")
+		}
+
+		protoAck := &syncv1.PushAck{
+			Uid:     svcAck.UID,
+			Version: int32(svcAck.Version),
+			Error:   svcAck.Error,
+		}
+		if ms, ok := syncx.ParseTimeToMs(svcAck.UpdatedAt); ok {
+			protoAck.UpdatedAt = timestamppb.New(syncx.MsToTime(ms))
+		}
+		if err := stream.Send(protoAck); err != nil {
+			logger.Warn().Err(err).Int("sent_count", sent).Msg("push stream send failed")
+			return err
+		}
+		sent++
+	}
+
+	logger.Info().Str("user_id", userID).Int("success_count", sent).Msgf("grpc_%s_push_stream_completed", entity)
+	return nil
+}
+
 // Pull implements NoteSyncService.Pull
 func (s *Server) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
 	logger := log.Ctx(ctx)
@@ -143,19 +218,11 @@ func (s *Server) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.Pul
 	}
 
 	// 2. Parse cursor and limit
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 500 // default
-	}
-	if limit > 1000 {
-		limit = 1000 // max
-	}
+	limit := s.pullLimit(ctx, "note", req.Limit)
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := syncx.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	logger.Info().
@@ -230,7 +297,7 @@ func (ts *TaskServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_tasks_push_started")
 
-	tx, err := ts.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, ts.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		return nil, status.Error(codes.Internal, "db error")
@@ -262,6 +329,11 @@ func (ts *TaskServer) Push(ctx context.Context, req *syncv1.PushRequest) (*syncv
 	return &syncv1.PushResponse{Acks: acks}, nil
 }
 
+// PushStream implements TaskSyncService.PushStream
+func (ts *TaskServer) PushStream(req *syncv1.PushRequest, stream grpc.ServerStreamingServer[syncv1.PushAck]) error {
+	return ts.pushStream("tasks", req, stream, ts.TaskSvc.PushTaskItem)
+}
+
 // Pull implements TaskSyncService.Pull
 func (ts *TaskServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
 	logger := log.Ctx(ctx)
@@ -270,19 +342,11 @@ func (ts *TaskServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv
 		return nil, status.Error(codes.Unauthenticated, "missing user")
 	}
 
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 500
-	}
-	if limit > 1000 {
-		limit = 1000
-	}
+	limit := ts.pullLimit(ctx, "task", req.Limit)
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := syncx.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_tasks_pull_started")
@@ -336,7 +400,7 @@ func (cs *CommentServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sy
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_comments_push_started")
 
-	tx, err := cs.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, cs.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		return nil, status.Error(codes.Internal, "db error")
@@ -368,6 +432,11 @@ func (cs *CommentServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sy
 	return &syncv1.PushResponse{Acks: acks}, nil
 }
 
+// PushStream implements CommentSyncService.PushStream
+func (cs *CommentServer) PushStream(req *syncv1.PushRequest, stream grpc.ServerStreamingServer[syncv1.PushAck]) error {
+	return cs.pushStream("comments", req, stream, cs.CommentSvc.PushCommentItem)
+}
+
 // Pull implements CommentSyncService.Pull
 func (cs *CommentServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
 	logger := log.Ctx(ctx)
@@ -376,19 +445,11 @@ func (cs *CommentServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sy
 		return nil, status.Error(codes.Unauthenticated, "missing user")
 	}
 
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 500
-	}
-	if limit > 1000 {
-		limit = 1000
-	}
+	limit := cs.pullLimit(ctx, "comment", req.Limit)
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := syncx.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_comments_pull_started")
@@ -442,7 +503,7 @@ func (chs *ChatServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sync
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_chats_push_started")
 
-	tx, err := chs.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, chs.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		return nil, status.Error(codes.Internal, "db error")
@@ -474,6 +535,11 @@ func (chs *ChatServer) Push(ctx context.Context, req *syncv1.PushRequest) (*sync
 	return &syncv1.PushResponse{Acks: acks}, nil
 }
 
+// PushStream implements ChatSyncService.PushStream
+func (chs *ChatServer) PushStream(req *syncv1.PushRequest, stream grpc.ServerStreamingServer[syncv1.PushAck]) error {
+	return chs.pushStream("chats", req, stream, chs.ChatSvc.PushChatItem)
+}
+
 // Pull implements ChatSyncService.Pull
 func (chs *ChatServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
 	logger := log.Ctx(ctx)
@@ -482,19 +548,11 @@ func (chs *ChatServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*sync
 		return nil, status.Error(codes.Unauthenticated, "missing user")
 	}
 
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 500
-	}
-	if limit > 1000 {
-		limit = 1000
-	}
+	limit := chs.pullLimit(ctx, "chat", req.Limit)
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := syncx.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_chats_pull_started")
@@ -548,7 +606,7 @@ func (cms *ChatMessageServer) Push(ctx context.Context, req *syncv1.PushRequest)
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_chat_messages_push_started")
 
-	tx, err := cms.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, cms.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		return nil, status.Error(codes.Internal, "db error")
@@ -580,6 +638,11 @@ func (cms *ChatMessageServer) Push(ctx context.Context, req *syncv1.PushRequest)
 	return &syncv1.PushResponse{Acks: acks}, nil
 }
 
+// PushStream implements ChatMessageSyncService.PushStream
+func (cms *ChatMessageServer) PushStream(req *syncv1.PushRequest, stream grpc.ServerStreamingServer[syncv1.PushAck]) error {
+	return cms.pushStream("chat_messages", req, stream, cms.ChatMessageSvc.PushChatMessageItem)
+}
+
 // Pull implements ChatMessageSyncService.Pull
 func (cms *ChatMessageServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
 	logger := log.Ctx(ctx)
@@ -588,19 +651,11 @@ func (cms *ChatMessageServer) Pull(ctx context.Context, req *syncv1.PullRequest)
 		return nil, status.Error(codes.Unauthenticated, "missing user")
 	}
 
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 500
-	}
-	if limit > 1000 {
-		limit = 1000
-	}
+	limit := cms.pullLimit(ctx, "chat_message", req.Limit)
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := syncx.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_chat_messages_pull_started")
@@ -654,7 +709,7 @@ func (tls *TaskListServer) Push(ctx context.Context, req *syncv1.PushRequest) (*
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_task_lists_push_started")
 
-	tx, err := tls.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, tls.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		return nil, status.Error(codes.Internal, "db error")
@@ -686,6 +741,11 @@ func (tls *TaskListServer) Push(ctx context.Context, req *syncv1.PushRequest) (*
 	return &syncv1.PushResponse{Acks: acks}, nil
 }
 
+// PushStream implements TaskListSyncService.PushStream
+func (tls *TaskListServer) PushStream(req *syncv1.PushRequest, stream grpc.ServerStreamingServer[syncv1.PushAck]) error {
+	return tls.pushStream("task_lists", req, stream, tls.TaskListSvc.PushTaskListItem)
+}
+
 // Pull implements TaskListSyncService.Pull
 func (tls *TaskListServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
 	logger := log.Ctx(ctx)
@@ -694,19 +754,11 @@ func (tls *TaskListServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*
 		return nil, status.Error(codes.Unauthenticated, "missing user")
 	}
 
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 500
-	}
-	if limit > 1000 {
-		limit = 1000
-	}
+	limit := tls.pullLimit(ctx, "task_list", req.Limit)
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := syncx.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_task_lists_pull_started")
@@ -760,7 +812,7 @@ func (tlcs *TaskListCategoryServer) Push(ctx context.Context, req *syncv1.PushRe
 
 	logger.Info().Str("user_id", userID).Int("item_count", len(req.Items)).Msg("grpc_task_list_categories_push_started")
 
-	tx, err := tlcs.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, tlcs.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		return nil, status.Error(codes.Internal, "db error")
@@ -792,6 +844,11 @@ func (tlcs *TaskListCategoryServer) Push(ctx context.Context, req *syncv1.PushRe
 	return &syncv1.PushResponse{Acks: acks}, nil
 }
 
+// PushStream implements TaskListCategorySyncService.PushStream
+func (tlcs *TaskListCategoryServer) PushStream(req *syncv1.PushRequest, stream grpc.ServerStreamingServer[syncv1.PushAck]) error {
+	return tlcs.pushStream("task_list_categories", req, stream, tlcs.TaskListCategorySvc.PushTaskListCategoryItem)
+}
+
 // Pull implements TaskListCategorySyncService.Pull
 func (tlcs *TaskListCategoryServer) Pull(ctx context.Context, req *syncv1.PullRequest) (*syncv1.PullResponse, error) {
 	logger := log.Ctx(ctx)
@@ -800,19 +857,11 @@ func (tlcs *TaskListCategoryServer) Pull(ctx context.Context, req *syncv1.PullRe
 		return nil, status.Error(codes.Unauthenticated, "missing user")
 	}
 
-	limit := int(req.Limit)
-	if limit <= 0 {
-		limit = 500
-	}
-	if limit > 1000 {
-		limit = 1000
-	}
+	limit := tlcs.pullLimit(ctx, "task_list_category", req.Limit)
 
-	cur := syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	if req.Cursor != "" {
-		if decoded, ok := syncx.DecodeCursor(req.Cursor); ok {
-			cur = decoded
-		}
+	cur, err := syncx.DecodeCursor(req.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
 	logger.Info().Str("user_id", userID).Int("limit", limit).Str("cursor", req.Cursor).Msg("grpc_task_list_categories_pull_started")
@@ -850,69 +899,94 @@ func (tlcs *TaskListCategoryServer) Pull(ctx context.Context, req *syncv1.PullRe
 // Core SyncService Implementation (Sessions, Info, Wipe)
 // ===================================================================
 
+// pullLimit resolves a pull's requested limit against the entity's page size (see
+// syncservice.Config.PageLimitFor). A clamped limit is reported in an x-limit-clamped response
+// header, as HTTP does with X-Limit-Clamped.
+func (s *Server) pullLimit(ctx context.Context, table string, requested int32) int {
+	limit, clamped := s.syncCfg().PageLimitFor(table).Resolve(int(requested))
+	if clamped {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-limit-clamped", strconv.Itoa(limit)))
+	}
+	return limit
+}
+
+// entityCapability describes an enabled entity in GetServerInfo
+func (s *Server) entityCapability(table string) *syncv1.EntityCapability {
+	limits := s.syncCfg().PageLimitFor(table)
+	return &syncv1.EntityCapability{
+		DefaultLimit: int32(limits.Default),
+		MaxLimit:     int32(limits.Max),
+		Push:         true,
+		Pull:         true,
+	}
+}
+
 // GetServerInfo implements SyncService.GetServerInfo
 // Returns server capabilities, API version, and supported features
 func (s *Server) GetServerInfo(ctx context.Context, req *syncv1.GetServerInfoRequest) (*syncv1.ServerInfo, error) {
 	logger := log.Ctx(ctx)
 	logger.Debug().Msg("GetServerInfo called")
 
+	entities := map[string]*syncv1.EntityCapability{
+		"notes":                s.entityCapability("note"),
+		"tasks":                s.entityCapability("task"),
+		"comments":             s.entityCapability("comment"),
+		"chats":                s.entityCapability("chat"),
+		"chat_messages":        s.entityCapability("chat_message"),
+		"task_lists":           s.entityCapability("task_list"),
+		"task_list_categories": s.entityCapability("task_list_category"),
+	}
+	for name := range s.DisabledEntities {
+		delete(entities, name)
+	}
+
 	return &syncv1.ServerInfo{
-		ApiVersion: "1.1",
+		ApiVersion: apiVersion,
 		ServerTime: timestamppb.Now(),
-		Entities: map[string]*syncv1.EntityCapability{
-			"notes": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"tasks": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"comments": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"chats": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"chat_messages": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"task_lists": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"task_list_categories": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-		},
+		Entities:   entities,
 		Locking: &syncv1.LockingCapability{
 			Supported: true,
 			Mode:      "session",
 		},
 		MinClientVersion: "0.1.0",
-		RateLimit: &syncv1.RateLimitInfo{
-			WindowSeconds: 60,
-			MaxRequests:   5,
-			Burst:         2,
-		},
-		Hints: &syncv1.SyncHints{
-			RecommendedBatch: 500,
-			BackoffMsOn_429:  1500,
-		},
+		RateLimit:        s.rateLimit(),
+		Hints:            s.syncHints(),
 	}, nil
 }
 
+// defaultRateLimit is reported when the server was built without the HTTP rate limit config
+var defaultRateLimit = &syncv1.RateLimitInfo{
+	WindowSeconds: 60,
+	MaxRequests:   5,
+	Burst:         2,
+}
+
+// rateLimit returns the rate limit GetServerInfo reports
+func (s *Server) rateLimit() *syncv1.RateLimitInfo {
+	if s.RateLimit != nil {
+		return s.RateLimit
+	}
+	return defaultRateLimit
+}
+
+// syncHints returns client hints, adjusted for current load when an estimator is configured
+func (s *Server) syncHints() *syncv1.SyncHints {
+	hints := &syncv1.SyncHints{
+		RecommendedBatch: 500,
+		BackoffMsOn_429:  1500,
+	}
+	if s.Load != nil {
+		h := s.Load.Hints(500)
+		hints.RecommendedBatch = int32(h.RecommendedBatch)
+		hints.RecommendedBackoffMs = int32(h.RecommendedBackoffMs)
+		hints.CurrentLoad = h.CurrentLoad
+	}
+	if s.MaxPushItems > 0 && int(hints.RecommendedBatch) > s.MaxPushItems {
+		hints.RecommendedBatch = int32(s.MaxPushItems)
+	}
+	return hints
+}
+
 // BeginSession implements SyncService.BeginSession
 // Creates a new sync session for the authenticated user
 func (s *Server) BeginSession(ctx context.Context, req *syncv1.BeginSessionRequest) (*syncv1.SyncSession, error) {
@@ -948,9 +1022,18 @@ func (s *Server) BeginSession(ctx context.Context, req *syncv1.BeginSessionReque
 		}
 	}
 
-	// Create session with epoch using shared session store
+	// Create session with epoch using shared session store (subject to the per-user session cap)
 	sessionStore := session.GetStore()
-	sess := sessionStore.CreateSession(userID, epoch)
+	sess, err := sessionStore.CreateSession(userID, epoch)
+	if err != nil {
+		if errors.Is(err, session.ErrSessionLimitReached) {
+			max, _ := sessionStore.UserSessionLimit()
+			logger.Warn().Str("userId", userID).Int("maxSessions", max).Msg("session limit reached")
+			return nil, status.Error(codes.FailedPrecondition, "too many active sessions; end an existing session first")
+		}
+		logger.Error().Err(err).Str("userId", userID).Msg("Failed to create session")
+		return nil, status.Error(codes.Internal, "Failed to create session")
+	}
 
 	logger.Info().
 		Str("sessionId", sess.ID).
@@ -1018,7 +1101,7 @@ func (s *Server) WipeAccount(ctx context.Context, req *syncv1.WipeAccountRequest
 		return nil, status.Error(codes.InvalidArgument, "confirmation required: must send confirm=\"WIPE\"")
 	}
 
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		logger.Error().Err(err).Str("userId", userID).Msg("Failed to begin transaction")
 		return nil, status.Error(codes.Internal, "transaction begin failed")
@@ -1044,6 +1127,7 @@ func (s *Server) WipeAccount(ctx context.Context, req *syncv1.WipeAccountRequest
 	}
 
 	// Delete all entity rows for this user
+	s.syncCfg().ItemCache.InvalidateTable(tx, "")
 	deleted := make(map[string]int32)
 	tables := []string{"chat_message", "comment", "chat", "task", "task_list", "task_list_category", "note"}
 
@@ -6,6 +6,7 @@ package grpcapi
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"testing"
@@ -15,6 +16,7 @@ import (
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	syncv1 "github.com/erauner12/toolbridge-api/gen/go/sync/v1"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
@@ -50,6 +52,7 @@ func getTestDB(t *testing.T) *pgxpool.Pool {
 		DELETE FROM task;
 		DELETE FROM note;
 		DELETE FROM owner_state;
+		DELETE FROM owner_usage;
 		DELETE FROM app_user;
 	`)
 	if err != nil {
@@ -66,15 +69,21 @@ func setupTestGrpcServer(t *testing.T, pool *pgxpool.Pool) *grpc.Server {
 	lis = bufconn.Listen(bufSize)
 
 	// Create gRPC server with full interceptor chain
+	interceptors := []grpc.UnaryServerInterceptor{
+		RecoveryInterceptor(),
+		CorrelationIDInterceptor(),
+		AuthInterceptor(pool, auth.JWTCfg{HS256Secret: "test-secret", DevMode: true}),
+		SessionInterceptor(),
+		EpochInterceptor(pool),
+		LoggingInterceptor(zerolog.InfoLevel),
+	}
+	streamInterceptors := make([]grpc.StreamServerInterceptor, len(interceptors))
+	for i, interceptor := range interceptors {
+		streamInterceptors[i] = StreamServerInterceptor(interceptor)
+	}
 	grpcServer := grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			RecoveryInterceptor(),
-			CorrelationIDInterceptor(),
-			AuthInterceptor(pool, auth.JWTCfg{HS256Secret: "test-secret", DevMode: true}),
-			SessionInterceptor(),
-			EpochInterceptor(pool),
-			LoggingInterceptor(),
-		),
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
 	)
 
 	// Create and register server implementation
@@ -653,6 +662,83 @@ func TestNotePush(t *testing.T) {
 	}
 }
 
+func TestNotePushStream(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	grpcServer := setupTestGrpcServer(t, pool)
+	defer grpcServer.Stop()
+
+	conn, syncClient, noteClient, _, _, _, _ := createTestClients(t)
+	defer conn.Close()
+
+	userID := "test-user-note-push-stream"
+	ctx := createDevModeContext(userID)
+
+	session, err := syncClient.BeginSession(ctx, &syncv1.BeginSessionRequest{})
+	if err != nil {
+		t.Fatalf("BeginSession failed: %v", err)
+	}
+
+	// Streams are checked by the same interceptors as unary RPCs
+	stream, err := noteClient.PushStream(ctx, &syncv1.PushRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("Expected FailedPrecondition without a session, got %v", err)
+	}
+
+	authCtx := createAuthenticatedContext(userID, session.Id, int(session.Epoch))
+
+	var items []*structpb.Struct
+	for _, m := range []map[string]interface{}{
+		{"uid": "aaaa5555-0000-0000-0000-000000000001", "title": "First", "updatedTs": "2025-11-09T10:00:00Z", "sync": map[string]interface{}{"version": 1}},
+		{"title": "No UID", "updatedTs": "2025-11-09T10:00:00Z"},
+		{"uid": "aaaa5555-0000-0000-0000-000000000002", "title": "Second", "updatedTs": "2025-11-09T10:00:00Z", "sync": map[string]interface{}{"version": 1}},
+	} {
+		item, err := structpb.NewStruct(m)
+		if err != nil {
+			t.Fatalf("Failed to create struct: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	stream, err = noteClient.PushStream(authCtx, &syncv1.PushRequest{Items: items})
+	if err != nil {
+		t.Fatalf("PushStream failed: %v", err)
+	}
+	var acks []*syncv1.PushAck
+	for {
+		ack, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		acks = append(acks, ack)
+	}
+
+	// One ack per item, in order; an invalid item doesn't stop the ones after it
+	if len(acks) != 3 {
+		t.Fatalf("Expected 3 acks, got %d", len(acks))
+	}
+	if acks[0].Uid != "aaaa5555-0000-0000-0000-000000000001" || acks[0].Error != "" {
+		t.Errorf("Unexpected first ack: %v", acks[0])
+	}
+	if acks[1].Error == "" {
+		t.Error("Expected error ack for missing UID")
+	}
+	if acks[2].Uid != "aaaa5555-0000-0000-0000-000000000002" || acks[2].Version != 1 {
+		t.Errorf("Unexpected third ack: %v", acks[2])
+	}
+}
+
 func TestNotePull(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -0,0 +1,84 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// AccessLogCfg configures the per-request access log
+type AccessLogCfg struct {
+	Level  zerolog.Level // Level of the access log line (zerolog.Disabled = no access log)
+	Probes bool          // Also log health probes (/healthz, /readyz), which are skipped by default
+}
+
+// DefaultAccessLogCfg logs every request except health probes at info
+var DefaultAccessLogCfg = AccessLogCfg{Level: zerolog.InfoLevel}
+
+// ParseAccessLogLevel parses the access log level from config
+// Accepts zerolog level names, plus "off" to disable access logging.
+func ParseAccessLogLevel(v string) (zerolog.Level, error) {
+	if strings.EqualFold(v, "off") {
+		return zerolog.Disabled, nil
+	}
+	level, err := zerolog.ParseLevel(strings.ToLower(v))
+	if err != nil || v == "" {
+		return zerolog.NoLevel, fmt.Errorf("unknown access log level %q (want debug, info, warn, error or off)", v)
+	}
+	return level, nil
+}
+
+// probePaths are the health endpoints polled by load balancers and orchestrators
+var probePaths = map[string]bool{"/healthz": true, "/readyz": true}
+
+// AccessLog emits one structured line per request once it completes: method, route
+// pattern, status, latency, bytes written and the authenticated user.
+// Must run after CorrelationMiddleware so log.Ctx carries the correlation ID.
+func AccessLog(cfg AccessLogCfg) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if cfg.Level == zerolog.Disabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !cfg.Probes && probePaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			ctx, userID := auth.TrackUserID(r.Context())
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK // handler wrote nothing
+			}
+			route := ""
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+
+			log.Ctx(ctx).WithLevel(cfg.Level).
+				Str("method", r.Method).
+				Str("path", r.URL.Path).
+				Str("route", route).
+				Int("status", status).
+				Float64("latency_ms", float64(time.Since(start).Microseconds())/1000).
+				Int("bytes", ww.BytesWritten()).
+				Str("user_id", userID()).
+				Str("request_id", middleware.GetReqID(ctx)).
+				Str("remote_addr", r.RemoteAddr).
+				Msg("http_request")
+		})
+	}
+}
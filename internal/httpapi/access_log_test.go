@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	newRouter := func(cfg AccessLogCfg) http.Handler {
+		r := chi.NewRouter()
+		r.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(logger.WithContext(r.Context())))
+			})
+		})
+		r.Use(AccessLog(cfg))
+		r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) })
+		r.Get("/v1/notes/{uid}", func(w http.ResponseWriter, r *http.Request) {
+			// Authentication runs inside the access log, as in Routes
+			r = r.WithContext(auth.WithUserID(r.Context(), "user-1"))
+			writeError(w, r, http.StatusNotFound, "not found")
+		})
+		return r
+	}
+
+	router := newRouter(DefaultAccessLogCfg)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/notes/abc", nil))
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("Expected one JSON log line, got %q: %v", buf.String(), err)
+	}
+	expected := map[string]any{
+		"level":   "info",
+		"method":  "GET",
+		"route":   "/v1/notes/{uid}",
+		"status":  float64(http.StatusNotFound),
+		"user_id": "user-1",
+		"message": "http_request",
+	}
+	for k, v := range expected {
+		if line[k] != v {
+			t.Errorf("%s = %v, want %v", k, line[k], v)
+		}
+	}
+	if _, ok := line["latency_ms"]; !ok {
+		t.Error("Expected latency_ms field")
+	}
+	if n, _ := line["bytes"].(float64); n == 0 {
+		t.Errorf("Expected bytes written, got %v", line["bytes"])
+	}
+
+	// Health probes are skipped unless enabled
+	buf.Reset()
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log line for /healthz, got %s", buf.String())
+	}
+	newRouter(AccessLogCfg{Level: zerolog.InfoLevel, Probes: true}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	if buf.Len() == 0 {
+		t.Error("Expected a log line for /healthz with Probes enabled")
+	}
+
+	// Disabled
+	buf.Reset()
+	newRouter(AccessLogCfg{Level: zerolog.Disabled}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/v1/notes/abc", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log line when disabled, got %s", buf.String())
+	}
+}
+
+func TestParseAccessLogLevel(t *testing.T) {
+	tests := map[string]zerolog.Level{"info": zerolog.InfoLevel, "DEBUG": zerolog.DebugLevel, "off": zerolog.Disabled}
+	for v, want := range tests {
+		if got, err := ParseAccessLogLevel(v); err != nil || got != want {
+			t.Errorf("ParseAccessLogLevel(%q) = %v, %v; want %v", v, got, err, want)
+		}
+	}
+	for _, v := range []string{"", "verbose"} {
+		if _, err := ParseAccessLogLevel(v); err == nil {
+			t.Errorf("ParseAccessLogLevel(%q) expected error", v)
+		}
+	}
+}
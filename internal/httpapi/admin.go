@@ -0,0 +1,370 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// adminUserStateResponse is the response for GET /v1/admin/users/{sub}/state
+type adminUserStateResponse struct {
+	Subject        string             `json:"subject"`
+	UserID         string             `json:"userId"`
+	Epoch          int                `json:"epoch"`
+	LastWipeAt     *time.Time         `json:"lastWipeAt,omitempty"`
+	LastWipeBy     *string            `json:"lastWipeBy,omitempty"`
+	ActiveSessions int                `json:"activeSessions"`
+	MaxUpdatedAt   map[string]*string `json:"maxUpdatedAt"` // table -> latest updatedAt (nil if empty)
+	PayloadBytes   int64              `json:"payloadBytes"` // size of live items (see MAX_PAYLOAD_BYTES)
+}
+
+// AdminTokenRequired middleware guards admin endpoints with a static token.
+//
+// The token is read from the X-Admin-Token header and compared in constant time.
+// This is deliberately separate from user JWT auth: admin callers (support tooling)
+// are not app users and must not be able to act as one.
+func AdminTokenRequired(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided := r.Header.Get("X-Admin-Token")
+			if token == "" || provided == "" ||
+				subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				log.Warn().
+					Str("path", r.URL.Path).
+					Str("remote_addr", r.RemoteAddr).
+					Msg("admin request rejected: invalid or missing admin token")
+				writeError(w, r, http.StatusUnauthorized, "invalid admin token")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetAdminUserState handles GET /v1/admin/users/{sub}/state
+//
+// Returns a read-only diagnostic snapshot for a user identified by OIDC subject:
+// - epoch, lastWipeAt, lastWipeBy from owner_state, payloadBytes from owner_usage
+// - number of active sync sessions
+// - latest updatedAt per entity table
+//
+// Used by support to diagnose "my sync is stuck" reports (usually epoch drift).
+func (s *Server) GetAdminUserState(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sub := chi.URLParam(r, "sub")
+	if sub == "" {
+		writeError(w, r, http.StatusBadRequest, "subject required")
+		return
+	}
+
+	var userID string
+	err := s.DB.QueryRow(ctx, `SELECT id FROM app_user WHERE sub = $1`, sub).Scan(&userID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			writeError(w, r, http.StatusNotFound, "user not found")
+			return
+		}
+		log.Error().Err(err).Str("sub", sub).Msg("Failed to look up user")
+		writeError(w, r, http.StatusInternalServerError, "failed to look up user")
+		return
+	}
+
+	// owner_state is lazily created; a missing row means default epoch 1
+	resp := adminUserStateResponse{
+		Subject:      sub,
+		UserID:       userID,
+		Epoch:        1,
+		MaxUpdatedAt: make(map[string]*string, len(syncTables)),
+	}
+
+	var epoch int
+	var lastWipeAt sql.NullTime
+	var lastWipeBy sql.NullString
+	err = s.DB.QueryRow(ctx, `
+		SELECT epoch, last_wipe_at, last_wipe_by
+		FROM owner_state
+		WHERE owner_id = $1
+	`, userID).Scan(&epoch, &lastWipeAt, &lastWipeBy)
+	switch {
+	case err == nil:
+		resp.Epoch = epoch
+		if lastWipeAt.Valid {
+			resp.LastWipeAt = &lastWipeAt.Time
+		}
+		if lastWipeBy.Valid {
+			resp.LastWipeBy = &lastWipeBy.String
+		}
+	case err != pgx.ErrNoRows:
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to load owner state")
+		writeError(w, r, http.StatusInternalServerError, "failed to load owner state")
+		return
+	}
+
+	if resp.PayloadBytes, err = syncservice.PayloadBytes(ctx, s.DB, userID); err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to load storage usage")
+		writeError(w, r, http.StatusInternalServerError, "failed to load storage usage")
+		return
+	}
+
+	for _, table := range syncTables {
+		var maxMs *int64
+		if err := s.DB.QueryRow(ctx,
+			`SELECT MAX(updated_at_ms) FROM `+table+` WHERE owner_id = $1`,
+			userID,
+		).Scan(&maxMs); err != nil {
+			log.Error().Err(err).Str("table", table).Str("userId", userID).Msg("Failed to load max updatedAt")
+			writeError(w, r, http.StatusInternalServerError, "failed to load entity state: "+table)
+			return
+		}
+
+		if maxMs != nil {
+			ts := syncx.RFC3339(*maxMs)
+			resp.MaxUpdatedAt[table] = &ts
+		} else {
+			resp.MaxUpdatedAt[table] = nil
+		}
+	}
+
+	resp.ActiveSessions = sessionStore.CountUserSessions(userID)
+
+	log.Info().
+		Str("sub", sub).
+		Str("userId", userID).
+		Int("epoch", resp.Epoch).
+		Msg("admin user state inspected")
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// adminMergeRequest is the request body for POST /v1/admin/users/merge
+type adminMergeRequest struct {
+	FromSubject string `json:"fromSubject"` // old IdP subject (data source)
+	ToSubject   string `json:"toSubject"`   // new IdP subject (created if it has never signed in)
+	Confirm     string `json:"confirm"`     // Must be "MERGE"
+}
+
+// adminMergeResponse is the response for POST /v1/admin/users/merge
+type adminMergeResponse struct {
+	FromSubject string                             `json:"fromSubject"`
+	ToSubject   string                             `json:"toSubject"`
+	UserID      string                             `json:"userId"` // destination user ID
+	Epoch       int                                `json:"epoch"`  // destination epoch after the merge
+	Tables      map[string]syncservice.MergeResult `json:"tables"`
+}
+
+// MergeAdminUsers handles POST /v1/admin/users/merge
+//
+// Reassigns all synced data from one OIDC subject to another, e.g. after an IdP
+// migration changed a user's subject. In a single transaction:
+//  1. Moves every entity row (and purge marker) to the destination user;
+//     UID collisions are resolved by LWW (destination wins ties)
+//  2. Bumps the destination epoch past both users' epochs so every device resyncs
+//  3. Removes the old user's owner_state, owner_usage and app_user rows
+//
+// Sessions for both users are invalidated after commit.
+func (s *Server) MergeAdminUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req adminMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Confirm != "MERGE" {
+		writeError(w, r, http.StatusBadRequest, "confirmation required: must send \"confirm\":\"MERGE\"")
+		return
+	}
+	if req.FromSubject == "" || req.ToSubject == "" {
+		writeError(w, r, http.StatusBadRequest, "fromSubject and toSubject are required")
+		return
+	}
+	if req.FromSubject == req.ToSubject {
+		writeError(w, r, http.StatusBadRequest, "fromSubject and toSubject must differ")
+		return
+	}
+
+	tx, err := db.Begin(ctx, s.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to begin merge transaction")
+		writeError(w, r, http.StatusInternalServerError, "transaction begin failed")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var fromUserID string
+	if err := tx.QueryRow(ctx, `SELECT id FROM app_user WHERE sub = $1 FOR UPDATE`, req.FromSubject).Scan(&fromUserID); err != nil {
+		if err == pgx.ErrNoRows {
+			writeError(w, r, http.StatusNotFound, "fromSubject not found")
+			return
+		}
+		log.Error().Err(err).Str("sub", req.FromSubject).Msg("Failed to look up user")
+		writeError(w, r, http.StatusInternalServerError, "failed to look up user")
+		return
+	}
+
+	// Destination may not have signed in yet; create it the same way auth middleware does
+	var toUserID string
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO app_user (sub) VALUES ($1)
+		ON CONFLICT (sub) DO UPDATE SET sub = excluded.sub
+		RETURNING id
+	`, req.ToSubject).Scan(&toUserID); err != nil {
+		log.Error().Err(err).Str("sub", req.ToSubject).Msg("Failed to upsert destination user")
+		writeError(w, r, http.StatusInternalServerError, "failed to create destination user")
+		return
+	}
+
+	resp := adminMergeResponse{
+		FromSubject: req.FromSubject,
+		ToSubject:   req.ToSubject,
+		UserID:      toUserID,
+		Tables:      make(map[string]syncservice.MergeResult, len(syncTables)),
+	}
+
+	for _, table := range syncTables {
+		res, err := syncservice.MergeOwnerTx(ctx, tx, s.syncCfg().ItemCache, table, fromUserID, toUserID)
+		if err != nil {
+			log.Error().Err(err).Str("table", table).Msg("Failed to merge owner rows")
+			writeError(w, r, http.StatusInternalServerError, "merge failed: "+table)
+			return
+		}
+		resp.Tables[table] = res
+	}
+
+	if err := syncservice.MergePurgeMarkersTx(ctx, tx, fromUserID, toUserID); err != nil {
+		log.Error().Err(err).Msg("Failed to merge purge markers")
+		writeError(w, r, http.StatusInternalServerError, "merge failed: purge_marker")
+		return
+	}
+
+	// New epoch must exceed both users' epochs so devices of either account reset
+	// (owner_state is lazily created; a missing row means epoch 1)
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO owner_state (owner_id, epoch, created_at, updated_at)
+		VALUES ($2, GREATEST(
+			COALESCE((SELECT epoch FROM owner_state WHERE owner_id = $1), 1),
+			COALESCE((SELECT epoch FROM owner_state WHERE owner_id = $2), 1)
+		) + 1, NOW(), NOW())
+		ON CONFLICT (owner_id) DO UPDATE
+			SET epoch = EXCLUDED.epoch,
+				updated_at = NOW()
+		RETURNING epoch
+	`, fromUserID, toUserID).Scan(&resp.Epoch); err != nil {
+		log.Error().Err(err).Msg("Failed to bump epoch after merge")
+		writeError(w, r, http.StatusInternalServerError, "epoch update failed")
+		return
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM owner_state WHERE owner_id = $1`, fromUserID); err != nil {
+		log.Error().Err(err).Msg("Failed to delete old owner state")
+		writeError(w, r, http.StatusInternalServerError, "merge failed: owner_state")
+		return
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM owner_usage WHERE owner_id = $1`, fromUserID); err != nil {
+		log.Error().Err(err).Msg("Failed to delete old owner usage")
+		writeError(w, r, http.StatusInternalServerError, "merge failed: owner_usage")
+		return
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM app_user WHERE id = $1`, fromUserID); err != nil {
+		log.Error().Err(err).Msg("Failed to delete old user")
+		writeError(w, r, http.StatusInternalServerError, "merge failed: app_user")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to commit merge transaction")
+		writeError(w, r, http.StatusInternalServerError, "commit failed")
+		return
+	}
+
+	sessionStore.DeleteUserSessions(fromUserID)
+	sessionStore.DeleteUserSessions(toUserID)
+
+	log.Info().
+		Str("fromSub", req.FromSubject).
+		Str("toSub", req.ToSubject).
+		Str("toUserId", toUserID).
+		Int("epoch", resp.Epoch).
+		Interface("tables", resp.Tables).
+		Msg("admin user merge completed")
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// adminRecomputeResponse is the response for POST /v1/admin/users/{sub}/recompute
+type adminRecomputeResponse struct {
+	Subject string `json:"subject"`
+	UserID  string `json:"userId"`
+	syncservice.CounterRecompute
+}
+
+// RecomputeAdminUserCounters handles POST /v1/admin/users/{sub}/recompute
+//
+// Rebuilds the counters cached in owner_state (payload bytes, task display ID
+// sequence) from the entity tables in one transaction, for when they have drifted
+// from the stored data. Reports each counter before and after, and the live item
+// count and bytes per table. Safe to repeat: a second run changes nothing.
+func (s *Server) RecomputeAdminUserCounters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sub := chi.URLParam(r, "sub")
+
+	tx, err := db.Begin(ctx, s.DB)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to begin recompute transaction")
+		writeError(w, r, http.StatusInternalServerError, "transaction begin failed")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var userID string
+	if err := tx.QueryRow(ctx, `SELECT id FROM app_user WHERE sub = $1`, sub).Scan(&userID); err != nil {
+		if err == pgx.ErrNoRows {
+			writeError(w, r, http.StatusNotFound, "user not found")
+			return
+		}
+		log.Error().Err(err).Str("sub", sub).Msg("Failed to look up user")
+		writeError(w, r, http.StatusInternalServerError, "failed to look up user")
+		return
+	}
+
+	res, err := syncservice.RecomputeOwnerCountersTx(ctx, tx, userID, syncTables)
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to recompute owner counters")
+		writeError(w, r, http.StatusInternalServerError, "recompute failed")
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to commit recompute transaction")
+		writeError(w, r, http.StatusInternalServerError, "commit failed")
+		return
+	}
+
+	log.Info().
+		Str("sub", sub).
+		Str("userId", userID).
+		Int64("payloadBytesBefore", res.PayloadBytes.Before).
+		Int64("payloadBytesAfter", res.PayloadBytes.After).
+		Int64("taskSeqBefore", res.TaskSeq.Before).
+		Int64("taskSeqAfter", res.TaskSeq.After).
+		Msg("admin owner counters recomputed")
+
+	writeJSON(w, http.StatusOK, adminRecomputeResponse{Subject: sub, UserID: userID, CounterRecompute: res})
+}
+
+// GetAdminItemCache handles GET /v1/admin/item_cache
+// Reports the single-item read cache's size and hit/miss/eviction counters
+// (see ITEM_CACHE_SIZE). Counters are per process and reset on restart.
+func (s *Server) GetAdminItemCache(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.syncCfg().ItemCache.Metrics())
+}
@@ -0,0 +1,238 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+)
+
+func TestAdminTokenRequired(t *testing.T) {
+	okHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name           string
+		configured     string
+		provided       string
+		expectedStatus int
+	}{
+		{"valid token", "s3cret", "s3cret", http.StatusOK},
+		{"wrong token", "s3cret", "nope", http.StatusUnauthorized},
+		{"missing token", "s3cret", "", http.StatusUnauthorized},
+		{"not configured", "", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := AdminTokenRequired(tt.configured)(okHandler)
+
+			req := httptest.NewRequest("GET", "/v1/admin/users/some-sub/state", nil)
+			if tt.provided != "" {
+				req.Header.Set("X-Admin-Token", tt.provided)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAdminRoutes_DisabledWithoutToken(t *testing.T) {
+	srv := &Server{}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	req := httptest.NewRequest("GET", "/v1/admin/users/some-sub/state", nil)
+	req.Header.Set("X-Admin-Token", "anything")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code == http.StatusOK {
+		t.Errorf("Expected admin routes to be unavailable without ADMIN_TOKEN, got %d", w.Code)
+	}
+}
+
+func TestMergeAdminUsers_Validation(t *testing.T) {
+	srv := &Server{AdminToken: "s3cret"}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{"fromSubject":`},
+		{"missing confirmation", `{"fromSubject":"old","toSubject":"new"}`},
+		{"missing subject", `{"fromSubject":"old","confirm":"MERGE"}`},
+		{"same subject", `{"fromSubject":"old","toSubject":"old","confirm":"MERGE"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/admin/users/merge", strings.NewReader(tt.body))
+			req.Header.Set("X-Admin-Token", "s3cret")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestMergeAdminUsers_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{DB: pool, AdminToken: "s3cret"}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	ctx := context.Background()
+	fromSub := "merge-old-" + uuid.NewString()
+	toSub := "merge-new-" + uuid.NewString()
+	fromUserID := createTestUser(t, pool, fromSub)
+	toUserID := createTestUser(t, pool, toSub)
+
+	noteSvc := syncservice.NewNoteService(pool)
+	shared := uuid.New()
+	onlyOld := uuid.New()
+	newer := int64(1_700_000_100_000)
+	older := int64(1_700_000_000_000)
+
+	// Old subject has the newer copy of the shared note, so it must win LWW
+	for _, m := range []struct {
+		userID string
+		uid    uuid.UUID
+		title  string
+		ms     *int64
+	}{
+		{fromUserID, shared, "old-account", &newer},
+		{toUserID, shared, "new-account", &older},
+		{fromUserID, onlyOld, "only-old", &older},
+	} {
+		if _, err := noteSvc.ApplyNoteMutation(ctx, m.userID, map[string]any{
+			"uid":   m.uid.String(),
+			"title": m.title,
+		}, syncservice.MutationOpts{ForceTimestampMs: m.ms}); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	body := `{"fromSubject":"` + fromSub + `","toSubject":"` + toSub + `","confirm":"MERGE"}`
+	req := httptest.NewRequest("POST", "/v1/admin/users/merge", strings.NewReader(body))
+	req.Header.Set("X-Admin-Token", "s3cret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp adminMergeResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if got := resp.Tables["note"]; got.Moved != 2 || got.Dropped != 1 {
+		t.Errorf("Expected 2 moved / 1 dropped notes, got %+v", got)
+	}
+	if resp.Epoch < 2 {
+		t.Errorf("Expected epoch bump, got %d", resp.Epoch)
+	}
+
+	item, err := noteSvc.GetNote(ctx, toUserID, shared)
+	if err != nil || item == nil {
+		t.Fatalf("Expected shared note under new subject: %v", err)
+	}
+	if item.Payload["title"] != "old-account" {
+		t.Errorf("Expected newer (old-account) copy to win, got %v", item.Payload["title"])
+	}
+	if item, _ := noteSvc.GetNote(ctx, toUserID, onlyOld); item == nil {
+		t.Error("Expected non-colliding note to move to new subject")
+	}
+
+	var remaining int
+	if err := pool.QueryRow(ctx, `SELECT COUNT(*) FROM app_user WHERE sub = $1`, fromSub).Scan(&remaining); err != nil || remaining != 0 {
+		t.Errorf("Expected old app_user to be removed, count=%d err=%v", remaining, err)
+	}
+}
+
+func TestRecomputeAdminUserCounters_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{DB: pool, AdminToken: "s3cret"}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	ctx := context.Background()
+	sub := "recompute-" + uuid.NewString()
+	userID := createTestUser(t, pool, sub)
+
+	noteSvc := syncservice.NewNoteService(pool)
+	for _, title := range []string{"one", "two"} {
+		if _, err := noteSvc.ApplyNoteMutation(ctx, userID, map[string]any{"title": title}, syncservice.MutationOpts{}); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+	var want int64
+	if err := pool.QueryRow(ctx, `SELECT payload_bytes FROM owner_usage WHERE owner_id = $1`, userID).Scan(&want); err != nil {
+		t.Fatalf("Failed to read payload bytes: %v", err)
+	}
+
+	// Simulate drift
+	if _, err := pool.Exec(ctx, `UPDATE owner_usage SET payload_bytes = 999999 WHERE owner_id = $1`, userID); err != nil {
+		t.Fatalf("Failed to corrupt counter: %v", err)
+	}
+
+	recompute := func() adminRecomputeResponse {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/v1/admin/users/"+sub+"/recompute", nil)
+		req.Header.Set("X-Admin-Token", "s3cret")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp adminRecomputeResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	resp := recompute()
+	if resp.PayloadBytes.Before != 999999 || resp.PayloadBytes.After != want {
+		t.Errorf("payloadBytes = %+v, want 999999 -> %d", resp.PayloadBytes, want)
+	}
+	if resp.LiveItems["note"] != 2 {
+		t.Errorf("Expected 2 live notes, got %d", resp.LiveItems["note"])
+	}
+	if again := recompute(); again.PayloadBytes.Before != want || again.PayloadBytes.After != want {
+		t.Errorf("Second recompute changed payloadBytes: %+v", again.PayloadBytes)
+	}
+
+	// Unknown subject
+	req := httptest.NewRequest("POST", "/v1/admin/users/no-such-"+uuid.NewString()+"/recompute", nil)
+	req.Header.Set("X-Admin-Token", "s3cret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown subject, got %d", w.Code)
+	}
+}
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// maxBatchUIDs caps the number of items in one batch request
+const maxBatchUIDs = 500
+
+// archiveMarkers set each entity's archived field, matching POST /<entity>/{uid}/archive
+var archiveMarkers = map[string]func(payload map[string]any){
+	"note":    setField("status", "archived"),
+	"comment": setField("status", "archived"),
+	// Tasks set both status and done for compatibility
+	"task":               func(p map[string]any) { p["status"] = "archived"; p["done"] = true },
+	"chat":               setField("archived", true),
+	"chat_message":       setField("archived", true),
+	"task_list":          setField("archived", true),
+	"task_list_category": setField("archived", true),
+}
+
+// batchReq is the request body for batch item operations
+type batchReq struct {
+	UIDs []string `json:"uids"`
+}
+
+// batchResult is the outcome for one UID of a batch operation
+type batchResult struct {
+	UID    string                `json:"uid"`
+	Status string                `json:"status"` // "archived"/"patched", "not_found", "deleted", "invalid", "conflict", "quota_exceeded", "append_only"
+	Error  string                `json:"error,omitempty"`
+	Item   *syncservice.RESTItem `json:"item,omitempty"`
+}
+
+// batchResponse is the response body for batch item operations
+type batchResponse struct {
+	Results []batchResult `json:"results"`
+}
+
+// BatchArchive returns the handler for POST /v1/<entity>/batch_archive
+// Archives every listed item in one transaction (same field as the per-item archive) and
+// reports a result per UID. Missing, deleted and invalid items are reported and skipped;
+// a database error rolls back the whole batch.
+func (s *Server) BatchArchive(table string) http.HandlerFunc {
+	markArchived := archiveMarkers[table]
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req batchReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, 400, "invalid JSON")
+			return
+		}
+		if !checkBatchUIDs(w, r, req.UIDs) {
+			return
+		}
+		s.runBatch(w, r, table, req.UIDs, "archive", "archived", markArchived)
+	}
+}
+
+// checkBatchUIDs writes a 400 and returns false if a batch lists no UIDs or too many
+func checkBatchUIDs(w http.ResponseWriter, r *http.Request, uids []string) bool {
+	if len(uids) == 0 {
+		writeError(w, r, 400, "uids must not be empty")
+		return false
+	}
+	if len(uids) > maxBatchUIDs {
+		writeError(w, r, 400, "too many uids (max "+strconv.Itoa(maxBatchUIDs)+")")
+		return false
+	}
+	return true
+}
+
+// runBatch applies mutate to the payload of each listed live item in one transaction and
+// writes a result per UID, with status done for the items written (op names the operation
+// in logs and errors). Per-item failures are reported and skipped; a database error rolls
+// back the whole batch with a 500.
+func (s *Server) runBatch(w http.ResponseWriter, r *http.Request, table string, uids []string, op, done string, mutate func(payload map[string]any)) {
+	label := strings.ReplaceAll(table, "_", " ")
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+	store := s.itemStores()[table]
+
+	tx, err := db.Begin(ctx, s.DB)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to begin transaction")
+		writeError(w, r, 500, "batch "+op+" failed")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	results := make([]batchResult, 0, len(uids))
+	for _, raw := range uids {
+		uid, err := uuid.Parse(raw)
+		if err != nil {
+			results = append(results, batchResult{UID: raw, Status: "invalid", Error: "invalid UID"})
+			continue
+		}
+
+		existing, err := store.get(ctx, userID, uid)
+		if err != nil {
+			logger.Error().Err(err).Str("uid", raw).Msg("failed to get " + label + " for " + op)
+			writeError(w, r, 500, "failed to get "+label)
+			return
+		}
+		if existing == nil {
+			results = append(results, batchResult{UID: raw, Status: "not_found", Error: label + " not found"})
+			continue
+		}
+		if existing.DeletedAt != nil {
+			results = append(results, batchResult{UID: raw, Status: "deleted", Error: label + " deleted"})
+			continue
+		}
+
+		payload := existing.Payload
+		mutate(payload)
+
+		item, err := store.applyTx(ctx, tx, userID, payload, syncservice.MutationOpts{})
+		if err != nil {
+			// Validation failures are rejected before any write, so the batch can continue
+			var fieldErr *syncx.FieldError
+			if errors.As(err, &fieldErr) {
+				results = append(results, batchResult{UID: raw, Status: "invalid", Error: fieldErr.Error()})
+				continue
+			}
+			var uniqueErr *syncservice.UniqueConflictError
+			if errors.As(err, &uniqueErr) {
+				results = append(results, batchResult{UID: raw, Status: "conflict", Error: uniqueErr.Error()})
+				continue
+			}
+			var quotaErr *syncservice.QuotaExceededError
+			if errors.As(err, &quotaErr) {
+				results = append(results, batchResult{UID: raw, Status: "quota_exceeded", Error: quotaErr.Error()})
+				continue
+			}
+			var appendOnlyErr *syncservice.AppendOnlyError
+			if errors.As(err, &appendOnlyErr) {
+				results = append(results, batchResult{UID: raw, Status: "append_only", Error: appendOnlyErr.Error()})
+				continue
+			}
+			logger.Error().Err(err).Str("uid", raw).Msg("failed to " + op + " " + label)
+			writeError(w, r, 500, "batch "+op+" failed")
+			return
+		}
+		results = append(results, batchResult{UID: raw, Status: done, Item: item})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error().Err(err).Msg("failed to commit batch " + op)
+		writeError(w, r, 500, "batch "+op+" failed")
+		return
+	}
+
+	writeJSON(w, 200, batchResponse{Results: results})
+}
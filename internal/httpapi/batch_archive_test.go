@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+)
+
+func TestBatchArchive_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+		ChatSvc:         syncservice.NewChatService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	ctx := context.Background()
+	userID := createTestUser(t, pool, testUserSubject)
+	session := createTestSession(t, router)
+
+	create := func(apply func(context.Context, string, map[string]any, syncservice.MutationOpts) (*syncservice.RESTItem, error), opts syncservice.MutationOpts) string {
+		t.Helper()
+		uid := uuid.New().String()
+		if _, err := apply(ctx, userID, map[string]any{"uid": uid, "title": "Batch"}, opts); err != nil {
+			t.Fatalf("Failed to create item: %v", err)
+		}
+		return uid
+	}
+
+	active := create(srv.NoteSvc.ApplyNoteMutation, syncservice.MutationOpts{})
+	deleted := create(srv.NoteSvc.ApplyNoteMutation, syncservice.MutationOpts{SetDeleted: true})
+	missing := uuid.New().String()
+	chat := create(srv.ChatSvc.ApplyChatMutation, syncservice.MutationOpts{})
+
+	tests := []struct {
+		name       string
+		path       string
+		uids       []string
+		wantStatus []string
+		check      func(t *testing.T, item *syncservice.RESTItem)
+	}{
+		{
+			name:       "notes use status=archived",
+			path:       "/v1/notes/batch_archive",
+			uids:       []string{active, deleted, missing, "not-a-uid"},
+			wantStatus: []string{"archived", "deleted", "not_found", "invalid"},
+			check: func(t *testing.T, item *syncservice.RESTItem) {
+				if item.Payload["status"] != "archived" {
+					t.Errorf("Expected status=archived, got %v", item.Payload["status"])
+				}
+			},
+		},
+		{
+			name:       "chats use archived=true",
+			path:       "/v1/chats/batch_archive",
+			uids:       []string{chat},
+			wantStatus: []string{"archived"},
+			check: func(t *testing.T, item *syncservice.RESTItem) {
+				if item.Payload["archived"] != true {
+					t.Errorf("Expected archived=true, got %v", item.Payload["archived"])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := makeRequestWithSession(t, router, "POST", tt.path, batchReq{UIDs: tt.uids}, session)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected 200, got %d %s", w.Code, w.Body.String())
+			}
+			var resp batchResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(resp.Results) != len(tt.wantStatus) {
+				t.Fatalf("Expected %d results, got %+v", len(tt.wantStatus), resp.Results)
+			}
+			for i, res := range resp.Results {
+				if res.UID != tt.uids[i] || res.Status != tt.wantStatus[i] {
+					t.Errorf("Result %d: expected %s %s, got %+v", i, tt.uids[i], tt.wantStatus[i], res)
+				}
+				if res.Status == "archived" {
+					tt.check(t, res.Item)
+				}
+			}
+		})
+	}
+
+	if w := makeRequestWithSession(t, router, "POST", "/v1/notes/batch_archive", batchReq{}, session); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for empty uids, got %d", w.Code)
+	}
+}
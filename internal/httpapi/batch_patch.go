@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// batchPatchReq is the request body for POST /v1/<entity>/batch_patch
+type batchPatchReq struct {
+	UIDs  []string       `json:"uids"`
+	Patch map[string]any `json:"patch"`
+}
+
+// BatchPatch returns the handler for POST /v1/<entity>/batch_patch
+// Merges the same partial update into every listed item in one transaction, the way
+// PATCH /v1/<entity>/{uid} merges it into one (see mergePatch), and reports a result per
+// UID. The patch goes through the reserved key mode once, up front (422 for the batch).
+func (s *Server) BatchPatch(table string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req batchPatchReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, 400, "invalid JSON")
+			return
+		}
+		if !checkBatchUIDs(w, r, req.UIDs) {
+			return
+		}
+		if len(req.Patch) == 0 {
+			writeError(w, r, 400, "patch must not be empty")
+			return
+		}
+		if err := s.syncCfg().CheckReservedKeys(req.Patch); err != nil {
+			writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		s.runBatch(w, r, table, req.UIDs, "patch", "patched", func(payload map[string]any) {
+			mergePatch(payload, req.Patch)
+		})
+	}
+}
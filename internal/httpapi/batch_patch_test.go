@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+)
+
+func TestBatchPatch_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	ctx := context.Background()
+	userID := createTestUser(t, pool, testUserSubject)
+	session := createTestSession(t, router)
+
+	create := func(opts syncservice.MutationOpts) string {
+		t.Helper()
+		uid := uuid.New().String()
+		if _, err := srv.NoteSvc.ApplyNoteMutation(ctx, userID, map[string]any{"uid": uid, "title": "Batch", "tags": []any{"old"}}, opts); err != nil {
+			t.Fatalf("Failed to create note: %v", err)
+		}
+		return uid
+	}
+	first, second := create(syncservice.MutationOpts{}), create(syncservice.MutationOpts{})
+	deleted := create(syncservice.MutationOpts{SetDeleted: true})
+	missing := uuid.New().String()
+
+	uids := []string{first, deleted, second, missing, "not-a-uid"}
+	w := makeRequestWithSession(t, router, "POST", "/v1/notes/batch_patch", batchPatchReq{
+		UIDs:  uids,
+		Patch: map[string]any{"tags": []any{"work"}, "uid": "ignored"},
+	}, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	var resp batchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	wantStatus := []string{"patched", "deleted", "patched", "not_found", "invalid"}
+	if len(resp.Results) != len(wantStatus) {
+		t.Fatalf("Expected %d results, got %+v", len(wantStatus), resp.Results)
+	}
+	for i, res := range resp.Results {
+		if res.UID != uids[i] || res.Status != wantStatus[i] {
+			t.Errorf("Result %d: expected %s %s, got %+v", i, uids[i], wantStatus[i], res)
+			continue
+		}
+		if res.Status != "patched" {
+			continue
+		}
+		if res.Item.UID != uids[i] || res.Item.Version != 2 {
+			t.Errorf("Result %d: expected %s at version 2, got %s v%d", i, uids[i], res.Item.UID, res.Item.Version)
+		}
+		if res.Item.Payload["title"] != "Batch" {
+			t.Errorf("Result %d: untouched field lost, got title %v", i, res.Item.Payload["title"])
+		}
+		if tags, _ := res.Item.Payload["tags"].([]any); len(tags) != 1 || tags[0] != "work" {
+			t.Errorf("Result %d: expected tags [work], got %v", i, res.Item.Payload["tags"])
+		}
+	}
+
+	for _, body := range []batchPatchReq{
+		{Patch: map[string]any{"tags": []any{}}},
+		{UIDs: []string{first}},
+	} {
+		if w := makeRequestWithSession(t, router, "POST", "/v1/notes/batch_patch", body, session); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %+v, got %d", body, w.Code)
+		}
+	}
+}
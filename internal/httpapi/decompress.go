@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultMaxDecompressedBytes caps a decompressed request body when Server.MaxDecompressedBytes is unset
+const DefaultMaxDecompressedBytes int64 = 32 << 20 // 32 MiB
+
+// errDecompressedTooLarge is returned by a decompressed body once it passes the size cap
+var errDecompressedTooLarge = errors.New("decompressed request body too large")
+
+// RequestDecompression transparently decompresses request bodies sent with
+// Content-Encoding: gzip, so handlers' JSON decoders see plain bytes. Decompressed output is
+// capped at maxBytes to stop zip bombs: reads past the cap fail, and the handler's error
+// response is replaced with a 413. Other encodings are rejected with 415.
+func RequestDecompression(maxBytes int64) func(http.Handler) http.Handler {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxDecompressedBytes
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			switch encoding {
+			case "", "identity":
+				next.ServeHTTP(w, r)
+				return
+			case "gzip", "x-gzip":
+			default:
+				writeError(w, r, http.StatusUnsupportedMediaType, "unsupported Content-Encoding "+strconv.Quote(encoding)+" (supported: gzip)")
+				return
+			}
+
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "invalid gzip request body")
+				return
+			}
+			defer gz.Close()
+
+			body := &cappedBody{r: gz, remaining: maxBytes, orig: r.Body}
+			r.Body = body
+			r.ContentLength = -1
+			r.Header.Del("Content-Length")
+			r.Header.Del("Content-Encoding")
+
+			next.ServeHTTP(&decompressWriter{ResponseWriter: w, r: r, body: body, maxBytes: maxBytes}, r)
+		})
+	}
+}
+
+// cappedBody reads a decompressed stream and fails once more than remaining bytes are read
+type cappedBody struct {
+	r         io.Reader
+	orig      io.Closer // the compressed request body
+	remaining int64
+	exceeded  bool
+}
+
+func (b *cappedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errDecompressedTooLarge
+	}
+	// Read one byte past the cap so an exactly-full body still succeeds
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.r.Read(p)
+	if int64(n) > b.remaining {
+		b.exceeded = true
+		return int(b.remaining), errDecompressedTooLarge
+	}
+	b.remaining -= int64(n)
+	return n, err
+}
+
+func (b *cappedBody) Close() error {
+	return b.orig.Close()
+}
+
+// decompressWriter turns the handler's error response into a 413 when the decompressed body
+// hit the cap (handlers only see a failed read and would answer 400 or 500)
+type decompressWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	body        *cappedBody
+	maxBytes    int64
+	wroteHeader bool
+	replaced    bool // handler response is discarded in favor of the 413
+}
+
+func (dw *decompressWriter) WriteHeader(code int) {
+	if dw.wroteHeader {
+		return
+	}
+	dw.wroteHeader = true
+
+	if code >= 400 && dw.body.exceeded {
+		dw.replaced = true
+		log.Ctx(dw.r.Context()).Warn().Str("path", dw.r.URL.Path).Int("handler_status", code).Msg("decompressed request body too large")
+		writeError(dw.ResponseWriter, dw.r, http.StatusRequestEntityTooLarge,
+			"decompressed request body exceeds "+strconv.FormatInt(dw.maxBytes, 10)+" bytes")
+		return
+	}
+	dw.ResponseWriter.WriteHeader(code)
+}
+
+func (dw *decompressWriter) Write(b []byte) (int, error) {
+	if !dw.wroteHeader {
+		dw.WriteHeader(http.StatusOK)
+	}
+	if dw.replaced {
+		return len(b), nil
+	}
+	return dw.ResponseWriter.Write(b)
+}
+
+func (dw *decompressWriter) Flush() {
+	if !dw.wroteHeader {
+		dw.WriteHeader(http.StatusOK)
+	}
+	if dw.replaced {
+		return
+	}
+	http.NewResponseController(dw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the connection's writer
+func (dw *decompressWriter) Unwrap() http.ResponseWriter {
+	return dw.ResponseWriter
+}
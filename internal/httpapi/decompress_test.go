@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestDecompression(t *testing.T) {
+	gzipped := func(s string) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write([]byte(s)); err != nil {
+			t.Fatalf("Failed to compress body: %v", err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatalf("Failed to compress body: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	// Echoes the decoded JSON like a real handler, answering 400 on a failed decode
+	handler := RequestDecompression(64)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, r, http.StatusBadRequest, "invalid json")
+			return
+		}
+		if r.Header.Get("Content-Encoding") != "" {
+			t.Errorf("Expected Content-Encoding to be removed, got %q", r.Header.Get("Content-Encoding"))
+		}
+		writeJSON(w, http.StatusOK, body)
+	}))
+
+	tests := []struct {
+		name           string
+		encoding       string
+		body           []byte
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "plain body passes through",
+			body:           []byte(`{"title":"plain"}`),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"title":"plain"`,
+		},
+		{
+			name:           "gzip body is decompressed",
+			encoding:       "gzip",
+			body:           gzipped(`{"title":"gzip"}`),
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"title":"gzip"`,
+		},
+		{
+			name:           "body over the cap is rejected",
+			encoding:       "gzip",
+			body:           gzipped(`{"title":"` + strings.Repeat("a", 1000) + `"}`),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   "exceeds 64 bytes",
+		},
+		{
+			name:           "corrupt gzip body",
+			encoding:       "gzip",
+			body:           []byte(`{"title":"not gzip"}`),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   "invalid gzip",
+		},
+		{
+			name:           "unsupported encoding",
+			encoding:       "br",
+			body:           []byte(`{}`),
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedBody:   "supported: gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/notes", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			got, _ := io.ReadAll(w.Body)
+			if !strings.Contains(string(got), tt.expectedBody) {
+				t.Errorf("Expected body to contain %q, got %s", tt.expectedBody, got)
+			}
+		})
+	}
+}
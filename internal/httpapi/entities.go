@@ -0,0 +1,121 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// entityDef describes one entity type exposed over sync and REST
+type entityDef struct {
+	Name    string      // URL name (e.g. "chat_messages")
+	Table   string      // database table (e.g. "chat_message")
+	Actions actionTable // POST /<entity>/{uid}/process actions
+}
+
+// entityCatalog is the authoritative list of entity types, in documentation order.
+// Sync info, GET /v1/entities and per-entity wipe are all derived from it.
+var entityCatalog = []entityDef{
+	{"notes", "note", noteActions},
+	{"tasks", "task", taskActions},
+	{"comments", "comment", commentActions},
+	{"chats", "chat", chatActions},
+	{"chat_messages", "chat_message", chatMessageActions},
+	{"task_lists", "task_list", taskListActions},
+	{"task_list_categories", "task_list_category", taskListCategoryActions},
+}
+
+// ParseDisabledEntities parses a comma-separated list of entity names (e.g. "task_list_categories")
+// to ship dark from config
+func ParseDisabledEntities(v string) (map[string]bool, error) {
+	disabled := map[string]bool{}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := syncEntityTables[name]; !ok {
+			return nil, fmt.Errorf("unknown entity %q", name)
+		}
+		disabled[name] = true
+	}
+	return disabled, nil
+}
+
+// EntityEnabled reports whether an entity type is served (see Server.DisabledEntities)
+func (s *Server) EntityEnabled(name string) bool {
+	return !s.DisabledEntities[name]
+}
+
+// itemStore reads and mutates one entity type through its service (REST semantics)
+type itemStore struct {
+	get     func(ctx context.Context, userID string, uid uuid.UUID) (*syncservice.RESTItem, error)
+	apply   func(ctx context.Context, userID string, payload map[string]any, opts syncservice.MutationOpts) (*syncservice.RESTItem, error)
+	applyTx func(ctx context.Context, tx pgx.Tx, userID string, payload map[string]any, opts syncservice.MutationOpts) (*syncservice.RESTItem, error)
+}
+
+// itemStores maps entity tables to their services
+func (s *Server) itemStores() map[string]itemStore {
+	return map[string]itemStore{
+		"note":               {s.NoteSvc.GetNote, s.NoteSvc.ApplyNoteMutation, s.NoteSvc.ApplyNoteMutationTx},
+		"task":               {s.TaskSvc.GetTask, s.TaskSvc.ApplyTaskMutation, s.TaskSvc.ApplyTaskMutationTx},
+		"comment":            {s.CommentSvc.GetComment, s.CommentSvc.ApplyCommentMutation, s.CommentSvc.ApplyCommentMutationTx},
+		"chat":               {s.ChatSvc.GetChat, s.ChatSvc.ApplyChatMutation, s.ChatSvc.ApplyChatMutationTx},
+		"chat_message":       {s.ChatMessageSvc.GetChatMessage, s.ChatMessageSvc.ApplyChatMessageMutation, s.ChatMessageSvc.ApplyChatMessageMutationTx},
+		"task_list":          {s.TaskListSvc.GetTaskList, s.TaskListSvc.ApplyTaskListMutation, s.TaskListSvc.ApplyTaskListMutationTx},
+		"task_list_category": {s.TaskListCategorySvc.GetTaskListCategory, s.TaskListCategorySvc.ApplyTaskListCategoryMutation, s.TaskListCategorySvc.ApplyTaskListCategoryMutationTx},
+	}
+}
+
+// sortableFields are the orderings REST lists and pulls support (cursor order is fixed)
+var sortableFields = []string{"updatedAt"}
+
+// EntityDescription is the machine-readable description of one entity type
+type EntityDescription struct {
+	Name             string   `json:"name"`
+	Push             bool     `json:"push"`
+	Pull             bool     `json:"pull"`
+	DefaultLimit     int      `json:"defaultLimit"` // page size when a pull or list sends no limit
+	MaxLimit         int      `json:"maxLimit"`     // larger limits are clamped to it
+	ProcessActions   []string `json:"processActions"`
+	FilterableFields []string `json:"filterableFields"` // keys accepted by ?where=key:value
+	SortableFields   []string `json:"sortableFields"`
+}
+
+// entitiesResponse is the body of GET /v1/entities
+type entitiesResponse struct {
+	Entities []EntityDescription `json:"entities"`
+}
+
+// ListEntities handles GET /v1/entities
+// Describes each entity type's process actions, filterable fields and sort order so
+// clients and the MCP layer don't have to hard-code them. Unauthenticated, like /v1/sync/info.
+// Disabled entity types are left out.
+func (s *Server) ListEntities(w http.ResponseWriter, r *http.Request) {
+	resp := entitiesResponse{Entities: make([]EntityDescription, 0, len(entityCatalog))}
+	for _, e := range entityCatalog {
+		if !s.EntityEnabled(e.Name) {
+			continue
+		}
+		filterable := s.syncCfg().QueryableFields(e.Table)
+		if filterable == nil {
+			filterable = []string{}
+		}
+		resp.Entities = append(resp.Entities, EntityDescription{
+			Name:             e.Name,
+			Push:             true,
+			Pull:             true,
+			DefaultLimit:     s.syncCfg().PageLimitFor(e.Table).Default,
+			MaxLimit:         s.syncCfg().PageLimitFor(e.Table).Max,
+			ProcessActions:   e.Actions.names(),
+			FilterableFields: filterable,
+			SortableFields:   sortableFields,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// APIVersion is the REST/sync API version reported by /v1/sync/info and response envelopes
+const APIVersion = "1.1"
+
+// responseMeta is server metadata piggybacked on enveloped responses
+// Lets clients detect wipes (epoch) and clock skew without extra calls.
+type responseMeta struct {
+	Epoch        *int   `json:"epoch,omitempty"` // only on epoch-validated routes
+	ServerTimeMs int64  `json:"serverTimeMs"`
+	APIVersion   string `json:"apiVersion"`
+}
+
+// responseEnvelope wraps a JSON response body when the client opts in
+type responseEnvelope struct {
+	Data any          `json:"data"`
+	Meta responseMeta `json:"meta"`
+}
+
+// EnvelopeMiddleware opts a request into enveloped responses ({data, meta})
+// Clients opt in with ?envelope=true or an Accept media type parameter
+// (Accept: application/json; envelope=true). The raw form stays the default.
+// writeJSON does the wrapping; error bodies from writeError are never enveloped.
+func EnvelopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if wantsEnvelope(r) {
+			w = &envelopeWriter{ResponseWriter: w}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// wantsEnvelope reports whether the client asked for enveloped responses
+func wantsEnvelope(r *http.Request) bool {
+	if r.URL.Query().Get("envelope") == "true" {
+		return true
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if _, params, err := mime.ParseMediaType(strings.TrimSpace(accept)); err == nil && params["envelope"] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// envelopeWriter marks a response for wrapping by writeJSON
+type envelopeWriter struct {
+	http.ResponseWriter
+}
+
+func (ew *envelopeWriter) Flush() {
+	http.NewResponseController(ew.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the connection's writer
+func (ew *envelopeWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
+
+// wrap builds the envelope for a response body
+// The epoch comes from the X-Sync-Epoch response header set by EpochRequired.
+func (ew *envelopeWriter) wrap(v any) responseEnvelope {
+	meta := responseMeta{
+		ServerTimeMs: syncx.NowMs(),
+		APIVersion:   APIVersion,
+	}
+	if epoch, err := strconv.Atoi(ew.Header().Get("X-Sync-Epoch")); err == nil {
+		meta.Epoch = &epoch
+	}
+	return responseEnvelope{Data: v, Meta: meta}
+}
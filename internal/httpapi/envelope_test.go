@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnvelopeMiddleware(t *testing.T) {
+	handler := EnvelopeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Sync-Epoch", "3")
+		writeJSON(w, http.StatusOK, map[string]any{"uid": "abc"})
+	}))
+
+	tests := []struct {
+		name     string
+		path     string
+		accept   string
+		envelope bool
+	}{
+		{"raw by default", "/v1/notes", "", false},
+		{"query opt-in", "/v1/notes?envelope=true", "", true},
+		{"accept param opt-in", "/v1/notes", "text/plain, application/json; envelope=true", true},
+		{"plain accept stays raw", "/v1/notes", "application/json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			var body map[string]any
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+
+			if !tt.envelope {
+				if body["uid"] != "abc" {
+					t.Errorf("Expected raw body, got %v", body)
+				}
+				return
+			}
+
+			data, _ := body["data"].(map[string]any)
+			if data["uid"] != "abc" {
+				t.Errorf("Expected data to hold the raw body, got %v", body)
+			}
+			meta, _ := body["meta"].(map[string]any)
+			if meta["apiVersion"] != APIVersion {
+				t.Errorf("Expected apiVersion %s, got %v", APIVersion, meta["apiVersion"])
+			}
+			if meta["epoch"] != float64(3) {
+				t.Errorf("Expected epoch 3, got %v", meta["epoch"])
+			}
+			if ms, _ := meta["serverTimeMs"].(float64); ms <= 0 {
+				t.Errorf("Expected serverTimeMs, got %v", meta["serverTimeMs"])
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/session"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/rs/zerolog/log"
@@ -13,11 +15,14 @@ import (
 // EpochRequired middleware validates that the client's X-Sync-Epoch header
 // matches the server's current epoch for the authenticated user.
 //
-// If the client's epoch is less than the server's epoch, returns 409 Conflict
-// with the current epoch in the response body and X-Sync-Epoch header.
+// If the client's epoch differs from the server's epoch, returns 409 Conflict
+// with code "resyncRequired" and the current epoch in the response body and
+// X-Sync-Epoch header, before the handler runs (gRPC: FailedPrecondition, see
+// grpcapi.EpochInterceptor).
 //
-// This prevents stale clients from pushing/pulling data after a server wipe.
-func EpochRequired(db *pgxpool.Pool) func(http.Handler) http.Handler {
+// This prevents stale clients from pushing/pulling data after a server wipe or
+// resync: a pull is rejected outright rather than paging against the old baseline.
+func EpochRequired(pool *pgxpool.Pool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			userID := auth.UserID(r.Context())
@@ -27,18 +32,21 @@ func EpochRequired(db *pgxpool.Pool) func(http.Handler) http.Handler {
 			}
 
 			// Load current user epoch (lazy create if not exists)
+			// Idempotent, so it is retried across brief connection loss (see db.Retry)
 			var epoch int
-			err := db.QueryRow(r.Context(), `
-				INSERT INTO owner_state(owner_id, epoch, created_at, updated_at)
-				VALUES ($1, 1, NOW(), NOW())
-				ON CONFLICT (owner_id) DO NOTHING
-				RETURNING epoch
-			`, userID).Scan(&epoch)
+			err := db.Retry(r.Context(), db.DefaultRetry, func() error {
+				return pool.QueryRow(r.Context(), `
+					INSERT INTO owner_state(owner_id, epoch, created_at, updated_at)
+					VALUES ($1, 1, NOW(), NOW())
+					ON CONFLICT (owner_id) DO NOTHING
+					RETURNING epoch
+				`, userID).Scan(&epoch)
+			})
 
 			if err != nil {
 				// If insert did nothing, select existing epoch
 				if err == pgx.ErrNoRows {
-					err = db.QueryRow(r.Context(),
+					err = pool.QueryRow(r.Context(),
 						`SELECT epoch FROM owner_state WHERE owner_id = $1`,
 						userID,
 					).Scan(&epoch)
@@ -61,8 +69,9 @@ func EpochRequired(db *pgxpool.Pool) func(http.Handler) http.Handler {
 				clientEpoch, _ = strconv.Atoi(clientEpochStr)
 			}
 
-			// If client epoch is behind server epoch, reject with 409
-			if clientEpoch < epoch {
+			// If client epoch is not the server epoch, reject with 409
+			// (ahead happens after a restore from backup; its baseline is just as invalid)
+			if clientEpoch != epoch {
 				log.Warn().
 					Str("userId", userID).
 					Int("clientEpoch", clientEpoch).
@@ -75,13 +84,15 @@ func EpochRequired(db *pgxpool.Pool) func(http.Handler) http.Handler {
 		
 				writeJSON(w, http.StatusConflict, map[string]any{
 					"error":          "epoch_mismatch",
+					"code":           session.ResyncRequiredCode,
 					"epoch":          epoch,
 					"correlation_id": r.Header.Get("X-Correlation-ID"),
 				})
 				return
 			}
 
-			// Epoch matches or client is ahead (shouldn't happen, but allow)
+			// Echo the server epoch so clients (and response envelopes) can see it on every response
+			w.Header().Set("X-Sync-Epoch", strconv.Itoa(epoch))
 			next.ServeHTTP(w, r)
 		})
 	}
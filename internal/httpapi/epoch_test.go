@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/session"
+)
+
+func TestEpochRequired_PullResyncRequired_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	sess := createTestSession(t, router)
+
+	if w := makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, sess); w.Code != http.StatusOK {
+		t.Fatalf("Pull with the current epoch: %d %s", w.Code, w.Body.String())
+	}
+
+	for _, epoch := range []int{sess.Epoch - 1, sess.Epoch + 1} {
+		stale := sess
+		stale.Epoch = epoch
+		w := makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, stale)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("Pull with epoch %d: expected 409, got %d %s", epoch, w.Code, w.Body.String())
+		}
+		var body struct {
+			Code  string `json:"code"`
+			Epoch int    `json:"epoch"`
+			Items []any  `json:"items"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if body.Code != session.ResyncRequiredCode || body.Epoch != sess.Epoch || body.Items != nil {
+			t.Errorf("Pull with epoch %d: got %+v, want code %q, epoch %d and no items", epoch, body, session.ResyncRequiredCode, sess.Epoch)
+		}
+		if got := w.Header().Get("X-Sync-Epoch"); got != strconv.Itoa(sess.Epoch) {
+			t.Errorf("Pull with epoch %d: X-Sync-Epoch = %q, want %d", epoch, got, sess.Epoch)
+		}
+	}
+}
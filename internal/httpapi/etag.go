@@ -0,0 +1,139 @@
+package httpapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+// ETagMode selects how REST item ETags are computed
+type ETagMode string
+
+const (
+	// ETagVersion uses the item version (e.g. "5"); If-Match is checked against the version
+	ETagVersion ETagMode = "version"
+	// ETagContent uses a hash of the payload, so items with equal versions but different
+	// content (e.g. after a resolved conflict) never share an ETag
+	ETagContent ETagMode = "content"
+)
+
+// ParseETagMode validates an ETag mode ("version" or "content")
+func ParseETagMode(v string) (ETagMode, error) {
+	switch m := ETagMode(v); m {
+	case ETagVersion, ETagContent:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown ETag mode %q (want %q or %q)", v, ETagVersion, ETagContent)
+}
+
+// contentHashLen is the number of hex characters kept from the SHA-256 (64 bits)
+const contentHashLen = 16
+
+// contentHash returns a stable hash of a payload: SHA-256 of its canonical JSON
+// (encoding/json sorts map keys), truncated to contentHashLen hex characters
+func contentHash(payload map[string]any) string {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])[:contentHashLen]
+}
+
+// itemETag returns the quoted ETag for an item under the server's ETag mode
+func (s *Server) itemETag(item *syncservice.RESTItem) string {
+	if s.ETagMode == ETagContent {
+		return `"` + contentHash(item.Payload) + `"`
+	}
+	return `"` + strconv.Itoa(item.Version) + `"`
+}
+
+// writeItem writes a single REST item with its ETag header
+func (s *Server) writeItem(w http.ResponseWriter, code int, item *syncservice.RESTItem) {
+	w.Header().Set("ETag", s.itemETag(item))
+	writeJSON(w, code, item)
+}
+
+// writeCreated writes a newly created item as 201 with its ETag and a Location header
+// pointing at the item's canonical URL under collection (e.g. "/v1/notes")
+func (s *Server) writeCreated(w http.ResponseWriter, collection string, item *syncservice.RESTItem) {
+	w.Header().Set("Location", collection+"/"+item.UID)
+	s.writeItem(w, http.StatusCreated, item)
+}
+
+// notModified reports whether If-None-Match matches the item's current ETag
+// (a comma-separated list; "*" and weak W/ tags are accepted)
+func (s *Server) notModified(r *http.Request, item *syncservice.RESTItem) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	etag := s.itemETag(item)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchOpts builds mutation options from the If-Match header for an update of existing.
+// In version mode the header carries the expected version and the service enforces it.
+// In content mode it must equal the content ETag of existing (412 otherwise); on a match
+// the service still enforces existing's version so a concurrent write is caught.
+// Without If-Match, If-Unmodified-Since is checked instead (see ifUnmodifiedSinceOpts).
+// Returns ok=false when a response has been written.
+func (s *Server) ifMatchOpts(w http.ResponseWriter, r *http.Request, existing *syncservice.RESTItem) (opts syncservice.MutationOpts, usedIfMatch bool, ok bool) {
+	if strings.TrimSpace(r.Header.Get("If-Match")) == "" {
+		if since, precise, ok := parseIfUnmodifiedSince(r); ok {
+			return s.ifUnmodifiedSinceOpts(w, r, existing, since, precise)
+		}
+	}
+	if s.ETagMode != ETagContent {
+		if version, ok := parseIfMatchHeader(r); ok {
+			return syncservice.MutationOpts{EnforceVersion: true, ExpectedVersion: version}, true, true
+		}
+		return opts, false, true
+	}
+
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" {
+		return opts, false, true
+	}
+	if ifMatch != "*" && strings.Trim(ifMatch, `"`) != contentHash(existing.Payload) {
+		w.Header().Set("ETag", s.itemETag(existing))
+		writeError(w, r, http.StatusPreconditionFailed, "precondition failed: item content has changed")
+		return opts, true, false
+	}
+	return syncservice.MutationOpts{EnforceVersion: true, ExpectedVersion: existing.Version}, true, true
+}
+
+// ifUnmodifiedSinceOpts checks If-Unmodified-Since for an update of existing: 412 if the
+// item's updatedAt is after since (compared in whole seconds for an HTTP-date). On success
+// the service enforces existing's version, so a write that lands in between is a 412 too.
+// RFC 7232 has If-Match take precedence, so this only runs when If-Match is absent.
+func (s *Server) ifUnmodifiedSinceOpts(w http.ResponseWriter, r *http.Request, existing *syncservice.RESTItem, since time.Time, precise bool) (syncservice.MutationOpts, bool, bool) {
+	updatedAt, err := time.Parse(time.RFC3339Nano, existing.UpdatedAt)
+	if err != nil {
+		// Stored timestamps are always RFC 3339; fall back to an unconditional write
+		return syncservice.MutationOpts{}, false, true
+	}
+	if !precise {
+		updatedAt = updatedAt.Truncate(time.Second)
+	}
+	if updatedAt.After(since) {
+		w.Header().Set("ETag", s.itemETag(existing))
+		w.Header().Set("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+		writeError(w, r, http.StatusPreconditionFailed, "precondition failed: item modified at "+existing.UpdatedAt)
+		return syncservice.MutationOpts{}, true, false
+	}
+	return syncservice.MutationOpts{EnforceVersion: true, ExpectedVersion: existing.Version}, true, true
+}
@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/rs/zerolog/log"
+)
+
+// maxExpandChildren caps how many children ?expand=children embeds; the embedded
+// nextCursor continues the same filtered list (GET /v1/<children>?where=<field>:<uid>)
+const maxExpandChildren = 50
+
+// itemExpansions lists the ?expand= values each entity table's single-item GET accepts
+var itemExpansions = map[string][]string{
+	"comment":      {"parent"},
+	"chat_message": {"parent"},
+	"chat":         {"children"},
+	"task_list":    {"children"},
+}
+
+// childRelation is how an entity table's children point back at it
+type childRelation struct {
+	Entity string // child entity name (e.g. "chat_messages")
+	Field  string // child payload field holding the parent UID (filterable, see FilterableFields)
+	list   func(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts syncservice.ListOpts) (*syncservice.RESTListResponse, error)
+}
+
+// childRelations maps parent tables to their children
+func (s *Server) childRelations() map[string]childRelation {
+	return map[string]childRelation{
+		"chat":      {"chat_messages", "chatUid", s.ChatMessageSvc.ListChatMessages},
+		"task_list": {"tasks", "taskListUid", s.TaskSvc.ListTasks},
+	}
+}
+
+// expandedItem is a single item with related items embedded (?expand=)
+// Parent is left out when the parent no longer exists; a soft-deleted parent is embedded
+// with its deletedAt so the client can tell.
+type expandedItem struct {
+	*syncservice.RESTItem
+	Parent   *syncservice.RESTItem         `json:"parent,omitempty"`
+	Children *syncservice.RESTListResponse `json:"children,omitempty"`
+}
+
+// parseExpand reads ?expand= for a single-item GET of table ("" = none)
+// Expansion is one level deep: embedded items are never expanded themselves.
+func parseExpand(r *http.Request, table string) (string, error) {
+	expand := r.URL.Query().Get("expand")
+	if expand == "" {
+		return "", nil
+	}
+	if strings.ContainsAny(expand, ".,") {
+		return "", fmt.Errorf("expand %q: only one relationship, one level deep, can be expanded", expand)
+	}
+	allowed := itemExpansions[table]
+	if !slices.Contains(allowed, expand) {
+		return "", fmt.Errorf("unknown expand %q (allowed: %s)", expand, strings.Join(allowed, ", "))
+	}
+	return expand, nil
+}
+
+// writeExpandedItem writes item with its expand relationship embedded
+// The body depends on more than the item, so it carries no ETag and skips If-None-Match.
+func (s *Server) writeExpandedItem(w http.ResponseWriter, r *http.Request, table, expand string, item *syncservice.RESTItem) {
+	ctx := r.Context()
+	userID := auth.UserID(ctx)
+
+	out := expandedItem{RESTItem: item}
+	var err error
+	switch expand {
+	case "parent":
+		out.Parent, err = s.expandParent(ctx, userID, table, item)
+	case "children":
+		out.Children, err = s.expandChildren(ctx, userID, table, item)
+	}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("table", table).Str("expand", expand).Msg("failed to expand item")
+		writeError(w, r, 500, "failed to expand "+expand)
+		return
+	}
+
+	writeJSON(w, 200, out)
+}
+
+// expandParent loads the item a comment or chat message belongs to (nil if it is gone
+// or its entity type is disabled)
+func (s *Server) expandParent(ctx context.Context, userID, table string, item *syncservice.RESTItem) (*syncservice.RESTItem, error) {
+	var parentTable, field string
+	switch table {
+	case "comment":
+		parentTable, _ = syncx.GetString(item.Payload, "parentType")
+		field = "parentUid"
+	case "chat_message":
+		parentTable, field = "chat", "chatUid"
+	}
+
+	raw, _ := syncx.GetString(item.Payload, field)
+	uid, ok := syncx.ParseUUID(raw)
+	store, known := s.itemStores()[parentTable]
+	if !ok || !known || !s.EntityEnabled(entityForTable(parentTable)) {
+		return nil, nil
+	}
+	return store.get(ctx, userID, uid)
+}
+
+// expandChildren lists the first maxExpandChildren live children of a chat or task list
+func (s *Server) expandChildren(ctx context.Context, userID, table string, item *syncservice.RESTItem) (*syncservice.RESTListResponse, error) {
+	rel := s.childRelations()[table]
+	if !s.EntityEnabled(rel.Entity) {
+		return nil, nil
+	}
+	opts := syncservice.ListOpts{Where: []syncservice.FieldFilter{{Key: rel.Field, Value: item.UID}}}
+	return rel.list(ctx, userID, syncx.Cursor{}, maxExpandChildren, opts)
+}
+
+// entityForTable returns the entity name for a table ("" if unknown)
+func entityForTable(table string) string {
+	for _, e := range entityCatalog {
+		if e.Table == table {
+			return e.Name
+		}
+	}
+	return ""
+}
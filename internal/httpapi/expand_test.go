@@ -0,0 +1,128 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+func TestParseExpand(t *testing.T) {
+	tests := []struct {
+		table   string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{table: "comment", query: "", want: ""},
+		{table: "comment", query: "expand=parent", want: "parent"},
+		{table: "chat", query: "expand=children", want: "children"},
+		{table: "chat", query: "expand=parent", wantErr: true},
+		{table: "note", query: "expand=parent", wantErr: true},
+		{table: "chat_message", query: "expand=parent.parent", wantErr: true}, // one level only
+		{table: "task_list", query: "expand=children,parent", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.table+"?"+tt.query, func(t *testing.T) {
+			got, err := parseExpand(httptest.NewRequest("GET", "/v1/x?"+tt.query, nil), tt.table)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("expand = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetExpand_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	for _, table := range []string{"chat_message", "chat", "comment", "task"} {
+		_, _ = pool.Exec(context.Background(), "DELETE FROM "+table)
+	}
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+		TaskSvc:         syncservice.NewTaskService(pool),
+		CommentSvc:      syncservice.NewCommentService(pool),
+		ChatSvc:         syncservice.NewChatService(pool),
+		ChatMessageSvc:  syncservice.NewChatMessageService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+	noteUID, _ := setupCommentTest(t, router, session)
+
+	create := func(path string, body map[string]any) string {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "POST", path, body, session)
+		if w.Code != 201 {
+			t.Fatalf("POST %s: status %d: %s", path, w.Code, w.Body.String())
+		}
+		var item syncservice.RESTItem
+		if err := json.NewDecoder(w.Body).Decode(&item); err != nil {
+			t.Fatalf("Failed to decode created item: %v", err)
+		}
+		return item.UID
+	}
+	get := func(path string) expandedItem {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "GET", path, nil, session)
+		if w.Code != 200 {
+			t.Fatalf("GET %s: status %d: %s", path, w.Code, w.Body.String())
+		}
+		if w.Header().Get("ETag") != "" {
+			t.Errorf("GET %s: expanded responses should carry no ETag", path)
+		}
+		var out expandedItem
+		if err := json.NewDecoder(w.Body).Decode(&out); err != nil {
+			t.Fatalf("Failed to decode expanded item: %v", err)
+		}
+		return out
+	}
+
+	// Comment -> parent note
+	commentUID := create("/v1/comments", map[string]any{"content": "hi", "parentType": "note", "parentUid": noteUID})
+	out := get("/v1/comments/" + commentUID + "?expand=parent")
+	if out.RESTItem == nil || out.UID != commentUID {
+		t.Fatalf("Expected the comment itself, got %+v", out.RESTItem)
+	}
+	if out.Parent == nil || out.Parent.UID != noteUID {
+		t.Errorf("Expected parent note %s, got %+v", noteUID, out.Parent)
+	}
+
+	// Chat -> children, capped at maxExpandChildren
+	chatUID := create("/v1/chats", map[string]any{"title": "c"})
+	for i := 0; i < maxExpandChildren+1; i++ {
+		create("/v1/chat_messages", map[string]any{"chatUid": chatUID, "role": "user", "content": "m"})
+	}
+	out = get("/v1/chats/" + chatUID + "?expand=children")
+	if out.Children == nil || len(out.Children.Items) != maxExpandChildren {
+		t.Fatalf("Expected %d embedded children, got %+v", maxExpandChildren, out.Children)
+	}
+	if out.Children.NextCursor == nil {
+		t.Error("Expected a nextCursor to continue listing children")
+	}
+
+	// Chat message -> parent chat
+	msg := out.Children.Items[0]
+	if parent := get("/v1/chat_messages/" + msg.UID + "?expand=parent").Parent; parent == nil || parent.UID != chatUID {
+		t.Errorf("Expected parent chat %s, got %+v", chatUID, parent)
+	}
+
+	// Unsupported expansions are rejected
+	if w := makeRequestWithSession(t, router, "GET", "/v1/chats/"+chatUID+"?expand=parent", nil, session); w.Code != 400 {
+		t.Errorf("Expected 400 for unsupported expand, got %d", w.Code)
+	}
+}
@@ -0,0 +1,170 @@
+package httpapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/rs/zerolog/log"
+)
+
+// exportPageSize is how many items exportCSV reads per list page (the client sees one file)
+const exportPageSize = 500
+
+// exportStatusTrailer is the trailer exportCSV sets to "complete" once every row is written
+const exportStatusTrailer = "X-Export-Status"
+
+// exportCSV handles GET /v1/<entity>/export.csv
+// Streams every matching item as one CSV row under a header row: the item's uid, version,
+// updatedAt and deletedAt, then its exportable payload fields (see syncservice.Config.ExportColumnsFor),
+// or the subset named by ?columns=a,b in that order. Takes the list filters
+// (?includeDeleted, ?deletedOnly, ?where=, ?order=). Pages are read with the list query
+// and flushed as they go, each under the request timeout (see responseStream).
+//
+// A failure on the first page is a plain 500. Once rows have been sent the status can't
+// change, so a later failure aborts the connection: the client sees a truncated transfer
+// rather than a file that merely looks short, and only a complete export ends with the
+// X-Export-Status: complete trailer.
+func (s *Server) exportCSV(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := auth.UserID(r.Context())
+		ctx := r.Context()
+		logger := log.Ctx(ctx)
+
+		columns, err := s.parseExportColumns(r, e.Table)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		listOpts, err := s.parseListOpts(r, e.Table)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		if e.listWhere != nil {
+			filters, err := e.listWhere(r)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, err.Error())
+				return
+			}
+			listOpts.Where = append(listOpts.Where, filters...)
+		}
+
+		stream := s.newResponseStream(w)
+		cw := csv.NewWriter(w)
+		started := false
+		count := 0
+		var cursor syncx.Cursor
+		for {
+			pageCtx, cancel := stream.page(ctx)
+			resp, err := e.list(pageCtx, userID, cursor, exportPageSize, listOpts)
+			cancel()
+			if err != nil {
+				logger.Error().Err(err).Str("table", e.Table).Int("count", count).Msg("export list failed")
+				if !started {
+					writeError(w, r, http.StatusInternalServerError, "export failed")
+					return
+				}
+				panic(http.ErrAbortHandler) // cut the body off without a clean end
+			}
+
+			// Headers wait for the first page, so an export that fails up front gets a real error
+			if !started {
+				started = true
+				w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+				w.Header().Set("Content-Disposition", `attachment; filename="`+e.Name+`.csv"`)
+				w.Header().Set("Trailer", exportStatusTrailer)
+				w.WriteHeader(http.StatusOK)
+				if err := cw.Write(append(slices.Clone(syncservice.ExportMetaColumns), columns...)); err != nil {
+					return // client went away
+				}
+			}
+			for _, item := range resp.Items {
+				if err := cw.Write(exportRow(item, columns)); err != nil {
+					return
+				}
+			}
+			count += len(resp.Items)
+			cw.Flush()
+			stream.flush()
+
+			if resp.NextCursor == nil || (len(resp.Items) < exportPageSize && !resp.ByteLimited) {
+				break
+			}
+			if cursor, err = syncx.DecodeCursor(*resp.NextCursor); err != nil {
+				logger.Error().Err(err).Str("table", e.Table).Msg("export produced an unreadable cursor")
+				panic(http.ErrAbortHandler)
+			}
+		}
+		w.Header().Set(exportStatusTrailer, "complete")
+
+		logger.Info().
+			Str("user_id", userID).
+			Str("table", e.Table).
+			Int("count", count).
+			Msg("rest_export_completed")
+	}
+}
+
+// parseExportColumns reads ?columns= against an entity's exportable fields
+// No parameter means all of them; an unknown name is an error.
+func (s *Server) parseExportColumns(r *http.Request, table string) ([]string, error) {
+	allowed := s.syncCfg().ExportColumnsFor(table)
+	raw := r.URL.Query().Get("columns")
+	if raw == "" {
+		return allowed, nil
+	}
+	var columns []string
+	for _, c := range strings.Split(raw, ",") {
+		if c = strings.TrimSpace(c); c == "" {
+			continue
+		}
+		if !slices.Contains(allowed, c) {
+			return nil, fmt.Errorf("column %q is not exportable (allowed: %s)", c, strings.Join(allowed, ", "))
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// exportRow flattens an item into CSV cells for the meta columns and columns
+func exportRow(item syncservice.RESTItem, columns []string) []string {
+	deletedAt := ""
+	if item.DeletedAt != nil {
+		deletedAt = *item.DeletedAt
+	}
+	row := []string{item.UID, strconv.Itoa(item.Version), item.UpdatedAt, deletedAt}
+	for _, c := range columns {
+		row = append(row, exportCell(item.Payload[c]))
+	}
+	return row
+}
+
+// exportCell renders one payload value as a CSV cell: strings as-is, absent and null
+// as empty, and numbers, booleans, objects and arrays as JSON. Strings a spreadsheet
+// would run as a formula (leading =, +, -, @, tab or carriage return) are prefixed
+// with a single quote.
+func exportCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,148 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+)
+
+func TestExportCell(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{nil, ""},
+		{"plain", "plain"},
+		{"", ""},
+		{"=SUM(A1)", "'=SUM(A1)"},
+		{"-1", "'-1"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\rcmd", "'\rcmd"},
+		{3.0, "3"},
+		{2.5, "2.5"},
+		{true, "true"},
+		{[]any{"a", "b"}, `["a","b"]`},
+		{map[string]any{"k": "v"}, `{"k":"v"}`},
+	}
+	for _, tt := range tests {
+		if got := exportCell(tt.in); got != tt.want {
+			t.Errorf("exportCell(%#v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	deletedAt := "2025-01-02T00:00:00Z"
+	all := []syncservice.RESTItem{
+		{UID: "u1", Version: 1, UpdatedAt: "2025-01-01T00:00:00Z", Payload: map[string]any{"title": "one", "tags": []any{"x"}, "done": false}},
+		{UID: "u2", Version: 2, UpdatedAt: "2025-01-01T00:00:01Z", Payload: map[string]any{"title": "two, with comma"}},
+		{UID: "u3", Version: 3, UpdatedAt: "2025-01-01T00:00:02Z", DeletedAt: &deletedAt, Payload: map[string]any{"title": "three"}},
+	}
+
+	// Serves all in byte-limited pages of two, keyed by the position in the cursor's Ms
+	var gotOpts syncservice.ListOpts
+	e := restEntity{
+		entityDef: entityDef{Name: "tasks", Table: "task"},
+		list: func(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts syncservice.ListOpts) (*syncservice.RESTListResponse, error) {
+			gotOpts = opts
+			start := int(cursor.Ms)
+			end := min(start+2, len(all))
+			resp := &syncservice.RESTListResponse{Items: all[start:end]}
+			if end < len(all) {
+				next := syncx.EncodeCursor(syncx.Cursor{Ms: int64(end), UID: uuid.New()})
+				resp.NextCursor = &next
+				resp.ByteLimited = true
+			}
+			return resp, nil
+		},
+	}
+
+	w := httptest.NewRecorder()
+	(&Server{}).exportCSV(e)(w, httptest.NewRequest("GET", "/v1/tasks/export.csv?columns=title,tags,done&includeDeleted=true&where=status:open", nil))
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !gotOpts.IncludeDeleted || len(gotOpts.Where) != 1 {
+		t.Errorf("list filters not passed through: %+v", gotOpts)
+	}
+	if got := w.Result().Trailer.Get(exportStatusTrailer); got != "complete" {
+		t.Errorf("%s trailer = %q, want complete", exportStatusTrailer, got)
+	}
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"uid", "version", "updatedAt", "deletedAt", "title", "tags", "done"},
+		{"u1", "1", "2025-01-01T00:00:00Z", "", "one", `["x"]`, "false"},
+		{"u2", "2", "2025-01-01T00:00:01Z", "", "two, with comma", "", ""},
+		{"u3", "3", "2025-01-01T00:00:02Z", deletedAt, "three", "", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %v", len(rows), len(want), rows)
+	}
+	for i := range want {
+		if !slices.Equal(rows[i], want[i]) {
+			t.Errorf("row %d = %v, want %v", i, rows[i], want[i])
+		}
+	}
+
+	w = httptest.NewRecorder()
+	(&Server{}).exportCSV(e)(w, httptest.NewRequest("GET", "/v1/tasks/export.csv?columns=title,secret", nil))
+	if w.Code != 400 {
+		t.Errorf("unknown column: status %d, want 400", w.Code)
+	}
+}
+
+func TestExportCSV_Failure(t *testing.T) {
+	items := []syncservice.RESTItem{{UID: "u1", Version: 1, UpdatedAt: "2025-01-01T00:00:00Z"}}
+	failingAt := func(page int64) restEntity {
+		return restEntity{
+			entityDef: entityDef{Name: "tasks", Table: "task"},
+			list: func(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts syncservice.ListOpts) (*syncservice.RESTListResponse, error) {
+				if cursor.Ms == page {
+					return nil, errors.New("connection reset")
+				}
+				next := syncx.EncodeCursor(syncx.Cursor{Ms: cursor.Ms + 1, UID: uuid.New()})
+				return &syncservice.RESTListResponse{Items: items, NextCursor: &next, ByteLimited: true}, nil
+			},
+		}
+	}
+
+	// Nothing sent yet: a plain error response
+	w := httptest.NewRecorder()
+	(&Server{}).exportCSV(failingAt(0))(w, httptest.NewRequest("GET", "/v1/tasks/export.csv", nil))
+	if w.Code != 500 || strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Errorf("first page failure: status %d %q, want a 500 error", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	// Rows already sent: the connection is aborted and no completion trailer is set
+	w = httptest.NewRecorder()
+	func() {
+		defer func() {
+			if p := recover(); p != http.ErrAbortHandler {
+				t.Errorf("later page failure: recovered %v, want http.ErrAbortHandler", p)
+			}
+		}()
+		(&Server{}).exportCSV(failingAt(1))(w, httptest.NewRequest("GET", "/v1/tasks/export.csv", nil))
+	}()
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "u1") {
+		t.Errorf("later page failure: status %d, body %q", w.Code, w.Body.String())
+	}
+	if got := w.Result().Trailer.Get(exportStatusTrailer); got != "" {
+		t.Errorf("truncated export has %s trailer %q", exportStatusTrailer, got)
+	}
+}
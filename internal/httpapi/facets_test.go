@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+func TestTaskFacets_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+	_, _ = pool.Exec(context.Background(), "DELETE FROM task")
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		TaskSvc:         syncservice.NewTaskService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	var deleted string
+	for i, status := range []string{"open", "open", "completed", "completed", "completed", ""} {
+		payload := map[string]any{"title": "task", "priority": "high"}
+		if status != "" {
+			payload["status"] = status
+		}
+		if i == 4 {
+			payload["priority"] = "low"
+		}
+		w := makeRequestWithSession(t, router, "POST", "/v1/tasks", payload, session)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Create failed: %d %s", w.Code, w.Body.String())
+		}
+		var item syncservice.RESTItem
+		if err := json.NewDecoder(w.Body).Decode(&item); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		deleted = item.UID // the last (status-less) task
+	}
+	if w := makeRequestWithSession(t, router, "DELETE", "/v1/tasks/"+deleted, nil, session); w.Code != http.StatusOK {
+		t.Fatalf("Delete failed: %d %s", w.Code, w.Body.String())
+	}
+
+	facets := func(query string) map[string]int64 {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "GET", "/v1/tasks/facets"+query, nil, session)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET facets%s: %d %s", query, w.Code, w.Body.String())
+		}
+		var resp syncservice.FacetResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode facets: %v", err)
+		}
+		counts := map[string]int64{}
+		for _, c := range resp.Counts {
+			key := "<null>"
+			if c.Value != nil {
+				key = *c.Value
+			}
+			counts[key] = c.Count
+		}
+		return counts
+	}
+
+	if got := facets("?by=status"); len(got) != 2 || got["completed"] != 3 || got["open"] != 2 {
+		t.Errorf("by=status: got %v", got)
+	}
+	if got := facets("?by=status&includeDeleted=true"); got["<null>"] != 1 {
+		t.Errorf("includeDeleted should count the tombstone under null, got %v", got)
+	}
+	if got := facets("?by=status&where=priority:high"); got["completed"] != 2 || got["open"] != 2 {
+		t.Errorf("where filter: got %v", got)
+	}
+
+	for _, query := range []string{"", "?by=title"} {
+		if w := makeRequestWithSession(t, router, "GET", "/v1/tasks/facets"+query, nil, session); w.Code != http.StatusBadRequest {
+			t.Errorf("GET facets%s: expected 400, got %d", query, w.Code)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package httpapi
+
+import (
+	"net"
+	"net/http"
+)
+
+// HTTPSRedirect answers every plain-HTTP request with a 308 to the same path and query
+// over HTTPS. Used on HTTP_REDIRECT_ADDR when the server terminates TLS itself; httpsPort
+// is the TLS listener's port, left out of the Location when it is the default 443.
+// 308 keeps the method and body, so a misconfigured client's POST is not turned into a GET.
+func HTTPSRedirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,32 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	tests := []struct {
+		name      string
+		httpsPort string
+		target    string
+		want      string
+	}{
+		{"default port", "443", "http://api.example.com/v1/notes?limit=5", "https://api.example.com/v1/notes?limit=5"},
+		{"custom port replaces the HTTP port", "8443", "http://api.example.com:8080/healthz", "https://api.example.com:8443/healthz"},
+		{"IPv6 host", "8443", "http://[::1]:8080/", "https://[::1]:8443/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			HTTPSRedirect(tt.httpsPort).ServeHTTP(w, httptest.NewRequest("POST", tt.target, nil))
+			if w.Code != http.StatusPermanentRedirect {
+				t.Errorf("Expected 308, got %d", w.Code)
+			}
+			if got := w.Header().Get("Location"); got != tt.want {
+				t.Errorf("Location = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package httpapi
 
 import (
+	"context"
 	"net/http"
 	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/rs/zerolog/log"
 )
 
 // ServerInfo represents the server's capabilities and configuration
@@ -26,16 +30,53 @@ type RateLimitInfo struct {
 
 // SyncHints provides recommendations for client behavior
 type SyncHints struct {
-	RecommendedBatch int `json:"recommendedBatch"` // safe batch size
-	BackoffMsOn429   int `json:"backoffMsOn429"`   // default backoff if Retry-After missing
+	RecommendedBatch     int     `json:"recommendedBatch"`     // safe batch size (shrinks under load)
+	BackoffMsOn429       int     `json:"backoffMsOn429"`       // default backoff if Retry-After missing
+	RecommendedBackoffMs int     `json:"recommendedBackoffMs"` // suggested delay between batches at current load (0 = none)
+	CurrentLoad          float64 `json:"currentLoad"`          // recent request volume relative to capacity (1.0 = at capacity)
+}
+
+// baseRecommendedBatch is the batch size recommended when the server is not under load
+const baseRecommendedBatch = 500
+
+// syncHints returns client hints, adjusted for current load when an estimator is configured
+func (s *Server) syncHints() *SyncHints {
+	hints := &SyncHints{
+		RecommendedBatch: baseRecommendedBatch,
+		BackoffMsOn429:   1500,
+	}
+	if s.Load != nil {
+		h := s.Load.Hints(baseRecommendedBatch)
+		hints.RecommendedBatch = h.RecommendedBatch
+		hints.RecommendedBackoffMs = h.RecommendedBackoffMs
+		hints.CurrentLoad = h.CurrentLoad
+	}
+	if s.MaxPushItems > 0 && hints.RecommendedBatch > s.MaxPushItems {
+		hints.RecommendedBatch = s.MaxPushItems
+	}
+	return hints
 }
 
 // EntityCapability describes capabilities for a specific entity type
 type EntityCapability struct {
-	MaxLimit int  `json:"maxLimit"`
-	Enabled  bool `json:"enabled,omitempty"` // deprecated, kept for backward compatibility
-	Push     bool `json:"push"`              // push operations enabled
-	Pull     bool `json:"pull"`              // pull operations enabled
+	DefaultLimit int  `json:"defaultLimit"`      // page size when a pull sends no limit
+	MaxLimit     int  `json:"maxLimit"`          // larger limits are clamped to it
+	Enabled      bool `json:"enabled,omitempty"` // deprecated, kept for backward compatibility
+	Push         bool `json:"push"`              // push operations enabled
+	Pull         bool `json:"pull"`              // pull operations enabled
+}
+
+// entityCapabilities returns the sync capabilities of every enabled entity in entityCatalog
+func (s *Server) entityCapabilities() map[string]EntityCapability {
+	caps := make(map[string]EntityCapability, len(entityCatalog))
+	for _, e := range entityCatalog {
+		if !s.EntityEnabled(e.Name) {
+			continue
+		}
+		limits := s.syncCfg().PageLimitFor(e.Table)
+		caps[e.Name] = EntityCapability{DefaultLimit: limits.Default, MaxLimit: limits.Max, Push: true, Pull: true}
+	}
+	return caps
 }
 
 // LockingCapability describes sync locking/session support
@@ -44,61 +85,67 @@ type LockingCapability struct {
 	Mode      string `json:"mode"` // "session" or "none"
 }
 
-// Info handles GET /v1/sync/info
+// Info handles GET /v1/sync/info and GET /v1/serverinfo
 // Returns server capabilities, API version, and supported features
 // This endpoint can be called without authentication to allow capability discovery
+// Fields match gRPC GetServerInfo, so REST-only clients bootstrap from the same values
 func (s *Server) Info(w http.ResponseWriter, r *http.Request) {
 	info := ServerInfo{
-		APIVersion: "1.1",
+		APIVersion: APIVersion,
 		ServerTime: time.Now().UTC().Format(time.RFC3339Nano),
-		Entities: map[string]EntityCapability{
-			"notes": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"tasks": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"comments": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"chats": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"chat_messages": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"task_lists": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-			"task_list_categories": {
-				MaxLimit: 1000,
-				Push:     true,
-				Pull:     true,
-			},
-		},
+		Entities:   s.entityCapabilities(),
 		Locking: LockingCapability{
 			Supported: true,
 			Mode:      "session",
 		},
 		MinClientVersion: "0.1.0",
 		RateLimit:        &s.RateLimitConfig,
-		Hints: &SyncHints{
-			RecommendedBatch: 500,
-			BackoffMsOn429:   1500,
-		},
+		Hints:            s.syncHints(),
 	}
 
 	writeJSON(w, http.StatusOK, info)
 }
+
+// ServerTimeResponse is the body of GET /v1/time
+type ServerTimeResponse struct {
+	ServerTimeMs int64  `json:"serverTimeMs"` // Unix milliseconds, same clock as sync timestamps
+	ServerTime   string `json:"serverTime"`   // RFC3339 with millisecond precision
+}
+
+// Time handles GET /v1/time
+// Lightweight, unauthenticated clock endpoint so clients can measure their skew
+// against the server before pushing (LWW compares client-supplied timestamps).
+func (s *Server) Time(w http.ResponseWriter, r *http.Request) {
+	nowMs := syncx.NowMs()
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, ServerTimeResponse{
+		ServerTimeMs: nowMs,
+		ServerTime:   syncx.RFC3339(nowMs),
+	})
+}
+
+// readyTimeout bounds the database ping behind GET /readyz
+const readyTimeout = 2 * time.Second
+
+// Ready handles GET /readyz
+// Pings Postgres so orchestrators stop routing traffic here while the database is
+// unreachable and resume once the pool reconnects. Liveness stays on /healthz, so a
+// database outage does not restart healthy replicas.
+func (s *Server) Ready(w http.ResponseWriter, r *http.Request) {
+	if s.DB == nil {
+		http.Error(w, "database not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+	defer cancel()
+	if err := s.DB.Ping(ctx); err != nil {
+		log.Ctx(r.Context()).Warn().Err(err).Msg("readiness check failed: database unreachable")
+		http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
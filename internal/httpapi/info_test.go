@@ -0,0 +1,248 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/loadest"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestTime_Unauthenticated(t *testing.T) {
+	srv := &Server{}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	before := syncx.NowMs()
+	req := httptest.NewRequest("GET", "/v1/time", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	after := syncx.NowMs()
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("Expected Cache-Control no-store, got %q", cc)
+	}
+
+	var resp ServerTimeResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.ServerTimeMs < before || resp.ServerTimeMs > after {
+		t.Errorf("serverTimeMs %d outside [%d, %d]", resp.ServerTimeMs, before, after)
+	}
+	if resp.ServerTime != syncx.RFC3339(resp.ServerTimeMs) {
+		t.Errorf("serverTime %q does not match serverTimeMs %d", resp.ServerTime, resp.ServerTimeMs)
+	}
+}
+
+func TestInfo_LoadAwareHints(t *testing.T) {
+	srv := &Server{Load: loadest.New(10)}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	// 9 requests + the info request itself = 10 per minute = full capacity
+	for i := 0; i < 9; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/healthz", nil))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/sync/info", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var info ServerInfo
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if info.Hints == nil {
+		t.Fatal("Expected hints")
+	}
+	if info.Hints.CurrentLoad != 1 {
+		t.Errorf("currentLoad = %v, want 1", info.Hints.CurrentLoad)
+	}
+	if info.Hints.RecommendedBatch != baseRecommendedBatch/5 || info.Hints.RecommendedBackoffMs != 1000 {
+		t.Errorf("Expected reduced batch and 1000ms backoff at capacity, got %+v", *info.Hints)
+	}
+}
+
+func TestServerInfo_MatchesSyncInfo(t *testing.T) {
+	limits := RateLimitInfo{WindowSeconds: 30, MaxRequests: 90, Burst: 15}
+	srv := &Server{RateLimitConfig: limits}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	get := func(path string) ServerInfo {
+		t.Helper()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d: %s", path, w.Code, w.Body.String())
+		}
+		var info ServerInfo
+		if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+			t.Fatalf("%s: failed to decode response: %v", path, err)
+		}
+		if info.ServerTime == "" {
+			t.Errorf("%s: expected serverTime", path)
+		}
+		info.ServerTime = ""
+		return info
+	}
+
+	info := get("/v1/serverinfo")
+	if !reflect.DeepEqual(info, get("/v1/sync/info")) {
+		t.Errorf("/v1/serverinfo differs from /v1/sync/info: %+v", info)
+	}
+	if info.RateLimit == nil || *info.RateLimit != limits {
+		t.Errorf("rateLimit = %+v, want the configured %+v", info.RateLimit, limits)
+	}
+	if len(info.Entities) != len(entityCatalog) {
+		t.Errorf("Expected %d entities, got %d", len(entityCatalog), len(info.Entities))
+	}
+}
+
+func TestListEntities_Unauthenticated(t *testing.T) {
+	srv := &Server{}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/entities", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp entitiesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Entities) != len(entityCatalog) {
+		t.Fatalf("Expected %d entities, got %d", len(entityCatalog), len(resp.Entities))
+	}
+
+	tasks := resp.Entities[1]
+	if tasks.Name != "tasks" {
+		t.Fatalf("Expected tasks second (catalog order), got %q", tasks.Name)
+	}
+	if want := []string{"start", "complete", "reopen", "move"}; !reflect.DeepEqual(tasks.ProcessActions, want) {
+		t.Errorf("processActions = %v, want %v", tasks.ProcessActions, want)
+	}
+	if !slices.Contains(tasks.FilterableFields, "priority") {
+		t.Errorf("Expected priority to be filterable for tasks, got %v", tasks.FilterableFields)
+	}
+
+	// Sync info advertises the same entity set
+	info := httptest.NewRecorder()
+	router.ServeHTTP(info, httptest.NewRequest("GET", "/v1/sync/info", nil))
+	var si ServerInfo
+	if err := json.NewDecoder(info.Body).Decode(&si); err != nil {
+		t.Fatalf("Failed to decode info: %v", err)
+	}
+	for _, e := range resp.Entities {
+		if _, ok := si.Entities[e.Name]; !ok {
+			t.Errorf("Entity %q missing from /v1/sync/info", e.Name)
+		}
+	}
+}
+
+func TestResourceOptions_Unauthenticated(t *testing.T) {
+	srv := &Server{}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	tests := []struct {
+		path        string
+		allow       string
+		conditional []string
+	}{
+		{"/v1/tasks/c1d9b7dc-0000-0000-0000-000000000000", "GET, HEAD, PUT, PATCH, DELETE, OPTIONS", []string{"If-Match", "If-None-Match", "If-Unmodified-Since"}},
+		{"/v1/tasks", "GET, HEAD, POST, OPTIONS", []string{}},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("OPTIONS", tt.path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("OPTIONS %s: expected 200, got %d: %s", tt.path, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("OPTIONS %s: Allow = %q, want %q", tt.path, got, tt.allow)
+		}
+
+		var resp resourceOptions
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.Entity != "tasks" || strings.Join(resp.Methods, ", ") != tt.allow {
+			t.Errorf("OPTIONS %s: unexpected body %+v", tt.path, resp)
+		}
+		if want := []string{"start", "complete", "reopen", "move"}; !reflect.DeepEqual(resp.ProcessActions, want) {
+			t.Errorf("OPTIONS %s: processActions = %v, want %v", tt.path, resp.ProcessActions, want)
+		}
+		if !reflect.DeepEqual(resp.ConditionalRequests, tt.conditional) {
+			t.Errorf("OPTIONS %s: conditionalRequests = %v, want %v", tt.path, resp.ConditionalRequests, tt.conditional)
+		}
+	}
+}
+
+func TestDisabledEntities(t *testing.T) {
+	disabled, err := ParseDisabledEntities(" task_list_categories ,")
+	if err != nil {
+		t.Fatalf("ParseDisabledEntities failed: %v", err)
+	}
+	if _, err := ParseDisabledEntities("widgets"); err == nil {
+		t.Error("Expected error for unknown entity")
+	}
+
+	srv := &Server{DisabledEntities: disabled}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/entities", nil))
+	var resp entitiesResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Entities) != len(entityCatalog)-1 {
+		t.Errorf("Expected %d entities, got %d", len(entityCatalog)-1, len(resp.Entities))
+	}
+	for _, e := range resp.Entities {
+		if e.Name == "task_list_categories" {
+			t.Error("Disabled entity listed in /v1/entities")
+		}
+	}
+
+	info := httptest.NewRecorder()
+	router.ServeHTTP(info, httptest.NewRequest("GET", "/v1/sync/info", nil))
+	var si ServerInfo
+	if err := json.NewDecoder(info.Body).Decode(&si); err != nil {
+		t.Fatalf("Failed to decode info: %v", err)
+	}
+	if _, ok := si.Entities["task_list_categories"]; ok {
+		t.Error("Disabled entity listed in /v1/sync/info")
+	}
+
+	// Routes are not registered, so they 404 before authentication runs
+	for _, path := range []string{"/v1/task_list_categories", "/v1/sync/task_list_categories/pull"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: expected 404, got %d", path, w.Code)
+		}
+	}
+}
+
+func TestReady_NoDatabase(t *testing.T) {
+	srv := &Server{}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a database, got %d", w.Code)
+	}
+}
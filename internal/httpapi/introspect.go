@@ -0,0 +1,63 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/rs/zerolog/log"
+)
+
+// introspectResp is the response for POST /v1/auth/introspect
+type introspectResp struct {
+	Active    bool     `json:"active"`
+	Subject   string   `json:"sub,omitempty"`
+	ExpiresAt *string  `json:"expiresAt,omitempty"` // RFC3339; omitted for tokens without exp
+	Scopes    []string `json:"scopes"`
+	Error     string   `json:"error,omitempty"` // why the token was rejected
+}
+
+// Introspect returns the handler for POST /v1/auth/introspect
+// Validates the Bearer token with the auth middleware's config and pipeline
+// (HS256 backend, RS256 upstream IdP, MCP OAuth audience rules) and reports what it grants.
+// Never touches the database and needs no session, so load balancers and clients can check a
+// token cheaply. Rejected tokens get the status a real request would: 403 for a token issued
+// for another audience, 401 otherwise.
+func (s *Server) Introspect(cfg auth.JWTCfg) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := log.Ctx(r.Context())
+
+		tok, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || tok == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="toolbridge"`)
+			writeJSON(w, http.StatusUnauthorized, introspectResp{Scopes: []string{}, Error: "missing bearer token"})
+			return
+		}
+
+		sub, claims, err := auth.ValidateToken(tok, cfg)
+		if err != nil {
+			logger.Info().Err(err).Msg("token introspection: token rejected")
+			status, errCode := http.StatusUnauthorized, "invalid_token"
+			if errors.Is(err, auth.ErrInvalidAudience) {
+				status, errCode = http.StatusForbidden, "insufficient_scope"
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="toolbridge", error=%q, error_description=%q`, errCode, err.Error()))
+			writeJSON(w, status, introspectResp{Scopes: []string{}, Error: err.Error()})
+			return
+		}
+
+		resp := introspectResp{Active: true, Subject: sub, Scopes: auth.TokenScopes(claims)}
+		if resp.Scopes == nil {
+			resp.Scopes = []string{}
+		}
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			expiresAt := syncx.RFC3339(exp.UnixMilli())
+			resp.ExpiresAt = &expiresAt
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestIntrospect(t *testing.T) {
+	const secret = "test-secret"
+	srv := &Server{} // no DB: introspection must not need one
+	router := srv.Routes(auth.JWTCfg{HS256Secret: secret, Audience: "toolbridge-api"})
+
+	sign := func(claims jwt.MapClaims, key string) string {
+		t.Helper()
+		tok, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return tok
+	}
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+		expectedActive bool
+		expectedScopes []string
+	}{
+		{
+			name:           "valid token",
+			token:          sign(jwt.MapClaims{"sub": "user_1", "aud": "toolbridge-api", "exp": exp.Unix(), "scope": "sync:read"}, secret),
+			expectedStatus: http.StatusOK,
+			expectedActive: true,
+			expectedScopes: []string{"sync:read"},
+		},
+		{
+			name:           "bad signature",
+			token:          sign(jwt.MapClaims{"sub": "user_1", "aud": "toolbridge-api", "exp": exp.Unix()}, "other-secret"),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "expired",
+			token:          sign(jwt.MapClaims{"sub": "user_1", "aud": "toolbridge-api", "exp": time.Now().Add(-time.Hour).Unix()}, secret),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong audience",
+			token:          sign(jwt.MapClaims{"sub": "user_1", "aud": "someone-else", "exp": exp.Unix()}, secret),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/v1/auth/introspect", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			var resp introspectResp
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if resp.Active != tt.expectedActive {
+				t.Errorf("Expected active=%v, got %+v", tt.expectedActive, resp)
+			}
+			if !tt.expectedActive {
+				if resp.Error == "" || w.Header().Get("WWW-Authenticate") == "" {
+					t.Errorf("Expected a rejection reason and challenge, got %+v", resp)
+				}
+				return
+			}
+			if resp.Subject != "user_1" || strings.Join(resp.Scopes, " ") != strings.Join(tt.expectedScopes, " ") {
+				t.Errorf("Unexpected introspection result %+v", resp)
+			}
+			if resp.ExpiresAt == nil || *resp.ExpiresAt != exp.UTC().Format(time.RFC3339) {
+				t.Errorf("Expected expiresAt %v, got %+v", exp.UTC(), resp)
+			}
+		})
+	}
+}
@@ -2,8 +2,17 @@ package httpapi
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/loadest"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
@@ -13,6 +22,7 @@ type contextKey string
 const (
 	sessionIDKey     contextKey = "sessionId"
 	correlationIDKey contextKey = "correlationId"
+	echoRequestIDKey contextKey = "echoRequestId"
 )
 
 // SessionMiddleware reads X-Sync-Session header and adds it to context
@@ -71,6 +81,27 @@ func CorrelationMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// RequestIDEcho returns the request ID (from middleware.RequestID, logged as request_id)
+// in an X-Request-ID response header and in error bodies next to the correlation ID,
+// so a user can paste either into a support ticket and it can be found in the logs.
+// Must run after middleware.RequestID.
+func RequestIDEcho(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set("X-Request-ID", id)
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), echoRequestIDKey, true)))
+	})
+}
+
+// errorRequestID returns the request ID to put in an error body ("" unless RequestIDEcho ran)
+func errorRequestID(ctx context.Context) string {
+	if echo, _ := ctx.Value(echoRequestIDKey).(bool); !echo {
+		return ""
+	}
+	return middleware.GetReqID(ctx)
+}
+
 // GetCorrelationID retrieves the correlation ID from context
 func GetCorrelationID(ctx context.Context) string {
 	if correlationID, ok := ctx.Value(correlationIDKey).(string); ok {
@@ -78,3 +109,194 @@ func GetCorrelationID(ctx context.Context) string {
 	}
 	return ""
 }
+
+// MutationActorMiddleware attributes REST mutations to the authenticated subject
+// Services record it as createdBy/updatedBy (see syncservice.WithActor).
+func MutationActorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := syncservice.WithActor(r.Context(), auth.Subject(r.Context()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// SessionChangeTracking records the pre-session state of items written under the
+// X-Sync-Session header so the session can be undone (see UndoSession).
+func SessionChangeTracking(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := syncservice.WithSession(r.Context(), GetSessionID(r.Context()))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ScopeRequired rejects requests whose token lacks the scope for the operation (403):
+// GET/HEAD and POST pulls need the read scope, every other request the write scope.
+func ScopeRequired(cfg auth.ScopeCfg) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := cfg.WriteScope
+			if r.Method == http.MethodGet || r.Method == http.MethodHead ||
+				strings.HasSuffix(r.URL.Path, "/pull") || strings.HasSuffix(r.URL.Path, "/pull_by_uid") {
+				scope = cfg.ReadScope
+			}
+			if !auth.HasScope(r.Context(), scope) {
+				log.Ctx(r.Context()).Warn().Str("path", r.URL.Path).Str("scope", scope).Msg("token lacks required scope")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="toolbridge", error="insufficient_scope", scope="`+scope+`"`)
+				writeError(w, r, http.StatusForbidden, "token lacks required scope "+scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ForceWriteRequired handles ?force=true on sync pushes: the token must hold scope
+// (403 otherwise, whether or not scope enforcement is on), and the push then replaces
+// stored items regardless of timestamps (see syncservice.WithForceWrite).
+func ForceWriteRequired(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			v := r.URL.Query().Get("force")
+			if v == "" || !strings.HasSuffix(r.URL.Path, "/push") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			force, err := strconv.ParseBool(v)
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "force must be true or false")
+				return
+			}
+			if !force {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if scope == "" || !auth.HasScope(r.Context(), scope) {
+				log.Ctx(r.Context()).Warn().Str("path", r.URL.Path).Str("scope", scope).Msg("forced push rejected: token lacks force-write scope")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="toolbridge", error="insufficient_scope", scope="`+scope+`"`)
+				writeError(w, r, http.StatusForbidden, "forced pushes need scope "+scope)
+				return
+			}
+			log.Ctx(r.Context()).Warn().Str("user_id", auth.UserID(r.Context())).Str("path", r.URL.Path).Msg("forced push: LWW bypassed")
+			next.ServeHTTP(w, r.WithContext(syncservice.WithForceWrite(r.Context())))
+		})
+	}
+}
+
+// allowMethodCandidates are the methods probed when building an Allow header
+var allowMethodCandidates = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// LoadMiddleware counts every request toward the server load estimate
+// reported to clients in the sync info hints (see loadest.Estimator).
+func LoadMiddleware(est *loadest.Estimator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			est.Record()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MethodNotAllowedHandler returns 405 with an Allow header listing the methods
+// actually routed for the request path (HEAD is implied by GET via middleware.GetHead).
+// chi's default 405 response has no body; this keeps the JSON error format consistent.
+func MethodNotAllowedHandler(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(allowedMethods(routes, r.URL.Path), ", "))
+		writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// allowedMethods lists the methods routed for path, in allowMethodCandidates order
+func allowedMethods(routes chi.Routes, path string) []string {
+	allowed := make([]string, 0, len(allowMethodCandidates))
+	for _, method := range allowMethodCandidates {
+		if routes.Match(chi.NewRouteContext(), method, path) ||
+			(method == http.MethodHead && routes.Match(chi.NewRouteContext(), http.MethodGet, path)) {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// TimeoutMiddleware bounds each request with a context deadline.
+// The deadline propagates into pgx queries (they take the request context), so slow
+// DB calls are cancelled instead of holding the connection open. Handlers run
+// synchronously: once the deadline has passed, a 5xx written by the handler (its
+// generic "query failed" error) or a missing response is replaced with a 504.
+// Streamed responses (see streamingRequest) are exempt and bound each page instead.
+func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if streamingRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{ResponseWriter: w, r: r}
+			next.ServeHTTP(tw, r)
+
+			if !tw.wroteHeader && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				tw.WriteHeader(http.StatusGatewayTimeout)
+			}
+		})
+	}
+}
+
+// timeoutWriter rewrites server errors caused by the request deadline into a 504
+type timeoutWriter struct {
+	http.ResponseWriter
+	r           *http.Request
+	wroteHeader bool
+	timedOut    bool // handler response is discarded in favor of the 504
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+
+	if code >= 500 && errors.Is(tw.r.Context().Err(), context.DeadlineExceeded) {
+		tw.timedOut = true
+		log.Ctx(tw.r.Context()).Warn().Str("path", tw.r.URL.Path).Int("handler_status", code).Msg("request timed out")
+		writeError(tw.ResponseWriter, tw.r, http.StatusGatewayTimeout, "request timed out")
+		return
+	}
+	tw.ResponseWriter.WriteHeader(code)
+}
+
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.timedOut {
+		return len(b), nil
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+func (tw *timeoutWriter) Flush() {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.timedOut {
+		return
+	}
+	http.NewResponseController(tw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the connection's writer
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
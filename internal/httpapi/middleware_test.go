@@ -0,0 +1,225 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+func TestMethodNotAllowed_AllowHeader(t *testing.T) {
+	srv := &Server{}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		expectedAllow string
+	}{
+		{"item route", "POST", "/v1/notes/c1d9b7dc-0000-0000-0000-000000000000", "GET, HEAD, PUT, PATCH, DELETE, OPTIONS"},
+		{"collection route", "DELETE", "/v1/notes", "GET, HEAD, POST, OPTIONS"},
+		{"pull route", "PUT", "/v1/sync/notes/pull", "GET, HEAD, POST"},
+		{"push route", "GET", "/v1/sync/notes/push", "POST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("Expected status 405, got %d: %s", w.Code, w.Body.String())
+			}
+			if got := w.Header().Get("Allow"); got != tt.expectedAllow {
+				t.Errorf("Expected Allow %q, got %q", tt.expectedAllow, got)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected JSON error body, got Content-Type %q", ct)
+			}
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedStatus int
+	}{
+		{
+			name: "fast handler unaffected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, 200, map[string]any{"ok": true})
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "server error after deadline becomes 504",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done() // simulates a cancelled pgx query
+				writeError(w, r, 500, "query failed")
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name: "no response after deadline becomes 504",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name: "client error after deadline is preserved",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				<-r.Context().Done()
+				writeError(w, r, 404, "not found")
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := TimeoutMiddleware(10 * time.Millisecond)(tt.handler)
+			req := httptest.NewRequest("GET", "/v1/notes", nil)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusGatewayTimeout && !strings.Contains(w.Body.String(), "request timed out") {
+				t.Errorf("Expected timeout error body, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestScopeRequired(t *testing.T) {
+	cfg := auth.ScopeCfg{Enforce: true, ReadScope: auth.DefaultReadScope, WriteScope: auth.DefaultWriteScope}
+	h := ScopeRequired(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		scopes         []string
+		expectedStatus int
+	}{
+		{"read token can GET", "GET", "/v1/notes", []string{"sync:read"}, http.StatusOK},
+		{"read token can POST pull", "POST", "/v1/sync/notes/pull", []string{"sync:read"}, http.StatusOK},
+		{"read token can POST pull_by_uid", "POST", "/v1/sync/notes/pull_by_uid", []string{"sync:read"}, http.StatusOK},
+		{"read token cannot push", "POST", "/v1/sync/notes/push", []string{"sync:read"}, http.StatusForbidden},
+		{"read token cannot delete", "DELETE", "/v1/notes/abc", []string{"sync:read"}, http.StatusForbidden},
+		{"write token cannot GET", "GET", "/v1/notes", []string{"sync:write"}, http.StatusForbidden},
+		{"write token can push", "POST", "/v1/sync/notes/push", []string{"sync:write"}, http.StatusOK},
+		{"token without scopes", "GET", "/v1/notes", nil, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req = req.WithContext(auth.WithScopes(req.Context(), tt.scopes))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusForbidden && !strings.Contains(w.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`) {
+				t.Errorf("Expected insufficient_scope challenge, got %q", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+
+	// Dev-mode requests without a token are not scope-restricted
+	req := httptest.NewRequest("POST", "/v1/sync/notes/push", nil)
+	req = req.WithContext(auth.WithAllScopes(req.Context()))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected dev-mode request to pass, got %d", w.Code)
+	}
+}
+
+func TestForceWriteRequired(t *testing.T) {
+	var forced bool
+	h := ForceWriteRequired(auth.DefaultForceWriteScope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forced = syncservice.IsForceWrite(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	writer := []string{auth.DefaultWriteScope}
+	admin := []string{auth.DefaultWriteScope, auth.DefaultForceWriteScope}
+	tests := []struct {
+		name           string
+		path           string
+		scopes         []string
+		expectedStatus int
+		expectForced   bool
+	}{
+		{"plain push", "/v1/sync/notes/push", writer, http.StatusOK, false},
+		{"force=false", "/v1/sync/notes/push?force=false", writer, http.StatusOK, false},
+		{"forced push without scope", "/v1/sync/notes/push?force=true", writer, http.StatusForbidden, false},
+		{"forced push with scope", "/v1/sync/notes/push?force=true", admin, http.StatusOK, true},
+		{"invalid force value", "/v1/sync/notes/push?force=maybe", admin, http.StatusBadRequest, false},
+		{"force ignored on pull", "/v1/sync/notes/pull?force=true", writer, http.StatusOK, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forced = false
+			req := httptest.NewRequest("POST", tt.path, nil)
+			req = req.WithContext(auth.WithScopes(req.Context(), tt.scopes))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if forced != tt.expectForced {
+				t.Errorf("Expected forced=%v, got %v", tt.expectForced, forced)
+			}
+		})
+	}
+}
+
+func TestRequestIDEcho(t *testing.T) {
+	fail := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, 404, "note not found")
+	})
+
+	for _, echo := range []bool{true, false} {
+		h := http.Handler(fail)
+		if echo {
+			h = RequestIDEcho(h)
+		}
+		h = middleware.RequestID(h)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/notes/x", nil))
+
+		var body errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("echo=%v: decode: %v", echo, err)
+		}
+		header := rec.Header().Get("X-Request-ID")
+		if echo {
+			if body.RequestID == "" || header != body.RequestID {
+				t.Errorf("echo=true: want matching request IDs, got body %q header %q", body.RequestID, header)
+			}
+		} else if body.RequestID != "" || header != "" {
+			t.Errorf("echo=false: want no request ID, got body %q header %q", body.RequestID, header)
+		}
+	}
+}
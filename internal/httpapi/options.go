@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// resourceOptions is the body of OPTIONS /v1/<entity> and /v1/<entity>/{uid}
+type resourceOptions struct {
+	Entity              string   `json:"entity"`
+	Methods             []string `json:"methods"`             // same list as the Allow header
+	ProcessActions      []string `json:"processActions"`      // POST /{uid}/process actions
+	ConditionalRequests []string `json:"conditionalRequests"` // precondition headers honoured on this resource
+}
+
+// itemConditionalHeaders are the precondition headers single-item routes honour:
+// If-None-Match on GET (304) and If-Match or If-Unmodified-Since on PUT, PATCH, archive
+// and process (412)
+var itemConditionalHeaders = []string{"If-Match", "If-None-Match", "If-Unmodified-Since"}
+
+// ResourceOptions handles OPTIONS for an entity's REST collection and item routes
+// Answers with an Allow header built from the routes actually registered for the path,
+// plus the entity's process actions and supported precondition headers, so clients can
+// discover per resource what GET /v1/entities describes per type. Unauthenticated like
+// /v1/entities: it reveals nothing about whether the item exists.
+func ResourceOptions(routes chi.Routes, e entityDef) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		methods := allowedMethods(routes, r.URL.Path)
+		conditional := []string{}
+		if chi.URLParam(r, "uid") != "" {
+			conditional = itemConditionalHeaders
+		}
+
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		writeJSON(w, http.StatusOK, resourceOptions{
+			Entity:              e.Name,
+			Methods:             methods,
+			ProcessActions:      e.Actions.names(),
+			ConditionalRequests: conditional,
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// setPaginationLinks sets an RFC 8288 Link header for a cursor-paginated GET response:
+// rel="first" (the same request without a cursor) and, unless this is the last page,
+// rel="next" (the same request at nextCursor). The cursor in the body stays authoritative.
+// URLs are relative to the request so they resolve correctly behind proxies.
+// POST pulls carry their parameters in the body, so they get no Link header.
+func setPaginationLinks(w http.ResponseWriter, r *http.Request, nextCursor *string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return
+	}
+
+	pageURL := func(cursor string) string {
+		q := r.URL.Query()
+		if cursor == "" {
+			q.Del("cursor")
+		} else {
+			q.Set("cursor", cursor)
+		}
+		u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+		return u.String()
+	}
+
+	links := []string{`<` + pageURL("") + `>; rel="first"`}
+	if nextCursor != nil {
+		links = append(links, `<`+pageURL(*nextCursor)+`>; rel="next"`)
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,48 @@
+package httpapi
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetPaginationLinks(t *testing.T) {
+	next := "ATE3MzA2MzUyMDAwMDB8YzFk"
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		next     *string
+		expected string
+	}{
+		{
+			name:     "first and next keep other params",
+			method:   "GET",
+			target:   "/v1/notes?limit=2&cursor=old&where=status:active",
+			next:     &next,
+			expected: `</v1/notes?limit=2&where=status%3Aactive>; rel="first", </v1/notes?cursor=ATE3MzA2MzUyMDAwMDB8YzFk&limit=2&where=status%3Aactive>; rel="next"`,
+		},
+		{
+			name:     "last page has no next",
+			method:   "GET",
+			target:   "/v1/sync/notes/pull?cursor=old",
+			expected: `</v1/sync/notes/pull>; rel="first"`,
+		},
+		{
+			name:   "POST pulls get no links",
+			method: "POST",
+			target: "/v1/sync/notes/pull",
+			next:   &next,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			setPaginationLinks(w, httptest.NewRequest(tt.method, tt.target, nil), tt.next)
+			if got := w.Header().Get("Link"); got != tt.expected {
+				t.Errorf("Link = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
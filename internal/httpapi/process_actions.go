@@ -0,0 +1,235 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// processAction is a named state transition for POST /<entity>/{uid}/process
+// Metadata lists the request metadata keys the action takes; they are validated before
+// anything is applied, then copied into the payload under the same key after Apply.
+type processAction struct {
+	Name     string
+	Apply    func(payload map[string]any) // nil = only copy metadata
+	Metadata []metadataField
+}
+
+// metadataField is one metadata key a process action accepts (and the payload field it sets)
+type metadataField struct {
+	Key      string
+	Kind     metadataKind
+	Required bool
+}
+
+// metadataKind is the JSON type a metadata value must have
+type metadataKind string
+
+const (
+	metadataString metadataKind = "string"
+	metadataUUID   metadataKind = "uuid" // a string holding a UUID
+	metadataBool   metadataKind = "bool"
+)
+
+// UnknownMetadataMode is what a process action does with metadata keys it doesn't declare
+type UnknownMetadataMode string
+
+const (
+	// UnknownMetadataIgnore drops undeclared keys (the default; metadata used to be ignored entirely)
+	UnknownMetadataIgnore UnknownMetadataMode = "ignore"
+	// UnknownMetadataReject fails the request with 400 invalid_metadata
+	UnknownMetadataReject UnknownMetadataMode = "reject"
+)
+
+// ParseUnknownMetadataMode parses a mode name from config ("" = ignore)
+func ParseUnknownMetadataMode(v string) (UnknownMetadataMode, error) {
+	switch UnknownMetadataMode(v) {
+	case "":
+		return UnknownMetadataIgnore, nil
+	case UnknownMetadataIgnore, UnknownMetadataReject:
+		return UnknownMetadataMode(v), nil
+	default:
+		return "", fmt.Errorf("unknown process metadata mode %q (want %q or %q)", v, UnknownMetadataIgnore, UnknownMetadataReject)
+	}
+}
+
+// errUnknownAction is returned by actionTable.apply for an action the entity doesn't support
+var errUnknownAction = errors.New("unknown action")
+
+// metadataError reports process metadata that doesn't match the action's schema
+type metadataError struct {
+	Action string
+	Key    string
+	Reason string // "is required", "must be a <kind>" or "is not accepted"
+}
+
+func (e *metadataError) Error() string {
+	return fmt.Sprintf("%s: metadata.%s %s", e.Action, e.Key, e.Reason)
+}
+
+// actionTable lists the process actions an entity supports, in documentation order
+type actionTable []processAction
+
+// apply validates metadata against the named action's schema and runs the action
+// against payload. Returns errUnknownAction or a *metadataError without touching payload.
+func (t actionTable) apply(name string, metadata map[string]any, unknown UnknownMetadataMode, payload map[string]any) error {
+	for _, a := range t {
+		if a.Name == name {
+			if err := a.validateMetadata(metadata, unknown); err != nil {
+				return err
+			}
+			if a.Apply != nil {
+				a.Apply(payload)
+			}
+			for _, f := range a.Metadata {
+				if v, ok := metadata[f.Key]; ok && v != nil {
+					payload[f.Key] = v
+				}
+			}
+			return nil
+		}
+	}
+	return errUnknownAction
+}
+
+// validateMetadata checks metadata against the action's declared keys
+func (a processAction) validateMetadata(metadata map[string]any, unknown UnknownMetadataMode) error {
+	declared := make(map[string]bool, len(a.Metadata))
+	for _, f := range a.Metadata {
+		declared[f.Key] = true
+		v, ok := metadata[f.Key]
+		if !ok || v == nil {
+			if f.Required {
+				return &metadataError{Action: a.Name, Key: f.Key, Reason: "is required"}
+			}
+			continue
+		}
+		if !f.Kind.matches(v) {
+			return &metadataError{Action: a.Name, Key: f.Key, Reason: "must be a " + string(f.Kind)}
+		}
+	}
+	if unknown == UnknownMetadataReject {
+		keys := make([]string, 0, len(metadata))
+		for k := range metadata {
+			if !declared[k] {
+				keys = append(keys, k)
+			}
+		}
+		if len(keys) > 0 {
+			sort.Strings(keys) // report the same key every time
+			return &metadataError{Action: a.Name, Key: keys[0], Reason: "is not accepted"}
+		}
+	}
+	return nil
+}
+
+// matches reports whether a decoded JSON value has the kind
+func (k metadataKind) matches(v any) bool {
+	switch k {
+	case metadataString:
+		_, ok := v.(string)
+		return ok
+	case metadataUUID:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		_, err := uuid.Parse(s)
+		return err == nil
+	case metadataBool:
+		_, ok := v.(bool)
+		return ok
+	}
+	return false
+}
+
+// names returns the allowed action names
+func (t actionTable) names() []string {
+	names := make([]string, len(t))
+	for i, a := range t {
+		names[i] = a.Name
+	}
+	return names
+}
+
+// setField returns an action that sets payload[key] = value
+func setField(key string, value any) func(map[string]any) {
+	return func(payload map[string]any) {
+		payload[key] = value
+	}
+}
+
+// Per-entity process actions
+var (
+	noteActions = actionTable{
+		{"pin", setField("pinned", true), nil},
+		{"unpin", setField("pinned", false), nil},
+		{"archive", setField("status", "archived"), nil},
+		{"unarchive", setField("status", "active"), nil},
+		{"cancel_delete", setField("deleteAfter", nil), nil}, // clear a scheduled deletion
+	}
+
+	// Tasks set both status and done for compatibility
+	taskActions = actionTable{
+		{"start", func(p map[string]any) { p["status"] = "in_progress"; p["done"] = false }, nil},
+		{"complete", func(p map[string]any) { p["status"] = "completed"; p["done"] = true }, nil},
+		{"reopen", func(p map[string]any) { p["status"] = "open"; p["done"] = false }, nil},
+		{"move", nil, []metadataField{{"taskListUid", metadataUUID, true}}}, // to another task list
+	}
+
+	chatActions = actionTable{
+		{"resolve", setField("status", "resolved"), nil},
+		{"reopen", setField("status", "active"), nil},
+	}
+
+	commentActions = actionTable{
+		{"resolve", setField("status", "resolved"), nil},
+		{"reopen", setField("status", "open"), nil},
+	}
+
+	chatMessageActions = actionTable{
+		{"mark_read", setField("read", true), nil},
+		{"mark_delivered", setField("delivered", true), nil},
+	}
+
+	taskListActions = actionTable{
+		{"unarchive", setField("archived", false), nil},
+	}
+
+	taskListCategoryActions = actionTable{
+		{"unarchive", setField("archived", false), nil},
+	}
+)
+
+// invalidActionResponse is the 400 body for an unknown process action or invalid metadata
+type invalidActionResponse struct {
+	Error          string   `json:"error"`
+	Code           string   `json:"code"`                     // stable machine-readable code: "invalid_action" or "invalid_metadata"
+	AllowedActions []string `json:"allowedActions,omitempty"` // invalid_action only
+	Field          string   `json:"field,omitempty"`          // invalid_metadata only: the offending metadata key
+	CorrelationID  string   `json:"correlation_id"`
+	RequestID      string   `json:"request_id,omitempty"`
+}
+
+// writeActionError writes the 400 for an actionTable.apply error: the actions the
+// entity supports for an unknown action, or the metadata key at fault
+func writeActionError(w http.ResponseWriter, r *http.Request, action string, table actionTable, err error) {
+	resp := invalidActionResponse{
+		Error:         "invalid action: " + action,
+		Code:          "invalid_action",
+		CorrelationID: GetCorrelationID(r.Context()),
+		RequestID:     errorRequestID(r.Context()),
+	}
+	var metaErr *metadataError
+	if errors.As(err, &metaErr) {
+		resp.Error = "invalid metadata for " + metaErr.Error()
+		resp.Code = "invalid_metadata"
+		resp.Field = metaErr.Key
+	} else {
+		resp.AllowedActions = table.names()
+	}
+	writeJSON(w, http.StatusBadRequest, resp)
+}
@@ -0,0 +1,107 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestActionTable_Apply(t *testing.T) {
+	tests := []struct {
+		name     string
+		table    actionTable
+		action   string
+		metadata map[string]any
+		wantErr  string // "" = applied
+		wantKeys map[string]any
+	}{
+		{"note pin", noteActions, "pin", nil, "", map[string]any{"pinned": true}},
+		{"note cancel_delete clears schedule", noteActions, "cancel_delete", nil, "", map[string]any{"deleteAfter": nil}},
+		{"task complete sets status and done", taskActions, "complete", nil, "", map[string]any{"status": "completed", "done": true}},
+		{"chat message mark_read", chatMessageActions, "mark_read", nil, "", map[string]any{"read": true}},
+		{"task list unarchive", taskListActions, "unarchive", nil, "", map[string]any{"archived": false}},
+		{"unknown action leaves payload untouched", noteActions, "explode", nil, "unknown action", map[string]any{}},
+		{"task move copies the list", taskActions, "move", map[string]any{"taskListUid": "c1d9b7dc-0000-0000-0000-000000000000"}, "",
+			map[string]any{"taskListUid": "c1d9b7dc-0000-0000-0000-000000000000"}},
+		{"task move without a list", taskActions, "move", nil, "move: metadata.taskListUid is required", map[string]any{}},
+		{"task move with a bad list", taskActions, "move", map[string]any{"taskListUid": "inbox"}, "move: metadata.taskListUid must be a uuid", map[string]any{}},
+		{"undeclared metadata ignored by default", noteActions, "pin", map[string]any{"reason": "x"}, "", map[string]any{"pinned": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := map[string]any{}
+			err := tt.table.apply(tt.action, tt.metadata, UnknownMetadataIgnore, payload)
+			if got := errString(err); got != tt.wantErr {
+				t.Fatalf("apply(%q) error = %q, want %q", tt.action, got, tt.wantErr)
+			}
+			if !reflect.DeepEqual(payload, tt.wantKeys) {
+				t.Errorf("payload = %v, want %v", payload, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestActionTable_RejectUnknownMetadata(t *testing.T) {
+	payload := map[string]any{}
+	err := noteActions.apply("pin", map[string]any{"reason": "x", "by": "y"}, UnknownMetadataReject, payload)
+	if got, want := errString(err), "pin: metadata.by is not accepted"; got != want {
+		t.Fatalf("error = %q, want %q", got, want)
+	}
+	if len(payload) != 0 {
+		t.Errorf("rejected action modified payload: %v", payload)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func TestWriteActionError(t *testing.T) {
+	req := httptest.NewRequest("POST", "/v1/chats/abc/process", nil)
+	w := httptest.NewRecorder()
+	writeActionError(w, req, "explode", chatActions, errUnknownAction)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+
+	var resp invalidActionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Code != "invalid_action" {
+		t.Errorf("Expected code invalid_action, got %q", resp.Code)
+	}
+	if resp.Error != "invalid action: explode" {
+		t.Errorf("Unexpected error message %q", resp.Error)
+	}
+	if want := []string{"resolve", "reopen"}; !reflect.DeepEqual(resp.AllowedActions, want) {
+		t.Errorf("Expected allowed actions %v, got %v", want, resp.AllowedActions)
+	}
+}
+
+func TestWriteActionError_Metadata(t *testing.T) {
+	req := httptest.NewRequest("POST", "/v1/tasks/abc/process", nil)
+	w := httptest.NewRecorder()
+	writeActionError(w, req, "move", taskActions, taskActions.apply("move", nil, UnknownMetadataIgnore, map[string]any{}))
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("Expected status 400, got %d", w.Code)
+	}
+	var resp invalidActionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Code != "invalid_metadata" || resp.Field != "taskListUid" || resp.AllowedActions != nil {
+		t.Errorf("Unexpected response %+v", resp)
+	}
+	if resp.Error != "invalid metadata for move: metadata.taskListUid is required" {
+		t.Errorf("Unexpected error message %q", resp.Error)
+	}
+}
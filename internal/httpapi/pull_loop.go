@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/rs/zerolog/log"
+)
+
+// pullLoopRetryAfter is the Retry-After of a throttled looping pull
+const pullLoopRetryAfter = 5 * time.Second
+
+// pullLoopMaxTracked bounds the (session, entity) pairs a PullLoopGuard remembers
+const pullLoopMaxTracked = 10000
+
+// PullLoopGuard spots clients stuck in a pull hot loop: a session asking for the same
+// cursor page over and over while newer data exists, typically a client that drops the
+// nextCursor it was given. Idle polling at the head of the data is not a loop.
+//
+// State is per replica, like the in-process rate limiter; a client spread across
+// replicas is caught on whichever replica sees enough of its repeats.
+type PullLoopGuard struct {
+	Repeats  int  // consecutive same-cursor pulls of one entity in a session that count as a loop
+	Throttle bool // refuse looping pulls with 429 instead of only logging them
+
+	mu      sync.Mutex
+	entries map[string]*pullLoopEntry // key: session ID + "/" + table
+}
+
+type pullLoopEntry struct {
+	cursor   string
+	repeats  int // pulls of cursor after the first
+	lastSeen time.Time
+}
+
+// NewPullLoopGuard returns a guard that flags a session after repeats same-cursor pulls
+func NewPullLoopGuard(repeats int, throttle bool) *PullLoopGuard {
+	return &PullLoopGuard{Repeats: repeats, Throttle: throttle, entries: make(map[string]*pullLoopEntry)}
+}
+
+// observe records a pull of cursor and returns how many times in a row it was repeated
+func (g *PullLoopGuard) observe(key, cursor string, now time.Time) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	e, ok := g.entries[key]
+	if !ok {
+		if len(g.entries) >= pullLoopMaxTracked {
+			g.pruneLocked(now)
+		}
+		e = &pullLoopEntry{cursor: cursor}
+		g.entries[key] = e
+	} else if e.cursor == cursor {
+		e.repeats++
+	} else {
+		e.cursor, e.repeats = cursor, 0
+	}
+	e.lastSeen = now
+	return e.repeats
+}
+
+// pruneLocked forgets pairs idle for longer than a session lives, or everything if
+// that frees nothing
+func (g *PullLoopGuard) pruneLocked(now time.Time) {
+	for key, e := range g.entries {
+		if now.Sub(e.lastSeen) > 30*time.Minute {
+			delete(g.entries, key)
+		}
+	}
+	if len(g.entries) >= pullLoopMaxTracked {
+		g.entries = make(map[string]*pullLoopEntry)
+	}
+}
+
+// checkPullLoop runs a pull through the PullLoopGuard, returning false after writing
+// 429 if the session is looping and throttling is on. Only once a cursor has been
+// repeated Repeats times is it compared with the user's newest position (see
+// syncservice.PullWatermark); watermark failures let the pull through.
+func (s *Server) checkPullLoop(w http.ResponseWriter, r *http.Request, table string, params pullParams) bool {
+	g := s.PullLoop
+	if g == nil || g.Repeats <= 0 {
+		return true
+	}
+	ctx := r.Context()
+	sessionID := GetSessionID(ctx)
+	if sessionID == "" {
+		return true
+	}
+
+	repeats := g.observe(sessionID+"/"+table, params.RawCursor, time.Now())
+	if repeats < g.Repeats {
+		return true
+	}
+
+	userID := auth.UserID(ctx)
+	watermark, err := syncservice.PullWatermark(ctx, s.DB, table, userID)
+	if err != nil || syncx.CompareCursors(params.Cursor, watermark) >= 0 {
+		return true
+	}
+
+	// Log on detection and then every Repeats pulls, not on every pull of the loop
+	if repeats%g.Repeats == 0 {
+		log.Ctx(ctx).Warn().
+			Str("user_id", userID).
+			Str("session_id", sessionID).
+			Str("table", table).
+			Str("cursor", params.RawCursor).
+			Int("repeats", repeats).
+			Bool("throttled", g.Throttle).
+			Msg("sync_pull_loop_detected")
+	}
+	if !g.Throttle {
+		return true
+	}
+
+	retryAfter := int(pullLoopRetryAfter.Seconds())
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	writeError(w, r, http.StatusTooManyRequests,
+		"Pull cursor repeated "+strconv.Itoa(repeats)+" times while newer data exists; continue from the nextCursor of the previous page.")
+	return false
+}
@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPullLoopGuardObserve(t *testing.T) {
+	g := NewPullLoopGuard(3, false)
+	now := time.Now()
+
+	steps := []struct {
+		key, cursor string
+		want        int
+	}{
+		{"s1/note", "a", 0},
+		{"s1/note", "a", 1},
+		{"s1/note", "a", 2},
+		{"s1/task", "a", 0}, // entities are tracked separately
+		{"s2/note", "a", 0}, // and so are sessions
+		{"s1/note", "a", 3},
+		{"s1/note", "b", 0}, // an advancing cursor resets the count
+		{"s1/note", "a", 0},
+	}
+	for i, step := range steps {
+		if got := g.observe(step.key, step.cursor, now); got != step.want {
+			t.Errorf("step %d (%s %s): repeats = %d, want %d", i, step.key, step.cursor, got, step.want)
+		}
+	}
+}
+
+func TestPullLoopGuardPrune(t *testing.T) {
+	g := NewPullLoopGuard(3, false)
+	old := time.Now().Add(-time.Hour)
+	for i := 0; i < pullLoopMaxTracked; i++ {
+		g.observe(string(rune(i)), "a", old)
+	}
+	g.observe("fresh", "a", time.Now())
+	if len(g.entries) != 1 {
+		t.Errorf("expected idle entries to be pruned, %d left", len(g.entries))
+	}
+}
+
+func TestCheckPullLoop_BelowThreshold(t *testing.T) {
+	s := &Server{PullLoop: NewPullLoopGuard(3, true)}
+	r := httptest.NewRequest("GET", "/v1/sync/notes/pull?cursor=abc", nil)
+	r = r.WithContext(context.WithValue(r.Context(), sessionIDKey, "s1"))
+	params := pullParams{RawCursor: "abc"}
+
+	// Repeats under the threshold never reach the watermark lookup (s.DB is nil)
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		if !s.checkPullLoop(w, r, "note", params) {
+			t.Fatalf("pull %d refused below the threshold (status %d)", i, w.Code)
+		}
+	}
+
+	// Disabled guard and session-less requests are let through
+	off := &Server{}
+	if !off.checkPullLoop(httptest.NewRecorder(), r, "note", params) {
+		t.Error("nil guard refused a pull")
+	}
+	noSession := httptest.NewRequest("GET", "/v1/sync/notes/pull?cursor=abc", nil)
+	for i := 0; i < 10; i++ {
+		if !s.checkPullLoop(httptest.NewRecorder(), noSession, "note", params) {
+			t.Fatal("pull without a session refused")
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/rs/zerolog/log"
+)
+
+// pullWaitInterval is how often a waiting pull looks for changes past its cursor
+const pullWaitInterval = time.Second
+
+// pullWaitMargin is left before the request deadline (see TimeoutMiddleware) so the
+// pull that follows a wait still has time to run
+const pullWaitMargin = 2 * time.Second
+
+// errInvalidWait is returned by parsePullParams for an unreadable wait
+var errInvalidWait = errors.New("invalid wait: must be a non-negative duration such as 30s")
+
+// parsePullWait parses a pull's wait ("" = don't wait)
+func parsePullWait(raw string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+	wait, err := time.ParseDuration(raw)
+	if err != nil || wait < 0 {
+		return 0, errInvalidWait
+	}
+	return wait, nil
+}
+
+// waitForPullChanges long-polls: while nothing exists past the pull's cursor, it holds
+// the request for up to params.Wait (capped at MaxPullWait and the request deadline),
+// returning as soon as the table's watermark moves past the cursor. The caller then
+// pulls as usual, so a wait that runs out yields an empty page.
+//
+// Changes are found by polling PullWatermark every pullWaitInterval, the same index
+// lookups a conditional pull makes, so waiting needs no LISTEN connection per request.
+func (s *Server) waitForPullChanges(r *http.Request, table string, params pullParams) {
+	wait := min(params.Wait, s.MaxPullWait)
+	if wait <= 0 {
+		return
+	}
+
+	ctx := r.Context()
+	deadline := time.Now().Add(wait)
+	if dl, ok := ctx.Deadline(); ok && dl.Add(-pullWaitMargin).Before(deadline) {
+		deadline = dl.Add(-pullWaitMargin)
+	}
+	if !time.Now().Before(deadline) {
+		return
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	ticker := time.NewTicker(pullWaitInterval)
+	defer ticker.Stop()
+
+	userID := auth.UserID(ctx)
+	for {
+		watermark, err := syncservice.PullWatermark(ctx, s.DB, table, userID)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("table", table).Msg("failed to compute pull watermark while waiting")
+			return
+		}
+		if syncx.CompareCursors(params.Cursor, watermark) < 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pullNextCursor returns the nextCursor of a pull response. A waiting pull that found
+// nothing echoes the client's cursor, so a long-polling client can keep sending
+// whatever nextCursor it was last given.
+func pullNextCursor(params pullParams, resp *syncservice.PullResponse) *string {
+	if resp.NextCursor == nil && params.Wait > 0 && params.RawCursor != "" {
+		return &params.RawCursor
+	}
+	return resp.NextCursor
+}
@@ -0,0 +1,105 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+)
+
+func TestParsePullParams_Wait(t *testing.T) {
+	params, err := (&Server{}).parsePullParams(httptest.NewRequest("GET", "/v1/sync/notes/pull?wait=30s", nil), "note")
+	if err != nil || params.Wait != 30*time.Second {
+		t.Errorf("GET wait=30s: got %v, %v", params.Wait, err)
+	}
+
+	params, err = (&Server{}).parsePullParams(httptest.NewRequest("POST", "/v1/sync/notes/pull", strings.NewReader(`{"wait":"5s"}`)), "note")
+	if err != nil || params.Wait != 5*time.Second {
+		t.Errorf("POST wait 5s: got %v, %v", params.Wait, err)
+	}
+
+	for _, raw := range []string{"soon", "-1s"} {
+		_, err := (&Server{}).parsePullParams(httptest.NewRequest("GET", "/v1/sync/notes/pull?wait="+raw, nil), "note")
+		if !errors.Is(err, errInvalidWait) {
+			t.Errorf("wait=%s: expected errInvalidWait, got %v", raw, err)
+		}
+		if msg := pullParamsError(err); msg != errInvalidWait.Error() {
+			t.Errorf("wait=%s: 400 message = %q", raw, msg)
+		}
+	}
+}
+
+func TestWaitForPullChanges_Disabled(t *testing.T) {
+	// Neither call may touch the (nil) database
+	req := httptest.NewRequest("GET", "/v1/sync/notes/pull", nil)
+	(&Server{}).waitForPullChanges(req, "note", pullParams{Wait: 30 * time.Second})
+	(&Server{MaxPullWait: 25 * time.Second}).waitForPullChanges(req, "note", pullParams{})
+
+	// A request deadline closer than pullWaitMargin leaves no time to wait
+	ctx, cancel := context.WithTimeout(req.Context(), pullWaitMargin)
+	defer cancel()
+	(&Server{MaxPullWait: 25 * time.Second}).waitForPullChanges(req.WithContext(ctx), "note", pullParams{Wait: 30 * time.Second})
+}
+
+func TestPullNextCursor(t *testing.T) {
+	next := "next"
+	waiting := pullParams{RawCursor: "sent", Wait: time.Second}
+
+	if got := pullNextCursor(waiting, &syncservice.PullResponse{}); got == nil || *got != "sent" {
+		t.Errorf("Empty waiting pull should echo the sent cursor, got %v", got)
+	}
+	if got := pullNextCursor(waiting, &syncservice.PullResponse{NextCursor: &next}); got != &next {
+		t.Errorf("Expected the page's own cursor, got %v", got)
+	}
+	if got := pullNextCursor(pullParams{RawCursor: "sent"}, &syncservice.PullResponse{}); got != nil {
+		t.Errorf("Pull without wait should not echo the cursor, got %v", *got)
+	}
+}
+
+func TestWaitForPullChanges_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	userID := createTestUser(t, pool, "long-poll-"+uuid.NewString())
+	srv := &Server{DB: pool, MaxPullWait: 10 * time.Second}
+	noteSvc := syncservice.NewNoteService(pool)
+
+	ctx := auth.WithUserID(context.Background(), userID)
+	req := httptest.NewRequest("GET", "/v1/sync/notes/pull", nil).WithContext(ctx)
+	watermark, err := syncservice.PullWatermark(ctx, pool, "note", userID)
+	if err != nil {
+		t.Fatalf("Failed to read watermark: %v", err)
+	}
+	params := pullParams{Cursor: watermark, RawCursor: syncx.EncodeCursor(watermark), Wait: 10 * time.Second}
+
+	// Nothing changes: the wait runs to its (shortened) limit
+	start := time.Now()
+	short := params
+	short.Wait = 1500 * time.Millisecond
+	srv.waitForPullChanges(req, "note", short)
+	if waited := time.Since(start); waited < short.Wait {
+		t.Errorf("Expected to wait %v with no changes, returned after %v", short.Wait, waited)
+	}
+
+	// A write during the wait ends it early
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		noteSvc.ApplyNoteMutation(context.Background(), userID, map[string]any{"title": "arrived"}, syncservice.MutationOpts{})
+	}()
+	start = time.Now()
+	srv.waitForPullChanges(req, "note", params)
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("Expected the wait to end soon after the write, took %v", waited)
+	}
+}
@@ -0,0 +1,28 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/rs/zerolog/log"
+)
+
+// pushTooLarge rejects a push of more than MaxPushItems items with 413 and reports
+// whether it did. The batch is refused whole before any item is applied; the error
+// tells the client the batch size to split into (gRPC returns ResourceExhausted).
+func (s *Server) pushTooLarge(w http.ResponseWriter, r *http.Request, items int) bool {
+	if s.MaxPushItems <= 0 || items <= s.MaxPushItems {
+		return false
+	}
+
+	log.Ctx(r.Context()).Warn().
+		Str("user_id", auth.UserID(r.Context())).
+		Int("item_count", items).
+		Int("max_items", s.MaxPushItems).
+		Msg("push rejected: too many items")
+	writeJSON(w, http.StatusRequestEntityTooLarge, []pushAck{{
+		Error: fmt.Sprintf("push has %d items, max %d per request; split it into batches of at most %d", items, s.MaxPushItems, s.MaxPushItems),
+	}})
+	return true
+}
@@ -0,0 +1,41 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPushTooLarge(t *testing.T) {
+	srv := &Server{MaxPushItems: 2}
+
+	// Rejected before the handler touches the database
+	body := `{"items":[{"uid":"a"},{"uid":"b"},{"uid":"c"}]}`
+	w := httptest.NewRecorder()
+	srv.PushNotes(w, httptest.NewRequest("POST", "/v1/sync/notes/push", strings.NewReader(body)))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("Expected 413, got %d: %s", w.Code, w.Body.String())
+	}
+	var acks []pushAck
+	if err := json.NewDecoder(w.Body).Decode(&acks); err != nil || len(acks) != 1 {
+		t.Fatalf("Expected one error ack, got %s (%v)", w.Body.String(), err)
+	}
+	if !strings.Contains(acks[0].Error, "batches of at most 2") {
+		t.Errorf("Expected batch guidance in error, got %q", acks[0].Error)
+	}
+
+	// At the cap, or with no cap, the push goes through
+	if srv.pushTooLarge(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), 2) {
+		t.Error("Push at the cap should not be rejected")
+	}
+	if (&Server{}).pushTooLarge(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil), 10000) {
+		t.Error("MaxPushItems 0 should not limit pushes")
+	}
+
+	// Clients are told to batch no larger than the cap
+	if got := srv.syncHints().RecommendedBatch; got != 2 {
+		t.Errorf("Expected recommendedBatch capped at 2, got %d", got)
+	}
+}
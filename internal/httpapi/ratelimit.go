@@ -35,9 +35,10 @@ import (
 //   3. If tokens >= 1.0: consume 1, allow request
 //   4. Else: calculate wait time, return 429 with Retry-After
 //
-// Production Note:
-//   Current implementation uses in-memory map[userID]*TokenBucket.
-//   For distributed systems, replace with Redis-backed rate limiter.
+// Backends:
+//   RateLimiter keeps buckets in memory (map[userID]*TokenBucket), so each replica
+//   has its own budget. RedisLimiter (ratelimit_redis.go) runs the same algorithm in
+//   Redis so the budget is shared cluster-wide. Server.RateLimitBackend picks one.
 //
 // See: docs/sync_phase7_design_patterns.md for full pattern documentation
 // ============================================================================
@@ -96,6 +97,25 @@ func (tb *TokenBucket) Allow() (bool, int, time.Time, time.Time) {
 	return false, 0, nextTokenTime, fullResetTime
 }
 
+// Limiter is a per-user rate limit backend
+type Limiter interface {
+	// Allow consumes a token for userID if one is available
+	// Returns (allowed, tokensRemaining, nextTokenTime, fullResetTime) as TokenBucket.Allow does.
+	Allow(userID string) (allowed bool, remaining int, nextTokenTime, fullResetTime time.Time)
+	// Config returns the limits this limiter enforces (for the X-RateLimit-* headers)
+	Config() RateLimitInfo
+}
+
+// LimiterBackend builds the limiter for one rate-limited route group
+// name identifies the group ("sync", "rest", "auth", "session") so shared backends keep
+// separate budgets per group, matching one in-process limiter per middleware instance.
+type LimiterBackend func(name string, config RateLimitInfo) Limiter
+
+// InProcessLimiters is the default backend: per-replica in-memory token buckets
+func InProcessLimiters(name string, config RateLimitInfo) Limiter {
+	return NewRateLimiter(config)
+}
+
 // RateLimiter manages per-user token buckets
 type RateLimiter struct {
 	buckets map[string]*TokenBucket
@@ -148,6 +168,11 @@ func (rl *RateLimiter) Allow(userID string) (bool, int, time.Time, time.Time) {
 	return bucket.Allow()
 }
 
+// Config returns the limits this limiter enforces
+func (rl *RateLimiter) Config() RateLimitInfo {
+	return rl.config
+}
+
 // cleanupLoop periodically removes inactive buckets to prevent memory leaks
 func (rl *RateLimiter) cleanupLoop() {
 	ticker := time.NewTicker(10 * time.Minute)
@@ -170,19 +195,29 @@ func (rl *RateLimiter) cleanupLoop() {
 // RateLimitMiddleware returns a middleware that enforces rate limiting per user
 // Each middleware instance creates its own rate limiter with the provided configuration,
 // allowing different routes to have different rate limits.
-// Production Note: For distributed systems, replace with Redis-backed rate limiter.
+// Buckets are in-process; see Server.RateLimitBackend for a cluster-wide budget.
 func RateLimitMiddleware(config RateLimitInfo) func(http.Handler) http.Handler {
-	return rateLimitMiddlewareWithDefault(config, DefaultRateLimitConfig)
+	return rateLimitMiddlewareWithDefault(InProcessLimiters, "sync", config, DefaultRateLimitConfig)
 }
 
 // AuthRateLimitMiddleware returns rate limiting middleware with stricter auth defaults
 // Use this for auth/bootstrap endpoints (token-exchange, tenant resolution, sessions)
 func AuthRateLimitMiddleware(config RateLimitInfo) func(http.Handler) http.Handler {
-	return rateLimitMiddlewareWithDefault(config, DefaultAuthRateLimitConfig)
+	return rateLimitMiddlewareWithDefault(InProcessLimiters, "auth", config, DefaultAuthRateLimitConfig)
+}
+
+// rateLimitMiddleware returns rate limiting middleware for one route group on the
+// server's configured backend (in-process when RateLimitBackend is nil)
+func (s *Server) rateLimitMiddleware(name string, config, defaultConfig RateLimitInfo) func(http.Handler) http.Handler {
+	backend := s.RateLimitBackend
+	if backend == nil {
+		backend = InProcessLimiters
+	}
+	return rateLimitMiddlewareWithDefault(backend, name, config, defaultConfig)
 }
 
 // rateLimitMiddlewareWithDefault is the internal implementation that accepts a fallback default
-func rateLimitMiddlewareWithDefault(config, defaultConfig RateLimitInfo) func(http.Handler) http.Handler {
+func rateLimitMiddlewareWithDefault(backend LimiterBackend, name string, config, defaultConfig RateLimitInfo) func(http.Handler) http.Handler {
 	// Use provided default config if provided config is zero-valued (e.g., in tests)
 	// This prevents immediate 429s when Server{} is created without explicit config
 	if config.WindowSeconds == 0 || config.MaxRequests == 0 || config.Burst == 0 {
@@ -191,7 +226,20 @@ func rateLimitMiddlewareWithDefault(config, defaultConfig RateLimitInfo) func(ht
 
 	// Create a dedicated rate limiter for this middleware instance
 	// This allows different routes to have different rate limits
-	limiter := NewRateLimiter(config)
+	return limiterMiddleware(backend(name, config))
+}
+
+// SessionRateLimitMiddleware limits session creation per user with a dedicated limiter
+// BeginSession creates sessions and epoch rows, so a client stuck in a reconnect loop is
+// stopped well below the general limits. Share the limiter with the gRPC server so both
+// transports draw from the same per-user budget.
+func SessionRateLimitMiddleware(limiter Limiter) func(http.Handler) http.Handler {
+	return limiterMiddleware(limiter)
+}
+
+// limiterMiddleware enforces an existing rate limiter per user (429 with Retry-After)
+func limiterMiddleware(limiter Limiter) func(http.Handler) http.Handler {
+	config := limiter.Config()
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
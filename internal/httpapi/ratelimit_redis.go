@@ -0,0 +1,102 @@
+package httpapi
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// redisTokenBucket is the TokenBucket.Allow algorithm as one atomic Redis script
+// The bucket is a hash {tokens, ts} refilled from Redis' own clock, so replicas with
+// skewed clocks still share one consistent budget. Idle buckets expire once they would
+// be full again. Returns {allowed (0/1), tokens left (string, fractional)}.
+var redisTokenBucket = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2]) -- tokens per millisecond
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// redisLimiterTimeout bounds each Redis round trip so a slow Redis can't stall requests
+const redisLimiterTimeout = 250 * time.Millisecond
+
+// RedisLimiter is a Limiter whose per-user token buckets live in Redis, shared by every
+// replica. If Redis can't be reached the request is allowed (and logged): an outage of
+// the limiter must not take the API down with it.
+type RedisLimiter struct {
+	client redis.Scripter
+	prefix string // key prefix, e.g. "toolbridge:ratelimit:sync:"
+	config RateLimitInfo
+}
+
+// NewRedisLimiter creates a Redis-backed limiter; name separates its buckets from other
+// route groups' (see LimiterBackend)
+func NewRedisLimiter(client redis.Scripter, name string, config RateLimitInfo) *RedisLimiter {
+	return &RedisLimiter{client: client, prefix: "toolbridge:ratelimit:" + name + ":", config: config}
+}
+
+// RedisLimiters returns a LimiterBackend that keeps every group's budget in Redis
+func RedisLimiters(client redis.Scripter) LimiterBackend {
+	return func(name string, config RateLimitInfo) Limiter {
+		return NewRedisLimiter(client, name, config)
+	}
+}
+
+// Allow consumes a token from userID's shared bucket if one is available
+func (rl *RedisLimiter) Allow(userID string) (bool, int, time.Time, time.Time) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisLimiterTimeout)
+	defer cancel()
+
+	now := time.Now()
+	capacity := float64(rl.config.Burst)
+	refillRate := float64(rl.config.MaxRequests) / float64(rl.config.WindowSeconds) // tokens per second
+
+	res, err := redisTokenBucket.Run(ctx, rl.client, []string{rl.prefix + userID},
+		rl.config.Burst, strconv.FormatFloat(refillRate/1000, 'g', -1, 64)).Slice()
+	if err != nil || len(res) != 2 {
+		log.Warn().Err(err).Str("userId", userID).Msg("redis rate limiter unavailable, allowing request")
+		return true, rl.config.Burst, now, now
+	}
+	allowed, _ := res[0].(int64)
+	tokensStr, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		log.Warn().Err(err).Str("userId", userID).Msg("redis rate limiter returned a malformed bucket, allowing request")
+		return true, rl.config.Burst, now, now
+	}
+
+	fullResetTime := now.Add(time.Duration((capacity - tokens) / refillRate * float64(time.Second)))
+	if allowed == 1 {
+		return true, int(math.Floor(tokens)), now, fullResetTime
+	}
+	nextTokenTime := now.Add(time.Duration((1 - tokens) / refillRate * float64(time.Second)))
+	return false, 0, nextTokenTime, fullResetTime
+}
+
+// Config returns the limits this limiter enforces
+func (rl *RedisLimiter) Config() RateLimitInfo {
+	return rl.config
+}
@@ -0,0 +1,73 @@
+package httpapi
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestRedisLimiter_SharedBudget(t *testing.T) {
+	redisURL := os.Getenv("TEST_REDIS_URL")
+	if redisURL == "" {
+		t.Skip("TEST_REDIS_URL not set, skipping redis rate limit test")
+	}
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		t.Fatalf("Invalid TEST_REDIS_URL: %v", err)
+	}
+	client := redis.NewClient(opts)
+	defer client.Close()
+
+	// Two limiters on one Redis stand in for two replicas
+	config := RateLimitInfo{WindowSeconds: 60, MaxRequests: 1, Burst: 2}
+	replicaA := NewRedisLimiter(client, "test", config)
+	replicaB := NewRedisLimiter(client, "test", config)
+	user := "user-" + uuid.NewString()
+
+	if ok, remaining, _, _ := replicaA.Allow(user); !ok || remaining != 1 {
+		t.Fatalf("First request: allowed=%v remaining=%d, want true 1", ok, remaining)
+	}
+	if ok, _, _, _ := replicaB.Allow(user); !ok {
+		t.Fatal("Second request on the other replica should use the shared burst")
+	}
+	if ok, _, next, _ := replicaA.Allow(user); ok || next.IsZero() {
+		t.Errorf("Third request should be limited across replicas, got allowed=%v next=%v", ok, next)
+	}
+
+	// Other route groups and users have their own budget
+	if ok, _, _, _ := NewRedisLimiter(client, "other", config).Allow(user); !ok {
+		t.Error("Expected a separate budget for another group")
+	}
+	if ok, _, _, _ := replicaA.Allow("user-" + uuid.NewString()); !ok {
+		t.Error("Expected a separate budget for another user")
+	}
+}
+
+func TestRedisLimiter_FailsOpen(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+
+	limiter := NewRedisLimiter(client, "test", RateLimitInfo{WindowSeconds: 60, MaxRequests: 1, Burst: 1})
+	for i := 0; i < 3; i++ {
+		if ok, _, _, _ := limiter.Allow("user-1"); !ok {
+			t.Fatalf("Request %d: expected unreachable redis to allow the request", i+1)
+		}
+	}
+}
+
+func TestServerRateLimitBackend(t *testing.T) {
+	var groups []string
+	srv := &Server{RateLimitBackend: func(name string, config RateLimitInfo) Limiter {
+		groups = append(groups, name)
+		return NewRateLimiter(config)
+	}}
+	srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	if got := strings.Join(groups, ","); got != "auth,sync,rest" {
+		t.Errorf("Expected the backend to build the auth, sync and rest limiters, got %q", got)
+	}
+}
@@ -3,6 +3,7 @@ package httpapi
 import (
 	"context"
 	"encoding/json"
+	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"testing"
@@ -371,3 +372,33 @@ func TestRateLimiting_PerUser(t *testing.T) {
 		t.Error("User B should have tokens remaining (independent rate limit)")
 	}
 }
+
+func TestSessionRateLimitMiddleware(t *testing.T) {
+	limiter := NewRateLimiter(RateLimitInfo{WindowSeconds: 60, MaxRequests: 1, Burst: 2})
+	h := SessionRateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	beginSession := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/sync/sessions", nil)
+		req = req.WithContext(context.WithValue(req.Context(), auth.CtxUserID, userID))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// Burst of 2, then the reconnect loop is cut off
+	for i, expected := range []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests} {
+		if rec := beginSession("looping-user"); rec.Code != expected {
+			t.Fatalf("Request %d: expected %d, got %d", i+1, expected, rec.Code)
+		}
+	}
+	if rec := beginSession("looping-user"); rec.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After on a rate limited session creation")
+	}
+
+	// Other users have their own budget
+	if rec := beginSession("other-user"); rec.Code != http.StatusCreated {
+		t.Errorf("Expected other user to create a session, got %d", rec.Code)
+	}
+}
@@ -65,6 +65,9 @@ func TestNotesCRUD(t *testing.T) {
 				if resp.UID == "" {
 					t.Error("Expected UID to be set")
 				}
+				if loc := w.Header().Get("Location"); loc != "/v1/notes/"+resp.UID {
+					t.Errorf("Expected Location /v1/notes/%s, got %q", resp.UID, loc)
+				}
 			},
 		},
 		{
@@ -320,6 +323,90 @@ func TestNotesCRUD(t *testing.T) {
 	}
 }
 
+// TestNotesPurge tests DELETE /v1/notes/{uid}?purge=true and purge propagation through pull
+func TestNotesPurge(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	if _, err := pool.Exec(context.Background(), "DELETE FROM purge_marker"); err != nil {
+		t.Fatalf("Failed to clean purge_marker table: %v", err)
+	}
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	ctx := context.Background()
+	userID := createTestUser(t, pool, testUserSubject)
+	session := createTestSession(t, router)
+
+	noteUID := uuid.New()
+	if _, err := srv.NoteSvc.ApplyNoteMutation(ctx, userID, map[string]any{
+		"uid":   noteUID.String(),
+		"title": "Purge Test Note",
+	}, syncservice.MutationOpts{}); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+	purgePath := fmt.Sprintf("/v1/notes/%s?purge=true", noteUID)
+
+	// Active note cannot be purged
+	w := makeRequestWithSession(t, router, "DELETE", purgePath, nil, session)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected 409 purging active note, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	// Soft delete, then purge
+	w = makeRequestWithSession(t, router, "DELETE", fmt.Sprintf("/v1/notes/%s", noteUID), nil, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Soft delete failed: %d. Body: %s", w.Code, w.Body.String())
+	}
+	w = makeRequestWithSession(t, router, "DELETE", purgePath, nil, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 purging tombstone, got %d. Body: %s", w.Code, w.Body.String())
+	}
+
+	// Row is gone
+	w = makeRequestWithSession(t, router, "GET", fmt.Sprintf("/v1/notes/%s?includeDeleted=true", noteUID), nil, session)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 after purge, got %d", w.Code)
+	}
+
+	// Purging again is a 404
+	w = makeRequestWithSession(t, router, "DELETE", purgePath, nil, session)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 purging twice, got %d", w.Code)
+	}
+
+	// Pull from the beginning surfaces a purge marker in deletes
+	w = makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Pull failed: %d. Body: %s", w.Code, w.Body.String())
+	}
+	var pull pullResp
+	if err := json.NewDecoder(w.Body).Decode(&pull); err != nil {
+		t.Fatalf("Failed to decode pull response: %v", err)
+	}
+	found := false
+	for _, del := range pull.Deletes {
+		if del["uid"] == noteUID.String() {
+			found = true
+			if del["purged"] != true {
+				t.Errorf("Expected purged=true on delete entry, got %v", del)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected purge marker for %s in pull deletes: %+v", noteUID, pull.Deletes)
+	}
+}
+
 // TestTasksCRUD tests comprehensive CRUD operations for tasks
 func TestTasksCRUD(t *testing.T) {
 	if testing.Short() {
@@ -885,6 +972,19 @@ func TestChatMessagesCRUD(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:   "POST - Reject unknown role",
+			method: "POST",
+			pathFunc: func(_ string) string {
+				return "/v1/chat_messages"
+			},
+			body: map[string]any{
+				"chatUid": chatUID.String(),
+				"role":    "narrator",
+				"content": "Once upon a time",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
 		{
 			name:   "GET - Retrieve message",
 			method: "GET",
@@ -1088,6 +1188,19 @@ func TestCommentsCRUD(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:   "POST - Reject malformed parentUid",
+			method: "POST",
+			pathFunc: func(_ string) string {
+				return "/v1/comments"
+			},
+			body: map[string]any{
+				"parentType": "note",
+				"parentUid":  "not-a-uuid",
+				"content":    "Orphan",
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
 		{
 			name:   "GET - Retrieve comment",
 			method: "GET",
@@ -0,0 +1,570 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// restEntity is everything the generic REST handlers need to serve one entity type
+// Exposing an entity over REST is one entry in Server.restEntities plus mountRESTEntity.
+type restEntity struct {
+	entityDef
+	store itemStore
+	list  func(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts syncservice.ListOpts) (*syncservice.RESTListResponse, error)
+
+	// listWhere adds filters from query params beyond ?where= (e.g. a parent); it
+	// validates them and returns a 400-worthy error. nil = none.
+	listWhere func(r *http.Request) ([]syncservice.FieldFilter, error)
+
+	// archive marks a payload archived for POST /{uid}/archive (see archiveMarkers)
+	archive func(payload map[string]any)
+
+	// purge hard-deletes a tombstone for DELETE ?purge=true (nil = not supported)
+	purge func(ctx context.Context, userID string, uid uuid.UUID) (int64, error)
+
+	// getBySeq reads an item by its sequential display ID for GET /seq/{n} (nil = not supported)
+	getBySeq func(ctx context.Context, userID string, seqID int64) (*syncservice.RESTItem, error)
+}
+
+// label is the singular name used in messages ("note", "chat message")
+func (e restEntity) label() string { return strings.ReplaceAll(e.Table, "_", " ") }
+
+// plural is the collection name used in messages ("notes", "chat messages")
+func (e restEntity) plural() string { return strings.ReplaceAll(e.Name, "_", " ") }
+
+// restEntities maps entity names to their REST wiring
+func (s *Server) restEntities() map[string]restEntity {
+	stores := s.itemStores()
+	def := func(name string) entityDef {
+		for _, e := range entityCatalog {
+			if e.Name == name {
+				return e
+			}
+		}
+		panic("unknown entity " + name)
+	}
+	return map[string]restEntity{
+		"notes": {
+			entityDef: def("notes"),
+			store:     stores["note"],
+			list:      s.NoteSvc.ListNotes,
+			archive:   archiveMarkers["note"],
+			purge:     s.NoteSvc.PurgeNote,
+		},
+		"tasks": {
+			entityDef: def("tasks"),
+			store:     stores["task"],
+			list:      s.TaskSvc.ListTasks,
+			archive:   archiveMarkers["task"],
+			getBySeq:  s.TaskSvc.GetTaskBySeq,
+		},
+	}
+}
+
+// mountRESTEntity registers the CRUD, facet, export, archive, process, batch and seq routes of a registered entity
+func (s *Server) mountRESTEntity(r chi.Router, name string) {
+	e, ok := s.restEntities()[name]
+	if !ok {
+		panic("entity " + name + " is not registered in restEntities")
+	}
+	base := "/v1/" + e.Name
+	r.Get(base, s.listItems(e))
+	r.Get(base+"/facets", s.facetItems(e))
+	if len(s.syncCfg().ExportColumnsFor(e.Table)) > 0 {
+		r.Get(base+"/export.csv", s.exportCSV(e))
+	}
+	r.Post(base, s.createItem(e))
+	r.Get(base+"/{uid}", s.getItem(e))
+	r.Put(base+"/{uid}", s.updateItem(e))
+	r.Patch(base+"/{uid}", s.patchItem(e))
+	r.Delete(base+"/{uid}", s.deleteItem(e))
+	r.Post(base+"/{uid}/archive", s.archiveItem(e))
+	r.Post(base+"/{uid}/process", s.processItem(e))
+	r.Post(base+"/batch_archive", s.BatchArchive(e.Table))
+	r.Post(base+"/batch_patch", s.BatchPatch(e.Table))
+	if e.getBySeq != nil {
+		r.Get(base+"/seq/{n}", s.getItemBySeq(e))
+	}
+}
+
+// listItems handles GET /v1/<entity>
+func (s *Server) listItems(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := auth.UserID(r.Context())
+		ctx := r.Context()
+		logger := log.Ctx(ctx)
+
+		// Parse pagination params
+		limit := s.pageLimit(w, r.URL.Query().Get("limit"), e.Table)
+		cur, err := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+		if err != nil {
+			writeError(w, r, 400, err.Error())
+			return
+		}
+		listOpts, err := s.parseListOpts(r, e.Table)
+		if err != nil {
+			writeError(w, r, 400, err.Error())
+			return
+		}
+		if e.listWhere != nil {
+			filters, err := e.listWhere(r)
+			if err != nil {
+				writeError(w, r, 400, err.Error())
+				return
+			}
+			listOpts.Where = append(listOpts.Where, filters...)
+		}
+
+		resp, err := e.list(ctx, userID, cur, limit, listOpts)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to list " + e.plural())
+			writeError(w, r, 500, "failed to list "+e.plural())
+			return
+		}
+
+		setPaginationLinks(w, r, resp.NextCursor)
+		writeJSON(w, 200, resp)
+	}
+}
+
+// facetItems handles GET /v1/<entity>/facets?by=<field>
+// Counts items per value of an allowlisted payload field in one grouped query. Takes
+// the list filters (?includeDeleted, ?deletedOnly, ?where=); tombstones are left out by default.
+func (s *Server) facetItems(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := auth.UserID(r.Context())
+		ctx := r.Context()
+
+		field, err := s.syncCfg().ParseFacetField(e.Table, r.URL.Query().Get("by"))
+		if err != nil {
+			writeError(w, r, 400, err.Error())
+			return
+		}
+		listOpts, err := s.parseListOpts(r, e.Table)
+		if err != nil {
+			writeError(w, r, 400, err.Error())
+			return
+		}
+		if e.listWhere != nil {
+			filters, err := e.listWhere(r)
+			if err != nil {
+				writeError(w, r, 400, err.Error())
+				return
+			}
+			listOpts.Where = append(listOpts.Where, filters...)
+		}
+
+		resp, err := syncservice.CountFacets(ctx, s.DB, e.Table, userID, field, listOpts)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to count " + e.label() + " facets")
+			writeError(w, r, 500, "failed to count "+e.label()+" facets")
+			return
+		}
+		writeJSON(w, 200, resp)
+	}
+}
+
+// createItem handles POST /v1/<entity> (server generates the UID if missing)
+func (s *Server) createItem(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID := auth.UserID(r.Context())
+		ctx := r.Context()
+
+		payload, ok := s.readMutationPayload(w, r)
+		if !ok {
+			return
+		}
+
+		item, err := e.store.apply(ctx, userID, payload, syncservice.MutationOpts{})
+		if err != nil {
+			writeMutationError(w, r, err, false, "create "+e.label())
+			return
+		}
+
+		s.writeCreated(w, "/v1/"+e.Name, item)
+	}
+}
+
+// getItem handles GET /v1/<entity>/{uid}
+func (s *Server) getItem(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid, ok := parseUIDParam(r)
+		if !ok {
+			writeError(w, r, 400, "invalid UID")
+			return
+		}
+
+		item, err := e.store.get(r.Context(), auth.UserID(r.Context()), uid)
+		if err != nil {
+			log.Ctx(r.Context()).Error().Err(err).Msg("failed to get " + e.label())
+			writeError(w, r, 500, "failed to get "+e.label())
+			return
+		}
+		s.serveItem(w, r, e, item)
+	}
+}
+
+// getItemBySeq handles GET /v1/<entity>/seq/{n} (see syncservice.SetSeqIDs)
+// Answers like GET /v1/<entity>/{uid} for the item holding display ID n.
+func (s *Server) getItemBySeq(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		seqID, err := strconv.ParseInt(chi.URLParam(r, "n"), 10, 64)
+		if err != nil || seqID < 1 {
+			writeError(w, r, 400, "invalid seq id (want a positive integer)")
+			return
+		}
+
+		item, err := e.getBySeq(r.Context(), auth.UserID(r.Context()), seqID)
+		if err != nil {
+			log.Ctx(r.Context()).Error().Err(err).Msg("failed to get " + e.label() + " by seq id")
+			writeError(w, r, 500, "failed to get "+e.label())
+			return
+		}
+		s.serveItem(w, r, e, item)
+	}
+}
+
+// serveItem writes the response of a single-item GET: 404 if missing, 410 if deleted
+// (unless ?includeDeleted), 304 if the client's copy is current, and the item otherwise
+func (s *Server) serveItem(w http.ResponseWriter, r *http.Request, e restEntity, item *syncservice.RESTItem) {
+	if item == nil {
+		writeError(w, r, 404, e.label()+" not found")
+		return
+	}
+	if item.DeletedAt != nil && !parseIncludeDeleted(r) {
+		writeJSON(w, 410, map[string]any{
+			"error":     e.label() + " deleted",
+			"deletedAt": item.DeletedAt,
+		})
+		return
+	}
+
+	// Conditional GET: the client's copy is current
+	if s.notModified(r, item) {
+		w.Header().Set("ETag", s.itemETag(item))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.writeItem(w, 200, item)
+}
+
+// updateItem handles PUT /v1/<entity>/{uid} (full replacement)
+func (s *Server) updateItem(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		uid, existing, ok := s.loadLiveItem(w, r, e, "update")
+		if !ok {
+			return
+		}
+
+		payload, ok := s.readMutationPayload(w, r)
+		if !ok {
+			return
+		}
+
+		// Ensure UID in payload matches URL
+		payload["uid"] = uid.String()
+
+		opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+		if !ok {
+			return
+		}
+
+		item, err := e.store.apply(r.Context(), auth.UserID(r.Context()), payload, opts)
+		if err != nil {
+			writeMutationError(w, r, err, usedIfMatch, "update "+e.label())
+			return
+		}
+
+		s.writeItem(w, 200, item)
+	}
+}
+
+// patchItem handles PATCH /v1/<entity>/{uid} (shallow merge into the stored payload)
+func (s *Server) patchItem(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, existing, ok := s.loadLiveItem(w, r, e, "patch")
+		if !ok {
+			return
+		}
+
+		partial, ok := s.readMutationPayload(w, r)
+		if !ok {
+			return
+		}
+
+		// Check If-Match against the stored item before merging into it
+		opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+		if !ok {
+			return
+		}
+
+		merged := existing.Payload
+		mergePatch(merged, partial)
+
+		item, err := e.store.apply(r.Context(), auth.UserID(r.Context()), merged, opts)
+		if err != nil {
+			writeMutationError(w, r, err, usedIfMatch, "patch "+e.label())
+			return
+		}
+
+		s.writeItem(w, 200, item)
+	}
+}
+
+// deleteItem handles DELETE /v1/<entity>/{uid} (soft delete)
+// With ?purge=true, entities that support it permanently delete an already
+// soft-deleted item (see purgeItem).
+func (s *Server) deleteItem(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		uid, ok := parseUIDParam(r)
+		if !ok {
+			writeError(w, r, 400, "invalid UID")
+			return
+		}
+
+		if e.purge != nil && r.URL.Query().Get("purge") == "true" {
+			s.purgeItem(w, r, e, uid)
+			return
+		}
+
+		existing, err := e.store.get(ctx, auth.UserID(ctx), uid)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to get " + e.label() + " for delete")
+			writeError(w, r, 500, "failed to get "+e.label())
+			return
+		}
+		if existing == nil {
+			writeError(w, r, 404, e.label()+" not found")
+			return
+		}
+		if existing.DeletedAt != nil {
+			writeJSON(w, 410, map[string]any{
+				"error":     e.label() + " already deleted",
+				"deletedAt": existing.DeletedAt,
+			})
+			return
+		}
+
+		opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+		if !ok {
+			return
+		}
+		opts.SetDeleted = true
+
+		item, err := e.store.apply(ctx, auth.UserID(ctx), existing.Payload, opts)
+		if err != nil {
+			writeMutationError(w, r, err, usedIfMatch, "delete "+e.label())
+			return
+		}
+
+		s.writeItem(w, 200, item)
+	}
+}
+
+// purgeItem hard-deletes a tombstoned item ("delete forever")
+// The item must be soft-deleted first (409 otherwise); 404 if missing or already purged.
+// Other clients drop the item via a purged delete entry in /v1/sync/<entity>/pull.
+func (s *Server) purgeItem(w http.ResponseWriter, r *http.Request, e restEntity, uid uuid.UUID) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	purgedAtMs, err := e.purge(ctx, userID, uid)
+	switch {
+	case errors.Is(err, syncservice.ErrNotDeleted):
+		writeError(w, r, 409, e.label()+" must be deleted before it can be purged")
+		return
+	case errors.Is(err, syncservice.ErrAlreadyPurged):
+		writeError(w, r, 404, e.label()+" already purged")
+		return
+	case errors.Is(err, syncservice.ErrNotFound):
+		writeError(w, r, 404, e.label()+" not found")
+		return
+	case err != nil:
+		logger.Error().Err(err).Msg("failed to purge " + e.label())
+		writeError(w, r, 500, "failed to purge "+e.label())
+		return
+	}
+
+	logger.Info().Str("user_id", userID).Str("uid", uid.String()).Msg(e.Table + "_purged")
+
+	writeJSON(w, 200, map[string]any{
+		"uid":      uid.String(),
+		"purged":   true,
+		"purgedAt": syncx.RFC3339(purgedAtMs),
+	})
+}
+
+// archiveItem handles POST /v1/<entity>/{uid}/archive
+func (s *Server) archiveItem(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, existing, ok := s.loadLiveItem(w, r, e, "archive")
+		if !ok {
+			return
+		}
+
+		opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+		if !ok {
+			return
+		}
+
+		payload := existing.Payload
+		e.archive(payload)
+
+		item, err := e.store.apply(r.Context(), auth.UserID(r.Context()), payload, opts)
+		if err != nil {
+			writeMutationError(w, r, err, usedIfMatch, "archive "+e.label())
+			return
+		}
+
+		s.writeItem(w, 200, item)
+	}
+}
+
+// processItem handles POST /v1/<entity>/{uid}/process (see entityDef.Actions)
+func (s *Server) processItem(e restEntity) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := parseUIDParam(r); !ok {
+			writeError(w, r, 400, "invalid UID")
+			return
+		}
+
+		var req struct {
+			Action   string         `json:"action"`
+			Metadata map[string]any `json:"metadata,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, 400, "invalid JSON")
+			return
+		}
+
+		_, existing, ok := s.loadLiveItem(w, r, e, "process")
+		if !ok {
+			return
+		}
+
+		opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+		if !ok {
+			return
+		}
+
+		payload := existing.Payload
+		if err := e.Actions.apply(req.Action, req.Metadata, s.UnknownProcessMetadata, payload); err != nil {
+			writeActionError(w, r, req.Action, e.Actions, err)
+			return
+		}
+
+		item, err := e.store.apply(r.Context(), auth.UserID(r.Context()), payload, opts)
+		if err != nil {
+			writeMutationError(w, r, err, usedIfMatch, "process "+e.label())
+			return
+		}
+
+		s.writeItem(w, 200, item)
+	}
+}
+
+// readMutationPayload decodes a REST create, PUT or PATCH body and applies the reserved
+// key mode (see syncservice.Config.CheckReservedKeys), writing 400/422 and returning false on failure
+func (s *Server) readMutationPayload(w http.ResponseWriter, r *http.Request) (map[string]any, bool) {
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		writeError(w, r, 400, "invalid JSON")
+		return nil, false
+	}
+	if err := s.syncCfg().CheckReservedKeys(payload); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+	return payload, true
+}
+
+// mergePatch shallow-merges a PATCH body into a stored payload
+// uid and sync are skipped: a patch can't re-key the item or override sync metadata.
+func mergePatch(payload, partial map[string]any) {
+	for k, v := range partial {
+		if k != "uid" && k != "sync" {
+			payload[k] = v
+		}
+	}
+}
+
+// loadLiveItem reads the {uid} item a mutation (op) applies to, writing 400/404/410/500
+// and returning false if there is none to mutate
+func (s *Server) loadLiveItem(w http.ResponseWriter, r *http.Request, e restEntity, op string) (uuid.UUID, *syncservice.RESTItem, bool) {
+	ctx := r.Context()
+
+	uid, ok := parseUIDParam(r)
+	if !ok {
+		writeError(w, r, 400, "invalid UID")
+		return uuid.Nil, nil, false
+	}
+
+	existing, err := e.store.get(ctx, auth.UserID(ctx), uid)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to get " + e.label() + " for " + op)
+		writeError(w, r, 500, "failed to get "+e.label())
+		return uuid.Nil, nil, false
+	}
+	if existing == nil {
+		writeError(w, r, 404, e.label()+" not found")
+		return uuid.Nil, nil, false
+	}
+	if existing.DeletedAt != nil {
+		writeJSON(w, 410, map[string]any{
+			"error":     e.label() + " deleted",
+			"deletedAt": existing.DeletedAt,
+		})
+		return uuid.Nil, nil, false
+	}
+	return uid, existing, true
+}
+
+// writeMutationError maps a failed create/update to its HTTP status
+// A version mismatch is 412 when the client sent If-Match and 409 otherwise (RFC 7232).
+func writeMutationError(w http.ResponseWriter, r *http.Request, err error, usedIfMatch bool, op string) {
+	if _, ok := err.(*syncservice.VersionMismatchError); ok {
+		statusCode := 412
+		if !usedIfMatch {
+			statusCode = 409
+		}
+		writeError(w, r, statusCode, "version mismatch: "+err.Error())
+		return
+	}
+	var uniqueErr *syncservice.UniqueConflictError
+	if errors.As(err, &uniqueErr) {
+		writeUniqueConflict(w, r, uniqueErr)
+		return
+	}
+	var quotaErr *syncservice.QuotaExceededError
+	if errors.As(err, &quotaErr) {
+		writeError(w, r, http.StatusForbidden, err.Error())
+		return
+	}
+	var appendOnlyErr *syncservice.AppendOnlyError
+	if errors.As(err, &appendOnlyErr) {
+		writeError(w, r, http.StatusConflict, err.Error())
+		return
+	}
+	var fieldErr *syncx.FieldError
+	if errors.As(err, &fieldErr) {
+		writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	log.Ctx(r.Context()).Error().Err(err).Msg("failed to " + op)
+	writeError(w, r, 500, "failed to "+op)
+}
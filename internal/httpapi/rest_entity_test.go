@@ -0,0 +1,190 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/payloadcrypt"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestRESTEntitiesWiring(t *testing.T) {
+	srv := &Server{
+		NoteSvc:             syncservice.NewNoteService(nil),
+		TaskSvc:             syncservice.NewTaskService(nil),
+		CommentSvc:          syncservice.NewCommentService(nil),
+		ChatSvc:             syncservice.NewChatService(nil),
+		ChatMessageSvc:      syncservice.NewChatMessageService(nil),
+		TaskListSvc:         syncservice.NewTaskListService(nil),
+		TaskListCategorySvc: syncservice.NewTaskListCategoryService(nil),
+	}
+
+	for name, e := range srv.restEntities() {
+		if e.Name != name {
+			t.Errorf("%s: registered under the wrong name %q", name, e.Name)
+		}
+		if syncEntityTables[name] != e.Table {
+			t.Errorf("%s: table %q does not match the sync table %q", name, e.Table, syncEntityTables[name])
+		}
+		if e.store.get == nil || e.store.apply == nil || e.list == nil {
+			t.Errorf("%s: missing store or list wiring", name)
+		}
+		if e.archive == nil {
+			t.Errorf("%s: no archive marker", name)
+		}
+		if len(e.Actions) == 0 {
+			t.Errorf("%s: no process actions", name)
+		}
+	}
+}
+
+func TestRESTEntityLabels(t *testing.T) {
+	e := restEntity{entityDef: entityDef{Name: "chat_messages", Table: "chat_message"}}
+	if got := e.label(); got != "chat message" {
+		t.Errorf("label() = %q", got)
+	}
+	if got := e.plural(); got != "chat messages" {
+		t.Errorf("plural() = %q", got)
+	}
+}
+
+func TestGetItemBySeq(t *testing.T) {
+	seqID := int64(7)
+	deletedAt := "2025-01-01T00:00:00Z"
+	items := map[int64]*syncservice.RESTItem{
+		7: {UID: "11111111-1111-1111-1111-111111111111", Version: 2, SeqID: &seqID, Payload: map[string]any{"title": "seven"}},
+		8: {UID: "22222222-2222-2222-2222-222222222222", Version: 3, DeletedAt: &deletedAt, Payload: map[string]any{}},
+	}
+	var lookups int
+	e := restEntity{
+		entityDef: entityDef{Name: "tasks", Table: "task"},
+		getBySeq: func(ctx context.Context, userID string, n int64) (*syncservice.RESTItem, error) {
+			lookups++
+			return items[n], nil
+		},
+	}
+	r := chi.NewRouter()
+	r.Get("/v1/tasks/seq/{n}", (&Server{}).getItemBySeq(e))
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/v1/tasks/seq/7", 200},
+		{"/v1/tasks/seq/8", 410},
+		{"/v1/tasks/seq/9", 404},
+		{"/v1/tasks/seq/0", 400},
+		{"/v1/tasks/seq/abc", 400},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d (%s)", tt.path, w.Code, tt.want, w.Body.String())
+		}
+	}
+	if lookups != 3 {
+		t.Errorf("expected invalid seq ids to be rejected before lookup, got %d lookups", lookups)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/tasks/seq/7", nil))
+	var got syncservice.RESTItem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.SeqID == nil || *got.SeqID != 7 || got.UID != items[7].UID {
+		t.Errorf("unexpected item: %+v", got)
+	}
+}
+
+func TestListItems_EncryptedFilter(t *testing.T) {
+	c, err := payloadcrypt.NewCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	srv := &Server{SyncCfg: syncservice.DefaultConfig()}
+	srv.SyncCfg.PayloadCipher = c
+
+	var listed []syncservice.ListOpts
+	e := restEntity{
+		entityDef: entityDef{Name: "tasks", Table: "task"},
+		list: func(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts syncservice.ListOpts) (*syncservice.RESTListResponse, error) {
+			listed = append(listed, opts)
+			return &syncservice.RESTListResponse{}, nil
+		},
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"?where=taskListUid:abc", 200}, // relationship fields stay plaintext
+		{"?where=status:open", 400},     // encrypted at rest: would silently match nothing
+		{"?where=taskListUid:abc&where=priority:high", 400},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		srv.listItems(e)(w, httptest.NewRequest("GET", "/v1/tasks"+tt.query, nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.query, w.Code, tt.want, w.Body.String())
+		}
+	}
+	if len(listed) != 1 {
+		t.Errorf("expected only the plaintext filter to reach the list, got %d lists", len(listed))
+	}
+}
+
+func TestFacetItems_EncryptedField(t *testing.T) {
+	c, err := payloadcrypt.NewCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	srv := &Server{SyncCfg: syncservice.DefaultConfig()}
+	srv.SyncCfg.PayloadCipher = c
+
+	e := restEntity{entityDef: entityDef{Name: "tasks", Table: "task"}}
+	for _, query := range []string{
+		"?by=status",                          // encrypted at rest: every item would count as null
+		"?by=taskListUid&where=priority:high", // same allowlist as list filters
+	} {
+		w := httptest.NewRecorder()
+		srv.facetItems(e)(w, httptest.NewRequest("GET", "/v1/tasks/facets"+query, nil))
+		if w.Code != 400 {
+			t.Errorf("%s: status %d, want 400: %s", query, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestWriteMutationError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		usedIfMatch bool
+		want        int
+	}{
+		{"version mismatch with If-Match", &syncservice.VersionMismatchError{Expected: 1, Actual: 2}, true, 412},
+		{"version mismatch without If-Match", &syncservice.VersionMismatchError{Expected: 1, Actual: 2}, false, 409},
+		{"unique conflict", &syncservice.UniqueConflictError{Field: "name", Value: "x"}, false, 409},
+		{"quota exceeded", &syncservice.QuotaExceededError{Used: 10, Limit: 10, Grow: 1}, false, 403},
+		{"append-only", fmt.Errorf("wrapped: %w", &syncservice.AppendOnlyError{Table: "chat_message"}), true, 409},
+		{"field error", &syncx.FieldError{Field: "title", Reason: "too long"}, false, 422},
+		{"other", errors.New("boom"), false, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			writeMutationError(w, httptest.NewRequest("POST", "/v1/chats", nil), tt.err, tt.usedIfMatch, "archive chat")
+			if w.Code != tt.want {
+				t.Errorf("Expected %d, got %d (%s)", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
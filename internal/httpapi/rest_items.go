@@ -2,8 +2,12 @@ package httpapi
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
@@ -29,14 +33,16 @@ import (
 // /v1/sync/<entity>/pull operations.
 //
 // Endpoints per entity:
-// - GET    /<entity>              - List (cursor pagination, supports ?includeDeleted=true)
+// - GET    /<entity>              - List (cursor pagination, supports ?includeDeleted=true and ?deletedOnly=true)
 // - POST   /<entity>              - Create (server generates UID if missing)
 // - GET    /<entity>/{uid}        - Retrieve single
 // - PUT    /<entity>/{uid}        - Replace (full update, supports If-Match)
 // - PATCH  /<entity>/{uid}        - Partial update
-// - DELETE /<entity>/{uid}        - Soft delete
-// - POST   /<entity>/{uid}/archive - Archive (sets status/archived field)
-// - POST   /<entity>/{uid}/process - Process action (state machine transitions)
+// - DELETE /<entity>/{uid}        - Soft delete (notes: ?purge=true hard-deletes a tombstone)
+// - POST   /<entity>/{uid}/archive - Archive (sets status/archived field, supports If-Match)
+// - POST   /<entity>/batch_archive - Archive many in one transaction (see BatchArchive)
+// - POST   /<entity>/batch_patch   - Merge one partial update into many in one transaction (see BatchPatch)
+// - POST   /<entity>/{uid}/process - Process action (state machine transitions, supports If-Match)
 //
 // ============================================================================
 
@@ -76,785 +82,62 @@ func parseIfMatchHeader(r *http.Request) (int, bool) {
 	return version, true
 }
 
-// parseIncludeDeleted parses ?includeDeleted query param
-func parseIncludeDeleted(r *http.Request) bool {
-	return r.URL.Query().Get("includeDeleted") == "true"
-}
-
-// ============================================================================
-// Notes Handlers
-// ============================================================================
-
-// ListNotes handles GET /v1/notes
-func (s *Server) ListNotes(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	// Parse pagination params
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	}
-	includeDeleted := parseIncludeDeleted(r)
-
-	// Call service
-	resp, err := s.NoteSvc.ListNotes(ctx, userID, cur, limit, includeDeleted)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to list notes")
-		writeError(w, r, 500, "failed to list notes")
-		return
-	}
-
-	writeJSON(w, 200, resp)
-}
-
-// CreateNote handles POST /v1/notes
-func (s *Server) CreateNote(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Create note (server generates UID if missing)
-	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, payload, syncservice.MutationOpts{})
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to create note")
-		writeError(w, r, 500, "failed to create note")
-		return
-	}
-
-	writeJSON(w, 201, item)
-}
-
-// GetNote handles GET /v1/notes/{uid}
-func (s *Server) GetNote(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	includeDeleted := parseIncludeDeleted(r)
-	item, err := s.NoteSvc.GetNote(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get note")
-		writeError(w, r, 500, "failed to get note")
-		return
-	}
-
-	if item == nil {
-		writeError(w, r, 404, "note not found")
-		return
-	}
-
-	// Check if deleted
-	if item.DeletedAt != nil && !includeDeleted {
-		writeJSON(w, 410, map[string]any{
-			"error":     "note deleted",
-			"deletedAt": item.DeletedAt,
-		})
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// UpdateNote handles PUT /v1/notes/{uid}
-func (s *Server) UpdateNote(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Check if note exists and is not deleted
-	existing, err := s.NoteSvc.GetNote(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get note for update")
-		writeError(w, r, 500, "failed to get note")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "note not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "note deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Ensure UID in payload matches URL
-	payload["uid"] = uid.String()
-
-	// Check for optimistic locking
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
-
-	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, payload, opts)
-	if err != nil {
-		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to update note")
-		writeError(w, r, 500, "failed to update note")
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// PatchNote handles PATCH /v1/notes/{uid}
-func (s *Server) PatchNote(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Fetch existing note
-	existing, err := s.NoteSvc.GetNote(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get note for patch")
-		writeError(w, r, 500, "failed to get note")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "note not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "note deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	// Parse partial update
-	var partial map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&partial); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Merge partial into existing payload
-	merged := existing.Payload
-	for k, v := range partial {
-		if k != "uid" && k != "sync" { // Don't allow overriding sync metadata
-			merged[k] = v
-		}
-	}
-
-	// Apply mutation
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
-
-	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, merged, opts)
-	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to patch note")
-		writeError(w, r, 500, "failed to patch note")
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// DeleteNote handles DELETE /v1/notes/{uid}
-func (s *Server) DeleteNote(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Fetch existing note to get payload
-	existing, err := s.NoteSvc.GetNote(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get note for delete")
-		writeError(w, r, 500, "failed to get note")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "note not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "note already deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	// Soft delete
-	opts := syncservice.MutationOpts{SetDeleted: true}
-	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, existing.Payload, opts)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete note")
-		writeError(w, r, 500, "failed to delete note")
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// ArchiveNote handles POST /v1/notes/{uid}/archive
-func (s *Server) ArchiveNote(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Fetch existing note
-	existing, err := s.NoteSvc.GetNote(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get note for archive")
-		writeError(w, r, 500, "failed to get note")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "note not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "note deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	// Set archived status
-	payload := existing.Payload
-	payload["status"] = "archived"
-
-	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, payload, syncservice.MutationOpts{})
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to archive note")
-		writeError(w, r, 500, "failed to archive note")
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// ProcessNote handles POST /v1/notes/{uid}/process
-func (s *Server) ProcessNote(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Parse action
-	var req struct {
-		Action   string         `json:"action"`
-		Metadata map[string]any `json:"metadata,omitempty"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Fetch existing note
-	existing, err := s.NoteSvc.GetNote(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get note for process")
-		writeError(w, r, 500, "failed to get note")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "note not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "note deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	// Apply action
-	payload := existing.Payload
-	switch req.Action {
-	case "pin":
-		payload["pinned"] = true
-	case "unpin":
-		payload["pinned"] = false
-	case "archive":
-		payload["status"] = "archived"
-	case "unarchive":
-		payload["status"] = "active"
-	default:
-		writeError(w, r, 400, "invalid action: "+req.Action)
-		return
-	}
-
-	item, err := s.NoteSvc.ApplyNoteMutation(ctx, userID, payload, syncservice.MutationOpts{})
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to process note")
-		writeError(w, r, 500, "failed to process note")
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// ============================================================================
-// Tasks Handlers
-// ============================================================================
-
-// ListTasks handles GET /v1/tasks
-func (s *Server) ListTasks(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	// Parse pagination params
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
-	}
-	includeDeleted := parseIncludeDeleted(r)
-
-	// Call service
-	resp, err := s.TaskSvc.ListTasks(ctx, userID, cur, limit, includeDeleted)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to list tasks")
-		writeError(w, r, 500, "failed to list tasks")
-		return
-	}
-
-	writeJSON(w, 200, resp)
-}
-
-// CreateTask handles POST /v1/tasks
-func (s *Server) CreateTask(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Create task (server generates UID if missing)
-	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, payload, syncservice.MutationOpts{})
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to create task")
-		writeError(w, r, 500, "failed to create task")
-		return
-	}
-
-	writeJSON(w, 201, item)
-}
-
-// GetTask handles GET /v1/tasks/{uid}
-func (s *Server) GetTask(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	includeDeleted := parseIncludeDeleted(r)
-	item, err := s.TaskSvc.GetTask(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get task")
-		writeError(w, r, 500, "failed to get task")
-		return
-	}
-
-	if item == nil {
-		writeError(w, r, 404, "task not found")
-		return
-	}
-
-	// Check if deleted
-	if item.DeletedAt != nil && !includeDeleted {
-		writeJSON(w, 410, map[string]any{
-			"error":     "task deleted",
-			"deletedAt": item.DeletedAt,
-		})
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// UpdateTask handles PUT /v1/tasks/{uid}
-func (s *Server) UpdateTask(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Check if task exists and is not deleted
-	existing, err := s.TaskSvc.GetTask(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get task for update")
-		writeError(w, r, 500, "failed to get task")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "task not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "task deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Ensure UID in payload matches URL
-	payload["uid"] = uid.String()
-
-	// Check for optimistic locking
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
-
-	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, payload, opts)
-	if err != nil {
-		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to update task")
-		writeError(w, r, 500, "failed to update task")
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// PatchTask handles PATCH /v1/tasks/{uid}
-func (s *Server) PatchTask(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Fetch existing task
-	existing, err := s.TaskSvc.GetTask(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get task for patch")
-		writeError(w, r, 500, "failed to get task")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "task not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "task deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	// Parse partial update
-	var partial map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&partial); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Merge partial into existing payload
-	merged := existing.Payload
-	for k, v := range partial {
-		if k != "uid" && k != "sync" { // Don't allow overriding sync metadata
-			merged[k] = v
-		}
-	}
-
-	// Apply mutation
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
-
-	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, merged, opts)
-	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to patch task")
-		writeError(w, r, 500, "failed to patch task")
-		return
-	}
-
-	writeJSON(w, 200, item)
-}
-
-// DeleteTask handles DELETE /v1/tasks/{uid}
-func (s *Server) DeleteTask(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Fetch existing task to get payload
-	existing, err := s.TaskSvc.GetTask(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get task for delete")
-		writeError(w, r, 500, "failed to get task")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "task not found")
-		return
+// parseIfUnmodifiedSince extracts the time from an If-Unmodified-Since header
+// Accepts an HTTP-date (RFC 7232, whole seconds) or, for clients that echo an item's
+// updatedAt, RFC 3339 with milliseconds; precise reports the latter. An absent or
+// unreadable header is ignored, as RFC 7232 section 3.4 requires.
+func parseIfUnmodifiedSince(r *http.Request) (since time.Time, precise bool, ok bool) {
+	raw := strings.TrimSpace(r.Header.Get("If-Unmodified-Since"))
+	if raw == "" {
+		return time.Time{}, false, false
 	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "task already deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
+	if t, err := http.ParseTime(raw); err == nil {
+		return t, false, true
 	}
-
-	// Soft delete
-	opts := syncservice.MutationOpts{SetDeleted: true}
-	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, existing.Payload, opts)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete task")
-		writeError(w, r, 500, "failed to delete task")
-		return
+	if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return t, true, true
 	}
-
-	writeJSON(w, 200, item)
+	return time.Time{}, false, false
 }
 
-// ArchiveTask handles POST /v1/tasks/{uid}/archive
-func (s *Server) ArchiveTask(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Fetch existing task
-	existing, err := s.TaskSvc.GetTask(ctx, userID, uid)
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to get task for archive")
-		writeError(w, r, 500, "failed to get task")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "task not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "task deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
-	}
-
-	// Set archived status - both status and done for compatibility
-	payload := existing.Payload
-	payload["status"] = "archived"
-	payload["done"] = true
-
-	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, payload, syncservice.MutationOpts{})
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to archive task")
-		writeError(w, r, 500, "failed to archive task")
-		return
-	}
-
-	writeJSON(w, 200, item)
+// parseIncludeDeleted parses ?includeDeleted query param
+func parseIncludeDeleted(r *http.Request) bool {
+	return r.URL.Query().Get("includeDeleted") == "true"
 }
 
-// ProcessTask handles POST /v1/tasks/{uid}/process
-func (s *Server) ProcessTask(w http.ResponseWriter, r *http.Request) {
-	userID := auth.UserID(r.Context())
-	ctx := r.Context()
-	logger := log.Ctx(ctx)
-
-	uid, ok := parseUIDParam(r)
-	if !ok {
-		writeError(w, r, 400, "invalid UID")
-		return
-	}
-
-	// Parse action
-	var req struct {
-		Action   string         `json:"action"`
-		Metadata map[string]any `json:"metadata,omitempty"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, r, 400, "invalid JSON")
-		return
-	}
-
-	// Fetch existing task
-	existing, err := s.TaskSvc.GetTask(ctx, userID, uid)
+// parseListOpts parses list filter query params for an entity table
+// ?deletedOnly=true returns only tombstones (trash view) and implies includeDeleted
+// ?where=key:value (repeatable, AND-combined) filters on allowlisted payload fields
+// ?order=uid pages in UID order instead of by update time (only when UIDv7 is required)
+func (s *Server) parseListOpts(r *http.Request, table string) (syncservice.ListOpts, error) {
+	where, err := s.syncCfg().ParseWhereFilters(table, r.URL.Query()["where"])
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to get task for process")
-		writeError(w, r, 500, "failed to get task")
-		return
-	}
-	if existing == nil {
-		writeError(w, r, 404, "task not found")
-		return
-	}
-	if existing.DeletedAt != nil {
-		writeJSON(w, 410, map[string]any{
-			"error":     "task deleted",
-			"deletedAt": existing.DeletedAt,
-		})
-		return
+		return syncservice.ListOpts{}, err
 	}
 
-	// Apply action - set both status and done for compatibility
-	payload := existing.Payload
-	switch req.Action {
-	case "start":
-		payload["status"] = "in_progress"
-		payload["done"] = false
-	case "complete":
-		payload["status"] = "completed"
-		payload["done"] = true
-	case "reopen":
-		payload["status"] = "open"
-		payload["done"] = false
+	// order=uid lists in UID order, which is creation order only when every UID is a v7
+	orderByUID := false
+	switch r.URL.Query().Get("order") {
+	case "", "updated":
+	case "uid":
+		if s.syncCfg().RequiredUIDVersion != 7 {
+			return syncservice.ListOpts{}, errors.New("order=uid requires UID_VERSION=7")
+		}
+		if cur, err := syncx.DecodeCursor(r.URL.Query().Get("cursor")); err == nil && cur.Ms != 0 {
+			return syncservice.ListOpts{}, errors.New("cursor is not from an order=uid listing")
+		}
+		orderByUID = true
 	default:
-		writeError(w, r, 400, "invalid action: "+req.Action)
-		return
-	}
-
-	item, err := s.TaskSvc.ApplyTaskMutation(ctx, userID, payload, syncservice.MutationOpts{})
-	if err != nil {
-		logger.Error().Err(err).Msg("failed to process task")
-		writeError(w, r, 500, "failed to process task")
-		return
+		return syncservice.ListOpts{}, errors.New("invalid order (want updated or uid)")
 	}
 
-	writeJSON(w, 200, item)
+	deletedOnly := r.URL.Query().Get("deletedOnly") == "true"
+	return syncservice.ListOpts{
+		IncludeDeleted: deletedOnly || parseIncludeDeleted(r),
+		DeletedOnly:    deletedOnly,
+		Where:          where,
+		OrderByUID:     orderByUID,
+	}, nil
 }
 
 // ============================================================================
@@ -868,21 +151,27 @@ func (s *Server) ListChats(w http.ResponseWriter, r *http.Request) {
 	logger := log.Ctx(ctx)
 
 	// Parse pagination params
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	limit := s.pageLimit(w, r.URL.Query().Get("limit"), "chat")
+	cur, err := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+	listOpts, err := s.parseListOpts(r, "chat")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
 	}
-	includeDeleted := parseIncludeDeleted(r)
 
 	// Call service
-	resp, err := s.ChatSvc.ListChats(ctx, userID, cur, limit, includeDeleted)
+	resp, err := s.ChatSvc.ListChats(ctx, userID, cur, limit, listOpts)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list chats")
 		writeError(w, r, 500, "failed to list chats")
 		return
 	}
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, resp)
 }
 
@@ -890,23 +179,20 @@ func (s *Server) ListChats(w http.ResponseWriter, r *http.Request) {
 func (s *Server) CreateChat(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	// Create chat (server generates UID if missing)
 	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, payload, syncservice.MutationOpts{})
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to create chat")
-		writeError(w, r, 500, "failed to create chat")
+		writeMutationError(w, r, err, false, "create chat")
 		return
 	}
 
-	writeJSON(w, 201, item)
+	s.writeCreated(w, "/v1/chats", item)
 }
 
 // GetChat handles GET /v1/chats/{uid}
@@ -922,6 +208,12 @@ func (s *Server) GetChat(w http.ResponseWriter, r *http.Request) {
 	}
 
 	includeDeleted := parseIncludeDeleted(r)
+	expand, err := parseExpand(r, "chat")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+
 	item, err := s.ChatSvc.GetChat(ctx, userID, uid)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to get chat")
@@ -943,7 +235,19 @@ func (s *Server) GetChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, 200, item)
+	if expand != "" {
+		s.writeExpandedItem(w, r, "chat", expand, item)
+		return
+	}
+
+	// Conditional GET: the client's copy is current
+	if s.notModified(r, item) {
+		w.Header().Set("ETag", s.itemETag(item))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.writeItem(w, 200, item)
 }
 
 // UpdateChat handles PUT /v1/chats/{uid}
@@ -977,42 +281,27 @@ func (s *Server) UpdateChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	// Ensure UID in payload matches URL
 	payload["uid"] = uid.String()
 
-	// Check for optimistic locking
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
+	// Check for optimistic locking (If-Match)
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
 	}
 
 	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, payload, opts)
 	if err != nil {
-		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to update chat")
-		writeError(w, r, 500, "failed to update chat")
+		writeMutationError(w, r, err, usedIfMatch, "update chat")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // PatchChat handles PATCH /v1/chats/{uid}
@@ -1047,46 +336,29 @@ func (s *Server) PatchChat(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse partial update
-	var partial map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&partial); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	partial, ok := s.readMutationPayload(w, r)
+	if !ok {
+		return
+	}
+
+	// Check If-Match against the stored item before merging into it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
 		return
 	}
 
 	// Merge partial into existing payload
 	merged := existing.Payload
-	for k, v := range partial {
-		if k != "uid" && k != "sync" { // Don't allow overriding sync metadata
-			merged[k] = v
-		}
-	}
+	mergePatch(merged, partial)
 
 	// Apply mutation
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
-
 	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to patch chat")
-		writeError(w, r, 500, "failed to patch chat")
+		writeMutationError(w, r, err, usedIfMatch, "patch chat")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // DeleteChat handles DELETE /v1/chats/{uid}
@@ -1120,16 +392,20 @@ func (s *Server) DeleteChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft delete
-	opts := syncservice.MutationOpts{SetDeleted: true}
+	// Check If-Match / If-Unmodified-Since against the stored item before deleting it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+	opts.SetDeleted = true
+
 	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, existing.Payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete chat")
-		writeError(w, r, 500, "failed to delete chat")
+		writeMutationError(w, r, err, usedIfMatch, "delete chat")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ArchiveChat handles POST /v1/chats/{uid}/archive
@@ -1163,18 +439,23 @@ func (s *Server) ArchiveChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	// Set archived field
 	payload := existing.Payload
 	payload["archived"] = true
 
-	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to archive chat")
-		writeError(w, r, 500, "failed to archive chat")
+		writeMutationError(w, r, err, usedIfMatch, "archive chat")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ProcessChat handles POST /v1/chats/{uid}/process
@@ -1218,54 +499,87 @@ func (s *Server) ProcessChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	// Apply action
 	payload := existing.Payload
-	switch req.Action {
-	case "resolve":
-		payload["status"] = "resolved"
-	case "reopen":
-		payload["status"] = "active"
-	default:
-		writeError(w, r, 400, "invalid action: "+req.Action)
+	if err := chatActions.apply(req.Action, req.Metadata, s.UnknownProcessMetadata, payload); err != nil {
+		writeActionError(w, r, req.Action, chatActions, err)
 		return
 	}
 
-	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.ChatSvc.ApplyChatMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to process chat")
-		writeError(w, r, 500, "failed to process chat")
+		writeMutationError(w, r, err, usedIfMatch, "process chat")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ============================================================================
 // Comments Handlers
 // ============================================================================
 
+// parseCommentParentFilter parses ?parentType=note|task&parentUid=<uuid> into list filters
+// (shorthand for where=parentType:...&where=parentUid:...), validating them like comment pushes
+func parseCommentParentFilter(r *http.Request) ([]syncservice.FieldFilter, error) {
+	var filters []syncservice.FieldFilter
+	if parentType := r.URL.Query().Get("parentType"); parentType != "" {
+		if parentType != "note" && parentType != "task" {
+			return nil, fmt.Errorf("invalid parentType: %s (must be 'note' or 'task')", parentType)
+		}
+		filters = append(filters, syncservice.FieldFilter{Key: "parentType", Value: parentType})
+	}
+	if raw := r.URL.Query().Get("parentUid"); raw != "" {
+		parentUID, err := uuid.Parse(raw)
+		if err != nil {
+			return nil, errors.New("invalid parentUid: must be a UUID")
+		}
+		filters = append(filters, syncservice.FieldFilter{Key: "parentUid", Value: parentUID.String()})
+	}
+	return filters, nil
+}
+
 // ListComments handles GET /v1/comments
+// ?parentType=&parentUid= list one note's or task's comments (see parseCommentParentFilter)
 func (s *Server) ListComments(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
 	logger := log.Ctx(ctx)
 
 	// Parse pagination params
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	limit := s.pageLimit(w, r.URL.Query().Get("limit"), "comment")
+	cur, err := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
 	}
-	includeDeleted := parseIncludeDeleted(r)
+	listOpts, err := s.parseListOpts(r, "comment")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+	parentFilters, err := parseCommentParentFilter(r)
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+	listOpts.Where = append(listOpts.Where, parentFilters...)
 
 	// Call service
-	resp, err := s.CommentSvc.ListComments(ctx, userID, cur, limit, includeDeleted)
+	resp, err := s.CommentSvc.ListComments(ctx, userID, cur, limit, listOpts)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list comments")
 		writeError(w, r, 500, "failed to list comments")
 		return
 	}
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, resp)
 }
 
@@ -1273,23 +587,20 @@ func (s *Server) ListComments(w http.ResponseWriter, r *http.Request) {
 func (s *Server) CreateComment(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	// Create comment (server generates UID if missing)
 	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, payload, syncservice.MutationOpts{})
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to create comment")
-		writeError(w, r, 500, "failed to create comment")
+		writeMutationError(w, r, err, false, "create comment")
 		return
 	}
 
-	writeJSON(w, 201, item)
+	s.writeCreated(w, "/v1/comments", item)
 }
 
 // GetComment handles GET /v1/comments/{uid}
@@ -1305,6 +616,12 @@ func (s *Server) GetComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	includeDeleted := parseIncludeDeleted(r)
+	expand, err := parseExpand(r, "comment")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+
 	item, err := s.CommentSvc.GetComment(ctx, userID, uid)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to get comment")
@@ -1326,7 +643,19 @@ func (s *Server) GetComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, 200, item)
+	if expand != "" {
+		s.writeExpandedItem(w, r, "comment", expand, item)
+		return
+	}
+
+	// Conditional GET: the client's copy is current
+	if s.notModified(r, item) {
+		w.Header().Set("ETag", s.itemETag(item))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.writeItem(w, 200, item)
 }
 
 // UpdateComment handles PUT /v1/comments/{uid}
@@ -1360,42 +689,27 @@ func (s *Server) UpdateComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	// Ensure UID in payload matches URL
 	payload["uid"] = uid.String()
 
-	// Check for optimistic locking
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
+	// Check for optimistic locking (If-Match)
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
 	}
 
 	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, payload, opts)
 	if err != nil {
-		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to update comment")
-		writeError(w, r, 500, "failed to update comment")
+		writeMutationError(w, r, err, usedIfMatch, "update comment")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // PatchComment handles PATCH /v1/comments/{uid}
@@ -1430,46 +744,29 @@ func (s *Server) PatchComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse partial update
-	var partial map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&partial); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	partial, ok := s.readMutationPayload(w, r)
+	if !ok {
+		return
+	}
+
+	// Check If-Match against the stored item before merging into it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
 		return
 	}
 
 	// Merge partial into existing payload
 	merged := existing.Payload
-	for k, v := range partial {
-		if k != "uid" && k != "sync" { // Don't allow overriding sync metadata
-			merged[k] = v
-		}
-	}
+	mergePatch(merged, partial)
 
 	// Apply mutation
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
-
 	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to patch comment")
-		writeError(w, r, 500, "failed to patch comment")
+		writeMutationError(w, r, err, usedIfMatch, "patch comment")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // DeleteComment handles DELETE /v1/comments/{uid}
@@ -1503,16 +800,20 @@ func (s *Server) DeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft delete
-	opts := syncservice.MutationOpts{SetDeleted: true}
+	// Check If-Match / If-Unmodified-Since against the stored item before deleting it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+	opts.SetDeleted = true
+
 	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, existing.Payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete comment")
-		writeError(w, r, 500, "failed to delete comment")
+		writeMutationError(w, r, err, usedIfMatch, "delete comment")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ArchiveComment handles POST /v1/comments/{uid}/archive
@@ -1546,18 +847,23 @@ func (s *Server) ArchiveComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	// Set archived status
 	payload := existing.Payload
 	payload["status"] = "archived"
 
-	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to archive comment")
-		writeError(w, r, 500, "failed to archive comment")
+		writeMutationError(w, r, err, usedIfMatch, "archive comment")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ProcessComment handles POST /v1/comments/{uid}/process
@@ -1601,26 +907,26 @@ func (s *Server) ProcessComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	// Apply action
 	payload := existing.Payload
-	switch req.Action {
-	case "resolve":
-		payload["status"] = "resolved"
-	case "reopen":
-		payload["status"] = "open"
-	default:
-		writeError(w, r, 400, "invalid action: "+req.Action)
+	if err := commentActions.apply(req.Action, req.Metadata, s.UnknownProcessMetadata, payload); err != nil {
+		writeActionError(w, r, req.Action, commentActions, err)
 		return
 	}
 
-	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.CommentSvc.ApplyCommentMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to process comment")
-		writeError(w, r, 500, "failed to process comment")
+		writeMutationError(w, r, err, usedIfMatch, "process comment")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ============================================================================
@@ -1634,21 +940,27 @@ func (s *Server) ListChatMessages(w http.ResponseWriter, r *http.Request) {
 	logger := log.Ctx(ctx)
 
 	// Parse pagination params
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	limit := s.pageLimit(w, r.URL.Query().Get("limit"), "chat_message")
+	cur, err := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+	listOpts, err := s.parseListOpts(r, "chat_message")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
 	}
-	includeDeleted := parseIncludeDeleted(r)
 
 	// Call service
-	resp, err := s.ChatMessageSvc.ListChatMessages(ctx, userID, cur, limit, includeDeleted)
+	resp, err := s.ChatMessageSvc.ListChatMessages(ctx, userID, cur, limit, listOpts)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list chat messages")
 		writeError(w, r, 500, "failed to list chat messages")
 		return
 	}
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, resp)
 }
 
@@ -1656,23 +968,20 @@ func (s *Server) ListChatMessages(w http.ResponseWriter, r *http.Request) {
 func (s *Server) CreateChatMessage(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	// Create chat message (server generates UID if missing)
 	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, payload, syncservice.MutationOpts{})
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to create chat message")
-		writeError(w, r, 500, "failed to create chat message")
+		writeMutationError(w, r, err, false, "create chat message")
 		return
 	}
 
-	writeJSON(w, 201, item)
+	s.writeCreated(w, "/v1/chat_messages", item)
 }
 
 // GetChatMessage handles GET /v1/chat_messages/{uid}
@@ -1688,6 +997,12 @@ func (s *Server) GetChatMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	includeDeleted := parseIncludeDeleted(r)
+	expand, err := parseExpand(r, "chat_message")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+
 	item, err := s.ChatMessageSvc.GetChatMessage(ctx, userID, uid)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to get chat message")
@@ -1709,7 +1024,19 @@ func (s *Server) GetChatMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, 200, item)
+	if expand != "" {
+		s.writeExpandedItem(w, r, "chat_message", expand, item)
+		return
+	}
+
+	// Conditional GET: the client's copy is current
+	if s.notModified(r, item) {
+		w.Header().Set("ETag", s.itemETag(item))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.writeItem(w, 200, item)
 }
 
 // UpdateChatMessage handles PUT /v1/chat_messages/{uid}
@@ -1743,42 +1070,27 @@ func (s *Server) UpdateChatMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	// Ensure UID in payload matches URL
 	payload["uid"] = uid.String()
 
-	// Check for optimistic locking
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
+	// Check for optimistic locking (If-Match)
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
 	}
 
 	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, payload, opts)
 	if err != nil {
-		// Check for version mismatch
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to update chat message")
-		writeError(w, r, 500, "failed to update chat message")
+		writeMutationError(w, r, err, usedIfMatch, "update chat message")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // PatchChatMessage handles PATCH /v1/chat_messages/{uid}
@@ -1813,46 +1125,29 @@ func (s *Server) PatchChatMessage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse partial update
-	var partial map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&partial); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	partial, ok := s.readMutationPayload(w, r)
+	if !ok {
+		return
+	}
+
+	// Check If-Match against the stored item before merging into it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
 		return
 	}
 
 	// Merge partial into existing payload
 	merged := existing.Payload
-	for k, v := range partial {
-		if k != "uid" && k != "sync" { // Don't allow overriding sync metadata
-			merged[k] = v
-		}
-	}
+	mergePatch(merged, partial)
 
 	// Apply mutation
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
-
 	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			// RFC 7232: Return 412 Precondition Failed for If-Match failures
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409 // Conflict for other version mismatches
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to patch chat message")
-		writeError(w, r, 500, "failed to patch chat message")
+		writeMutationError(w, r, err, usedIfMatch, "patch chat message")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // DeleteChatMessage handles DELETE /v1/chat_messages/{uid}
@@ -1886,16 +1181,20 @@ func (s *Server) DeleteChatMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Soft delete
-	opts := syncservice.MutationOpts{SetDeleted: true}
+	// Check If-Match / If-Unmodified-Since against the stored item before deleting it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+	opts.SetDeleted = true
+
 	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, existing.Payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete chat message")
-		writeError(w, r, 500, "failed to delete chat message")
+		writeMutationError(w, r, err, usedIfMatch, "delete chat message")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ArchiveChatMessage handles POST /v1/chat_messages/{uid}/archive
@@ -1929,18 +1228,23 @@ func (s *Server) ArchiveChatMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	// Set archived status
 	payload := existing.Payload
 	payload["archived"] = true
 
-	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to archive chat message")
-		writeError(w, r, 500, "failed to archive chat message")
+		writeMutationError(w, r, err, usedIfMatch, "archive chat message")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ProcessChatMessage handles POST /v1/chat_messages/{uid}/process
@@ -1984,24 +1288,24 @@ func (s *Server) ProcessChatMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	// Apply action
 	payload := existing.Payload
-	switch req.Action {
-	case "mark_read":
-		payload["read"] = true
-	case "mark_delivered":
-		payload["delivered"] = true
-	default:
-		writeError(w, r, 400, "invalid action: "+req.Action)
+	if err := chatMessageActions.apply(req.Action, req.Metadata, s.UnknownProcessMetadata, payload); err != nil {
+		writeActionError(w, r, req.Action, chatMessageActions, err)
 		return
 	}
 
-	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.ChatMessageSvc.ApplyChatMessageMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to process chat message")
-		writeError(w, r, 500, "failed to process chat message")
+		writeMutationError(w, r, err, usedIfMatch, "process chat message")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
@@ -641,6 +644,113 @@ func TestParseIfMatchHeader(t *testing.T) {
 	}
 }
 
+// TestContentETag tests content-hash ETags: equal versions with different content differ
+func TestContentETag(t *testing.T) {
+	srv := &Server{ETagMode: ETagContent}
+	a := &syncservice.RESTItem{Version: 5, Payload: map[string]any{"title": "A", "tags": []any{"x"}}}
+	b := &syncservice.RESTItem{Version: 5, Payload: map[string]any{"title": "B", "tags": []any{"x"}}}
+	same := &syncservice.RESTItem{Version: 5, Payload: map[string]any{"tags": []any{"x"}, "title": "A"}}
+
+	if srv.itemETag(a) == srv.itemETag(b) {
+		t.Errorf("Different content at the same version should not share an ETag: %s", srv.itemETag(a))
+	}
+	if srv.itemETag(a) != srv.itemETag(same) {
+		t.Errorf("Equal content should share an ETag: %s vs %s", srv.itemETag(a), srv.itemETag(same))
+	}
+	if got := (&Server{}).itemETag(a); got != `"5"` {
+		t.Errorf("Version ETag = %s, want \"5\"", got)
+	}
+
+	tests := []struct {
+		name        string
+		ifMatch     string
+		wantOk      bool
+		wantEnforce bool
+	}{
+		{"no header", "", true, false},
+		{"matching hash", srv.itemETag(a), true, true},
+		{"wildcard", "*", true, true},
+		{"stale hash", srv.itemETag(b), false, false},
+		{"version number", `"5"`, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/test", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			opts, _, ok := srv.ifMatchOpts(w, req, a)
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok && w.Code != http.StatusPreconditionFailed {
+				t.Errorf("Expected 412, got %d", w.Code)
+			}
+			if ok && (opts.EnforceVersion != tt.wantEnforce || (tt.wantEnforce && opts.ExpectedVersion != a.Version)) {
+				t.Errorf("Unexpected opts: %+v", opts)
+			}
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+srv.itemETag(a))
+	if !srv.notModified(req, a) {
+		t.Error("Expected If-None-Match to match the current content ETag")
+	}
+	if srv.notModified(req, b) {
+		t.Error("Expected If-None-Match not to match changed content")
+	}
+}
+
+// TestIfUnmodifiedSince tests timestamp preconditions on mutations
+func TestIfUnmodifiedSince(t *testing.T) {
+	srv := &Server{}
+	item := &syncservice.RESTItem{Version: 4, UpdatedAt: "2025-03-01T10:00:00.250Z", Payload: map[string]any{}}
+	modified := time.Date(2025, 3, 1, 10, 0, 0, 250*int(time.Millisecond), time.UTC)
+
+	tests := []struct {
+		name        string
+		since       string
+		ifMatch     string
+		wantOk      bool
+		wantEnforce bool
+	}{
+		{"no header", "", "", true, false},
+		{"HTTP-date same second", modified.Format(http.TimeFormat), "", true, true},
+		{"HTTP-date later", modified.Add(time.Minute).Format(http.TimeFormat), "", true, true},
+		{"HTTP-date earlier", modified.Add(-time.Second).Format(http.TimeFormat), "", false, false},
+		{"updatedAt echoed back", item.UpdatedAt, "", true, true},
+		{"RFC 3339 a millisecond early", "2025-03-01T10:00:00.249Z", "", false, false},
+		{"unreadable date is ignored", "yesterday", "", true, false},
+		{"If-Match takes precedence", modified.Add(-time.Hour).Format(http.TimeFormat), `"4"`, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PUT", "/test", nil)
+			if tt.since != "" {
+				req.Header.Set("If-Unmodified-Since", tt.since)
+			}
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+
+			opts, _, ok := srv.ifMatchOpts(w, req, item)
+			if ok != tt.wantOk {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok && w.Code != http.StatusPreconditionFailed {
+				t.Errorf("Expected 412, got %d", w.Code)
+			}
+			if ok && (opts.EnforceVersion != tt.wantEnforce || (tt.wantEnforce && opts.ExpectedVersion != item.Version)) {
+				t.Errorf("Unexpected opts: %+v", opts)
+			}
+		})
+	}
+}
+
 // TestOptimisticLocking_QuotedETag tests that optimistic locking works with quoted ETags
 func TestOptimisticLocking_QuotedETag(t *testing.T) {
 	if testing.Short() {
@@ -740,4 +850,457 @@ func TestOptimisticLocking_QuotedETag(t *testing.T) {
 			t.Errorf("Expected 200 OK with unquoted ETag, got %d: %s", w.Code, w.Body.String())
 		}
 	})
+
+	t.Run("process_and_archive_honor_if_match", func(t *testing.T) {
+		for _, path := range []string{"/v1/notes/%s/process", "/v1/notes/%s/archive"} {
+			req := httptest.NewRequest("POST", fmt.Sprintf(path, noteUID), toJSONReader(map[string]any{"action": "pin"}))
+			req.Header.Set("X-Debug-Sub", testUserSubject)
+			req.Header.Set("X-Sync-Session", session.ID)
+			req.Header.Set("X-Sync-Epoch", fmt.Sprintf("%d", session.Epoch))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", `"1"`) // Stale quoted ETag
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusPreconditionFailed {
+				t.Errorf("POST %s: expected 412 Precondition Failed with stale ETag, got %d: %s", path, w.Code, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("delete_honors_preconditions", func(t *testing.T) {
+		for name, header := range map[string][2]string{
+			"If-Match":            {"If-Match", `"1"`},
+			"If-Unmodified-Since": {"If-Unmodified-Since", "Mon, 01 Jan 2024 00:00:00 GMT"},
+		} {
+			req := httptest.NewRequest("DELETE", fmt.Sprintf("/v1/notes/%s", noteUID), nil)
+			req.Header.Set("X-Debug-Sub", testUserSubject)
+			req.Header.Set("X-Sync-Session", session.ID)
+			req.Header.Set("X-Sync-Epoch", fmt.Sprintf("%d", session.Epoch))
+			req.Header.Set(header[0], header[1])
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusPreconditionFailed {
+				t.Errorf("DELETE with stale %s: expected 412 Precondition Failed, got %d: %s", name, w.Code, w.Body.String())
+			}
+		}
+
+		stillLive, err := srv.NoteSvc.GetNote(ctx, userID, noteUID)
+		if err != nil || stillLive == nil || stillLive.DeletedAt != nil {
+			t.Fatalf("Stale deletes must leave the note live: %+v, %v", stillLive, err)
+		}
+	})
+}
+
+// TestParseListOpts tests deletion and field filter parsing for list endpoints
+func TestParseListOpts(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantOpts syncservice.ListOpts
+		wantErr  bool
+	}{
+		{
+			name:     "default_active_only",
+			query:    "",
+			wantOpts: syncservice.ListOpts{},
+		},
+		{
+			name:     "include_deleted",
+			query:    "?includeDeleted=true",
+			wantOpts: syncservice.ListOpts{IncludeDeleted: true},
+		},
+		{
+			name:     "deleted_only_implies_include_deleted",
+			query:    "?deletedOnly=true",
+			wantOpts: syncservice.ListOpts{IncludeDeleted: true, DeletedOnly: true},
+		},
+		{
+			name:     "deleted_only_with_include_deleted_false",
+			query:    "?deletedOnly=true&includeDeleted=false",
+			wantOpts: syncservice.ListOpts{IncludeDeleted: true, DeletedOnly: true},
+		},
+		{
+			name:  "where_filters_and_combined",
+			query: "?where=priority:high&where=status:in:progress",
+			wantOpts: syncservice.ListOpts{Where: []syncservice.FieldFilter{
+				{Key: "priority", Value: "high"},
+				{Key: "status", Value: "in:progress"},
+			}},
+		},
+		{
+			name:    "where_key_not_filterable",
+			query:   "?where=title:secret",
+			wantErr: true,
+		},
+		{
+			name:    "where_missing_value_separator",
+			query:   "?where=priority",
+			wantErr: true,
+		},
+		{
+			name:    "order_uid_requires_uid_v7",
+			query:   "?order=uid",
+			wantErr: true,
+		},
+		{
+			name:    "order_unknown",
+			query:   "?order=title",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/notes"+tt.query, nil)
+			got, err := (&Server{}).parseListOpts(req, "note")
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseListOpts() = %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseListOpts() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantOpts) {
+				t.Errorf("parseListOpts() = %+v, want %+v", got, tt.wantOpts)
+			}
+		})
+	}
+}
+
+// TestParseCommentParentFilter tests the parentType/parentUid shorthand for comment lists
+func TestParseCommentParentFilter(t *testing.T) {
+	parent := uuid.New()
+
+	tests := []struct {
+		name    string
+		query   string
+		want    []syncservice.FieldFilter
+		wantErr bool
+	}{
+		{name: "none", query: ""},
+		{
+			name:  "type_and_uid",
+			query: "parentType=note&parentUid=" + strings.ToUpper(parent.String()),
+			want:  []syncservice.FieldFilter{{Key: "parentType", Value: "note"}, {Key: "parentUid", Value: parent.String()}},
+		},
+		{name: "unknown_type", query: "parentType=chat", wantErr: true},
+		{name: "invalid_uid", query: "parentUid=not-a-uuid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCommentParentFilter(httptest.NewRequest("GET", "/v1/comments?"+tt.query, nil))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filters = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseListOpts_OrderByUID tests the UID-ordered list mode offered when UIDv7 is required
+func TestParseListOpts_OrderByUID(t *testing.T) {
+	srv := &Server{SyncCfg: &syncservice.Config{RequiredUIDVersion: 7}}
+
+	uidCursor := syncx.EncodeCursor(syncx.Cursor{UID: uuid.New()})
+	timeCursor := syncx.EncodeCursor(syncx.Cursor{Ms: 1700000000000, UID: uuid.New()})
+
+	got, err := srv.parseListOpts(httptest.NewRequest("GET", "/v1/notes?order=uid&cursor="+uidCursor, nil), "note")
+	if err != nil || !got.OrderByUID {
+		t.Errorf("parseListOpts() = %+v, %v, want OrderByUID", got, err)
+	}
+
+	if _, err := srv.parseListOpts(httptest.NewRequest("GET", "/v1/notes?order=uid&cursor="+timeCursor, nil), "note"); err == nil {
+		t.Error("Expected an update-order cursor to be rejected with order=uid")
+	}
+}
+
+// TestApplyNoteMutation_InjectedClock verifies REST mutations take server timestamps from the
+// service Clock, including monotonic bumps when the clock hasn't advanced
+func TestApplyNoteMutation_InjectedClock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	clock := syncx.NewFakeClock(1_700_000_000_000)
+	noteSvc := syncservice.NewNoteService(pool)
+	noteSvc.Clock = clock
+
+	ctx := context.Background()
+	userID := createTestUser(t, pool, testUserSubject)
+	noteUID := uuid.New()
+
+	created, err := noteSvc.ApplyNoteMutation(ctx, userID, map[string]any{
+		"uid":   noteUID.String(),
+		"title": "v1",
+	}, syncservice.MutationOpts{})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if want := syncx.RFC3339(1_700_000_000_000); created.UpdatedAt != want {
+		t.Fatalf("expected updatedAt %s from injected clock, got %s", want, created.UpdatedAt)
+	}
+
+	// Clock has not advanced: update must still move strictly forward
+	updated, err := noteSvc.ApplyNoteMutation(ctx, userID, map[string]any{
+		"uid":   noteUID.String(),
+		"title": "v2",
+	}, syncservice.MutationOpts{})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if want := syncx.RFC3339(1_700_000_000_001); updated.UpdatedAt != want {
+		t.Fatalf("expected monotonic bump to %s, got %s", want, updated.UpdatedAt)
+	}
+
+	clock.Advance(60_000)
+	advanced, err := noteSvc.ApplyNoteMutation(ctx, userID, map[string]any{
+		"uid":   noteUID.String(),
+		"title": "v3",
+	}, syncservice.MutationOpts{})
+	if err != nil {
+		t.Fatalf("second update failed: %v", err)
+	}
+	if want := syncx.RFC3339(1_700_000_060_000); advanced.UpdatedAt != want {
+		t.Fatalf("expected updatedAt %s after advancing clock, got %s", want, advanced.UpdatedAt)
+	}
+}
+
+// TestApplyNoteMutation_Attribution verifies createdBy is write-once and updatedBy tracks the latest actor
+func TestApplyNoteMutation_Attribution(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	noteSvc := syncservice.NewNoteService(pool)
+	userID := createTestUser(t, pool, testUserSubject)
+	noteUID := uuid.New()
+
+	created, err := noteSvc.ApplyNoteMutation(syncservice.WithActor(context.Background(), "user-a"), userID, map[string]any{
+		"uid":   noteUID.String(),
+		"title": "v1",
+	}, syncservice.MutationOpts{})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	if created.CreatedBy == nil || *created.CreatedBy != "user-a" {
+		t.Fatalf("expected createdBy user-a, got %v", created.CreatedBy)
+	}
+
+	updated, err := noteSvc.ApplyNoteMutation(syncservice.WithActor(context.Background(), "user-b"), userID, map[string]any{
+		"uid":   noteUID.String(),
+		"title": "v2",
+	}, syncservice.MutationOpts{})
+	if err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+	if updated.CreatedBy == nil || *updated.CreatedBy != "user-a" {
+		t.Errorf("expected createdBy to stay user-a, got %v", updated.CreatedBy)
+	}
+	if updated.UpdatedBy == nil || *updated.UpdatedBy != "user-b" {
+		t.Errorf("expected updatedBy user-b, got %v", updated.UpdatedBy)
+	}
+
+	fetched, err := noteSvc.GetNote(context.Background(), userID, noteUID)
+	if err != nil || fetched == nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if fetched.UpdatedBy == nil || *fetched.UpdatedBy != "user-b" {
+		t.Errorf("expected stored updatedBy user-b, got %v", fetched.UpdatedBy)
+	}
+}
+
+func TestListTasks_WhereFilter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	taskSvc := syncservice.NewTaskService(pool)
+	userID := createTestUser(t, pool, testUserSubject)
+	ctx := context.Background()
+
+	match := uuid.New()
+	for uid, payload := range map[uuid.UUID]map[string]any{
+		match:      {"title": "urgent todo", "status": "todo", "priority": "high", "done": false},
+		uuid.New(): {"title": "low todo", "status": "todo", "priority": "low", "done": false},
+		uuid.New(): {"title": "urgent done", "status": "done", "priority": "high", "done": true},
+	} {
+		payload["uid"] = uid.String()
+		if _, err := taskSvc.ApplyTaskMutation(ctx, userID, payload, syncservice.MutationOpts{}); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+	}
+
+	opts := syncservice.ListOpts{Where: []syncservice.FieldFilter{
+		{Key: "priority", Value: "high"},
+		{Key: "done", Value: "false"},
+	}}
+	resp, err := taskSvc.ListTasks(ctx, userID, syncx.Cursor{}, 100, opts)
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].UID != match.String() {
+		t.Errorf("expected only %s, got %+v", match, resp.Items)
+	}
+}
+
+func TestSweepScheduledDeletes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	clock := syncx.NewFakeClock(1_700_000_000_000)
+	noteSvc := syncservice.NewNoteService(pool)
+	noteSvc.Clock = clock
+
+	ctx := context.Background()
+	userID := createTestUser(t, pool, testUserSubject)
+	noteUID := uuid.New()
+
+	_, err := noteSvc.ApplyNoteMutation(ctx, userID, map[string]any{
+		"uid":         noteUID.String(),
+		"title":       "Expires",
+		"deleteAfter": syncx.RFC3339(1_700_000_000_000 + 3_600_000),
+	}, syncservice.MutationOpts{})
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	// Not due yet: the note stays active
+	if n, err := noteSvc.SweepScheduledDeletes(ctx, 100); err != nil || n != 0 {
+		t.Fatalf("expected no deletions before deleteAfter, got %d (err %v)", n, err)
+	}
+
+	clock.Advance(2 * 3_600_000)
+	if n, err := noteSvc.SweepScheduledDeletes(ctx, 100); err != nil || n != 1 {
+		t.Fatalf("expected 1 deletion after deleteAfter, got %d (err %v)", n, err)
+	}
+
+	note, err := noteSvc.GetNote(ctx, userID, noteUID)
+	if err != nil || note == nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if note.DeletedAt == nil {
+		t.Fatal("expected note to be soft-deleted")
+	}
+	if note.UpdatedBy == nil || *note.UpdatedBy != "system:scheduled-delete" {
+		t.Errorf("expected sweeper attribution, got %v", note.UpdatedBy)
+	}
+
+	// Already deleted notes are not swept again
+	if n, err := noteSvc.SweepScheduledDeletes(ctx, 100); err != nil || n != 0 {
+		t.Errorf("expected no further deletions, got %d (err %v)", n, err)
+	}
+}
+
+// TestSweepScheduledDeletes_SkipsFailingNotes checks that notes which can't be deleted
+// don't keep later due notes out of a sweep's batch
+func TestSweepScheduledDeletes_SkipsFailingNotes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	clock := syncx.NewFakeClock(1_700_000_000_000)
+	noteSvc := syncservice.NewNoteService(pool)
+	noteSvc.Clock = clock
+
+	ctx := context.Background()
+	userID := createTestUser(t, pool, testUserSubject)
+	_, _ = pool.Exec(ctx, "DELETE FROM note WHERE delete_after_ms IS NOT NULL")
+
+	create := func(deleteAfterMs int64) uuid.UUID {
+		t.Helper()
+		uid := uuid.New()
+		if _, err := noteSvc.ApplyNoteMutation(ctx, userID, map[string]any{
+			"uid":         uid.String(),
+			"title":       "Expires",
+			"deleteAfter": syncx.RFC3339(deleteAfterMs),
+		}, syncservice.MutationOpts{}); err != nil {
+			t.Fatalf("create failed: %v", err)
+		}
+		return uid
+	}
+	broken := create(1_700_000_000_000 + 1_000)
+	healthy := create(1_700_000_000_000 + 2_000)
+
+	// A sealed payload with no key configured can never be decoded
+	if _, err := pool.Exec(ctx,
+		`UPDATE note SET payload_json = payload_json || '{"_enc": {"v": 1}}' WHERE owner_id = $1 AND uid = $2`,
+		userID, broken); err != nil {
+		t.Fatalf("failed to corrupt note: %v", err)
+	}
+
+	clock.Advance(3_600_000)
+	if n, err := noteSvc.SweepScheduledDeletes(ctx, 1); err != nil || n != 1 {
+		t.Fatalf("expected the healthy note to be swept past the broken one, got %d (err %v)", n, err)
+	}
+	if n, err := noteSvc.SweepScheduledDeletes(ctx, 1); err != nil || n != 0 {
+		t.Fatalf("expected nothing left to sweep, got %d (err %v)", n, err)
+	}
+
+	note, err := noteSvc.GetNote(ctx, userID, healthy)
+	if err != nil || note == nil || note.DeletedAt == nil {
+		t.Fatalf("expected the healthy note to be soft-deleted: %+v (err %v)", note, err)
+	}
+}
+
+func TestCreateNote_InvalidDeleteAfter(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	createTestUser(t, pool, testUserSubject)
+	session := createTestSession(t, router)
+
+	// A malformed schedule is a client error, not a server failure
+	for _, deleteAfter := range []any{"next tuesday", 1_700_000_000_000} {
+		req := httptest.NewRequest("POST", "/v1/notes", toJSONReader(map[string]any{
+			"title":       "Expires",
+			"deleteAfter": deleteAfter,
+		}))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Debug-Sub", testUserSubject)
+		req.Header.Set("X-Sync-Session", session.ID)
+		req.Header.Set("X-Sync-Epoch", fmt.Sprintf("%d", session.Epoch))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("deleteAfter %v: expected 422, got %d: %s", deleteAfter, w.Code, w.Body.String())
+		}
+	}
 }
@@ -7,7 +7,6 @@ import (
 	"github.com/erauner12/toolbridge-api/internal/auth"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
@@ -21,20 +20,26 @@ func (s *Server) ListTaskLists(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	logger := log.Ctx(ctx)
 
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	limit := s.pageLimit(w, r.URL.Query().Get("limit"), "task_list")
+	cur, err := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+	listOpts, err := s.parseListOpts(r, "task_list")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
 	}
-	includeDeleted := parseIncludeDeleted(r)
 
-	resp, err := s.TaskListSvc.ListTaskLists(ctx, userID, cur, limit, includeDeleted)
+	resp, err := s.TaskListSvc.ListTaskLists(ctx, userID, cur, limit, listOpts)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list task_lists")
 		writeError(w, r, 500, "failed to list task_lists")
 		return
 	}
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, resp)
 }
 
@@ -42,22 +47,19 @@ func (s *Server) ListTaskLists(w http.ResponseWriter, r *http.Request) {
 func (s *Server) CreateTaskList(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, payload, syncservice.MutationOpts{})
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to create task_list")
-		writeError(w, r, 500, "failed to create task_list")
+		writeMutationError(w, r, err, false, "create task_list")
 		return
 	}
 
-	writeJSON(w, 201, item)
+	s.writeCreated(w, "/v1/task_lists", item)
 }
 
 // GetTaskList handles GET /v1/task_lists/{uid}
@@ -73,6 +75,12 @@ func (s *Server) GetTaskList(w http.ResponseWriter, r *http.Request) {
 	}
 
 	includeDeleted := parseIncludeDeleted(r)
+	expand, err := parseExpand(r, "task_list")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+
 	item, err := s.TaskListSvc.GetTaskList(ctx, userID, uid)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to get task_list")
@@ -93,7 +101,19 @@ func (s *Server) GetTaskList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, 200, item)
+	if expand != "" {
+		s.writeExpandedItem(w, r, "task_list", expand, item)
+		return
+	}
+
+	// Conditional GET: the client's copy is current
+	if s.notModified(r, item) {
+		w.Header().Set("ETag", s.itemETag(item))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.writeItem(w, 200, item)
 }
 
 // UpdateTaskList handles PUT /v1/task_lists/{uid}
@@ -126,38 +146,25 @@ func (s *Server) UpdateTaskList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	payload["uid"] = uid.String()
 
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
 	}
 
 	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, payload, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to update task_list")
-		writeError(w, r, 500, "failed to update task_list")
+		writeMutationError(w, r, err, usedIfMatch, "update task_list")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // PatchTaskList handles PATCH /v1/task_lists/{uid}
@@ -190,43 +197,27 @@ func (s *Server) PatchTaskList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var partial map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&partial); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	partial, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
-	merged := existing.Payload
-	for k, v := range partial {
-		if k != "uid" && k != "sync" {
-			merged[k] = v
-		}
+	// Check If-Match against the stored item before merging into it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
 	}
 
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
+	merged := existing.Payload
+	mergePatch(merged, partial)
 
 	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to patch task_list")
-		writeError(w, r, 500, "failed to patch task_list")
+		writeMutationError(w, r, err, usedIfMatch, "patch task_list")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // DeleteTaskList handles DELETE /v1/task_lists/{uid}
@@ -260,12 +251,17 @@ func (s *Server) DeleteTaskList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match / If-Unmodified-Since against the stored item before deleting it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	// Atomically orphan tasks and soft-delete the task list
 	// Both operations succeed or fail together
-	result, err := s.TaskListSvc.DeleteTaskListWithOrphan(ctx, userID, uid, existing.Payload)
+	result, err := s.TaskListSvc.DeleteTaskListWithOrphan(ctx, userID, uid, existing.Payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete task_list")
-		writeError(w, r, 500, "failed to delete task_list")
+		writeMutationError(w, r, err, usedIfMatch, "delete task_list")
 		return
 	}
 
@@ -307,17 +303,22 @@ func (s *Server) ArchiveTaskList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	payload := existing.Payload
 	payload["archived"] = true
 
-	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to archive task_list")
-		writeError(w, r, 500, "failed to archive task_list")
+		writeMutationError(w, r, err, usedIfMatch, "archive task_list")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ProcessTaskList handles POST /v1/task_lists/{uid}/process
@@ -350,6 +351,12 @@ func (s *Server) ProcessTaskList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		Action   string         `json:"action"`
 		Metadata map[string]any `json:"metadata,omitempty"`
@@ -359,23 +366,18 @@ func (s *Server) ProcessTaskList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Process actions for task lists
-	switch req.Action {
-	case "unarchive":
-		existing.Payload["archived"] = false
-	default:
-		writeError(w, r, 400, "unknown action: "+req.Action)
+	if err := taskListActions.apply(req.Action, req.Metadata, s.UnknownProcessMetadata, existing.Payload); err != nil {
+		writeActionError(w, r, req.Action, taskListActions, err)
 		return
 	}
 
-	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, existing.Payload, syncservice.MutationOpts{})
+	item, err := s.TaskListSvc.ApplyTaskListMutation(ctx, userID, existing.Payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to process task_list")
-		writeError(w, r, 500, "failed to process task_list")
+		writeMutationError(w, r, err, usedIfMatch, "process task_list")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ============================================================================
@@ -388,20 +390,26 @@ func (s *Server) ListTaskListCategories(w http.ResponseWriter, r *http.Request)
 	ctx := r.Context()
 	logger := log.Ctx(ctx)
 
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	limit := s.pageLimit(w, r.URL.Query().Get("limit"), "task_list_category")
+	cur, err := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
+	}
+	listOpts, err := s.parseListOpts(r, "task_list_category")
+	if err != nil {
+		writeError(w, r, 400, err.Error())
+		return
 	}
-	includeDeleted := parseIncludeDeleted(r)
 
-	resp, err := s.TaskListCategorySvc.ListTaskListCategories(ctx, userID, cur, limit, includeDeleted)
+	resp, err := s.TaskListCategorySvc.ListTaskListCategories(ctx, userID, cur, limit, listOpts)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list task_list_categories")
 		writeError(w, r, 500, "failed to list task_list_categories")
 		return
 	}
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, resp)
 }
 
@@ -409,22 +417,19 @@ func (s *Server) ListTaskListCategories(w http.ResponseWriter, r *http.Request)
 func (s *Server) CreateTaskListCategory(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
-	logger := log.Ctx(ctx)
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, payload, syncservice.MutationOpts{})
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to create task_list_category")
-		writeError(w, r, 500, "failed to create task_list_category")
+		writeMutationError(w, r, err, false, "create task_list_category")
 		return
 	}
 
-	writeJSON(w, 201, item)
+	s.writeCreated(w, "/v1/task_list_categories", item)
 }
 
 // GetTaskListCategory handles GET /v1/task_list_categories/{uid}
@@ -460,7 +465,14 @@ func (s *Server) GetTaskListCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, 200, item)
+	// Conditional GET: the client's copy is current
+	if s.notModified(r, item) {
+		w.Header().Set("ETag", s.itemETag(item))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	s.writeItem(w, 200, item)
 }
 
 // UpdateTaskListCategory handles PUT /v1/task_list_categories/{uid}
@@ -493,38 +505,25 @@ func (s *Server) UpdateTaskListCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var payload map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	payload, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
 	payload["uid"] = uid.String()
 
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
 	}
 
 	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, payload, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to update task_list_category")
-		writeError(w, r, 500, "failed to update task_list_category")
+		writeMutationError(w, r, err, usedIfMatch, "update task_list_category")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // PatchTaskListCategory handles PATCH /v1/task_list_categories/{uid}
@@ -557,43 +556,27 @@ func (s *Server) PatchTaskListCategory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var partial map[string]any
-	if err := json.NewDecoder(r.Body).Decode(&partial); err != nil {
-		writeError(w, r, 400, "invalid JSON")
+	partial, ok := s.readMutationPayload(w, r)
+	if !ok {
 		return
 	}
 
-	merged := existing.Payload
-	for k, v := range partial {
-		if k != "uid" && k != "sync" {
-			merged[k] = v
-		}
+	// Check If-Match against the stored item before merging into it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
 	}
 
-	opts := syncservice.MutationOpts{}
-	usedIfMatch := false
-	if version, ok := parseIfMatchHeader(r); ok {
-		opts.EnforceVersion = true
-		opts.ExpectedVersion = version
-		usedIfMatch = true
-	}
+	merged := existing.Payload
+	mergePatch(merged, partial)
 
 	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, merged, opts)
 	if err != nil {
-		if _, ok := err.(*syncservice.VersionMismatchError); ok {
-			statusCode := 412
-			if !usedIfMatch {
-				statusCode = 409
-			}
-			writeError(w, r, statusCode, "version mismatch: "+err.Error())
-			return
-		}
-		logger.Error().Err(err).Msg("failed to patch task_list_category")
-		writeError(w, r, 500, "failed to patch task_list_category")
+		writeMutationError(w, r, err, usedIfMatch, "patch task_list_category")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // DeleteTaskListCategory handles DELETE /v1/task_list_categories/{uid}
@@ -626,15 +609,20 @@ func (s *Server) DeleteTaskListCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	opts := syncservice.MutationOpts{SetDeleted: true}
+	// Check If-Match / If-Unmodified-Since against the stored item before deleting it
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+	opts.SetDeleted = true
+
 	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, existing.Payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to delete task_list_category")
-		writeError(w, r, 500, "failed to delete task_list_category")
+		writeMutationError(w, r, err, usedIfMatch, "delete task_list_category")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ArchiveTaskListCategory handles POST /v1/task_list_categories/{uid}/archive
@@ -667,17 +655,22 @@ func (s *Server) ArchiveTaskListCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	payload := existing.Payload
 	payload["archived"] = true
 
-	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, payload, syncservice.MutationOpts{})
+	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to archive task_list_category")
-		writeError(w, r, 500, "failed to archive task_list_category")
+		writeMutationError(w, r, err, usedIfMatch, "archive task_list_category")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
 
 // ProcessTaskListCategory handles POST /v1/task_list_categories/{uid}/process
@@ -710,6 +703,12 @@ func (s *Server) ProcessTaskListCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Check If-Match against the stored item before applying the change
+	opts, usedIfMatch, ok := s.ifMatchOpts(w, r, existing)
+	if !ok {
+		return
+	}
+
 	var req struct {
 		Action   string         `json:"action"`
 		Metadata map[string]any `json:"metadata,omitempty"`
@@ -719,20 +718,16 @@ func (s *Server) ProcessTaskListCategory(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	switch req.Action {
-	case "unarchive":
-		existing.Payload["archived"] = false
-	default:
-		writeError(w, r, 400, "unknown action: "+req.Action)
+	if err := taskListCategoryActions.apply(req.Action, req.Metadata, s.UnknownProcessMetadata, existing.Payload); err != nil {
+		writeActionError(w, r, req.Action, taskListCategoryActions, err)
 		return
 	}
 
-	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, existing.Payload, syncservice.MutationOpts{})
+	item, err := s.TaskListCategorySvc.ApplyTaskListCategoryMutation(ctx, userID, existing.Payload, opts)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to process task_list_category")
-		writeError(w, r, 500, "failed to process task_list_category")
+		writeMutationError(w, r, err, usedIfMatch, "process task_list_category")
 		return
 	}
 
-	writeJSON(w, 200, item)
+	s.writeItem(w, 200, item)
 }
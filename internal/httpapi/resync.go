@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/rs/zerolog/log"
+)
+
+type resyncResponse struct {
+	Epoch int `json:"epoch"`
+}
+
+// ResyncAccount forces every device of the authenticated user to resync from scratch.
+//
+// Unlike WipeAccount it only bumps the tenant epoch: all entity data stays intact.
+// Requests carrying the old epoch then fail with 412, and clients re-run BeginSession,
+// drop their local copy and pull everything again. Sessions stay valid.
+//
+// Requires:
+// - Valid authentication
+// - Active sync session (X-Sync-Session header)
+//
+// Returns:
+// - 200: Epoch bumped, returns the new epoch
+// - 401: Unauthorized
+// - 500: Database error
+func (s *Server) ResyncAccount(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	if userID == "" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	ctx := r.Context()
+	var newEpoch int
+	err := s.DB.QueryRow(ctx, `
+		INSERT INTO owner_state(owner_id, epoch, created_at, updated_at)
+		VALUES ($1, 2, NOW(), NOW())
+		ON CONFLICT (owner_id) DO UPDATE
+			SET epoch = owner_state.epoch + 1,
+				updated_at = NOW()
+		RETURNING epoch
+	`, userID).Scan(&newEpoch)
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to bump epoch")
+		writeError(w, r, http.StatusInternalServerError, "epoch update failed")
+		return
+	}
+
+	log.Info().
+		Str("userId", userID).
+		Int("newEpoch", newEpoch).
+		Msg("Account resync forced")
+
+	writeJSON(w, http.StatusOK, resyncResponse{Epoch: newEpoch})
+}
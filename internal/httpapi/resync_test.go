@@ -0,0 +1,60 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+)
+
+func TestResyncAccount_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	uid := uuid.New().String()
+	item := map[string]any{"uid": uid, "title": "Kept", "updatedTs": "2025-11-03T10:00:00Z"}
+	if w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session); w.Code != http.StatusOK {
+		t.Fatalf("Push failed: %d %s", w.Code, w.Body.String())
+	}
+
+	w := makeRequestWithSession(t, router, "POST", "/v1/sync/resync", nil, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	var resp resyncResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Epoch != session.Epoch+1 {
+		t.Errorf("Expected epoch %d, got %d", session.Epoch+1, resp.Epoch)
+	}
+
+	// The old epoch is rejected
+	if w := makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, session); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 with the old epoch, got %d %s", w.Code, w.Body.String())
+	}
+
+	// A fresh session sees the new epoch and all data
+	fresh := createTestSession(t, router)
+	if fresh.Epoch != resp.Epoch {
+		t.Errorf("Expected new session at epoch %d, got %d", resp.Epoch, fresh.Epoch)
+	}
+	if w := makeRequestWithSession(t, router, "GET", "/v1/notes/"+uid, nil, fresh); w.Code != http.StatusOK {
+		t.Errorf("Expected note to survive resync, got %d %s", w.Code, w.Body.String())
+	}
+}
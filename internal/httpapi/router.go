@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/loadest"
 	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -19,10 +21,29 @@ type Server struct {
 	DB              *pgxpool.Pool
 	RateLimitConfig     RateLimitInfo // Centralized rate limit configuration for sync endpoints
 	AuthRateLimitConfig RateLimitInfo // Stricter rate limit for auth/bootstrap endpoints
+	RateLimitBackend LimiterBackend // Where per-user rate limit budgets live (nil = in-process, per replica)
 	JWTCfg          auth.JWTCfg   // JWT authentication configuration
 	WorkOSClient    *usermanagement.Client // WorkOS client for tenant resolution
 	DefaultTenantID string        // Default tenant ID for B2C users (no organization memberships)
 	TenantAuthCache *auth.TenantAuthCache // In-memory cache for tenant authorization validation
+	AdminToken      string        // Static token for /v1/admin endpoints (empty = admin endpoints disabled)
+	RequestTimeout  time.Duration // Per-request context deadline (0 = no deadline)
+	Load            *loadest.Estimator // Recent request volume for load-aware sync hints (nil = static hints)
+	ColdPullMaxAge  time.Duration // How far back a pull without a cursor goes unless full=true (0 = no limit)
+	MaxPullWait     time.Duration // Longest a pull's ?wait= holds it open for changes (0 = long-polling off)
+	SessionUndo     bool          // Track per-session changes and enable POST /v1/sync/sessions/{id}/undo
+	PullLoop        *PullLoopGuard // Same-cursor pull hot-loop detection per session (nil = off)
+	ETagMode        ETagMode      // How REST item ETags are computed ("" = version)
+	SessionLimiter  Limiter       // Per-user limit on session creation, shared with gRPC (nil = not limited)
+	DisabledEntities map[string]bool // Entity types (URL names) shipped dark: no routes, left out of discovery
+	UnknownEntityStatus int        // Status for sync routes naming an unknown entity type, 400 or 404 (0 = 404)
+	AccessLog       AccessLogCfg  // Per-request access log (zero value logs at debug; see DefaultAccessLogCfg)
+	MaxDecompressedBytes int64    // Cap on a decompressed (Content-Encoding: gzip) request body (0 = DefaultMaxDecompressedBytes)
+	MaxPushItems    int           // Cap on items per push request, 413 beyond it (0 = unlimited)
+	Scopes          auth.ScopeCfg // Token scopes required for reads and writes (Enforce=false = not checked)
+	EchoRequestID   bool          // Return the request ID in X-Request-ID and in error bodies (see RequestIDEcho)
+	UnknownProcessMetadata UnknownMetadataMode // What process actions do with undeclared metadata keys ("" = ignore)
+	SyncCfg         *syncservice.Config // Sync settings shared with the services (nil = syncservice.DefaultConfig)
 	// Services
 	NoteSvc             *syncservice.NoteService
 	TaskSvc             *syncservice.TaskService
@@ -31,6 +52,15 @@ type Server struct {
 	CommentSvc          *syncservice.CommentService
 	ChatSvc             *syncservice.ChatService
 	ChatMessageSvc      *syncservice.ChatMessageService
+	SearchSvc           *syncservice.SearchService
+}
+
+// syncCfg returns the sync settings handlers read limits, columns and fields from
+func (s *Server) syncCfg() *syncservice.Config {
+	if s.SyncCfg == nil {
+		return syncservice.DefaultConfig()
+	}
+	return s.SyncCfg
 }
 
 // DefaultRateLimitConfig provides the default rate limiting configuration for sync endpoints
@@ -49,6 +79,15 @@ var DefaultAuthRateLimitConfig = RateLimitInfo{
 	Burst:         20, // Small burst allowance
 }
 
+// DefaultSessionRateLimitConfig limits session creation (POST /v1/sync/sessions)
+// Off by default: the MCP server opens a session per tool call, so any per-minute cap
+// low enough to stop a reconnect loop also throttles ordinary MCP use.
+var DefaultSessionRateLimitConfig = RateLimitInfo{
+	WindowSeconds: 60, // 1 minute window
+	MaxRequests:   0,  // No dedicated limit (SESSION_CREATE_PER_MINUTE enables one)
+	Burst:         5,  // A few reconnects in quick succession once enabled
+}
+
 // Common request/response types for sync endpoints
 
 // pushReq is the request body for push endpoints
@@ -68,11 +107,25 @@ type pushAck struct {
 type pullResp struct {
 	Upserts    []map[string]any `json:"upserts"`
 	Deletes    []map[string]any `json:"deletes"`
+	Inserts    []string         `json:"inserts"` // UIDs of first-version upserts (see pullInserts)
 	NextCursor *string          `json:"nextCursor,omitempty"`
+	WipedAt    *string          `json:"wipedAt,omitempty"` // latest per-entity wipe; reset local data when it changes
+
+	// Truncated is set when a cold pull skipped history older than TruncatedBefore;
+	// pull again with full=true to get everything
+	Truncated       bool    `json:"truncated,omitempty"`
+	TruncatedBefore *string `json:"truncatedBefore,omitempty"`
+
+	// ByteLimited is set when the page holds fewer than limit items to stay under MAX_PAGE_BYTES
+	ByteLimited bool `json:"byteLimited,omitempty"`
 }
 
 // writeJSON writes a JSON response with the given status code
+// Wraps the body in {data, meta} when the client opted in (see EnvelopeMiddleware)
 func writeJSON(w http.ResponseWriter, code int, v any) {
+	if ew, ok := w.(*envelopeWriter); ok {
+		v = ew.wrap(v)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
 	if err := json.NewEncoder(w).Encode(v); err != nil {
@@ -84,6 +137,9 @@ func writeJSON(w http.ResponseWriter, code int, v any) {
 type errorResponse struct {
 	Error         string `json:"error"`
 	CorrelationID string `json:"correlation_id"`
+	RequestID     string `json:"request_id,omitempty"`  // Set when Server.EchoRequestID is on (see RequestIDEcho)
+	ConflictUID   string `json:"conflictUid,omitempty"` // Item holding a unique field value (409)
+	ValidEntities []string `json:"validEntities,omitempty"` // Entity types a sync route accepts (unknown entity)
 }
 
 // writeError writes an error response with correlation ID from context
@@ -94,6 +150,19 @@ func writeError(w http.ResponseWriter, r *http.Request, code int, message string
 	json.NewEncoder(w).Encode(errorResponse{
 		Error:         message,
 		CorrelationID: correlationID,
+		RequestID:     errorRequestID(r.Context()),
+	})
+}
+
+// writeUniqueConflict writes 409 for a write whose unique field value another item holds
+func writeUniqueConflict(w http.ResponseWriter, r *http.Request, err *syncservice.UniqueConflictError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(errorResponse{
+		Error:         err.Error(),
+		CorrelationID: GetCorrelationID(r.Context()),
+		RequestID:     errorRequestID(r.Context()),
+		ConflictUID:   err.UID,
 	})
 }
 
@@ -119,11 +188,29 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 
 	// Middleware
 	r.Use(middleware.RequestID)
+	if s.EchoRequestID {
+		r.Use(RequestIDEcho) // X-Request-ID header and request_id in error bodies
+	}
 	r.Use(middleware.RealIP)
+	if s.Load != nil {
+		r.Use(LoadMiddleware(s.Load)) // Feed the load estimate behind sync info hints
+	}
 	r.Use(CorrelationMiddleware) // Track X-Correlation-ID header for request tracing
-	r.Use(middleware.Logger)
+	r.Use(AccessLog(s.AccessLog)) // One structured line per request (method, route, status, latency, user)
 	r.Use(middleware.Recoverer)
 	r.Use(SessionMiddleware) // Track X-Sync-Session header
+	r.Use(middleware.GetHead) // Serve HEAD from GET routes
+	if s.RequestTimeout > 0 {
+		r.Use(TimeoutMiddleware(s.RequestTimeout)) // Cancel slow handlers/DB calls, respond 504
+	}
+	r.Use(RequestDecompression(s.MaxDecompressedBytes)) // gzip request bodies, capped against zip bombs
+	r.Use(EnvelopeMiddleware) // Opt-in {data, meta} responses; must stay last so handlers see its writer
+
+	// 405 with an accurate Allow header for every route group
+	r.MethodNotAllowed(MethodNotAllowedHandler(r))
+
+	// Unknown entity types on sync routes get a 404/400 listing the valid ones
+	r.NotFound(s.NotFoundHandler)
 
 	// Health check (unauthenticated)
 	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) {
@@ -131,8 +218,41 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 		w.Write([]byte("ok"))
 	})
 
+	// Readiness check (unauthenticated): fails while Postgres is unreachable
+	r.Get("/readyz", s.Ready)
+
 	// Server info / capability discovery (unauthenticated)
 	r.Get("/v1/sync/info", s.Info)
+	r.Get("/v1/serverinfo", s.Info) // REST counterpart of gRPC GetServerInfo
+	r.Get("/v1/entities", s.ListEntities)
+
+	// Per-resource capability discovery for REST entity routes (unauthenticated)
+	for _, e := range entityCatalog {
+		if s.EntityEnabled(e.Name) {
+			r.Options("/v1/"+e.Name, ResourceOptions(r, e))
+			r.Options("/v1/"+e.Name+"/{uid}", ResourceOptions(r, e))
+		}
+	}
+
+	// Server clock for client skew correction (unauthenticated)
+	r.Get("/v1/time", s.Time)
+
+	// Token introspection: validates the Bearer token itself, without the auth middleware's
+	// user upsert, so it needs neither the database nor a session
+	r.Post("/v1/auth/introspect", s.Introspect(jwt))
+
+	// Admin endpoints (support diagnostics) use a static admin token, not user auth
+	// Only registered when ADMIN_TOKEN is configured
+	if s.AdminToken != "" {
+		r.Group(func(r chi.Router) {
+			r.Use(AdminTokenRequired(s.AdminToken))
+
+			r.Get("/v1/admin/users/{sub}/state", s.GetAdminUserState)
+			r.Post("/v1/admin/users/merge", s.MergeAdminUsers)
+			r.Post("/v1/admin/users/{sub}/recompute", s.RecomputeAdminUserCounters)
+			r.Get("/v1/admin/item_cache", s.GetAdminItemCache)
+		})
+	}
 
 	// All sync endpoints require authentication
 	r.Group(func(r chi.Router) {
@@ -142,7 +262,7 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 		// These are used to discover tenant ID or exchange tokens before tenant is known
 		// Rate limited with stricter auth defaults (60 req/min vs 600 for sync endpoints)
 		r.Group(func(r chi.Router) {
-			r.Use(AuthRateLimitMiddleware(s.AuthRateLimitConfig))
+			r.Use(s.rateLimitMiddleware("auth", s.AuthRateLimitConfig, DefaultAuthRateLimitConfig))
 
 			// Token exchange (Path B OAuth 2.1)
 			// Converts MCP OAuth tokens to backend JWTs
@@ -155,7 +275,11 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 			r.Get("/v1/auth/tenant", s.ResolveTenant)
 
 			// Session management (rate limited but no session header required for these)
-			r.Post("/v1/sync/sessions", s.BeginSession)
+			if s.SessionLimiter != nil {
+				r.With(SessionRateLimitMiddleware(s.SessionLimiter)).Post("/v1/sync/sessions", s.BeginSession)
+			} else {
+				r.Post("/v1/sync/sessions", s.BeginSession)
+			}
 			r.Get("/v1/sync/sessions/{id}", s.GetSession)
 			r.Delete("/v1/sync/sessions/{id}", s.EndSession)
 		})
@@ -171,36 +295,71 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 		// Entity sync endpoints require active session, rate limiting, and epoch validation
 		r.Group(func(r chi.Router) {
 			r.Use(SessionRequired) // Enforce X-Sync-Session header
-			r.Use(RateLimitMiddleware(s.RateLimitConfig))
+			r.Use(s.rateLimitMiddleware("sync", s.RateLimitConfig, DefaultRateLimitConfig))
+			if s.Scopes.Enforce {
+				r.Use(ScopeRequired(s.Scopes))
+			}
+			r.Use(ForceWriteRequired(s.Scopes.ForceWriteScope)) // ?force=true pushes skip LWW
 			r.Use(EpochRequired(s.DB)) // NEW: Validate epoch on all entity operations
+			if s.SessionUndo {
+				r.Use(SessionChangeTracking)
+			}
 
 			// Notes
-			r.Post("/v1/sync/notes/push", s.PushNotes)
-			r.Get("/v1/sync/notes/pull", s.PullNotes)
+			if s.EntityEnabled("notes") {
+				r.Post("/v1/sync/notes/push", s.PushNotes)
+				r.Get("/v1/sync/notes/pull", s.PullNotes)
+				r.Post("/v1/sync/notes/pull", s.PullNotes)
+				r.Post("/v1/sync/notes/pull_by_uid", s.PullNotesByUID)
+			}
 
 			// Tasks
-			r.Post("/v1/sync/tasks/push", s.PushTasks)
-			r.Get("/v1/sync/tasks/pull", s.PullTasks)
+			if s.EntityEnabled("tasks") {
+				r.Post("/v1/sync/tasks/push", s.PushTasks)
+				r.Get("/v1/sync/tasks/pull", s.PullTasks)
+				r.Post("/v1/sync/tasks/pull", s.PullTasks)
+			}
 
 			// Comments
-			r.Post("/v1/sync/comments/push", s.PushComments)
-			r.Get("/v1/sync/comments/pull", s.PullComments)
+			if s.EntityEnabled("comments") {
+				r.Post("/v1/sync/comments/push", s.PushComments)
+				r.Get("/v1/sync/comments/pull", s.PullComments)
+				r.Post("/v1/sync/comments/pull", s.PullComments)
+			}
 
 			// Chats
-			r.Post("/v1/sync/chats/push", s.PushChats)
-			r.Get("/v1/sync/chats/pull", s.PullChats)
+			if s.EntityEnabled("chats") {
+				r.Post("/v1/sync/chats/push", s.PushChats)
+				r.Get("/v1/sync/chats/pull", s.PullChats)
+				r.Post("/v1/sync/chats/pull", s.PullChats)
+			}
 
 			// Chat Messages
-			r.Post("/v1/sync/chat_messages/push", s.PushChatMessages)
-			r.Get("/v1/sync/chat_messages/pull", s.PullChatMessages)
+			if s.EntityEnabled("chat_messages") {
+				r.Post("/v1/sync/chat_messages/push", s.PushChatMessages)
+				r.Get("/v1/sync/chat_messages/pull", s.PullChatMessages)
+				r.Post("/v1/sync/chat_messages/pull", s.PullChatMessages)
+			}
 
 			// Task Lists
-			r.Post("/v1/sync/task_lists/push", s.PushTaskLists)
-			r.Get("/v1/sync/task_lists/pull", s.PullTaskLists)
+			if s.EntityEnabled("task_lists") {
+				r.Post("/v1/sync/task_lists/push", s.PushTaskLists)
+				r.Get("/v1/sync/task_lists/pull", s.PullTaskLists)
+				r.Post("/v1/sync/task_lists/pull", s.PullTaskLists)
+			}
 
 			// Task List Categories
-			r.Post("/v1/sync/task_list_categories/push", s.PushTaskListCategories)
-			r.Get("/v1/sync/task_list_categories/pull", s.PullTaskListCategories)
+			if s.EntityEnabled("task_list_categories") {
+				r.Post("/v1/sync/task_list_categories/push", s.PushTaskListCategories)
+				r.Get("/v1/sync/task_list_categories/pull", s.PullTaskListCategories)
+				r.Post("/v1/sync/task_list_categories/pull", s.PullTaskListCategories)
+			}
+
+			// Bounded diff between two cursors (streams NDJSON; see DiffEntity)
+			r.Get("/v1/sync/{entity}/diff", s.DiffEntity)
+
+			// Per-entity wipe (keeps the epoch; see WipeEntity)
+			r.Post("/v1/sync/{entity}/wipe", s.WipeEntity)
 		})
 
 		// REST CRUD endpoints require same protections as sync endpoints
@@ -208,86 +367,115 @@ func (s *Server) Routes(jwt auth.JWTCfg) http.Handler {
 		// so we don't need to apply it again here
 		r.Group(func(r chi.Router) {
 			r.Use(SessionRequired)
-			r.Use(RateLimitMiddleware(s.RateLimitConfig))
+			r.Use(s.rateLimitMiddleware("rest", s.RateLimitConfig, DefaultRateLimitConfig))
+			if s.Scopes.Enforce {
+				r.Use(ScopeRequired(s.Scopes))
+			}
 			r.Use(EpochRequired(s.DB))
+			r.Use(MutationActorMiddleware) // createdBy/updatedBy attribution
+			if s.SessionUndo {
+				r.Use(SessionChangeTracking)
+			}
 
 			// Notes REST endpoints
-			r.Get("/v1/notes", s.ListNotes)
-			r.Post("/v1/notes", s.CreateNote)
-			r.Get("/v1/notes/{uid}", s.GetNote)
-			r.Put("/v1/notes/{uid}", s.UpdateNote)
-			r.Patch("/v1/notes/{uid}", s.PatchNote)
-			r.Delete("/v1/notes/{uid}", s.DeleteNote)
-			r.Post("/v1/notes/{uid}/archive", s.ArchiveNote)
-			r.Post("/v1/notes/{uid}/process", s.ProcessNote)
+			if s.EntityEnabled("notes") {
+				s.mountRESTEntity(r, "notes")
+			}
 
 			// Tasks REST endpoints
-			r.Get("/v1/tasks", s.ListTasks)
-			r.Post("/v1/tasks", s.CreateTask)
-			r.Get("/v1/tasks/{uid}", s.GetTask)
-			r.Put("/v1/tasks/{uid}", s.UpdateTask)
-			r.Patch("/v1/tasks/{uid}", s.PatchTask)
-			r.Delete("/v1/tasks/{uid}", s.DeleteTask)
-			r.Post("/v1/tasks/{uid}/archive", s.ArchiveTask)
-			r.Post("/v1/tasks/{uid}/process", s.ProcessTask)
+			if s.EntityEnabled("tasks") {
+				s.mountRESTEntity(r, "tasks")
+			}
 
 			// Comments REST endpoints
-			r.Get("/v1/comments", s.ListComments)
-			r.Post("/v1/comments", s.CreateComment)
-			r.Get("/v1/comments/{uid}", s.GetComment)
-			r.Put("/v1/comments/{uid}", s.UpdateComment)
-			r.Patch("/v1/comments/{uid}", s.PatchComment)
-			r.Delete("/v1/comments/{uid}", s.DeleteComment)
-			r.Post("/v1/comments/{uid}/archive", s.ArchiveComment)
-			r.Post("/v1/comments/{uid}/process", s.ProcessComment)
+			if s.EntityEnabled("comments") {
+				r.Get("/v1/comments", s.ListComments)
+				r.Post("/v1/comments", s.CreateComment)
+				r.Get("/v1/comments/{uid}", s.GetComment)
+				r.Put("/v1/comments/{uid}", s.UpdateComment)
+				r.Patch("/v1/comments/{uid}", s.PatchComment)
+				r.Delete("/v1/comments/{uid}", s.DeleteComment)
+				r.Post("/v1/comments/{uid}/archive", s.ArchiveComment)
+				r.Post("/v1/comments/{uid}/process", s.ProcessComment)
+				r.Post("/v1/comments/batch_archive", s.BatchArchive("comment"))
+				r.Post("/v1/comments/batch_patch", s.BatchPatch("comment"))
+			}
 
 			// Chats REST endpoints
-			r.Get("/v1/chats", s.ListChats)
-			r.Post("/v1/chats", s.CreateChat)
-			r.Get("/v1/chats/{uid}", s.GetChat)
-			r.Put("/v1/chats/{uid}", s.UpdateChat)
-			r.Patch("/v1/chats/{uid}", s.PatchChat)
-			r.Delete("/v1/chats/{uid}", s.DeleteChat)
-			r.Post("/v1/chats/{uid}/archive", s.ArchiveChat)
-			r.Post("/v1/chats/{uid}/process", s.ProcessChat)
+			if s.EntityEnabled("chats") {
+				r.Get("/v1/chats", s.ListChats)
+				r.Post("/v1/chats", s.CreateChat)
+				r.Get("/v1/chats/{uid}", s.GetChat)
+				r.Put("/v1/chats/{uid}", s.UpdateChat)
+				r.Patch("/v1/chats/{uid}", s.PatchChat)
+				r.Delete("/v1/chats/{uid}", s.DeleteChat)
+				r.Post("/v1/chats/{uid}/archive", s.ArchiveChat)
+				r.Post("/v1/chats/{uid}/process", s.ProcessChat)
+				r.Post("/v1/chats/batch_archive", s.BatchArchive("chat"))
+				r.Post("/v1/chats/batch_patch", s.BatchPatch("chat"))
+			}
 
 			// Chat Messages REST endpoints
-			r.Get("/v1/chat_messages", s.ListChatMessages)
-			r.Post("/v1/chat_messages", s.CreateChatMessage)
-			r.Get("/v1/chat_messages/{uid}", s.GetChatMessage)
-			r.Put("/v1/chat_messages/{uid}", s.UpdateChatMessage)
-			r.Patch("/v1/chat_messages/{uid}", s.PatchChatMessage)
-			r.Delete("/v1/chat_messages/{uid}", s.DeleteChatMessage)
-			r.Post("/v1/chat_messages/{uid}/archive", s.ArchiveChatMessage)
-			r.Post("/v1/chat_messages/{uid}/process", s.ProcessChatMessage)
+			if s.EntityEnabled("chat_messages") {
+				r.Get("/v1/chat_messages", s.ListChatMessages)
+				r.Post("/v1/chat_messages", s.CreateChatMessage)
+				r.Get("/v1/chat_messages/{uid}", s.GetChatMessage)
+				r.Put("/v1/chat_messages/{uid}", s.UpdateChatMessage)
+				r.Patch("/v1/chat_messages/{uid}", s.PatchChatMessage)
+				r.Delete("/v1/chat_messages/{uid}", s.DeleteChatMessage)
+				r.Post("/v1/chat_messages/{uid}/archive", s.ArchiveChatMessage)
+				r.Post("/v1/chat_messages/{uid}/process", s.ProcessChatMessage)
+				r.Post("/v1/chat_messages/batch_archive", s.BatchArchive("chat_message"))
+				r.Post("/v1/chat_messages/batch_patch", s.BatchPatch("chat_message"))
+			}
 
 			// Task Lists REST endpoints
-			r.Get("/v1/task_lists", s.ListTaskLists)
-			r.Post("/v1/task_lists", s.CreateTaskList)
-			r.Get("/v1/task_lists/{uid}", s.GetTaskList)
-			r.Put("/v1/task_lists/{uid}", s.UpdateTaskList)
-			r.Patch("/v1/task_lists/{uid}", s.PatchTaskList)
-			r.Delete("/v1/task_lists/{uid}", s.DeleteTaskList)
-			r.Post("/v1/task_lists/{uid}/archive", s.ArchiveTaskList)
-			r.Post("/v1/task_lists/{uid}/process", s.ProcessTaskList)
+			if s.EntityEnabled("task_lists") {
+				r.Get("/v1/task_lists", s.ListTaskLists)
+				r.Post("/v1/task_lists", s.CreateTaskList)
+				r.Get("/v1/task_lists/{uid}", s.GetTaskList)
+				r.Put("/v1/task_lists/{uid}", s.UpdateTaskList)
+				r.Patch("/v1/task_lists/{uid}", s.PatchTaskList)
+				r.Delete("/v1/task_lists/{uid}", s.DeleteTaskList)
+				r.Post("/v1/task_lists/{uid}/archive", s.ArchiveTaskList)
+				r.Post("/v1/task_lists/{uid}/process", s.ProcessTaskList)
+				r.Post("/v1/task_lists/batch_archive", s.BatchArchive("task_list"))
+				r.Post("/v1/task_lists/batch_patch", s.BatchPatch("task_list"))
+			}
 
 			// Task List Categories REST endpoints
-			r.Get("/v1/task_list_categories", s.ListTaskListCategories)
-			r.Post("/v1/task_list_categories", s.CreateTaskListCategory)
-			r.Get("/v1/task_list_categories/{uid}", s.GetTaskListCategory)
-			r.Put("/v1/task_list_categories/{uid}", s.UpdateTaskListCategory)
-			r.Patch("/v1/task_list_categories/{uid}", s.PatchTaskListCategory)
-			r.Delete("/v1/task_list_categories/{uid}", s.DeleteTaskListCategory)
-			r.Post("/v1/task_list_categories/{uid}/archive", s.ArchiveTaskListCategory)
-			r.Post("/v1/task_list_categories/{uid}/process", s.ProcessTaskListCategory)
+			if s.EntityEnabled("task_list_categories") {
+				r.Get("/v1/task_list_categories", s.ListTaskListCategories)
+				r.Post("/v1/task_list_categories", s.CreateTaskListCategory)
+				r.Get("/v1/task_list_categories/{uid}", s.GetTaskListCategory)
+				r.Put("/v1/task_list_categories/{uid}", s.UpdateTaskListCategory)
+				r.Patch("/v1/task_list_categories/{uid}", s.PatchTaskListCategory)
+				r.Delete("/v1/task_list_categories/{uid}", s.DeleteTaskListCategory)
+				r.Post("/v1/task_list_categories/{uid}/archive", s.ArchiveTaskListCategory)
+				r.Post("/v1/task_list_categories/{uid}/process", s.ProcessTaskListCategory)
+				r.Post("/v1/task_list_categories/batch_archive", s.BatchArchive("task_list_category"))
+				r.Post("/v1/task_list_categories/batch_patch", s.BatchPatch("task_list_category"))
+			}
+
+			// Cross-entity full-text search
+			r.Get("/v1/search", s.Search)
+
+			// Session-scoped undo (see UndoSession)
+			if s.SessionUndo {
+				r.Post("/v1/sync/sessions/{id}/undo", s.UndoSession)
+			}
 		})
 
-			// Wipe & state routes require auth + session, but NO epoch check
+			// Wipe, resync & state routes require auth + session, but NO epoch check
 			// (otherwise you can't wipe when epoch is mismatched!)
 			r.Group(func(r chi.Router) {
 				r.Use(SessionRequired)
+				if s.Scopes.Enforce {
+					r.Use(ScopeRequired(s.Scopes))
+				}
 
 				r.Post("/v1/sync/wipe", s.WipeAccount)
+				r.Post("/v1/sync/resync", s.ResyncAccount)
 				r.Get("/v1/sync/state", s.GetSyncState)
 			})
 		}) // End tenant header middleware group
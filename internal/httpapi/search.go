@@ -0,0 +1,79 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/rs/zerolog/log"
+)
+
+// parseEntityTypes parses a comma-separated ?types= query param
+// Returns nil (all types) when the param is empty
+func parseEntityTypes(r *http.Request) []string {
+	raw := r.URL.Query().Get("types")
+	if raw == "" {
+		return nil
+	}
+	types := make([]string, 0)
+	for _, t := range strings.Split(raw, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// Search handles GET /v1/search?q=<query>&types=notes,tasks&limit=<int>
+// Returns active items across entity types ranked by full-text relevance
+func (s *Server) Search(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, r, 400, "query parameter q is required")
+		return
+	}
+
+	limit := parseLimit(r.URL.Query().Get("limit"), 20, 100)
+	entityTypes := parseEntityTypes(r)
+
+	// Disabled entity types are neither searched nor accepted as filters
+	if len(s.DisabledEntities) > 0 {
+		if len(entityTypes) == 0 {
+			for _, e := range entityCatalog {
+				if s.EntityEnabled(e.Name) {
+					entityTypes = append(entityTypes, e.Name)
+				}
+			}
+		}
+		for _, et := range entityTypes {
+			if !s.EntityEnabled(et) {
+				writeError(w, r, 400, (&syncservice.UnknownEntityTypeError{EntityType: et}).Error())
+				return
+			}
+		}
+	}
+
+	resp, err := s.SearchSvc.Search(ctx, userID, query, entityTypes, limit)
+	if err != nil {
+		if _, ok := err.(*syncservice.UnknownEntityTypeError); ok {
+			writeError(w, r, 400, err.Error())
+			return
+		}
+		logger.Error().Err(err).Msg("failed to search")
+		writeError(w, r, 500, "search failed")
+		return
+	}
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("result_count", len(resp.Results)).
+		Strs("types", entityTypes).
+		Msg("search_completed")
+
+	writeJSON(w, 200, resp)
+}
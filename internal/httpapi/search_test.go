@@ -0,0 +1,29 @@
+package httpapi
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseEntityTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"absent", "", nil},
+		{"single", "?types=notes", []string{"notes"}},
+		{"multiple with spaces", "?types=notes,%20tasks", []string{"notes", "tasks"}},
+		{"trailing comma", "?types=notes,", []string{"notes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/v1/search"+tt.query, nil)
+			if got := parseEntityTypes(req); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEntityTypes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
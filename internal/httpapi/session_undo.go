@@ -0,0 +1,195 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// undoConflict is an item the undo left alone
+type undoConflict struct {
+	Entity string `json:"entity"`
+	UID    string `json:"uid"`
+	Reason string `json:"reason"`
+}
+
+// undoSessionResponse summarizes POST /v1/sync/sessions/{id}/undo
+type undoSessionResponse struct {
+	SessionID string         `json:"sessionId"`
+	Reverted  int            `json:"reverted"`  // items restored to their pre-session state
+	Deleted   int            `json:"deleted"`   // items created in the session, now tombstoned
+	Unchanged int            `json:"unchanged"` // nothing to undo (session writes lost LWW or already reverted)
+	Conflicts []undoConflict `json:"conflicts"` // changed by another writer since; left as is
+}
+
+// UndoSession handles POST /v1/sync/sessions/{id}/undo
+// Reverts every item the session wrote to its state before the session: items the session
+// created are soft-deleted, other items get their previous payload (and tombstone) back.
+// An item is skipped as a conflict when another writer changed it after the session's last
+// write, so undo never clobbers newer data. The undo itself is a normal REST mutation
+// (new timestamps, synced to other devices) applied in one transaction; failing to read
+// or commit rolls all of it back with a 500. Items that were undone (or had nothing to
+// undo) leave the session's change log; conflicts keep their baseline, so the undo can
+// be retried.
+// Works after the session has ended, but only for the user who owned it.
+func (s *Server) UndoSession(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	sessionID := chi.URLParam(r, "id")
+	// The undo's own writes are not part of any session
+	ctx := syncservice.WithSession(r.Context(), "")
+	logger := log.Ctx(ctx)
+
+	changes, err := syncservice.SessionChanges(ctx, s.DB, s.syncCfg(), userID, sessionID)
+	if err != nil {
+		logger.Error().Err(err).Str("sessionId", sessionID).Msg("failed to load session changes")
+		writeError(w, r, http.StatusInternalServerError, "failed to load session changes")
+		return
+	}
+	if len(changes) == 0 {
+		writeError(w, r, http.StatusNotFound, "no changes recorded for session")
+		return
+	}
+
+	resp := undoSessionResponse{SessionID: sessionID, Conflicts: []undoConflict{}}
+	stores := s.itemStores()
+
+	// Restore parents before children (a comment needs its parent back), then delete
+	// created items children first
+	var restores, deletes []syncservice.SessionChange
+	for i := len(syncTables) - 1; i >= 0; i-- {
+		for _, c := range changes {
+			if c.Entity == syncTables[i] && c.Existed {
+				restores = append(restores, c)
+			}
+		}
+	}
+	for _, table := range syncTables {
+		for _, c := range changes {
+			if c.Entity == table && !c.Existed {
+				deletes = append(deletes, c)
+			}
+		}
+	}
+
+	tx, err := db.Begin(ctx, s.DB)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to begin transaction")
+		writeError(w, r, http.StatusInternalServerError, "undo failed")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	forget := func(c syncservice.SessionChange) bool {
+		if err := syncservice.ForgetSessionChangeTx(ctx, tx, userID, sessionID, c.Entity, c.UID); err != nil {
+			logger.Error().Err(err).Str("entity", c.Entity).Str("uid", c.UID.String()).Msg("failed to clear session change")
+			writeError(w, r, http.StatusInternalServerError, "undo failed")
+			return false
+		}
+		return true
+	}
+
+	for _, c := range append(restores, deletes...) {
+		store, ok := stores[c.Entity]
+		if !ok {
+			continue
+		}
+		if c.LastMs == nil {
+			resp.Unchanged++
+			if !forget(c) {
+				return
+			}
+			continue
+		}
+
+		updatedAtMs, version, err := syncservice.CurrentRowStateTx(ctx, tx, c.Entity, userID, c.UID)
+		if errors.Is(err, syncservice.ErrNotFound) {
+			resp.Conflicts = append(resp.Conflicts, undoConflict{c.Entity, c.UID.String(), "item no longer exists"})
+			continue
+		}
+		if err != nil {
+			logger.Error().Err(err).Str("entity", c.Entity).Str("uid", c.UID.String()).Msg("failed to read item for undo")
+			writeError(w, r, http.StatusInternalServerError, "failed to read item")
+			return
+		}
+		if updatedAtMs != *c.LastMs {
+			resp.Conflicts = append(resp.Conflicts, undoConflict{c.Entity, c.UID.String(), "modified after the session"})
+			continue
+		}
+
+		opts := syncservice.MutationOpts{EnforceVersion: true, ExpectedVersion: version}
+		var payload map[string]any
+		if c.Existed {
+			payload = c.PrevPayload
+			opts.SetDeleted = c.PrevDeletedAtMs != nil
+		} else {
+			current, err := store.get(ctx, userID, c.UID)
+			if err != nil || current == nil {
+				logger.Error().Err(err).Str("entity", c.Entity).Str("uid", c.UID.String()).Msg("failed to read item for undo")
+				writeError(w, r, http.StatusInternalServerError, "failed to read item")
+				return
+			}
+			if current.DeletedAt != nil {
+				resp.Unchanged++
+				if !forget(c) {
+					return
+				}
+				continue
+			}
+			payload = current.Payload
+			opts.SetDeleted = true
+		}
+
+		// Each revert runs under a savepoint so a rejected item leaves the rest of the
+		// undo usable and keeps its change log entry
+		sp, err := tx.Begin(ctx)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to begin savepoint")
+			writeError(w, r, http.StatusInternalServerError, "undo failed")
+			return
+		}
+		if _, err := store.applyTx(ctx, sp, userID, payload, opts); err != nil {
+			_ = sp.Rollback(ctx)
+			var vErr *syncservice.VersionMismatchError
+			if errors.As(err, &vErr) {
+				resp.Conflicts = append(resp.Conflicts, undoConflict{c.Entity, c.UID.String(), "modified after the session"})
+				continue
+			}
+			logger.Error().Err(err).Str("entity", c.Entity).Str("uid", c.UID.String()).Msg("failed to revert item")
+			resp.Conflicts = append(resp.Conflicts, undoConflict{c.Entity, c.UID.String(), "revert failed: " + err.Error()})
+			continue
+		}
+		if err := sp.Commit(ctx); err != nil {
+			logger.Error().Err(err).Msg("failed to release savepoint")
+			writeError(w, r, http.StatusInternalServerError, "undo failed")
+			return
+		}
+		if !forget(c) {
+			return
+		}
+		if c.Existed {
+			resp.Reverted++
+		} else {
+			resp.Deleted++
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error().Err(err).Str("sessionId", sessionID).Msg("failed to commit undo")
+		writeError(w, r, http.StatusInternalServerError, "undo failed")
+		return
+	}
+
+	logger.Info().
+		Str("sessionId", sessionID).
+		Int("reverted", resp.Reverted).
+		Int("deleted", resp.Deleted).
+		Int("conflicts", len(resp.Conflicts)).
+		Msg("session undone")
+
+	writeJSON(w, http.StatusOK, resp)
+}
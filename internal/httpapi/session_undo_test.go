@@ -0,0 +1,91 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+)
+
+func TestUndoSession_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		SessionUndo:     true,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	before := createTestSession(t, router)
+	undone := createTestSession(t, router)
+	after := createTestSession(t, router)
+
+	push := func(session TestSession, uid, title, ts string) {
+		t.Helper()
+		item := map[string]any{"uid": uid, "title": title, "updatedTs": ts}
+		if w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session); w.Code != http.StatusOK {
+			t.Fatalf("Push failed: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	edited, conflicted, created := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	push(before, edited, "Original", "2025-11-03T10:00:00Z")
+	push(before, conflicted, "Original", "2025-11-03T10:00:00Z")
+
+	push(undone, edited, "Edited in session", "2025-11-03T11:00:00Z")
+	push(undone, conflicted, "Edited in session", "2025-11-03T11:00:00Z")
+	push(undone, created, "Created in session", "2025-11-03T11:00:00Z")
+
+	// A later write by another session wins over undo
+	push(after, conflicted, "Edited later", "2025-11-03T12:00:00Z")
+
+	w := makeRequestWithSession(t, router, "POST", "/v1/sync/sessions/"+undone.ID+"/undo", nil, after)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	var resp undoSessionResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Reverted != 1 || resp.Deleted != 1 || len(resp.Conflicts) != 1 || resp.Conflicts[0].UID != conflicted {
+		t.Errorf("Unexpected undo summary: %+v", resp)
+	}
+
+	for uid, want := range map[string]string{edited: "Original", conflicted: "Edited later"} {
+		w := makeRequestWithSession(t, router, "GET", "/v1/notes/"+uid, nil, after)
+		var item syncservice.RESTItem
+		if err := json.NewDecoder(w.Body).Decode(&item); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("Get %s failed: %d %s", uid, w.Code, w.Body.String())
+		}
+		if item.Payload["title"] != want {
+			t.Errorf("Note %s: expected title %q, got %v", uid, want, item.Payload["title"])
+		}
+	}
+
+	if w := makeRequestWithSession(t, router, "GET", "/v1/notes/"+created, nil, after); w.Code != http.StatusGone {
+		t.Errorf("Expected created note to be deleted (410), got %d %s", w.Code, w.Body.String())
+	}
+
+	// Undone items leave the change log; the conflict keeps its baseline so a retry
+	// reports it again instead of treating the reverted items as modified
+	w = makeRequestWithSession(t, router, "POST", "/v1/sync/sessions/"+undone.ID+"/undo", nil, after)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 for a retry, got %d %s", w.Code, w.Body.String())
+	}
+	resp = undoSessionResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Reverted != 0 || resp.Deleted != 0 || len(resp.Conflicts) != 1 || resp.Conflicts[0].UID != conflicted {
+		t.Errorf("Expected only the conflict on retry, got %+v", resp)
+	}
+}
@@ -1,6 +1,7 @@
 package httpapi
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -53,19 +54,30 @@ func (s *Server) BeginSession(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Create session with epoch
-	session := sessionStore.CreateSession(userID, epoch)
+	// Create session with epoch (subject to the per-user session cap)
+	sess, err := sessionStore.CreateSession(userID, epoch)
+	if err != nil {
+		if errors.Is(err, session.ErrSessionLimitReached) {
+			max, _ := sessionStore.UserSessionLimit()
+			log.Warn().Str("userId", userID).Int("maxSessions", max).Msg("session limit reached")
+			writeError(w, r, http.StatusConflict, "too many active sessions; end an existing session first")
+			return
+		}
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to create session")
+		writeError(w, r, http.StatusInternalServerError, "Failed to create session")
+		return
+	}
 
 	log.Info().
-		Str("sessionId", session.ID).
+		Str("sessionId", sess.ID).
 		Str("userId", userID).
 		Int("epoch", epoch).
-		Time("expiresAt", session.ExpiresAt).
+		Time("expiresAt", sess.ExpiresAt).
 		Msg("sync session created")
 
 	// Return session with epoch in header
 	w.Header().Set("X-Sync-Epoch", strconv.Itoa(epoch))
-	writeJSON(w, http.StatusCreated, session)
+	writeJSON(w, http.StatusCreated, sess)
 }
 
 // EndSession handles DELETE /v1/sync/sessions/{id}
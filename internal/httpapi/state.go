@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
 	"github.com/jackc/pgx/v5"
 	"github.com/rs/zerolog/log"
 )
@@ -14,6 +15,14 @@ type syncStateResponse struct {
 	Epoch      int        `json:"epoch"`
 	LastWipeAt *time.Time `json:"lastWipeAt,omitempty"`
 	LastWipeBy *string    `json:"lastWipeBy,omitempty"`
+
+	// EntityWipes maps entity names (as used in sync URLs) to their last per-entity wipe
+	EntityWipes map[string]string `json:"entityWipes,omitempty"`
+
+	// PayloadBytes is the size of the user's live items; PayloadBytesLimit is the
+	// cap writes are checked against (omitted when unlimited, see MAX_PAYLOAD_BYTES)
+	PayloadBytes      int64 `json:"payloadBytes"`
+	PayloadBytesLimit int64 `json:"payloadBytesLimit,omitempty"`
 }
 
 // GetSyncState returns the current sync state for the authenticated user.
@@ -22,6 +31,8 @@ type syncStateResponse struct {
 // - epoch: Current tenant epoch
 // - lastWipeAt: Timestamp of last wipe operation (if any)
 // - lastWipeBy: User ID who triggered the last wipe (if any)
+// - entityWipes: Last per-entity wipe by entity name (if any)
+// - payloadBytes, payloadBytesLimit: Stored payload size and the per-user cap
 //
 // This endpoint is used by clients to check if a reset is required
 // without triggering a full sync operation.
@@ -36,7 +47,15 @@ func (s *Server) GetSyncState(w http.ResponseWriter, r *http.Request) {
 	var lastWipeAt sql.NullTime
 	var lastWipeBy sql.NullString
 
-	err := s.DB.QueryRow(r.Context(), `
+	// Storage usage is kept apart from owner_state (migration 0020)
+	payloadBytes, err := syncservice.PayloadBytes(r.Context(), s.DB, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to load storage usage")
+		writeError(w, r, http.StatusInternalServerError, "failed to load sync state")
+		return
+	}
+
+	err = s.DB.QueryRow(r.Context(), `
 		SELECT epoch, last_wipe_at, last_wipe_by
 		FROM owner_state
 		WHERE owner_id = $1
@@ -46,7 +65,9 @@ func (s *Server) GetSyncState(w http.ResponseWriter, r *http.Request) {
 		// If row doesn't exist, return default state (epoch=1)
 		if err == pgx.ErrNoRows {
 			writeJSON(w, http.StatusOK, syncStateResponse{
-				Epoch: 1,
+				Epoch:             1,
+				PayloadBytes:      payloadBytes,
+				PayloadBytesLimit: s.syncCfg().MaxPayloadBytes,
 			})
 			return
 		}
@@ -57,7 +78,9 @@ func (s *Server) GetSyncState(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := syncStateResponse{
-		Epoch: epoch,
+		Epoch:             epoch,
+		PayloadBytes:      payloadBytes,
+		PayloadBytesLimit: s.syncCfg().MaxPayloadBytes,
 	}
 
 	if lastWipeAt.Valid {
@@ -67,5 +90,20 @@ func (s *Server) GetSyncState(w http.ResponseWriter, r *http.Request) {
 		resp.LastWipeBy = &lastWipeBy.String
 	}
 
+	wipes, err := syncservice.EntityWipes(r.Context(), s.DB, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userId", userID).Msg("Failed to load entity wipes")
+		writeError(w, r, http.StatusInternalServerError, "failed to load sync state")
+		return
+	}
+	for entity, table := range syncEntityTables {
+		if wipedAt, ok := wipes[table]; ok {
+			if resp.EntityWipes == nil {
+				resp.EntityWipes = make(map[string]string)
+			}
+			resp.EntityWipes[entity] = wipedAt
+		}
+	}
+
 	writeJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+)
+
+func TestStorageQuota_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+	_, _ = pool.Exec(context.Background(), "DELETE FROM note")
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		SyncCfg:         syncservice.DefaultConfig(),
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	srv.NoteSvc.Cfg = srv.SyncCfg
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	state := func() syncStateResponse {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "GET", "/v1/sync/state", nil, session)
+		var resp syncStateResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode sync state: %v", err)
+		}
+		return resp
+	}
+
+	// Other entity tests may have left items behind; measure against them
+	baseline := state().PayloadBytes
+	limit := baseline + 2000
+	srv.SyncCfg.MaxPayloadBytes = limit
+
+	w := makeRequestWithSession(t, router, "POST", "/v1/notes", map[string]any{"title": "small"}, session)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create failed: %d %s", w.Code, w.Body.String())
+	}
+	var note syncservice.RESTItem
+	if err := json.NewDecoder(w.Body).Decode(&note); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	used := state()
+	if used.PayloadBytes <= baseline || used.PayloadBytesLimit != limit {
+		t.Fatalf("Expected usage to grow under a %d byte limit, got %+v", limit, used)
+	}
+
+	// Growing past the cap is refused and changes nothing
+	w = makeRequestWithSession(t, router, "POST", "/v1/notes", map[string]any{"title": strings.Repeat("x", 2000)}, session)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403, got %d %s", w.Code, w.Body.String())
+	}
+	if got := state().PayloadBytes; got != used.PayloadBytes {
+		t.Errorf("Refused write changed usage: %d -> %d", used.PayloadBytes, got)
+	}
+
+	// Deleting gives the bytes back
+	w = makeRequestWithSession(t, router, "DELETE", "/v1/notes/"+note.UID, nil, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Delete failed: %d %s", w.Code, w.Body.String())
+	}
+	if got := state().PayloadBytes; got != baseline {
+		t.Errorf("Expected %d bytes after delete, got %d", baseline, got)
+	}
+}
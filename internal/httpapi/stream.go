@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// streamWriteTimeout is how long each page of a streamed response may take to reach the
+// client. It replaces the http.Server WriteTimeout, which would cut a long stream short.
+const streamWriteTimeout = 30 * time.Second
+
+// streamingRequest reports whether r is for a response streamed in pages (the NDJSON
+// diff and CSV exports). These are exempt from TimeoutMiddleware's whole-request
+// deadline: a large stream legitimately outlives it, so each page is bounded instead
+// (see responseStream).
+func streamingRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if strings.HasPrefix(r.URL.Path, "/v1/sync/") {
+		return strings.HasSuffix(r.URL.Path, "/diff")
+	}
+	return strings.HasSuffix(r.URL.Path, "/export.csv")
+}
+
+// responseStream writes a response page by page, flushing each one to the client
+type responseStream struct {
+	rc          *http.ResponseController
+	pageTimeout time.Duration // deadline for each page's queries (0 = none)
+}
+
+// newResponseStream starts a streamed response on w; each page gets the request timeout
+func (s *Server) newResponseStream(w http.ResponseWriter) *responseStream {
+	return &responseStream{rc: http.NewResponseController(w), pageTimeout: s.RequestTimeout}
+}
+
+// page starts the next page: it pushes the connection's write deadline out by
+// streamWriteTimeout and returns the context the page's queries should run under
+func (st *responseStream) page(ctx context.Context) (context.Context, context.CancelFunc) {
+	// Not supported by httptest recorders; a real connection always supports it
+	_ = st.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	if st.pageTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, st.pageTimeout)
+}
+
+// flush sends everything written so far to the client
+func (st *responseStream) flush() {
+	// A client that went away shows up as an error on the next write
+	_ = st.rc.Flush()
+}
@@ -0,0 +1,120 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/go-chi/chi/v5"
+)
+
+func TestStreamingRequest(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         bool
+	}{
+		{"GET", "/v1/sync/notes/diff", true},
+		{"HEAD", "/v1/sync/tasks/diff", true},
+		{"POST", "/v1/sync/notes/diff", false},
+		{"GET", "/v1/sync/notes/pull", false},
+		{"GET", "/v1/notes/diff", false},
+		{"GET", "/v1/tasks/export.csv", true},
+		{"GET", "/v1/sync/tasks/export.csv", false},
+	}
+	for _, tt := range tests {
+		if got := streamingRequest(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.want {
+			t.Errorf("%s %s: got %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestResponseStream_MiddlewareChain streams through the router's real middleware (access
+// log, request timeout, gzip request bodies, envelopes) on a real connection: each page
+// must reach the client as soon as it is flushed, and the stream must outlive the request
+// timeout as long as each page stays within it.
+func TestResponseStream_MiddlewareChain(t *testing.T) {
+	var gzipped bytes.Buffer
+	gzip.NewWriter(&gzipped).Close()
+
+	tests := []struct {
+		name   string
+		query  string
+		header http.Header
+		body   []byte
+	}{
+		{name: "plain"},
+		{name: "enveloped", query: "?envelope=true"},
+		{name: "gzip request body", header: http.Header{"Content-Encoding": {"gzip"}}, body: gzipped.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &Server{RequestTimeout: 50 * time.Millisecond}
+			router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true}).(*chi.Mux)
+
+			firstRead := make(chan struct{})
+			router.Get("/v1/sync/test/diff", func(w http.ResponseWriter, r *http.Request) {
+				stream := srv.newResponseStream(w)
+				w.WriteHeader(http.StatusOK)
+				for i := range 3 {
+					ctx, cancel := stream.page(r.Context())
+					time.Sleep(30 * time.Millisecond) // three pages run well past the request timeout
+					err := ctx.Err()
+					cancel()
+					if err != nil {
+						fmt.Fprintf(w, "page %d: %v\n", i, err)
+						return
+					}
+					fmt.Fprintf(w, "page %d\n", i)
+					stream.flush()
+					if i == 0 {
+						select {
+						case <-firstRead:
+						case <-time.After(2 * time.Second):
+							fmt.Fprintln(w, "page 0 was not flushed")
+							return
+						}
+					}
+				}
+				fmt.Fprintln(w, "end")
+			})
+			ts := httptest.NewServer(router)
+			defer ts.Close()
+
+			req, _ := http.NewRequest("GET", ts.URL+"/v1/sync/test/diff"+tt.query, bytes.NewReader(tt.body))
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected 200, got %d", resp.StatusCode)
+			}
+
+			reader := bufio.NewReader(resp.Body)
+			first, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("Reading first page: %v", err)
+			}
+			close(firstRead)
+			var rest strings.Builder
+			if _, err := reader.WriteTo(&rest); err != nil {
+				t.Fatalf("Reading rest of stream: %v", err)
+			}
+
+			if got, want := first+rest.String(), "page 0\npage 1\npage 2\nend\n"; got != want {
+				t.Errorf("Stream = %q, want %q", got, want)
+			}
+		})
+	}
+}
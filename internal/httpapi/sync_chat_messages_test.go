@@ -181,6 +181,26 @@ func TestPushChatMessages_Integration(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "push with role outside allowlist",
+			body: pushReq{
+				Items: []map[string]any{
+					{
+						"uid":       "a5b6c7d8-e5f6-7a8b-9c0d-1e2f3a4b5c6d",
+						"content":   "Unknown role",
+						"role":      "narrator",
+						"chatUid":   chatUID,
+						"updatedTs": "2025-11-03T10:00:00Z",
+					},
+				},
+			},
+			wantStatus: 200,
+			checkResp: func(t *testing.T, acks []pushAck) {
+				if want := `invalid role: "narrator" is not an allowed role`; acks[0].Error != want {
+					t.Errorf("Error = %q, want %q", acks[0].Error, want)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -5,8 +5,7 @@ import (
 	"net/http"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
-	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/rs/zerolog/log"
 )
 
@@ -26,11 +25,14 @@ func (s *Server) PushChats(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, 400, []pushAck{{Error: "invalid json"}})
 		return
 	}
+	if s.pushTooLarge(w, r, len(req.Items)) {
+		return
+	}
 
 	acks := make([]pushAck, 0, len(req.Items))
 
 	// Use transaction for atomicity (all-or-nothing per batch)
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
@@ -66,6 +68,7 @@ func (s *Server) PushChats(w http.ResponseWriter, r *http.Request) {
 }
 
 // PullChats handles GET /v1/sync/chats/pull?cursor=<opaque>&limit=<int>
+// Also accepts POST with a JSON body {cursor, limit} (see parsePullParams)
 // Returns upserts and deletes in deterministic order using cursor-based pagination
 func (s *Server) PullChats(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
@@ -73,22 +76,37 @@ func (s *Server) PullChats(w http.ResponseWriter, r *http.Request) {
 	// Use contextual logger with correlation ID
 	logger := log.Ctx(ctx)
 
-	// Parse query params
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		// No cursor = start from beginning (epoch)
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	// Parse pull params (query string for GET, JSON body for POST)
+	params, err := s.parseCappedPullParams(w, r, "chat")
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid pull request")
+		writeError(w, r, 400, pullParamsError(err))
+		return
 	}
 
 	logger.Info().
 		Str("user_id", userID).
-		Int("limit", limit).
-		Str("cursor", r.URL.Query().Get("cursor")).
+		Int("limit", params.Limit).
+		Str("cursor", params.RawCursor).
 		Msg("sync_pull_started: chats")
 
+	// A cursor repeated while newer data exists is a client hot loop (see PullLoopGuard)
+	if !s.checkPullLoop(w, r, "chat", params) {
+		return
+	}
+
+	// Long-poll: with ?wait=, hold an up-to-date pull until changes arrive (see waitForPullChanges)
+	s.waitForPullChanges(r, "chat", params)
+
+	// Conditional pull: nothing newer than the cursor, skip the query
+	if s.pullUpToDate(r, "chat", params) {
+		logger.Info().Str("user_id", userID).Msg("sync_pull_not_modified: chats")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	// Call the refactored service layer
-	resp, err := s.ChatSvc.PullChats(ctx, userID, cur, limit)
+	resp, err := s.ChatSvc.PullChats(ctx, userID, params.Cursor, params.Limit)
 	if err != nil {
 		writeError(w, r, 500, "pull failed")
 		return
@@ -101,9 +119,15 @@ func (s *Server) PullChats(w http.ResponseWriter, r *http.Request) {
 		Bool("has_next_page", resp.NextCursor != nil).
 		Msg("sync_pull_completed: chats")
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, pullResp{
-		Upserts:    resp.Upserts,
-		Deletes:    resp.Deletes,
-		NextCursor: resp.NextCursor,
+		Upserts:         resp.Upserts,
+		Deletes:         resp.Deletes,
+		Inserts:         pullInserts(params, resp),
+		NextCursor:      pullNextCursor(params, resp),
+		WipedAt:         s.entityWipedAt(r, "chat"),
+		Truncated:       params.TruncatedBefore != nil,
+		TruncatedBefore: params.TruncatedBefore,
+		ByteLimited:     resp.ByteLimited,
 	})
 }
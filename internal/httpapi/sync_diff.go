@@ -0,0 +1,131 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// pullRangeFunc pulls one page of an entity table strictly after cursor and at or before to
+type pullRangeFunc func(ctx context.Context, userID string, cursor, to syncx.Cursor, limit int) (*syncservice.PullResponse, error)
+
+// pullRangeFuncs maps entity tables to their bounded pull
+func (s *Server) pullRangeFuncs() map[string]pullRangeFunc {
+	return map[string]pullRangeFunc{
+		"note":               s.NoteSvc.PullNotesRange,
+		"task":               s.TaskSvc.PullTasksRange,
+		"comment":            s.CommentSvc.PullCommentsRange,
+		"chat":               s.ChatSvc.PullChatsRange,
+		"chat_message":       s.ChatMessageSvc.PullChatMessagesRange,
+		"task_list":          s.TaskListSvc.PullTaskListsRange,
+		"task_list_category": s.TaskListCategorySvc.PullTaskListCategoriesRange,
+	}
+}
+
+// diffPageSize is how many rows DiffEntity reads per pull page (the client sees one stream)
+const diffPageSize = 1000
+
+// diffLine is one line of the NDJSON diff stream
+// Op is "upsert" or "delete" (Item set), then a final "end" (Count set) or "error" (Error set).
+type diffLine struct {
+	Op    string         `json:"op"`
+	Item  map[string]any `json:"item,omitempty"`
+	Count *int           `json:"count,omitempty"`
+	Error string         `json:"error,omitempty"`
+}
+
+// DiffEntity handles GET /v1/sync/{entity}/diff?from=<cursor>&to=<cursor>
+// Streams every upsert and delete in (from, to] as newline-delimited JSON, one pull page
+// at a time (upserts then deletes), so clients can replay exactly what changed between
+// two cursors they already hold.
+// An empty from starts at the beginning of history; to is required. Pages are read with
+// the same cursor query as pull and flushed as they go, so the range can be arbitrarily large:
+// the request timeout bounds each page rather than the whole stream (see responseStream).
+// Failures after the first line can't change the status; they end the stream with an "error" line.
+func (s *Server) DiffEntity(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	entity := chi.URLParam(r, "entity")
+	table, ok := syncEntityTables[entity]
+	if !ok || !s.EntityEnabled(entity) {
+		s.writeUnknownEntity(w, r, entity)
+		return
+	}
+
+	rawTo := r.URL.Query().Get("to")
+	if rawTo == "" {
+		writeError(w, r, http.StatusBadRequest, "to is required")
+		return
+	}
+	from, err := syncx.DecodeCursor(r.URL.Query().Get("from"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "from: "+err.Error())
+		return
+	}
+	to, err := syncx.DecodeCursor(rawTo)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "to: "+err.Error())
+		return
+	}
+	if syncx.CompareCursors(from, to) > 0 {
+		writeError(w, r, http.StatusBadRequest, "from must not be after to")
+		return
+	}
+
+	pull := s.pullRangeFuncs()[table]
+	stream := s.newResponseStream(w)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+
+	count := 0
+	cursor := from
+	for {
+		pageCtx, cancel := stream.page(ctx)
+		resp, err := pull(pageCtx, userID, cursor, to, diffPageSize)
+		cancel()
+		if err != nil {
+			logger.Error().Err(err).Str("table", table).Msg("diff pull failed")
+			enc.Encode(diffLine{Op: "error", Error: "diff failed"})
+			return
+		}
+
+		for _, item := range resp.Upserts {
+			if err := enc.Encode(diffLine{Op: "upsert", Item: item}); err != nil {
+				return // client went away
+			}
+		}
+		for _, item := range resp.Deletes {
+			if err := enc.Encode(diffLine{Op: "delete", Item: item}); err != nil {
+				return
+			}
+		}
+		count += len(resp.Upserts) + len(resp.Deletes)
+		stream.flush()
+
+		if resp.NextCursor == nil || (len(resp.Upserts)+len(resp.Deletes) < diffPageSize && !resp.ByteLimited) {
+			break
+		}
+		if cursor, err = syncx.DecodeCursor(*resp.NextCursor); err != nil {
+			logger.Error().Err(err).Str("table", table).Msg("diff produced an unreadable cursor")
+			enc.Encode(diffLine{Op: "error", Error: "diff failed"})
+			return
+		}
+	}
+
+	logger.Info().
+		Str("user_id", userID).
+		Str("table", table).
+		Int("count", count).
+		Msg("sync_diff_completed")
+
+	enc.Encode(diffLine{Op: "end", Count: &count})
+}
@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+)
+
+func TestDiffEntity_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	// Three notes, pushed one at a time; record the pull cursor after each
+	uids := make([]string, 3)
+	cursors := make([]string, 3)
+	for i := range uids {
+		uids[i] = uuid.New().String()
+		item := map[string]any{"uid": uids[i], "title": "Note", "updatedTs": "2025-11-03T10:00:00Z"}
+		if w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session); w.Code != http.StatusOK {
+			t.Fatalf("Push failed: %d %s", w.Code, w.Body.String())
+		}
+
+		path := "/v1/sync/notes/pull?limit=1"
+		if i > 0 {
+			path += "&cursor=" + cursors[i-1]
+		}
+		w := makeRequestWithSession(t, router, "GET", path, nil, session)
+		var resp pullResp
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.NextCursor == nil {
+			t.Fatalf("Pull failed: %d %s", w.Code, w.Body.String())
+		}
+		cursors[i] = *resp.NextCursor
+	}
+
+	// (cursor0, cursor2] holds exactly the second and third note
+	w := makeRequestWithSession(t, router, "GET", "/v1/sync/notes/diff?from="+cursors[0]+"&to="+cursors[2], nil, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Expected NDJSON content type, got %q", ct)
+	}
+	var lines []diffLine
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var line diffLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("Invalid line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("Expected 2 upserts and an end line, got %+v", lines)
+	}
+	for i, line := range lines[:2] {
+		if line.Op != "upsert" || line.Item["uid"] != uids[i+1] {
+			t.Errorf("Line %d: expected upsert of %s, got %+v", i, uids[i+1], line)
+		}
+	}
+	if end := lines[2]; end.Op != "end" || end.Count == nil || *end.Count != 2 {
+		t.Errorf("Expected end line with count 2, got %+v", end)
+	}
+
+	// Reversed and missing bounds are rejected
+	for _, path := range []string{
+		"/v1/sync/notes/diff?from=" + cursors[2] + "&to=" + cursors[0],
+		"/v1/sync/notes/diff?from=" + cursors[0],
+		"/v1/sync/notes/diff?from=" + cursors[0] + "&to=garbage",
+	} {
+		if w := makeRequestWithSession(t, router, "GET", path, nil, session); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d %s", path, w.Code, w.Body.String())
+		}
+	}
+	if w := makeRequestWithSession(t, router, "GET", "/v1/sync/widgets/diff?to="+cursors[0], nil, session); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown entity, got %d", w.Code)
+	}
+}
@@ -2,10 +2,11 @@ package httpapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
-	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
@@ -26,11 +27,14 @@ func (s *Server) PushNotes(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, 400, []pushAck{{Error: "invalid json"}})
 		return
 	}
+	if s.pushTooLarge(w, r, len(req.Items)) {
+		return
+	}
 
 	acks := make([]pushAck, 0, len(req.Items))
 
 	// Use transaction for atomicity (all-or-nothing per batch)
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
@@ -66,6 +70,7 @@ func (s *Server) PushNotes(w http.ResponseWriter, r *http.Request) {
 }
 
 // PullNotes handles GET /v1/sync/notes/pull?cursor=<opaque>&limit=<int>
+// Also accepts POST with a JSON body {cursor, limit} (see parsePullParams)
 // Returns upserts and deletes in deterministic order using cursor-based pagination
 func (s *Server) PullNotes(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
@@ -73,22 +78,37 @@ func (s *Server) PullNotes(w http.ResponseWriter, r *http.Request) {
 	// Use contextual logger with correlation ID
 	logger := log.Ctx(ctx)
 
-	// Parse query params
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		// No cursor = start from beginning (epoch)
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	// Parse pull params (query string for GET, JSON body for POST)
+	params, err := s.parseCappedPullParams(w, r, "note")
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid pull request")
+		writeError(w, r, 400, pullParamsError(err))
+		return
 	}
 
 	logger.Info().
 		Str("user_id", userID).
-		Int("limit", limit).
-		Str("cursor", r.URL.Query().Get("cursor")).
+		Int("limit", params.Limit).
+		Str("cursor", params.RawCursor).
 		Msg("sync_pull_started: notes")
 
+	// A cursor repeated while newer data exists is a client hot loop (see PullLoopGuard)
+	if !s.checkPullLoop(w, r, "note", params) {
+		return
+	}
+
+	// Long-poll: with ?wait=, hold an up-to-date pull until changes arrive (see waitForPullChanges)
+	s.waitForPullChanges(r, "note", params)
+
+	// Conditional pull: nothing newer than the cursor, skip the query
+	if s.pullUpToDate(r, "note", params) {
+		logger.Info().Str("user_id", userID).Msg("sync_pull_not_modified: notes")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	// Call the refactored service layer
-	resp, err := s.NoteSvc.PullNotes(ctx, userID, cur, limit)
+	resp, err := s.NoteSvc.PullNotes(ctx, userID, params.Cursor, params.Limit)
 	if err != nil {
 		writeError(w, r, 500, "pull failed")
 		return
@@ -101,9 +121,76 @@ func (s *Server) PullNotes(w http.ResponseWriter, r *http.Request) {
 		Bool("has_next_page", resp.NextCursor != nil).
 		Msg("sync_pull_completed: notes")
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, pullResp{
-		Upserts:    resp.Upserts,
-		Deletes:    resp.Deletes,
-		NextCursor: resp.NextCursor,
+		Upserts:         resp.Upserts,
+		Deletes:         resp.Deletes,
+		Inserts:         pullInserts(params, resp),
+		NextCursor:      pullNextCursor(params, resp),
+		WipedAt:         s.entityWipedAt(r, "note"),
+		Truncated:       params.TruncatedBefore != nil,
+		TruncatedBefore: params.TruncatedBefore,
+		ByteLimited:     resp.ByteLimited,
 	})
 }
+
+// maxPullByUID caps the number of UIDs per pull_by_uid request (matches the pull page limit)
+const maxPullByUID = 1000
+
+// pullByUIDReq is the request body for POST /v1/sync/notes/pull_by_uid
+type pullByUIDReq struct {
+	UIDs []string `json:"uids"`
+}
+
+// PullNotesByUID handles POST /v1/sync/notes/pull_by_uid
+// Returns the current state of specific notes for push-driven clients that already
+// know which UIDs changed. Complements cursor pulls; does not advance any cursor.
+func (s *Server) PullNotesByUID(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	var req pullByUIDReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, 400, "invalid json")
+		return
+	}
+	if len(req.UIDs) == 0 {
+		writeError(w, r, 400, "uids is required")
+		return
+	}
+	if len(req.UIDs) > maxPullByUID {
+		writeError(w, r, 400, fmt.Sprintf("too many uids (max %d)", maxPullByUID))
+		return
+	}
+
+	seen := make(map[uuid.UUID]bool, len(req.UIDs))
+	uids := make([]uuid.UUID, 0, len(req.UIDs))
+	for _, raw := range req.UIDs {
+		uid, err := uuid.Parse(raw)
+		if err != nil {
+			writeError(w, r, 400, fmt.Sprintf("invalid uid: %q", raw))
+			return
+		}
+		if !seen[uid] {
+			seen[uid] = true
+			uids = append(uids, uid)
+		}
+	}
+
+	resp, err := s.NoteSvc.PullNotesByUID(ctx, userID, uids)
+	if err != nil {
+		writeError(w, r, 500, "pull failed")
+		return
+	}
+
+	logger.Info().
+		Str("user_id", userID).
+		Int("requested", len(uids)).
+		Int("upsert_count", len(resp.Upserts)).
+		Int("delete_count", len(resp.Deletes)).
+		Int("missing_count", len(resp.Missing)).
+		Msg("sync_pull_by_uid_completed: notes")
+
+	writeJSON(w, 200, resp)
+}
@@ -32,6 +32,12 @@ func getTestDB(t *testing.T) *pgxpool.Pool {
 		t.Fatalf("Failed to clean notes table: %v", err)
 	}
 
+	// Wipe markers refuse pushes stamped before them; don't let one test's wipe leak into the next
+	_, err = pool.Exec(context.Background(), "DELETE FROM entity_wipe")
+	if err != nil {
+		t.Fatalf("Failed to clean entity wipes: %v", err)
+	}
+
 	return pool
 }
 
@@ -406,3 +412,92 @@ func TestSoftDelete_Integration(t *testing.T) {
 		t.Errorf("Wrong note in deletes: %v", pullResp.Deletes[0])
 	}
 }
+
+// TestPushAbsentVsNull_Integration covers the default ("keep") push field semantics across
+// two clients: an omitted field keeps its stored value, an explicit null clears it.
+func TestPushAbsentVsNull_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	clientA := createTestSession(t, router)
+	clientB := createTestSession(t, router)
+	const uid = "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"
+
+	push := func(session TestSession, item map[string]any) {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session)
+		var acks []pushAck
+		if err := json.NewDecoder(w.Body).Decode(&acks); err != nil || len(acks) != 1 || acks[0].Error != "" {
+			t.Fatalf("Push failed: %d %s", w.Code, w.Body.String())
+		}
+	}
+	pull := func(session TestSession) map[string]any {
+		t.Helper()
+		w := makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, session)
+		var resp pullResp
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || len(resp.Upserts) != 1 {
+			t.Fatalf("Expected 1 upsert, got %s", w.Body.String())
+		}
+		return resp.Upserts[0]
+	}
+
+	// Client A creates the note with a color
+	push(clientA, map[string]any{
+		"uid":       uid,
+		"title":     "Shared",
+		"color":     "red",
+		"updatedTs": "2025-11-03T10:00:00Z",
+	})
+
+	// Client B doesn't know about color and omits it: the color is kept
+	push(clientB, map[string]any{
+		"uid":       uid,
+		"title":     "Renamed by B",
+		"updatedTs": "2025-11-03T10:01:00Z",
+	})
+	note := pull(clientA)
+	if note["title"] != "Renamed by B" || note["color"] != "red" {
+		t.Errorf("Absent field should be kept: got title=%v color=%v", note["title"], note["color"])
+	}
+
+	// Client A clears the color with an explicit null
+	push(clientA, map[string]any{
+		"uid":       uid,
+		"color":     nil,
+		"updatedTs": "2025-11-03T10:02:00Z",
+	})
+	note = pull(clientB)
+	if color, present := note["color"]; !present || color != nil {
+		t.Errorf("Explicit null should clear the field: got color=%v (present=%v)", color, present)
+	}
+	if note["title"] != "Renamed by B" {
+		t.Errorf("Title should be unchanged, got %v", note["title"])
+	}
+
+	// Sync metadata is never merged: an un-delete that omits sync is stored as live
+	push(clientA, map[string]any{
+		"uid":       uid,
+		"updatedTs": "2025-11-03T10:03:00Z",
+		"sync":      map[string]any{"isDeleted": true, "deletedAt": "2025-11-03T10:03:00Z"},
+	})
+	push(clientB, map[string]any{
+		"uid":       uid,
+		"title":     "Restored",
+		"updatedTs": "2025-11-03T10:04:00Z",
+	})
+	note = pull(clientA)
+	if syncBlock, _ := note["sync"].(map[string]any); syncBlock["isDeleted"] == true {
+		t.Errorf("Un-deleted note still carries the stored sync.isDeleted: %v", note)
+	}
+}
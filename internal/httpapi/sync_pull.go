@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// pullReq is the request body for POST pull endpoints
+// Mirrors the GET query params so long cursors don't have to travel in the URL
+type pullReq struct {
+	Cursor string `json:"cursor,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Full   bool   `json:"full,omitempty"`
+	Wait   string `json:"wait,omitempty"` // long-poll duration, e.g. "30s"
+}
+
+// pullParams holds parsed pull parameters shared by the GET and POST forms
+type pullParams struct {
+	Cursor    syncx.Cursor
+	RawCursor string // opaque cursor as sent by the client (for logging)
+	Limit     int
+	Clamped   bool          // the requested limit was over the entity's max (see pageLimit)
+	Full      bool          // client asked for complete history (bypasses ColdPullMaxAge)
+	Wait      time.Duration // hold an up-to-date pull open for changes (see waitForPullChanges)
+
+	// TruncatedBefore is set when a cold pull was started at the depth cutoff
+	// instead of the beginning of history (RFC3339)
+	TruncatedBefore *string
+}
+
+// parsePullParams reads pull parameters from the query string (GET) or JSON body (POST)
+// Both forms go through the same defaults and validation so they behave identically.
+// An empty POST body is treated like a GET without query params.
+func (s *Server) parsePullParams(r *http.Request, table string) (pullParams, error) {
+	rawCursor := r.URL.Query().Get("cursor")
+	rawLimit := r.URL.Query().Get("limit")
+	full := r.URL.Query().Get("full") == "true"
+	rawWait := r.URL.Query().Get("wait")
+
+	if r.Method == http.MethodPost {
+		var req pullReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			return pullParams{}, err
+		}
+		rawCursor = req.Cursor
+		full = req.Full
+		rawWait = req.Wait
+		rawLimit = ""
+		if req.Limit != 0 {
+			rawLimit = strconv.Itoa(req.Limit)
+		}
+	}
+
+	// No cursor = start from beginning (epoch); a cursor we can't read is an error rather
+	// than a silent restart, which would re-download everything
+	cur, err := syncx.DecodeCursor(rawCursor)
+	if err != nil {
+		return pullParams{}, err
+	}
+
+	wait, err := parsePullWait(rawWait)
+	if err != nil {
+		return pullParams{}, err
+	}
+
+	requested, _ := strconv.Atoi(rawLimit) // unreadable = none, as for parseLimit
+	limit, clamped := s.syncCfg().PageLimitFor(table).Resolve(requested)
+	return pullParams{
+		Cursor:    cur,
+		RawCursor: rawCursor,
+		Limit:     limit,
+		Clamped:   clamped,
+		Full:      full,
+		Wait:      wait,
+	}, nil
+}
+
+// pageLimit resolves a REST list's ?limit= against the entity's page size (see
+// syncservice.Config.PageLimitFor): none gets the default, and one over the max is clamped
+// and flagged with an X-Limit-Clamped header (see setLimitClamped)
+func (s *Server) pageLimit(w http.ResponseWriter, raw, table string) int {
+	requested, _ := strconv.Atoi(raw)
+	limit, clamped := s.syncCfg().PageLimitFor(table).Resolve(requested)
+	if clamped {
+		setLimitClamped(w, limit)
+	}
+	return limit
+}
+
+// setLimitClamped tells the client its limit was cut down to max rather than rejected
+func setLimitClamped(w http.ResponseWriter, max int) {
+	w.Header().Set("X-Limit-Clamped", strconv.Itoa(max))
+}
+
+// pullParamsError returns the 400 message for a parsePullParams error
+func pullParamsError(err error) string {
+	if errors.Is(err, syncx.ErrInvalidCursor) || errors.Is(err, errInvalidWait) {
+		return err.Error()
+	}
+	return "invalid json"
+}
+
+// parseCappedPullParams parses pull parameters and applies the cold pull depth cap.
+// A pull without a cursor normally starts at the beginning of history; with
+// ColdPullMaxAge set it starts at now-ColdPullMaxAge instead (unless the client sent
+// full=true) and the response reports truncated/truncatedBefore.
+// A clamped limit is flagged on w (see setLimitClamped).
+func (s *Server) parseCappedPullParams(w http.ResponseWriter, r *http.Request, table string) (pullParams, error) {
+	params, err := s.parsePullParams(r, table)
+	if err == nil && params.Clamped {
+		setLimitClamped(w, params.Limit)
+	}
+	if err != nil || s.ColdPullMaxAge <= 0 || params.RawCursor != "" || params.Full {
+		return params, err
+	}
+
+	cutoffMs := syncx.NowMs() - s.ColdPullMaxAge.Milliseconds()
+	params.Cursor = syncx.Cursor{Ms: cutoffMs, UID: uuid.Nil}
+	truncatedBefore := syncx.RFC3339(cutoffMs)
+	params.TruncatedBefore = &truncatedBefore
+	return params, nil
+}
+
+// pullInserts returns the UIDs of first-version upserts: on a cold pull (no cursor) every
+// upsert, otherwise those the server has never updated since creation (version 1). These
+// include items the pulling client pushed itself, so they are not guaranteed to be
+// missing locally; they only have no earlier server state to merge with.
+func pullInserts(params pullParams, resp *syncservice.PullResponse) []string {
+	if params.RawCursor != "" {
+		return resp.Inserts
+	}
+	inserts := make([]string, 0, len(resp.Upserts))
+	for _, item := range resp.Upserts {
+		if uid, ok := item["uid"].(string); ok {
+			inserts = append(inserts, uid)
+		}
+	}
+	return inserts
+}
+
+// pullConditionalHeader opts a client into 204 No Content for pulls that are already up to date.
+// Opt-in because existing clients expect a JSON body from every pull.
+const pullConditionalHeader = "X-Sync-Conditional"
+
+// pullUpToDate reports whether a conditional pull can be answered with 204 without scanning:
+// the client sent a cursor and it is at or past the user's newest position for the table.
+// Watermark lookup failures fall back to a normal pull.
+func (s *Server) pullUpToDate(r *http.Request, table string, params pullParams) bool {
+	if r.Header.Get(pullConditionalHeader) != "true" || params.RawCursor == "" {
+		return false
+	}
+
+	ctx := r.Context()
+	watermark, err := syncservice.PullWatermark(ctx, s.DB, table, auth.UserID(ctx))
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("table", table).Msg("failed to compute pull watermark")
+		return false
+	}
+	return syncx.CompareCursors(params.Cursor, watermark) >= 0
+}
+
+// entityWipedAt returns when the user last wiped this entity table (nil if never)
+// Lookup failures are logged and omitted rather than failing the pull.
+func (s *Server) entityWipedAt(r *http.Request, table string) *string {
+	ctx := r.Context()
+	wipes, err := syncservice.EntityWipes(ctx, s.DB, auth.UserID(ctx))
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("table", table).Msg("failed to load entity wipe marker")
+		return nil
+	}
+	if wipedAt, ok := wipes[table]; ok {
+		return &wipedAt
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+)
+
+func TestParsePullParams(t *testing.T) {
+	uid := uuid.New()
+	cursor := syncx.EncodeCursor(syncx.Cursor{Ms: 1700000000000, UID: uid})
+
+	tests := []struct {
+		name          string
+		method        string
+		query         string
+		body          string
+		expectedLimit int
+		expectedMs    int64
+		expectError   bool
+	}{
+		{"GET defaults", "GET", "", "", 500, 0, false},
+		{"GET with params", "GET", "?limit=50&cursor=" + cursor, "", 50, 1700000000000, false},
+		{"GET limit capped", "GET", "?limit=5000", "", 1000, 0, false},
+		{"POST empty body", "POST", "", "", 500, 0, false},
+		{"POST with params", "POST", "", `{"cursor":"` + cursor + `","limit":50}`, 50, 1700000000000, false},
+		{"POST limit capped", "POST", "", `{"limit":5000}`, 1000, 0, false},
+		{"POST ignores query", "POST", "?limit=50", `{}`, 500, 0, false},
+		{"POST invalid json", "POST", "", `{"limit":`, 0, 0, true},
+		{"GET unreadable cursor", "GET", "?cursor=AjEy", "", 0, 0, true},
+		{"POST unreadable cursor", "POST", "", `{"cursor":"not-a-cursor"}`, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/sync/notes/pull"+tt.query, strings.NewReader(tt.body))
+
+			params, err := (&Server{}).parsePullParams(req, "note")
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error for invalid request, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if params.Limit != tt.expectedLimit {
+				t.Errorf("Expected limit %d, got %d", tt.expectedLimit, params.Limit)
+			}
+			if params.Cursor.Ms != tt.expectedMs {
+				t.Errorf("Expected cursor ms %d, got %d", tt.expectedMs, params.Cursor.Ms)
+			}
+		})
+	}
+}
+
+func TestParseCappedPullParams_LimitClamped(t *testing.T) {
+	tests := []struct {
+		name        string
+		table       string
+		query       string
+		wantLimit   int
+		wantClamped string
+	}{
+		{"note default", "note", "", 500, ""},
+		{"chat message default", "chat_message", "", 100, ""},
+		{"within max", "chat_message", "?limit=1000", 1000, ""},
+		{"over max clamped", "chat_message", "?limit=5000", 1000, "1000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/v1/sync/pull"+tt.query, nil)
+			params, err := (&Server{}).parseCappedPullParams(w, req, tt.table)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if params.Limit != tt.wantLimit {
+				t.Errorf("Expected limit %d, got %d", tt.wantLimit, params.Limit)
+			}
+			if got := w.Header().Get("X-Limit-Clamped"); got != tt.wantClamped {
+				t.Errorf("X-Limit-Clamped = %q, want %q", got, tt.wantClamped)
+			}
+		})
+	}
+}
+
+func TestParseCappedPullParams(t *testing.T) {
+	cursor := syncx.EncodeCursor(syncx.Cursor{Ms: 1700000000000, UID: uuid.New()})
+	srv := &Server{ColdPullMaxAge: 30 * 24 * time.Hour}
+
+	tests := []struct {
+		name          string
+		method        string
+		query         string
+		body          string
+		wantTruncated bool
+	}{
+		{"GET cold pull", "GET", "", "", true},
+		{"GET with cursor", "GET", "?cursor=" + cursor, "", false},
+		{"GET full", "GET", "?full=true", "", false},
+		{"POST cold pull", "POST", "", `{}`, true},
+		{"POST full", "POST", "", `{"full":true}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/v1/sync/notes/pull"+tt.query, strings.NewReader(tt.body))
+			before := syncx.NowMs()
+
+			params, err := srv.parseCappedPullParams(httptest.NewRecorder(), req, "note")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := params.TruncatedBefore != nil; got != tt.wantTruncated {
+				t.Fatalf("Truncated = %v, want %v", got, tt.wantTruncated)
+			}
+			if tt.wantTruncated {
+				if want := before - srv.ColdPullMaxAge.Milliseconds(); params.Cursor.Ms < want {
+					t.Errorf("Expected cursor at the depth cutoff (>= %d), got %d", want, params.Cursor.Ms)
+				}
+			} else if params.RawCursor == "" && params.Cursor.Ms != 0 {
+				t.Errorf("Expected full pull from the beginning, got cursor ms %d", params.Cursor.Ms)
+			}
+		})
+	}
+
+	// No cap configured: cold pulls are never truncated
+	req := httptest.NewRequest("GET", "/v1/sync/notes/pull", nil)
+	if params, _ := (&Server{}).parseCappedPullParams(httptest.NewRecorder(), req, "note"); params.TruncatedBefore != nil {
+		t.Errorf("Expected no truncation without ColdPullMaxAge, got %s", *params.TruncatedBefore)
+	}
+}
+
+func TestPullInserts(t *testing.T) {
+	resp := &syncservice.PullResponse{
+		Upserts: []map[string]any{{"uid": "a", "version": 1}, {"uid": "b", "version": 3}},
+		Inserts: []string{"a"},
+	}
+
+	tests := []struct {
+		name     string
+		params   pullParams
+		expected []string
+	}{
+		{"cold pull marks every upsert new", pullParams{}, []string{"a", "b"}},
+		{"incremental pull uses server version", pullParams{RawCursor: "abc"}, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pullInserts(tt.params, resp)
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestConditionalPull_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	push := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{
+		Items: []map[string]any{{
+			"uid":       uuid.New().String(),
+			"title":     "Conditional",
+			"updatedTs": "2025-11-03T10:00:00Z",
+			"sync":      map[string]any{"version": float64(1)},
+		}},
+	}, session)
+	if push.Code != http.StatusOK {
+		t.Fatalf("Push failed: %d %s", push.Code, push.Body.String())
+	}
+
+	// Drain to the end of the stream
+	w := makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, session)
+	var resp pullResp
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.NextCursor == nil {
+		t.Fatalf("Expected a next cursor, got %s (err %v)", w.Body.String(), err)
+	}
+
+	conditionalPull := func(conditional bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/sync/notes/pull?cursor="+*resp.NextCursor, nil)
+		req.Header.Set("X-Debug-Sub", "test-user")
+		req.Header.Set("X-Sync-Session", session.ID)
+		req.Header.Set("X-Sync-Epoch", strconv.Itoa(session.Epoch))
+		if conditional {
+			req.Header.Set(pullConditionalHeader, "true")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := conditionalPull(true); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("Expected empty 204 for up-to-date conditional pull, got %d %s", w.Code, w.Body.String())
+	}
+	if w := conditionalPull(false); w.Code != http.StatusOK {
+		t.Errorf("Expected 200 without conditional header, got %d", w.Code)
+	}
+}
+
+func TestPullNotesByUID_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	liveUID, deletedUID, otherUID, unknownUID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	push := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{
+		Items: []map[string]any{
+			{"uid": liveUID.String(), "title": "Live", "updatedTs": "2025-11-03T10:00:00Z"},
+			{"uid": deletedUID.String(), "title": "Gone", "updatedTs": "2025-11-03T10:00:00Z",
+				"sync": map[string]any{"isDeleted": true, "deletedAt": "2025-11-03T10:00:00Z"}},
+			{"uid": otherUID.String(), "title": "Not requested", "updatedTs": "2025-11-03T10:00:00Z"},
+		},
+	}, session)
+	if push.Code != http.StatusOK {
+		t.Fatalf("Push failed: %d %s", push.Code, push.Body.String())
+	}
+
+	w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/pull_by_uid", pullByUIDReq{
+		UIDs: []string{liveUID.String(), deletedUID.String(), unknownUID.String(), liveUID.String()},
+	}, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", w.Code, w.Body.String())
+	}
+
+	var resp syncservice.PullByUIDResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(resp.Upserts) != 1 || resp.Upserts[0]["uid"] != liveUID.String() {
+		t.Errorf("Expected only the live note in upserts, got %v", resp.Upserts)
+	}
+	if len(resp.Deletes) != 1 || resp.Deletes[0]["uid"] != deletedUID.String() {
+		t.Errorf("Expected the deleted note in deletes, got %v", resp.Deletes)
+	}
+	if len(resp.Missing) != 1 || resp.Missing[0] != unknownUID.String() {
+		t.Errorf("Expected the unknown uid in missing, got %v", resp.Missing)
+	}
+
+	for name, body := range map[string]pullByUIDReq{
+		"empty":       {},
+		"invalid uid": {UIDs: []string{"not-a-uuid"}},
+	} {
+		if w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/pull_by_uid", body, session); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, w.Code)
+		}
+	}
+}
+
+func TestWipeEntity_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+		TaskSvc:         syncservice.NewTaskService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	item := map[string]any{"uid": uuid.New().String(), "title": "Keep or wipe", "updatedTs": "2025-11-03T10:00:00Z"}
+	for _, path := range []string{"/v1/sync/notes/push", "/v1/sync/tasks/push"} {
+		if w := makeRequestWithSession(t, router, "POST", path, pushReq{Items: []map[string]any{item}}, session); w.Code != http.StatusOK {
+			t.Fatalf("Push to %s failed: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	for name, tc := range map[string]struct {
+		path string
+		body any
+		want int
+	}{
+		"unknown entity":       {"/v1/sync/widgets/wipe", wipeRequest{Confirm: "WIPE"}, http.StatusNotFound},
+		"missing confirmation": {"/v1/sync/notes/wipe", wipeRequest{}, http.StatusBadRequest},
+	} {
+		if w := makeRequestWithSession(t, router, "POST", tc.path, tc.body, session); w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d %s", name, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/wipe", wipeRequest{Confirm: "WIPE"}, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d %s", w.Code, w.Body.String())
+	}
+	var wiped entityWipeResponse
+	if err := json.NewDecoder(w.Body).Decode(&wiped); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if wiped.Entity != "notes" || wiped.Deleted != 1 || wiped.WipedAt == "" {
+		t.Errorf("Unexpected wipe response: %+v", wiped)
+	}
+
+	// Same session and epoch still work; notes are empty and carry the wipe marker
+	var notes pullResp
+	w = makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, session)
+	if err := json.NewDecoder(w.Body).Decode(&notes); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Notes pull failed: %d %s", w.Code, w.Body.String())
+	}
+	if len(notes.Upserts) != 0 || notes.WipedAt == nil || *notes.WipedAt != wiped.WipedAt {
+		t.Errorf("Expected empty notes with wipedAt %s, got %+v", wiped.WipedAt, notes)
+	}
+
+	// Other entity types are untouched
+	var tasks pullResp
+	w = makeRequestWithSession(t, router, "GET", "/v1/sync/tasks/pull", nil, session)
+	if err := json.NewDecoder(w.Body).Decode(&tasks); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Tasks pull failed: %d %s", w.Code, w.Body.String())
+	}
+	if len(tasks.Upserts) != 1 || tasks.WipedAt != nil {
+		t.Errorf("Expected tasks to be untouched, got %+v", tasks)
+	}
+
+	var state syncStateResponse
+	w = makeRequestWithSession(t, router, "GET", "/v1/sync/state", nil, session)
+	if err := json.NewDecoder(w.Body).Decode(&state); err != nil {
+		t.Fatalf("Failed to decode state: %v", err)
+	}
+	if state.Epoch != session.Epoch || state.EntityWipes["notes"] != wiped.WipedAt {
+		t.Errorf("Expected unchanged epoch and notes wipe in state, got %+v", state)
+	}
+}
+
+func TestWipeEntity_RejectsStalePush(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	staleUID := uuid.New().String()
+	item := map[string]any{"uid": staleUID, "title": "Before the wipe", "updatedTs": "2025-11-03T10:00:00Z"}
+	if w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session); w.Code != http.StatusOK {
+		t.Fatalf("Push failed: %d %s", w.Code, w.Body.String())
+	}
+	if w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/wipe", wipeRequest{Confirm: "WIPE"}, session); w.Code != http.StatusOK {
+		t.Fatalf("Wipe failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// An offline device pushes its pre-wipe copy (edited, but still before the wipe)
+	// alongside a note written after it
+	freshUID := uuid.New().String()
+	stale := map[string]any{"uid": staleUID, "title": "Edited offline", "updatedTs": "2025-11-03T11:00:00Z"}
+	fresh := map[string]any{"uid": freshUID, "title": "After the wipe", "updatedTs": syncx.RFC3339(time.Now().Add(time.Minute).UnixMilli())}
+	w := makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{stale, fresh}}, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Push failed: %d %s", w.Code, w.Body.String())
+	}
+	var acks []pushAck
+	if err := json.NewDecoder(w.Body).Decode(&acks); err != nil {
+		t.Fatalf("Failed to decode acks: %v", err)
+	}
+	if len(acks) != 2 || acks[0].Error == "" || acks[1].Error != "" {
+		t.Fatalf("Expected the stale item refused and the fresh one stored, got %+v", acks)
+	}
+
+	// The wiped note stays gone
+	var notes pullResp
+	w = makeRequestWithSession(t, router, "GET", "/v1/sync/notes/pull", nil, session)
+	if err := json.NewDecoder(w.Body).Decode(&notes); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Notes pull failed: %d %s", w.Code, w.Body.String())
+	}
+	if len(notes.Upserts) != 1 || notes.Upserts[0]["uid"] != freshUID {
+		t.Errorf("Expected only the post-wipe note, got %+v", notes.Upserts)
+	}
+}
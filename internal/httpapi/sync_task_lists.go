@@ -5,8 +5,7 @@ import (
 	"net/http"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
-	"github.com/erauner12/toolbridge-api/internal/syncx"
-	"github.com/google/uuid"
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/rs/zerolog/log"
 )
 
@@ -28,10 +27,13 @@ func (s *Server) PushTaskLists(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, 400, []pushAck{{Error: "invalid json"}})
 		return
 	}
+	if s.pushTooLarge(w, r, len(req.Items)) {
+		return
+	}
 
 	acks := make([]pushAck, 0, len(req.Items))
 
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
@@ -64,24 +66,42 @@ func (s *Server) PushTaskLists(w http.ResponseWriter, r *http.Request) {
 }
 
 // PullTaskLists handles GET /v1/sync/task_lists/pull
+// Also accepts POST with a JSON body {cursor, limit} (see parsePullParams)
 func (s *Server) PullTaskLists(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
 	logger := log.Ctx(ctx)
 
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	// Parse pull params (query string for GET, JSON body for POST)
+	params, err := s.parseCappedPullParams(w, r, "task_list")
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid pull request")
+		writeError(w, r, 400, pullParamsError(err))
+		return
 	}
 
 	logger.Info().
 		Str("user_id", userID).
-		Int("limit", limit).
-		Str("cursor", r.URL.Query().Get("cursor")).
+		Int("limit", params.Limit).
+		Str("cursor", params.RawCursor).
 		Msg("sync_pull_started: task_lists")
 
-	resp, err := s.TaskListSvc.PullTaskLists(ctx, userID, cur, limit)
+	// A cursor repeated while newer data exists is a client hot loop (see PullLoopGuard)
+	if !s.checkPullLoop(w, r, "task_list", params) {
+		return
+	}
+
+	// Long-poll: with ?wait=, hold an up-to-date pull until changes arrive (see waitForPullChanges)
+	s.waitForPullChanges(r, "task_list", params)
+
+	// Conditional pull: nothing newer than the cursor, skip the query
+	if s.pullUpToDate(r, "task_list", params) {
+		logger.Info().Str("user_id", userID).Msg("sync_pull_not_modified: task_lists")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	resp, err := s.TaskListSvc.PullTaskLists(ctx, userID, params.Cursor, params.Limit)
 	if err != nil {
 		writeError(w, r, 500, "pull failed")
 		return
@@ -94,10 +114,16 @@ func (s *Server) PullTaskLists(w http.ResponseWriter, r *http.Request) {
 		Bool("has_next_page", resp.NextCursor != nil).
 		Msg("sync_pull_completed: task_lists")
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, pullResp{
-		Upserts:    resp.Upserts,
-		Deletes:    resp.Deletes,
-		NextCursor: resp.NextCursor,
+		Upserts:         resp.Upserts,
+		Deletes:         resp.Deletes,
+		Inserts:         pullInserts(params, resp),
+		NextCursor:      pullNextCursor(params, resp),
+		WipedAt:         s.entityWipedAt(r, "task_list"),
+		Truncated:       params.TruncatedBefore != nil,
+		TruncatedBefore: params.TruncatedBefore,
+		ByteLimited:     resp.ByteLimited,
 	})
 }
 
@@ -119,10 +145,13 @@ func (s *Server) PushTaskListCategories(w http.ResponseWriter, r *http.Request)
 		writeJSON(w, 400, []pushAck{{Error: "invalid json"}})
 		return
 	}
+	if s.pushTooLarge(w, r, len(req.Items)) {
+		return
+	}
 
 	acks := make([]pushAck, 0, len(req.Items))
 
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to begin transaction")
 		writeJSON(w, 500, []pushAck{{Error: "transaction error"}})
@@ -155,24 +184,42 @@ func (s *Server) PushTaskListCategories(w http.ResponseWriter, r *http.Request)
 }
 
 // PullTaskListCategories handles GET /v1/sync/task_list_categories/pull
+// Also accepts POST with a JSON body {cursor, limit} (see parsePullParams)
 func (s *Server) PullTaskListCategories(w http.ResponseWriter, r *http.Request) {
 	userID := auth.UserID(r.Context())
 	ctx := r.Context()
 	logger := log.Ctx(ctx)
 
-	limit := parseLimit(r.URL.Query().Get("limit"), 500, 1000)
-	cur, ok := syncx.DecodeCursor(r.URL.Query().Get("cursor"))
-	if !ok {
-		cur = syncx.Cursor{Ms: 0, UID: uuid.Nil}
+	// Parse pull params (query string for GET, JSON body for POST)
+	params, err := s.parseCappedPullParams(w, r, "task_list_category")
+	if err != nil {
+		logger.Warn().Err(err).Msg("invalid pull request")
+		writeError(w, r, 400, pullParamsError(err))
+		return
 	}
 
 	logger.Info().
 		Str("user_id", userID).
-		Int("limit", limit).
-		Str("cursor", r.URL.Query().Get("cursor")).
+		Int("limit", params.Limit).
+		Str("cursor", params.RawCursor).
 		Msg("sync_pull_started: task_list_categories")
 
-	resp, err := s.TaskListCategorySvc.PullTaskListCategories(ctx, userID, cur, limit)
+	// A cursor repeated while newer data exists is a client hot loop (see PullLoopGuard)
+	if !s.checkPullLoop(w, r, "task_list_category", params) {
+		return
+	}
+
+	// Long-poll: with ?wait=, hold an up-to-date pull until changes arrive (see waitForPullChanges)
+	s.waitForPullChanges(r, "task_list_category", params)
+
+	// Conditional pull: nothing newer than the cursor, skip the query
+	if s.pullUpToDate(r, "task_list_category", params) {
+		logger.Info().Str("user_id", userID).Msg("sync_pull_not_modified: task_list_categories")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	resp, err := s.TaskListCategorySvc.PullTaskListCategories(ctx, userID, params.Cursor, params.Limit)
 	if err != nil {
 		writeError(w, r, 500, "pull failed")
 		return
@@ -185,9 +232,15 @@ func (s *Server) PullTaskListCategories(w http.ResponseWriter, r *http.Request)
 		Bool("has_next_page", resp.NextCursor != nil).
 		Msg("sync_pull_completed: task_list_categories")
 
+	setPaginationLinks(w, r, resp.NextCursor)
 	writeJSON(w, 200, pullResp{
-		Upserts:    resp.Upserts,
-		Deletes:    resp.Deletes,
-		NextCursor: resp.NextCursor,
+		Upserts:         resp.Upserts,
+		Deletes:         resp.Deletes,
+		Inserts:         pullInserts(params, resp),
+		NextCursor:      pullNextCursor(params, resp),
+		WipedAt:         s.entityWipedAt(r, "task_list_category"),
+		Truncated:       params.TruncatedBefore != nil,
+		TruncatedBefore: params.TruncatedBefore,
+		ByteLimited:     resp.ByteLimited,
 	})
 }
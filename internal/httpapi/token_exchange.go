@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
@@ -60,7 +61,7 @@ func (s *Server) TokenExchange(w http.ResponseWriter, r *http.Request) {
 	// Validate incoming MCP OAuth token
 	// This extracts the user identity (sub claim) from the MCP token
 	jwtCfg := s.getJWTConfig(r)
-	userID, _, err := auth.ValidateToken(incomingToken, jwtCfg)
+	userID, incomingClaims, err := auth.ValidateToken(incomingToken, jwtCfg)
 	if err != nil {
 		log.Ctx(ctx).Warn().
 			Err(err).
@@ -109,6 +110,11 @@ func (s *Server) TokenExchange(w http.ResponseWriter, r *http.Request) {
 		"exchanged_from": "mcp_oauth",  // Exchange source metadata
 	}
 
+	// Carry the MCP token's scopes over so a read-only token stays read-only
+	if scopes := auth.TokenScopes(incomingClaims); len(scopes) > 0 {
+		claims["scope"] = strings.Join(scopes, " ")
+	}
+
 	// Sign backend JWT using RS256 (if configured) or HS256 (fallback)
 	// See auth.SignBackendToken and JWTCfg.BackendRSAPrivateKeyPEM for RS256 migration details
 	tokenString, err := auth.SignBackendToken(claims, jwtCfg)
@@ -0,0 +1,77 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/google/uuid"
+)
+
+func TestUniqueFields_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	srv := &Server{
+		DB:              pool,
+		RateLimitConfig: DefaultRateLimitConfig,
+		NoteSvc:         syncservice.NewNoteService(pool),
+	}
+	srv.NoteSvc.Cfg.UniqueFields = map[string]string{"note": "externalId"}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+	session := createTestSession(t, router)
+
+	w := makeRequestWithSession(t, router, "POST", "/v1/notes", map[string]any{"title": "First", "externalId": "ext-1"}, session)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create failed: %d %s", w.Code, w.Body.String())
+	}
+	var first syncservice.RESTItem
+	if err := json.NewDecoder(w.Body).Decode(&first); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	// A second live item can't take the value
+	w = makeRequestWithSession(t, router, "POST", "/v1/notes", map[string]any{"title": "Second", "externalId": "ext-1"}, session)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Expected 409, got %d %s", w.Code, w.Body.String())
+	}
+	var errResp errorResponse
+	if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+		t.Fatalf("Failed to decode error: %v", err)
+	}
+	if errResp.ConflictUID != first.UID {
+		t.Errorf("Expected conflictUid %s, got %q", first.UID, errResp.ConflictUID)
+	}
+
+	// Sync pushes get an ack error
+	item := map[string]any{"uid": uuid.New().String(), "title": "Pushed", "externalId": "ext-1", "updatedTs": "2025-11-03T10:00:00Z"}
+	w = makeRequestWithSession(t, router, "POST", "/v1/sync/notes/push", pushReq{Items: []map[string]any{item}}, session)
+	var acks []pushAck
+	if err := json.NewDecoder(w.Body).Decode(&acks); err != nil || len(acks) != 1 {
+		t.Fatalf("Failed to decode push acks: %v %s", err, w.Body.String())
+	}
+	if acks[0].Error == "" {
+		t.Error("Expected push ack error for a duplicate externalId")
+	}
+
+	// Updating the holder itself is fine
+	w = makeRequestWithSession(t, router, "PATCH", "/v1/notes/"+first.UID, map[string]any{"title": "First (edited)"}, session)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Patch failed: %d %s", w.Code, w.Body.String())
+	}
+
+	// Deleting the holder frees the value
+	if w := makeRequestWithSession(t, router, "DELETE", "/v1/notes/"+first.UID, nil, session); w.Code != http.StatusOK {
+		t.Fatalf("Delete failed: %d %s", w.Code, w.Body.String())
+	}
+	w = makeRequestWithSession(t, router, "POST", "/v1/notes", map[string]any{"title": "Reuse", "externalId": "ext-1"}, session)
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected reuse after delete to succeed, got %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// syncEntityOps are the path segments after /v1/sync/{entity}/ that name a sync
+// operation, so a request for one of them with an unrouted entity is a typo'd or
+// unsupported entity type rather than some other missing route
+var syncEntityOps = map[string]bool{
+	"push":        true,
+	"pull":        true,
+	"pull_by_uid": true,
+	"diff":        true,
+	"wipe":        true,
+}
+
+// ParseUnknownEntityStatus parses the status returned for sync routes naming an
+// unknown entity type: "404" (the default) or "400" for clients that treat a bad
+// entity name as a malformed request
+func ParseUnknownEntityStatus(v string) (int, error) {
+	switch v {
+	case "", "404":
+		return http.StatusNotFound, nil
+	case "400":
+		return http.StatusBadRequest, nil
+	}
+	return 0, fmt.Errorf("unknown entity status must be 400 or 404, got %q", v)
+}
+
+// validEntities lists the enabled entity types in catalog order
+func (s *Server) validEntities() []string {
+	names := make([]string, 0, len(entityCatalog))
+	for _, e := range entityCatalog {
+		if s.EntityEnabled(e.Name) {
+			names = append(names, e.Name)
+		}
+	}
+	return names
+}
+
+// writeUnknownEntity rejects a sync route naming an entity type that does not exist
+// or is disabled, listing the valid ones in the message and in validEntities
+func (s *Server) writeUnknownEntity(w http.ResponseWriter, r *http.Request, entity string) {
+	code := s.UnknownEntityStatus
+	if code == 0 {
+		code = http.StatusNotFound
+	}
+	valid := s.validEntities()
+	writeJSON(w, code, errorResponse{
+		Error:         fmt.Sprintf("unknown entity %q; valid entity types: %s", entity, strings.Join(valid, ", ")),
+		CorrelationID: GetCorrelationID(r.Context()),
+		RequestID:     errorRequestID(r.Context()),
+		ValidEntities: valid,
+	})
+}
+
+// NotFoundHandler answers unrouted paths. /v1/sync/{entity}/{op} with an unknown or
+// disabled entity gets writeUnknownEntity; anything else gets chi's plain 404.
+// Per-entity sync routes are only registered for enabled entities, so these requests
+// never reach the session and auth middleware.
+func (s *Server) NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	if rest, ok := strings.CutPrefix(r.URL.Path, "/v1/sync/"); ok {
+		if entity, op, ok := strings.Cut(rest, "/"); ok && syncEntityOps[op] {
+			if _, known := syncEntityTables[entity]; !known || !s.EntityEnabled(entity) {
+				s.writeUnknownEntity(w, r, entity)
+				return
+			}
+		}
+	}
+	http.NotFound(w, r)
+}
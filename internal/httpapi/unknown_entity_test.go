@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/auth"
+)
+
+func TestUnknownSyncEntity(t *testing.T) {
+	tests := []struct {
+		name   string
+		srv    *Server
+		method string
+		path   string
+		want   int
+	}{
+		{"unknown push", &Server{}, "POST", "/v1/sync/widgets/push", http.StatusNotFound},
+		{"unknown pull", &Server{}, "GET", "/v1/sync/widgets/pull", http.StatusNotFound},
+		{"strict", &Server{UnknownEntityStatus: http.StatusBadRequest}, "POST", "/v1/sync/widgets/push", http.StatusBadRequest},
+		{"disabled", &Server{DisabledEntities: map[string]bool{"task_list_categories": true}}, "POST", "/v1/sync/task_list_categories/push", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := tt.srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if w.Code != tt.want {
+				t.Fatalf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+
+			var resp errorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if want := tt.srv.validEntities(); !reflect.DeepEqual(resp.ValidEntities, want) {
+				t.Errorf("validEntities = %v, want %v", resp.ValidEntities, want)
+			}
+			if !strings.Contains(resp.Error, "notes, tasks") {
+				t.Errorf("Expected the valid entity types in the message, got %q", resp.Error)
+			}
+		})
+	}
+}
+
+func TestUnknownSyncEntity_OtherPathsUnchanged(t *testing.T) {
+	srv := &Server{}
+	router := srv.Routes(auth.JWTCfg{HS256Secret: "test-secret", DevMode: true})
+
+	// Not a sync operation: plain 404
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/sync/widgets/frobnicate", nil))
+	if w.Code != http.StatusNotFound || strings.Contains(w.Body.String(), "validEntities") {
+		t.Errorf("Expected a plain 404, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// Known entity with the wrong method: still 405
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/sync/notes/push", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET on a known push route, got %d", w.Code)
+	}
+}
+
+func TestParseUnknownEntityStatus(t *testing.T) {
+	for v, want := range map[string]int{"": 404, "404": 404, "400": 400} {
+		if got, err := ParseUnknownEntityStatus(v); err != nil || got != want {
+			t.Errorf("ParseUnknownEntityStatus(%q) = %d, %v; want %d", v, got, err, want)
+		}
+	}
+	if _, err := ParseUnknownEntityStatus("422"); err == nil {
+		t.Error("Expected an error for 422")
+	}
+}
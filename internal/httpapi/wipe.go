@@ -5,9 +5,26 @@ import (
 	"net/http"
 
 	"github.com/erauner12/toolbridge-api/internal/auth"
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/service/syncservice"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
 )
 
+// syncTables lists every per-user entity table.
+// Order matters: children come before parents (e.g., chat_message before chat)
+var syncTables = []string{"chat_message", "comment", "chat", "task", "task_list", "task_list_category", "note"}
+
+// syncEntityTables maps the entity names used in sync URLs to their tables
+var syncEntityTables = func() map[string]string {
+	tables := make(map[string]string, len(entityCatalog))
+	for _, e := range entityCatalog {
+		tables[e.Name] = e.Table
+	}
+	return tables
+}()
+
 type wipeRequest struct {
 	Confirm string `json:"confirm"` // Must be "WIPE"
 	Mode    string `json:"mode"`    // "hard" (only mode supported currently)
@@ -56,7 +73,7 @@ func (s *Server) WipeAccount(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ctx := r.Context()
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		log.Error().Err(err).Str("userId", userID).Msg("Failed to begin transaction")
 		writeError(w, r, http.StatusInternalServerError, "transaction begin failed")
@@ -83,12 +100,11 @@ func (s *Server) WipeAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Delete all entity rows for this user
-	// Order matters: delete children before parents (e.g., chat_message before chat)
+	// Delete all entity rows for this user (syncTables is ordered children-first)
+	s.syncCfg().ItemCache.InvalidateTable(tx, "")
 	deleted := make(map[string]int)
-	tables := []string{"chat_message", "comment", "chat", "task", "task_list", "task_list_category", "note"}
 
-	for _, table := range tables {
+	for _, table := range syncTables {
 		var count int
 		err := tx.QueryRow(ctx, `
 			WITH del AS (
@@ -105,6 +121,16 @@ func (s *Server) WipeAccount(w http.ResponseWriter, r *http.Request) {
 		deleted[table] = count
 	}
 
+	// Purge and per-entity wipe markers only matter to devices on the old epoch,
+	// which are invalidated anyway; session undo logs would resurrect wiped items
+	for _, table := range []string{"purge_marker", "entity_wipe", "session_change"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE owner_id = $1`, userID); err != nil {
+			log.Error().Err(err).Str("userId", userID).Msg("Failed to delete " + table + " rows")
+			writeError(w, r, http.StatusInternalServerError, "delete failed: "+table)
+			return
+		}
+	}
+
 	// Commit transaction
 	if err := tx.Commit(ctx); err != nil {
 		log.Error().Err(err).Str("userId", userID).Msg("Failed to commit wipe transaction")
@@ -128,3 +154,81 @@ func (s *Server) WipeAccount(w http.ResponseWriter, r *http.Request) {
 		Deleted: deleted,
 	})
 }
+
+type entityWipeResponse struct {
+	Entity  string `json:"entity"`
+	Deleted int    `json:"deleted"`
+	WipedAt string `json:"wipedAt"`
+}
+
+// WipeEntity permanently deletes one entity type for the authenticated user.
+//
+// Unlike WipeAccount this does not bump the epoch or end sessions: other entity
+// types stay in sync. A per-entity wipe marker is recorded instead; pulls of the
+// entity return it as wipedAt, and clients that see a new value drop their local
+// copy of that entity and pull it again from an empty cursor.
+//
+// Requires:
+// - Active sync session and current epoch (entity sync group)
+// - Confirmation string "WIPE" in request body
+//
+// Returns:
+// - 200: Wipe successful, returns deletion count and wipe timestamp
+// - 400: Missing confirmation or invalid request
+// - 404: Unknown entity type
+// - 500: Database error
+func (s *Server) WipeEntity(w http.ResponseWriter, r *http.Request) {
+	userID := auth.UserID(r.Context())
+	ctx := r.Context()
+	logger := log.Ctx(ctx)
+
+	entity := chi.URLParam(r, "entity")
+	table, ok := syncEntityTables[entity]
+	if !ok || !s.EntityEnabled(entity) {
+		s.writeUnknownEntity(w, r, entity)
+		return
+	}
+
+	var req wipeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Confirm != "WIPE" {
+		writeError(w, r, http.StatusBadRequest, "confirmation required: must send {\"confirm\":\"WIPE\"}")
+		return
+	}
+
+	tx, err := db.Begin(ctx, s.DB)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to begin transaction")
+		writeError(w, r, http.StatusInternalServerError, "transaction begin failed")
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	deleted, wipedAtMs, err := syncservice.WipeEntityTx(ctx, tx, s.syncCfg().ItemCache, table, userID, syncx.NowMs())
+	if err != nil {
+		logger.Error().Err(err).Str("table", table).Msg("failed to wipe entity")
+		writeError(w, r, http.StatusInternalServerError, "delete failed: "+table)
+		return
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error().Err(err).Msg("failed to commit entity wipe")
+		writeError(w, r, http.StatusInternalServerError, "commit failed")
+		return
+	}
+
+	logger.Info().
+		Str("userId", userID).
+		Str("entity", entity).
+		Int("deleted", deleted).
+		Msg("Entity wiped successfully")
+
+	writeJSON(w, http.StatusOK, entityWipeResponse{
+		Entity:  entity,
+		Deleted: deleted,
+		WipedAt: syncx.RFC3339(wipedAtMs),
+	})
+}
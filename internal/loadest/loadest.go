@@ -0,0 +1,103 @@
+// Package loadest estimates recent server load from request volume so sync
+// clients can shrink batches and pace themselves before they hit rate limits.
+//
+// The estimator counts requests in one-second buckets over a sliding one-minute
+// window and reports load as that count relative to a configured capacity
+// (1.0 = at capacity). It is in-process only: each replica reports its own load.
+package loadest
+
+import (
+	"math"
+	"sync"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// windowSeconds is the sliding window length (and number of buckets)
+const windowSeconds = 60
+
+// maxBackoffMs caps the recommended inter-batch delay
+const maxBackoffMs = 10000
+
+// Estimator tracks request volume over a sliding one-minute window
+type Estimator struct {
+	capacity int         // requests per window considered full load
+	clock    syncx.Clock // time source (RealClock in production)
+
+	mu      sync.Mutex
+	counts  [windowSeconds]int64
+	seconds [windowSeconds]int64 // unix second each bucket was last used for
+}
+
+// Hints are load-adjusted recommendations for sync clients
+type Hints struct {
+	RecommendedBatch     int
+	RecommendedBackoffMs int
+	CurrentLoad          float64
+}
+
+// New creates an Estimator that treats capacityPerMinute requests as full load
+func New(capacityPerMinute int) *Estimator {
+	return NewWithClock(capacityPerMinute, syncx.RealClock{})
+}
+
+// NewWithClock creates an Estimator with an explicit time source (for tests)
+func NewWithClock(capacityPerMinute int, clock syncx.Clock) *Estimator {
+	if capacityPerMinute < 1 {
+		capacityPerMinute = 1
+	}
+	return &Estimator{capacity: capacityPerMinute, clock: clock}
+}
+
+// Record counts one request at the current time
+func (e *Estimator) Record() {
+	sec := e.clock.NowMs() / 1000
+	i := sec % windowSeconds
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.seconds[i] != sec {
+		e.seconds[i] = sec
+		e.counts[i] = 0
+	}
+	e.counts[i]++
+}
+
+// Load returns requests in the last minute relative to capacity, rounded to two decimals
+func (e *Estimator) Load() float64 {
+	sec := e.clock.NowMs() / 1000
+
+	e.mu.Lock()
+	var total int64
+	for i := range e.counts {
+		if sec-e.seconds[i] < windowSeconds {
+			total += e.counts[i]
+		}
+	}
+	e.mu.Unlock()
+
+	return math.Round(float64(total)/float64(e.capacity)*100) / 100
+}
+
+// Hints derives batch size and pacing recommendations from the current load
+// Below half capacity clients get the base batch and no delay; beyond that batches
+// shrink and the suggested delay grows with load (capped at maxBackoffMs).
+func (e *Estimator) Hints(baseBatch int) Hints {
+	load := e.Load()
+	h := Hints{RecommendedBatch: baseBatch, CurrentLoad: load}
+
+	switch {
+	case load >= 1:
+		h.RecommendedBatch = baseBatch / 5
+	case load >= 0.5:
+		h.RecommendedBatch = baseBatch / 2
+	}
+	if h.RecommendedBatch < 1 {
+		h.RecommendedBatch = 1
+	}
+
+	if load >= 0.5 {
+		h.RecommendedBackoffMs = int(math.Min(2000*(load-0.5), maxBackoffMs))
+	}
+	return h
+}
@@ -0,0 +1,73 @@
+package loadest
+
+import (
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestEstimator_LoadSlidingWindow(t *testing.T) {
+	clock := syncx.NewFakeClock(1_700_000_000_000)
+	est := NewWithClock(100, clock)
+
+	for i := 0; i < 50; i++ {
+		est.Record()
+	}
+	if got := est.Load(); got != 0.5 {
+		t.Errorf("Load() = %v, want 0.5", got)
+	}
+
+	clock.Advance(30_000)
+	for i := 0; i < 25; i++ {
+		est.Record()
+	}
+	if got := est.Load(); got != 0.75 {
+		t.Errorf("Load() after 30s = %v, want 0.75", got)
+	}
+
+	// First batch ages out of the window
+	clock.Advance(31_000)
+	if got := est.Load(); got != 0.25 {
+		t.Errorf("Load() after 61s = %v, want 0.25", got)
+	}
+
+	clock.Advance(60_000)
+	if got := est.Load(); got != 0 {
+		t.Errorf("Load() after idle minute = %v, want 0", got)
+	}
+}
+
+func TestEstimator_Hints(t *testing.T) {
+	tests := []struct {
+		name        string
+		requests    int
+		wantBatch   int
+		wantBackoff int
+	}{
+		{name: "idle", requests: 0, wantBatch: 500, wantBackoff: 0},
+		{name: "light", requests: 40, wantBatch: 500, wantBackoff: 0},
+		{name: "busy", requests: 75, wantBatch: 250, wantBackoff: 500},
+		{name: "at capacity", requests: 100, wantBatch: 100, wantBackoff: 1000},
+		{name: "overloaded", requests: 1000, wantBatch: 100, wantBackoff: maxBackoffMs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			est := NewWithClock(100, syncx.NewFakeClock(1_700_000_000_000))
+			for i := 0; i < tt.requests; i++ {
+				est.Record()
+			}
+
+			h := est.Hints(500)
+			if h.RecommendedBatch != tt.wantBatch {
+				t.Errorf("RecommendedBatch = %d, want %d", h.RecommendedBatch, tt.wantBatch)
+			}
+			if h.RecommendedBackoffMs != tt.wantBackoff {
+				t.Errorf("RecommendedBackoffMs = %d, want %d", h.RecommendedBackoffMs, tt.wantBackoff)
+			}
+			if want := float64(tt.requests) / 100; h.CurrentLoad != want {
+				t.Errorf("CurrentLoad = %v, want %v", h.CurrentLoad, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Package payloadcrypt implements optional application-level encryption of
+// entity payloads at rest.
+//
+// Envelope encryption: every payload is sealed with a fresh random data key
+// (AES-256-GCM), and the data key is wrapped with the configured key-encryption
+// key (KEK). The stored JSONB keeps sync/relationship fields in plaintext so
+// indexes and metadata queries still work, plus an "_enc" envelope holding the
+// full encrypted payload:
+//
+//	{"uid": "...", "sync": {...}, "parentUid": "...", "_enc": {"v": 1, "kid": "...", "dek": "...", "ct": "..."}}
+//
+// Rows written without encryption (or before it was enabled) are returned as-is,
+// so enabling encryption does not require a data migration.
+package payloadcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EnvelopeField is the payload key holding the encrypted envelope
+const EnvelopeField = "_enc"
+
+// envelopeVersion is the current envelope format version
+const envelopeVersion = 1
+
+// macKeyLabel derives the MAC key from the key-encryption key, so the KEK itself
+// is never used for two purposes
+const macKeyLabel = "toolbridge payload mac v1"
+
+// PlaintextFields are payload fields kept readable at rest.
+// These carry sync metadata and relationships used by indexes and filters;
+// everything else (titles, content, tags, ...) is only stored encrypted.
+var PlaintextFields = []string{
+	"uid",
+	"sync",
+	"parentUid",
+	"parentType",
+	"chatUid",
+	"categoryUid",
+	"taskListUid",
+}
+
+// ErrNoKey is returned when reading an encrypted payload without a configured key
+var ErrNoKey = errors.New("payload is encrypted but no encryption key is configured")
+
+// envelope is the stored form of an encrypted payload
+type envelope struct {
+	V   int    `json:"v"`
+	KID string `json:"kid"` // key ID of the KEK that wrapped the data key
+	DEK string `json:"dek"` // base64(nonce || wrapped data key)
+	CT  string `json:"ct"`  // base64(nonce || ciphertext of the full payload JSON)
+}
+
+// Cipher seals and opens payloads with a key-encryption key
+type Cipher struct {
+	kek    cipher.AEAD
+	keyID  string
+	macKey []byte
+}
+
+// NewCipher creates a Cipher from a 32-byte key-encryption key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	kek, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(key)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(macKeyLabel))
+	return &Cipher{kek: kek, keyID: hex.EncodeToString(sum[:4]), macKey: mac.Sum(nil)}, nil
+}
+
+// ParseKey decodes a base64-encoded 32-byte key (as configured via env)
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be base64: %w", err)
+	}
+	return key, nil
+}
+
+// KeyID returns a short, non-secret identifier for the configured key
+func (c *Cipher) KeyID() string {
+	return c.keyID
+}
+
+// MAC returns a keyed digest of data (HMAC-SHA256 under a key derived from the KEK)
+// Equal inputs give equal digests, but without the key a stored digest can't be
+// checked against a guessed plaintext.
+func (c *Cipher) MAC(data []byte) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// IsSealed reports whether a stored payload holds an encrypted envelope
+func IsSealed(stored map[string]any) bool {
+	_, ok := stored[EnvelopeField]
+	return ok
+}
+
+// Seal encrypts a payload and returns the JSON to store in payload_json
+func (c *Cipher) Seal(payload map[string]any) ([]byte, error) {
+	plaintext, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	dek, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := seal(dek, plaintext, aad(payload))
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := seal(c.kek, dataKey, []byte(c.keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]any, len(PlaintextFields)+1)
+	for _, field := range PlaintextFields {
+		if v, ok := payload[field]; ok {
+			stored[field] = v
+		}
+	}
+	stored[EnvelopeField] = envelope{
+		V:   envelopeVersion,
+		KID: c.keyID,
+		DEK: base64.StdEncoding.EncodeToString(wrapped),
+		CT:  base64.StdEncoding.EncodeToString(ct),
+	}
+	return json.Marshal(stored)
+}
+
+// Open decrypts a stored payload. Plaintext payloads are returned unchanged.
+// A nil Cipher can still read plaintext payloads but returns ErrNoKey for sealed ones.
+func (c *Cipher) Open(stored map[string]any) (map[string]any, error) {
+	raw, ok := stored[EnvelopeField]
+	if !ok {
+		return stored, nil
+	}
+	if c == nil {
+		return nil, ErrNoKey
+	}
+
+	// Round-trip through JSON: pgx scans JSONB into generic maps
+	envJSON, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var env envelope
+	if err := json.Unmarshal(envJSON, &env); err != nil {
+		return nil, fmt.Errorf("invalid payload envelope: %w", err)
+	}
+	if env.V != envelopeVersion {
+		return nil, fmt.Errorf("unsupported payload envelope version %d", env.V)
+	}
+	if env.KID != c.keyID {
+		return nil, fmt.Errorf("payload encrypted with unknown key %q", env.KID)
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(env.DEK)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload envelope: %w", err)
+	}
+	dataKey, err := open(c.kek, wrapped, []byte(env.KID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	dek, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ct, err := base64.StdEncoding.DecodeString(env.CT)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload envelope: %w", err)
+	}
+	plaintext, err := open(dek, ct, aad(stored))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// aad binds the ciphertext to the item's uid so envelopes can't be swapped between rows
+func aad(payload map[string]any) []byte {
+	uid, _ := payload["uid"].(string)
+	return []byte(uid)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext and returns nonce || ciphertext
+func seal(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+// open decrypts nonce || ciphertext
+func open(aead cipher.AEAD, data, additional []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ct := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ct, additional)
+}
@@ -0,0 +1,127 @@
+package payloadcrypt
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func testCipher(t *testing.T, fill byte) *Cipher {
+	t.Helper()
+	c, err := NewCipher(bytes.Repeat([]byte{fill}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	return c
+}
+
+// stored simulates a payload_json round-trip through JSONB
+func stored(t *testing.T, raw []byte) map[string]any {
+	t.Helper()
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatalf("unmarshal stored payload: %v", err)
+	}
+	return m
+}
+
+func TestSealOpen_RoundTrip(t *testing.T) {
+	c := testCipher(t, 1)
+	payload := map[string]any{
+		"uid":       "c1d9b7dc-0000-0000-0000-000000000000",
+		"parentUid": "p-1",
+		"title":     "secret title",
+		"content":   "secret content",
+	}
+
+	raw, err := c.Seal(payload)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if bytes.Contains(raw, []byte("secret")) {
+		t.Errorf("Sealed payload leaks plaintext content: %s", raw)
+	}
+
+	s := stored(t, raw)
+	if !IsSealed(s) {
+		t.Fatal("Expected stored payload to be sealed")
+	}
+	if s["uid"] != payload["uid"] || s["parentUid"] != "p-1" {
+		t.Errorf("Expected plaintext sync fields to be preserved, got %v", s)
+	}
+
+	opened, err := c.Open(s)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if opened["title"] != "secret title" || opened["content"] != "secret content" {
+		t.Errorf("Round-trip mismatch: %v", opened)
+	}
+}
+
+func TestOpen_PlaintextPassthrough(t *testing.T) {
+	plain := map[string]any{"uid": "u1", "title": "hello"}
+
+	var nilCipher *Cipher
+	for name, c := range map[string]*Cipher{"with key": testCipher(t, 1), "without key": nilCipher} {
+		t.Run(name, func(t *testing.T) {
+			opened, err := c.Open(plain)
+			if err != nil {
+				t.Fatalf("Open: %v", err)
+			}
+			if opened["title"] != "hello" {
+				t.Errorf("Expected plaintext payload unchanged, got %v", opened)
+			}
+		})
+	}
+}
+
+func TestOpen_Failures(t *testing.T) {
+	c := testCipher(t, 1)
+	raw, err := c.Seal(map[string]any{"uid": "u1", "title": "x"})
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+
+	t.Run("no key configured", func(t *testing.T) {
+		var nilCipher *Cipher
+		if _, err := nilCipher.Open(stored(t, raw)); err != ErrNoKey {
+			t.Errorf("Expected ErrNoKey, got %v", err)
+		}
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		if _, err := testCipher(t, 2).Open(stored(t, raw)); err == nil {
+			t.Error("Expected error opening with a different key")
+		}
+	})
+
+	t.Run("envelope moved to another row", func(t *testing.T) {
+		s := stored(t, raw)
+		s["uid"] = "u2"
+		if _, err := c.Open(s); err == nil {
+			t.Error("Expected error when uid does not match the sealed payload")
+		}
+	})
+}
+
+func TestNewCipher_KeyLength(t *testing.T) {
+	if _, err := NewCipher(make([]byte, 16)); err == nil {
+		t.Error("Expected error for 16-byte key")
+	}
+}
+
+func TestMAC(t *testing.T) {
+	a, b := testCipher(t, 1), testCipher(t, 2)
+	data := []byte(`{"title":"secret"}`)
+
+	if !bytes.Equal(a.MAC(data), a.MAC(data)) {
+		t.Error("MAC must be deterministic for one key")
+	}
+	if bytes.Equal(a.MAC(data), b.MAC(data)) {
+		t.Error("MAC must depend on the key")
+	}
+	if bytes.Equal(a.MAC(data), a.MAC([]byte(`{"title":"other"}`))) {
+		t.Error("different inputs need different MACs")
+	}
+}
@@ -0,0 +1,66 @@
+package syncservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ParseAppendOnlyEntities parses a comma-separated list of entity tables from config,
+// e.g. "chat_message" for chat transcripts that must stay as written
+func ParseAppendOnlyEntities(v string) (map[string]bool, error) {
+	tables := map[string]bool{}
+	for _, table := range strings.Split(v, ",") {
+		if table = strings.TrimSpace(table); table == "" {
+			continue
+		}
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in append-only entities", table)
+		}
+		tables[table] = true
+	}
+	return tables, nil
+}
+
+// AppendOnly reports whether an entity table is append-only
+func (c *Config) AppendOnly(table string) bool {
+	return c.AppendOnlyEntities[table]
+}
+
+// AppendOnlyError reports a write that would change an existing item of an append-only entity
+type AppendOnlyError struct {
+	Table string
+	UID   uuid.UUID
+}
+
+func (e *AppendOnlyError) Error() string {
+	return fmt.Sprintf("%s %s is append-only: it can be deleted but not edited", strings.ReplaceAll(e.Table, "_", " "), e.UID)
+}
+
+// checkAppendOnly returns an *AppendOnlyError if a write to an append-only entity would
+// change an item that already exists. Deletes always pass, and so does a write whose tie
+// key (see lwwTieKey) matches the stored one: that is a retry of the write that created
+// the item, which must stay an idempotent no-op. Deleted items cannot be restored.
+func (c *Config) checkAppendOnly(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID, tieKey []byte, deleted bool) error {
+	if !c.AppendOnlyEntities[table] || deleted {
+		return nil
+	}
+	var stored []byte
+	err := tx.QueryRow(ctx,
+		`SELECT tie_key FROM `+table+` WHERE owner_id = $1 AND uid = $2`,
+		userID, uid).Scan(&stored)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check append-only: %w", err)
+	}
+	if stored != nil && bytes.Equal(stored, tieKey) {
+		return nil
+	}
+	return &AppendOnlyError{Table: table, UID: uid}
+}
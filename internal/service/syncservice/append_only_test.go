@@ -0,0 +1,63 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseAppendOnlyEntities(t *testing.T) {
+	got, err := ParseAppendOnlyEntities(" chat_message, comment ,")
+	if err != nil {
+		t.Fatalf("ParseAppendOnlyEntities() error = %v", err)
+	}
+	if len(got) != 2 || !got["chat_message"] || !got["comment"] {
+		t.Errorf("ParseAppendOnlyEntities() = %v", got)
+	}
+
+	if got, err := ParseAppendOnlyEntities(""); err != nil || len(got) != 0 {
+		t.Errorf("empty list: got %v, %v", got, err)
+	}
+	if _, err := ParseAppendOnlyEntities("chat_messages"); err == nil {
+		t.Error("expected an error for an unknown entity")
+	}
+}
+
+// TestAppendOnly_Integration checks an append-only entity accepts creates, identical
+// re-pushes and deletes, and refuses edits
+func TestAppendOnly_Integration(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	svc := NewNoteService(pool)
+
+	svc.Cfg.AppendOnlyEntities = map[string]bool{"note": true}
+
+	uid := svc.Cfg.NewUID()
+	first := lwwWrite{ms: 1_700_000_000_000, title: "as written"}
+	if err := pushNote(ctx, svc, userID, uid, first); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if err := pushNote(ctx, svc, userID, uid, first); err != nil {
+		t.Errorf("Identical re-push refused: %v", err)
+	}
+	if err := pushNote(ctx, svc, userID, uid, lwwWrite{ms: first.ms + 1, title: "edited"}); err == nil {
+		t.Error("Expected the edit push to be refused")
+	}
+
+	_, err := svc.ApplyNoteMutation(ctx, userID, map[string]any{"uid": uid.String(), "title": "edited"}, MutationOpts{})
+	var appendOnlyErr *AppendOnlyError
+	if !errors.As(err, &appendOnlyErr) {
+		t.Errorf("REST edit: expected *AppendOnlyError, got %v", err)
+	}
+
+	deleted, err := svc.ApplyNoteMutation(ctx, userID, map[string]any{"uid": uid.String(), "title": "as written"}, MutationOpts{SetDeleted: true})
+	if err != nil {
+		t.Fatalf("Delete refused: %v", err)
+	}
+	if deleted.DeletedAt == nil {
+		t.Error("Expected the item to be deleted")
+	}
+	if got, _ := readNote(ctx, pool, userID, uid); got.title != "as written" {
+		t.Errorf("Stored title changed to %q", got.title)
+	}
+}
@@ -0,0 +1,72 @@
+package syncservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// DefaultArrayLimits is the built-in per-entity map of array-valued payload fields to
+// their maximum number of elements. Unbounded tag lists bloat payload indexes and slow
+// tag filters, so tags are capped by default.
+var DefaultArrayLimits = map[string]map[string]int{
+	"note": {"tags": 64},
+	"task": {"tags": 64},
+}
+
+// ParseArrayLimits parses array size caps from config: a JSON object mapping entity tables
+// to field limits, e.g. {"note":{"tags":32,"links":100}}. "{}" disables them.
+func ParseArrayLimits(v string) (map[string]map[string]int, error) {
+	var limits map[string]map[string]int
+	if err := json.Unmarshal([]byte(v), &limits); err != nil {
+		return nil, fmt.Errorf("array limits must be a JSON object of entity field limits: %w", err)
+	}
+	for table, fields := range limits {
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in array limits", table)
+		}
+		for field, limit := range fields {
+			if limit <= 0 {
+				return nil, fmt.Errorf("%s.%s: limit must be positive, got %d", table, field, limit)
+			}
+		}
+	}
+	return limits, nil
+}
+
+// validateArrayLimits checks an item's array fields against the table's size caps
+// Returns a *syncx.FieldError for the first oversized field. Fields that are absent,
+// null or not arrays are left to other validation.
+func (c *Config) validateArrayLimits(table string, item map[string]any) error {
+	rules := c.ArrayLimits[table]
+	fields := make([]string, 0, len(rules))
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields) // report the same field first on every attempt
+
+	for _, field := range fields {
+		arr, ok := item[field].([]any)
+		if !ok {
+			continue
+		}
+		if limit := rules[field]; len(arr) > limit {
+			return &syncx.FieldError{Field: field, Reason: fmt.Sprintf("must have at most %d elements, got %d", limit, len(arr))}
+		}
+	}
+	return nil
+}
+
+// validatePayloadFields applies the declarative per-field rules (field formats, array
+// caps, then text length caps) to an item about to be written
+func (c *Config) validatePayloadFields(table string, item map[string]any) error {
+	if err := c.validateFieldFormats(table, item); err != nil {
+		return err
+	}
+	if err := c.validateArrayLimits(table, item); err != nil {
+		return err
+	}
+	return c.validateTextLimits(table, item)
+}
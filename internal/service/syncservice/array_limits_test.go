@@ -0,0 +1,65 @@
+package syncservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestValidateArrayLimits(t *testing.T) {
+	tags := func(n int) []any {
+		arr := make([]any, n)
+		for i := range arr {
+			arr[i] = "t"
+		}
+		return arr
+	}
+
+	cfg := DefaultConfig()
+
+	// Default: 64 tags per note
+	if err := cfg.validateArrayLimits("note", map[string]any{"tags": tags(64)}); err != nil {
+		t.Errorf("64 tags should pass, got %v", err)
+	}
+	var fieldErr *syncx.FieldError
+	if err := cfg.validateArrayLimits("note", map[string]any{"tags": tags(65)}); !errors.As(err, &fieldErr) || fieldErr.Field != "tags" {
+		t.Errorf("65 tags should fail on tags, got %v", err)
+	}
+
+	// Absent, null and non-array values are not this check's concern
+	for _, item := range []map[string]any{{}, {"tags": nil}, {"tags": "a,b"}} {
+		if err := cfg.validateArrayLimits("note", item); err != nil {
+			t.Errorf("validateArrayLimits(%v) = %v, want nil", item, err)
+		}
+	}
+
+	// Configured limits replace the defaults; fields are reported in name order
+	cfg.ArrayLimits = map[string]map[string]int{"chat": {"members": 2, "labels": 1}}
+	if err := cfg.validateArrayLimits("note", map[string]any{"tags": tags(100)}); err != nil {
+		t.Errorf("Expected note caps to be replaced, got %v", err)
+	}
+	err := cfg.validatePayloadFields("chat", map[string]any{"members": tags(3), "labels": tags(2)})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "labels" {
+		t.Errorf("Expected labels to be reported first, got %v", err)
+	}
+}
+
+func TestParseArrayLimits(t *testing.T) {
+	limits, err := ParseArrayLimits(`{"note":{"tags":32},"task":{"tags":16}}`)
+	if err != nil {
+		t.Fatalf("ParseArrayLimits() error = %v", err)
+	}
+	if limits["note"]["tags"] != 32 || limits["task"]["tags"] != 16 {
+		t.Errorf("ParseArrayLimits() = %v", limits)
+	}
+	if limits, err := ParseArrayLimits(`{}`); err != nil || len(limits) != 0 {
+		t.Errorf(`ParseArrayLimits("{}") = %v, %v; want empty`, limits, err)
+	}
+
+	for _, v := range []string{`not json`, `{"widget":{"tags":1}}`, `{"note":{"tags":0}}`, `{"note":{"tags":"many"}}`} {
+		if _, err := ParseArrayLimits(v); err == nil {
+			t.Errorf("ParseArrayLimits(%q) expected error", v)
+		}
+	}
+}
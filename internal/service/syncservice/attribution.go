@@ -0,0 +1,44 @@
+package syncservice
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type actorKey struct{}
+
+// WithActor attaches the identity performing REST mutations to the context
+// Recorded as createdBy/updatedBy on the items it writes.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the mutation actor, or "" if none was attached
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey{}).(string)
+	return actor
+}
+
+// stampAttribution records the context actor on a row written by a REST mutation
+// updated_by is always overwritten; created_by only when the mutation created the row.
+// When the mutation didn't apply (LWW rejected it), the current values are returned unchanged.
+func stampAttribution(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID, isNew, applied bool) (createdBy, updatedBy *string, err error) {
+	if !applied {
+		err = tx.QueryRow(ctx, `
+			SELECT created_by, updated_by FROM `+table+`
+			WHERE owner_id = $1 AND uid = $2
+		`, userID, uid).Scan(&createdBy, &updatedBy)
+		return createdBy, updatedBy, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE `+table+`
+		SET updated_by = NULLIF($3, ''),
+			created_by = CASE WHEN $4::boolean THEN NULLIF($3, '') ELSE created_by END
+		WHERE owner_id = $1 AND uid = $2
+		RETURNING created_by, updated_by
+	`, userID, uid, ActorFromContext(ctx), isNew).Scan(&createdBy, &updatedBy)
+	return createdBy, updatedBy, err
+}
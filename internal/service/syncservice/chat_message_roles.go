@@ -0,0 +1,43 @@
+package syncservice
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// DefaultChatMessageRoles is the role vocabulary accepted when none is configured
+var DefaultChatMessageRoles = []string{"user", "assistant", "system", "tool"}
+
+// ParseChatMessageRoles parses a comma-separated role allowlist from config
+func ParseChatMessageRoles(v string) ([]string, error) {
+	var roles []string
+	for _, role := range strings.Split(v, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("chat message role allowlist %q is empty", v)
+	}
+	return roles, nil
+}
+
+// validateChatMessageRole checks a chat message's role against the allowlist
+// An absent or null role is accepted so partial pushes can leave it unchanged.
+func (c *Config) validateChatMessageRole(item map[string]any) error {
+	v, ok := item["role"]
+	if !ok || v == nil {
+		return nil
+	}
+	role, ok := v.(string)
+	if !ok {
+		return &syncx.FieldError{Field: "role", Reason: fmt.Sprintf("must be a string, got %T", v)}
+	}
+	if !slices.Contains(c.ChatMessageRoles, role) {
+		return &syncx.FieldError{Field: "role", Reason: fmt.Sprintf("%q is not an allowed role", role)}
+	}
+	return nil
+}
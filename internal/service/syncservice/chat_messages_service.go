@@ -2,9 +2,9 @@ package syncservice
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -14,12 +14,14 @@ import (
 
 // ChatMessageService encapsulates business logic for chat_message sync operations
 type ChatMessageService struct {
-	DB *pgxpool.Pool
+	DB    *pgxpool.Pool
+	Clock syncx.Clock // time source for server-assigned timestamps (RealClock in production)
+	Cfg   *Config     // sync settings, shared by all services of a server (DefaultConfig unless set)
 }
 
 // NewChatMessageService creates a new ChatMessageService
 func NewChatMessageService(db *pgxpool.Pool) *ChatMessageService {
-	return &ChatMessageService{DB: db}
+	return &ChatMessageService{DB: db, Clock: syncx.RealClock{}, Cfg: DefaultConfig()}
 }
 
 // PushChatMessageItem handles the push logic for a single chat_message item within a transaction
@@ -31,8 +33,20 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 	// Extract sync metadata + chat_uid from client JSON
 	ext, err := syncx.ExtractChatMessage(item)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Interface("item", s.Cfg.LogPayload(item)).Msg("failed to extract sync metadata")
+		return PushAck{Error: err.Error(), err: err}
+	}
+
+	// Tombstones skip role validation so messages stored before the allowlist can still be deleted
+	if ext.DeletedAtMs == nil {
+		if err := s.Cfg.validateChatMessageRole(item); err != nil {
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     err.Error(),
+			}
+		}
 	}
 
 	// Only validate parent chat exists if we're NOT deleting the message
@@ -67,8 +81,88 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 		}
 	}
 
+	// New items must use the configured UID version (see Config.RequiredUIDVersion)
+	if err := s.Cfg.checkPushedUIDVersion(ctx, tx, "chat_message", userID, ext.UID); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// New items may not be stamped implausibly far in the past (see Config.MaxCreateAgeDays)
+	if err := s.Cfg.checkCreateAge(ctx, tx, "chat_message", userID, ext, s.Clock.NowMs()); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Items last edited before a wipe of their entity stay wiped (see WipeEntityTx)
+	if err := checkEntityWipe(ctx, tx, "chat_message", userID, ext); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// A forced push replaces the stored row regardless of timestamps (see WithForceWrite)
+	if err := advanceForcedWrite(ctx, tx, "chat_message", userID, &ext, item); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to prepare forced write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Declarative field rules (see Config.validatePayloadFields); tombstones skip
+	// them so items stored before a rule was added can still be deleted
+	if ext.DeletedAtMs == nil {
+		if err := s.Cfg.validatePayloadFields("chat_message", item); err != nil {
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     err.Error(),
+			}
+		}
+	}
+
+	// Omitted fields keep their stored values, explicit nulls clear them (see AbsentFieldMode)
+	item, err = s.Cfg.mergeStoredPayload(ctx, tx, "chat_message", userID, ext.UID, item)
+	if err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to merge stored payload")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to merge stored payload",
+		}
+	}
+
+	// The configured unique field (see Config.UniqueFields) must not be held by another live item
+	uniqueValue, err := s.Cfg.checkUniqueKey(ctx, tx, "chat_message", userID, ext.UID, item, ext.DeletedAtMs != nil)
+	if err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Computed and normalized fields (see RegisterPayloadTransform)
+	s.Cfg.applyPayloadTransforms(ctx, "chat_message", item, ext)
+
 	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
+	payloadJSON, err := s.Cfg.encodePayload(item)
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
 		return PushAck{
@@ -79,25 +173,68 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 		}
 	}
 
+	// Deterministic winner between different writes with the same timestamp
+	tieKey := s.Cfg.lwwTieKey(item)
+
+	// Existing items of an append-only entity can only be deleted (see Config.AppendOnlyEntities)
+	if err := s.Cfg.checkAppendOnly(ctx, tx, "chat_message", userID, ext.UID, tieKey, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Growth past the per-user storage cap is rejected (see Config.MaxPayloadBytes)
+	if err := s.Cfg.checkPayloadQuota(ctx, tx, "chat_message", userID, ext.UID, payloadJSON, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Remember the pre-session state the first time this session writes the item (undo)
+	if err := captureSessionBaseline(ctx, tx, "chat_message", userID, ext.UID); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to capture session baseline")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Cached reads of this item must not outlive the write (see ItemCache.invalidateItem)
+	s.Cfg.ItemCache.invalidateItem(tx, "chat_message", userID, ext.UID)
+
 	// Insert or update with LWW conflict resolution
-	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
-	// If same timestamp arrives twice, version doesn't increment
+	// Key invariant: a duplicate push (same timestamp and content) matches neither branch of
+	// the WHERE clause, so it is a no-op and the version doesn't increment
 	_, err = tx.Exec(ctx, `
-		INSERT INTO chat_message (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, chat_uid)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7)
+		INSERT INTO chat_message (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, chat_uid, unique_key, tie_key)
+		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8, $9)
 		ON CONFLICT (owner_id, uid) DO UPDATE SET
 			payload_json   = EXCLUDED.payload_json,
+			unique_key     = EXCLUDED.unique_key,
+			tie_key        = EXCLUDED.tie_key,
 			updated_at_ms  = EXCLUDED.updated_at_ms,
 			deleted_at_ms  = EXCLUDED.deleted_at_ms,
 			chat_uid       = EXCLUDED.chat_uid,
-			-- Bump version only on strictly newer update (not >=, just >)
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > chat_message.updated_at_ms
-				THEN chat_message.version + 1
-				ELSE chat_message.version
-			END
+			version        = chat_message.version + 1
+		-- Strictly newer wins; on equal timestamps a delete beats an edit, and between two
+		-- edits (or two deletes) the larger tie key wins (see lwwTieKey)
 		WHERE EXCLUDED.updated_at_ms > chat_message.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, *ext.ChatUID)
+			OR (EXCLUDED.updated_at_ms = chat_message.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) > (chat_message.deleted_at_ms IS NOT NULL))
+			OR (EXCLUDED.updated_at_ms = chat_message.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) = (chat_message.deleted_at_ms IS NOT NULL)
+				AND EXCLUDED.tie_key > chat_message.tie_key)
+	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, *ext.ChatUID, uniqueValue, tieKey)
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert chat_message")
@@ -124,6 +261,16 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 		}
 	}
 
+	if err := recordSessionWrite(ctx, tx, "chat_message", userID, ext.UID, ext.UpdatedAtMs, serverMs); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record session write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
 	// Success - return server-authoritative values
 	return PushAck{
 		UID:       ext.UID.String(),
@@ -135,17 +282,23 @@ func (s *ChatMessageService) PushChatMessageItem(ctx context.Context, tx pgx.Tx,
 // PullChatMessages handles the pull logic for chat_messages
 // Returns upserts, deletes, and an optional next cursor for pagination
 func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	return s.PullChatMessagesRange(ctx, userID, cursor, syncx.MaxCursor, limit)
+}
+
+// PullChatMessagesRange pulls chat_messages strictly after cursor and at or before to
+func (s *ChatMessageService) PullChatMessagesRange(ctx context.Context, userID string, cursor, to syncx.Cursor, limit int) (*PullResponse, error) {
 	logger := log.With().Logger()
 
 	// Query chat_messages ordered by (updated_at_ms, uid) for deterministic pagination
 	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
 		FROM chat_message
 		WHERE owner_id = $1
 		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		  AND (updated_at_ms, uid) <= ($5, $6::uuid)
 		ORDER BY updated_at_ms, uid
 		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	`, userID, cursor.Ms, cursor.UID, limit, to.Ms, to.UID)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query chat_messages")
@@ -155,20 +308,32 @@ func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string
 
 	upserts := make([]map[string]any, 0, limit)
 	deletes := make([]map[string]any, 0)
+	inserts := make([]string, 0)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
+		var version int
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
 			logger.Error().Err(err).Msg("failed to scan chat_message row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode chat message payload")
+			return nil, err
+		}
+
+		if !budget.admitPullRow(payload, deletedAtMs != nil) {
+			break
+		}
+
 		if deletedAtMs != nil {
 			// Tombstone - return as delete
 			deletes = append(deletes, map[string]any{
@@ -178,6 +343,9 @@ func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string
 		} else {
 			// Active chat_message - return full payload
 			upserts = append(upserts, payload)
+			if version == 1 {
+				inserts = append(inserts, uid)
+			}
 		}
 
 		lastMs, lastUID = ms, uid
@@ -197,9 +365,11 @@ func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string
 	}
 
 	return &PullResponse{
-		Upserts:    upserts,
-		Deletes:    deletes,
-		NextCursor: nextCursor,
+		Upserts:     upserts,
+		Deletes:     deletes,
+		Inserts:     inserts,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
@@ -210,16 +380,22 @@ func (s *ChatMessageService) PullChatMessages(ctx context.Context, userID string
 func (s *ChatMessageService) GetChatMessage(ctx context.Context, userID string, uid uuid.UUID) (*RESTItem, error) {
 	logger := log.With().Logger()
 
+	cached, gen, ok := s.Cfg.ItemCache.get("chat_message", userID, uid)
+	if ok {
+		return cached, nil
+	}
+
 	var payload map[string]any
 	var version int
 	var updatedAtMs int64
 	var deletedAtMs *int64
+	var createdBy, updatedBy *string
 
 	err := s.DB.QueryRow(ctx, `
-		SELECT payload_json, version, updated_at_ms, deleted_at_ms
+		SELECT payload_json, version, updated_at_ms, deleted_at_ms, created_by, updated_by
 		FROM chat_message
 		WHERE owner_id = $1 AND uid = $2
-	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs)
+	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs, &createdBy, &updatedBy)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -229,11 +405,18 @@ func (s *ChatMessageService) GetChatMessage(ctx context.Context, userID string,
 		return nil, err
 	}
 
+	if payload, err = s.Cfg.decodePayload(payload); err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to decode chat message payload")
+		return nil, err
+	}
+
 	// Always return the item (even if deleted) - handler will decide 410 vs 200
 	item := &RESTItem{
 		UID:       uid.String(),
 		Version:   version,
 		UpdatedAt: syncx.RFC3339(updatedAtMs),
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   payload,
 	}
 
@@ -242,26 +425,28 @@ func (s *ChatMessageService) GetChatMessage(ctx context.Context, userID string,
 		item.DeletedAt = &deletedAt
 	}
 
+	s.Cfg.ItemCache.put(gen, "chat_message", userID, uid, item)
 	return item, nil
 }
 
 // ListChatMessages returns paginated chat messages for REST endpoints
-func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string, cursor syncx.Cursor, limit int, includeDeleted bool) (*RESTListResponse, error) {
+func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts ListOpts) (*RESTListResponse, error) {
 	logger := log.With().Logger()
 
-	// Build query based on includeDeleted
+	// Build query based on deletion filter
 	query := `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version, created_by, updated_by
 		FROM chat_message
 		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
 	`
-	if !includeDeleted {
-		query += ` AND deleted_at_ms IS NULL`
-	}
-	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
-
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	query += opts.positionClause()
+	query += opts.deletedClause()
+	filter, filterArgs := opts.whereClause(5)
+	query += filter
+	query += opts.orderClause() + ` LIMIT $4`
+
+	args := append([]any{userID, cursor.Ms, cursor.UID, limit}, filterArgs...)
+	rows, err := s.DB.Query(ctx, query, args...)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list chat_messages")
 		return nil, err
@@ -271,6 +456,7 @@ func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
@@ -278,16 +464,24 @@ func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string
 		var ms int64
 		var uid string
 		var version int
+		var createdBy, updatedBy *string
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version, &createdBy, &updatedBy); err != nil {
 			logger.Error().Err(err).Msg("failed to scan chat_message row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode chat message payload")
+			return nil, err
+		}
+
 		item := RESTItem{
 			UID:       uid,
 			Version:   version,
 			UpdatedAt: syncx.RFC3339(ms),
+			CreatedBy: createdBy,
+			UpdatedBy: updatedBy,
 			Payload:   payload,
 		}
 
@@ -296,6 +490,9 @@ func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string
 			item.DeletedAt = &deletedAt
 		}
 
+		if !budget.admit(item) {
+			break
+		}
 		items = append(items, item)
 		lastMs, lastUID = ms, uid
 	}
@@ -309,46 +506,73 @@ func (s *ChatMessageService) ListChatMessages(ctx context.Context, userID string
 	var nextCursor *string
 	if len(items) > 0 {
 		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
+		encoded := syncx.EncodeCursor(opts.cursorAt(lastMs, uid))
 		nextCursor = &encoded
 	}
 
 	return &RESTListResponse{
-		Items:      items,
-		NextCursor: nextCursor,
+		Items:       items,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
 // ApplyChatMessageMutation creates or updates a chat message via REST
 // Handles optimistic locking, monotonic timestamps, and soft deletes
 func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
-	logger := log.With().Logger()
-
-	// Start transaction
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to begin transaction")
+		log.Error().Err(err).Msg("failed to begin transaction")
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	item, err := s.ApplyChatMessageMutationTx(ctx, tx, userID, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to commit mutation")
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ApplyChatMessageMutationTx is ApplyChatMessageMutation within an existing transaction
+// The caller is responsible for committing or rolling back the transaction
+func (s *ChatMessageService) ApplyChatMessageMutationTx(ctx context.Context, tx pgx.Tx, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	logger := log.With().Logger()
+
+	// Reject unknown roles and malformed fields up front as a *syncx.FieldError (REST maps it to 422)
+	if !opts.SetDeleted {
+		if err := s.Cfg.validateChatMessageRole(payload); err != nil {
+			return nil, err
+		}
+		if err := s.Cfg.validatePayloadFields("chat_message", payload); err != nil {
+			return nil, err
+		}
+	}
+
 	// Extract UID or generate new one
 	var chatMessageUID uuid.UUID
 	if uidStr, ok := syncx.GetString(payload, "uid"); ok {
 		chatMessageUID, _ = uuid.Parse(uidStr)
 	}
 	if chatMessageUID == uuid.Nil {
-		chatMessageUID = uuid.New()
+		chatMessageUID = s.Cfg.NewUID()
 		payload["uid"] = chatMessageUID.String()
 	}
 
 	// Fetch existing chat_message to determine timestamp
 	var existingMs int64
 	var existingVersion int
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		SELECT updated_at_ms, version
 		FROM chat_message
 		WHERE owner_id = $1 AND uid = $2
+		FOR UPDATE
 	`, userID, chatMessageUID).Scan(&existingMs, &existingVersion)
 
 	if err != nil && err != pgx.ErrNoRows {
@@ -358,6 +582,15 @@ func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userI
 
 	isNew := err == pgx.ErrNoRows
 
+	// Reject new items whose UID is not the configured version (REST maps it to 422)
+	if isNew {
+		if err := s.Cfg.checkNewUIDVersion(chatMessageUID); err != nil {
+			return nil, err
+		}
+		// Fields the client left out start from the entity template (see Config.DefaultPayloads)
+		s.Cfg.applyDefaultPayload("chat_message", payload)
+	}
+
 	// Optimistic locking check
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
@@ -373,16 +606,29 @@ func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userI
 	if opts.ForceTimestampMs != nil {
 		timestampMs = *opts.ForceTimestampMs
 	} else if isNew {
-		timestampMs = syncx.NowMs()
+		timestampMs = s.Clock.NowMs()
 	} else {
-		timestampMs = syncx.EnsureMonotonicTimestamp(existingMs)
+		timestampMs = syncx.EnsureMonotonicTimestampAt(s.Clock.NowMs(), existingMs)
+	}
+
+	// Surface a unique field conflict as a typed error (REST maps it to 409)
+	if _, err := s.Cfg.checkUniqueKey(ctx, tx, "chat_message", userID, chatMessageUID, payload, opts.SetDeleted); err != nil {
+		return nil, err
 	}
 
 	// Build sync-compliant payload
 	mutatedPayload := syncx.BuildServerMutation(payload, timestampMs, opts.SetDeleted)
 
+	// Forced mutations win LWW whatever their timestamp (see MutationOpts.ForceWrite)
+	if opts.ForceWrite {
+		ctx = WithForceWrite(ctx)
+	}
+
 	// Call existing push logic
-	ack := s.PushChatMessageItem(ctx, tx, userID, mutatedPayload)
+	ack := s.PushChatMessageItem(withReplacePayload(ctx), tx, userID, mutatedPayload)
+	if ack.err != nil {
+		return nil, ack.err
+	}
 	if ack.Error != "" {
 		return nil, &MutationError{Message: ack.Error}
 	}
@@ -404,8 +650,10 @@ func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userI
 		syncBlock["version"] = ack.Version
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		logger.Error().Err(err).Msg("failed to commit mutation")
+	// Record who made this change (createdBy is write-once, updatedBy tracks the latest mutation)
+	createdBy, updatedBy, err := stampAttribution(ctx, tx, "chat_message", userID, chatMessageUID, isNew, true)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record attribution")
 		return nil, err
 	}
 
@@ -421,6 +669,8 @@ func (s *ChatMessageService) ApplyChatMessageMutation(ctx context.Context, userI
 		Version:   ack.Version,
 		UpdatedAt: ack.UpdatedAt,
 		DeletedAt: deletedAt,
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   mutatedPayload,
 	}, nil
 }
@@ -2,9 +2,9 @@ package syncservice
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -14,12 +14,14 @@ import (
 
 // CommentService encapsulates business logic for comment sync operations
 type CommentService struct {
-	DB *pgxpool.Pool
+	DB    *pgxpool.Pool
+	Clock syncx.Clock // time source for server-assigned timestamps (RealClock in production)
+	Cfg   *Config     // sync settings, shared by all services of a server (DefaultConfig unless set)
 }
 
 // NewCommentService creates a new CommentService
 func NewCommentService(db *pgxpool.Pool) *CommentService {
-	return &CommentService{DB: db}
+	return &CommentService{DB: db, Clock: syncx.RealClock{}, Cfg: DefaultConfig()}
 }
 
 // PushCommentItem handles the push logic for a single comment item within a transaction
@@ -31,8 +33,8 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 	// Extract sync metadata + parent fields from client JSON
 	ext, err := syncx.ExtractComment(item)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Interface("item", s.Cfg.LogPayload(item)).Msg("failed to extract sync metadata")
+		return PushAck{Error: err.Error(), err: err}
 	}
 
 	// Validate parent type
@@ -94,8 +96,88 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 		}
 	}
 
+	// New items must use the configured UID version (see Config.RequiredUIDVersion)
+	if err := s.Cfg.checkPushedUIDVersion(ctx, tx, "comment", userID, ext.UID); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// New items may not be stamped implausibly far in the past (see Config.MaxCreateAgeDays)
+	if err := s.Cfg.checkCreateAge(ctx, tx, "comment", userID, ext, s.Clock.NowMs()); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Items last edited before a wipe of their entity stay wiped (see WipeEntityTx)
+	if err := checkEntityWipe(ctx, tx, "comment", userID, ext); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// A forced push replaces the stored row regardless of timestamps (see WithForceWrite)
+	if err := advanceForcedWrite(ctx, tx, "comment", userID, &ext, item); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to prepare forced write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Declarative field rules (see Config.validatePayloadFields); tombstones skip
+	// them so items stored before a rule was added can still be deleted
+	if ext.DeletedAtMs == nil {
+		if err := s.Cfg.validatePayloadFields("comment", item); err != nil {
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     err.Error(),
+			}
+		}
+	}
+
+	// Omitted fields keep their stored values, explicit nulls clear them (see AbsentFieldMode)
+	item, err = s.Cfg.mergeStoredPayload(ctx, tx, "comment", userID, ext.UID, item)
+	if err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to merge stored payload")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to merge stored payload",
+		}
+	}
+
+	// The configured unique field (see Config.UniqueFields) must not be held by another live item
+	uniqueValue, err := s.Cfg.checkUniqueKey(ctx, tx, "comment", userID, ext.UID, item, ext.DeletedAtMs != nil)
+	if err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Computed and normalized fields (see RegisterPayloadTransform)
+	s.Cfg.applyPayloadTransforms(ctx, "comment", item, ext)
+
 	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
+	payloadJSON, err := s.Cfg.encodePayload(item)
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
 		return PushAck{
@@ -106,26 +188,69 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 		}
 	}
 
+	// Deterministic winner between different writes with the same timestamp
+	tieKey := s.Cfg.lwwTieKey(item)
+
+	// Existing items of an append-only entity can only be deleted (see Config.AppendOnlyEntities)
+	if err := s.Cfg.checkAppendOnly(ctx, tx, "comment", userID, ext.UID, tieKey, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Growth past the per-user storage cap is rejected (see Config.MaxPayloadBytes)
+	if err := s.Cfg.checkPayloadQuota(ctx, tx, "comment", userID, ext.UID, payloadJSON, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Remember the pre-session state the first time this session writes the item (undo)
+	if err := captureSessionBaseline(ctx, tx, "comment", userID, ext.UID); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to capture session baseline")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Cached reads of this item must not outlive the write (see ItemCache.invalidateItem)
+	s.Cfg.ItemCache.invalidateItem(tx, "comment", userID, ext.UID)
+
 	// Insert or update with LWW conflict resolution
-	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
-	// If same timestamp arrives twice, version doesn't increment
+	// Key invariant: a duplicate push (same timestamp and content) matches neither branch of
+	// the WHERE clause, so it is a no-op and the version doesn't increment
 	_, err = tx.Exec(ctx, `
-		INSERT INTO comment (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, parent_type, parent_uid)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8)
+		INSERT INTO comment (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, parent_type, parent_uid, unique_key, tie_key)
+		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8, $9, $10)
 		ON CONFLICT (owner_id, uid) DO UPDATE SET
 			payload_json   = EXCLUDED.payload_json,
+			unique_key     = EXCLUDED.unique_key,
+			tie_key        = EXCLUDED.tie_key,
 			updated_at_ms  = EXCLUDED.updated_at_ms,
 			deleted_at_ms  = EXCLUDED.deleted_at_ms,
 			parent_type    = EXCLUDED.parent_type,
 			parent_uid     = EXCLUDED.parent_uid,
-			-- Bump version only on strictly newer update (not >=, just >)
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > comment.updated_at_ms
-				THEN comment.version + 1
-				ELSE comment.version
-			END
+			version        = comment.version + 1
+		-- Strictly newer wins; on equal timestamps a delete beats an edit, and between two
+		-- edits (or two deletes) the larger tie key wins (see lwwTieKey)
 		WHERE EXCLUDED.updated_at_ms > comment.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, ext.ParentType, *ext.ParentUID)
+			OR (EXCLUDED.updated_at_ms = comment.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) > (comment.deleted_at_ms IS NOT NULL))
+			OR (EXCLUDED.updated_at_ms = comment.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) = (comment.deleted_at_ms IS NOT NULL)
+				AND EXCLUDED.tie_key > comment.tie_key)
+	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, ext.ParentType, *ext.ParentUID, uniqueValue, tieKey)
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert comment")
@@ -152,6 +277,16 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 		}
 	}
 
+	if err := recordSessionWrite(ctx, tx, "comment", userID, ext.UID, ext.UpdatedAtMs, serverMs); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record session write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
 	// Success - return server-authoritative values
 	return PushAck{
 		UID:       ext.UID.String(),
@@ -163,17 +298,23 @@ func (s *CommentService) PushCommentItem(ctx context.Context, tx pgx.Tx, userID
 // PullComments handles the pull logic for comments
 // Returns upserts, deletes, and an optional next cursor for pagination
 func (s *CommentService) PullComments(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	return s.PullCommentsRange(ctx, userID, cursor, syncx.MaxCursor, limit)
+}
+
+// PullCommentsRange pulls comments strictly after cursor and at or before to
+func (s *CommentService) PullCommentsRange(ctx context.Context, userID string, cursor, to syncx.Cursor, limit int) (*PullResponse, error) {
 	logger := log.With().Logger()
 
 	// Query comments ordered by (updated_at_ms, uid) for deterministic pagination
 	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
 		FROM comment
 		WHERE owner_id = $1
 		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		  AND (updated_at_ms, uid) <= ($5, $6::uuid)
 		ORDER BY updated_at_ms, uid
 		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	`, userID, cursor.Ms, cursor.UID, limit, to.Ms, to.UID)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query comments")
@@ -183,20 +324,32 @@ func (s *CommentService) PullComments(ctx context.Context, userID string, cursor
 
 	upserts := make([]map[string]any, 0, limit)
 	deletes := make([]map[string]any, 0)
+	inserts := make([]string, 0)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
+		var version int
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
 			logger.Error().Err(err).Msg("failed to scan comment row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode comment payload")
+			return nil, err
+		}
+
+		if !budget.admitPullRow(payload, deletedAtMs != nil) {
+			break
+		}
+
 		if deletedAtMs != nil {
 			// Tombstone - return as delete
 			deletes = append(deletes, map[string]any{
@@ -206,6 +359,9 @@ func (s *CommentService) PullComments(ctx context.Context, userID string, cursor
 		} else {
 			// Active comment - return full payload
 			upserts = append(upserts, payload)
+			if version == 1 {
+				inserts = append(inserts, uid)
+			}
 		}
 
 		lastMs, lastUID = ms, uid
@@ -225,9 +381,11 @@ func (s *CommentService) PullComments(ctx context.Context, userID string, cursor
 	}
 
 	return &PullResponse{
-		Upserts:    upserts,
-		Deletes:    deletes,
-		NextCursor: nextCursor,
+		Upserts:     upserts,
+		Deletes:     deletes,
+		Inserts:     inserts,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
@@ -238,16 +396,22 @@ func (s *CommentService) PullComments(ctx context.Context, userID string, cursor
 func (s *CommentService) GetComment(ctx context.Context, userID string, uid uuid.UUID) (*RESTItem, error) {
 	logger := log.With().Logger()
 
+	cached, gen, ok := s.Cfg.ItemCache.get("comment", userID, uid)
+	if ok {
+		return cached, nil
+	}
+
 	var payload map[string]any
 	var version int
 	var updatedAtMs int64
 	var deletedAtMs *int64
+	var createdBy, updatedBy *string
 
 	err := s.DB.QueryRow(ctx, `
-		SELECT payload_json, version, updated_at_ms, deleted_at_ms
+		SELECT payload_json, version, updated_at_ms, deleted_at_ms, created_by, updated_by
 		FROM comment
 		WHERE owner_id = $1 AND uid = $2
-	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs)
+	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs, &createdBy, &updatedBy)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -257,11 +421,18 @@ func (s *CommentService) GetComment(ctx context.Context, userID string, uid uuid
 		return nil, err
 	}
 
+	if payload, err = s.Cfg.decodePayload(payload); err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to decode comment payload")
+		return nil, err
+	}
+
 	// Always return the item (even if deleted) - handler will decide 410 vs 200
 	item := &RESTItem{
 		UID:       uid.String(),
 		Version:   version,
 		UpdatedAt: syncx.RFC3339(updatedAtMs),
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   payload,
 	}
 
@@ -270,26 +441,28 @@ func (s *CommentService) GetComment(ctx context.Context, userID string, uid uuid
 		item.DeletedAt = &deletedAt
 	}
 
+	s.Cfg.ItemCache.put(gen, "comment", userID, uid, item)
 	return item, nil
 }
 
 // ListComments returns paginated comments for REST endpoints
-func (s *CommentService) ListComments(ctx context.Context, userID string, cursor syncx.Cursor, limit int, includeDeleted bool) (*RESTListResponse, error) {
+func (s *CommentService) ListComments(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts ListOpts) (*RESTListResponse, error) {
 	logger := log.With().Logger()
 
-	// Build query based on includeDeleted
+	// Build query based on deletion filter
 	query := `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version, created_by, updated_by
 		FROM comment
 		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
 	`
-	if !includeDeleted {
-		query += ` AND deleted_at_ms IS NULL`
-	}
-	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
-
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	query += opts.positionClause()
+	query += opts.deletedClause()
+	filter, filterArgs := opts.whereClause(5)
+	query += filter
+	query += opts.orderClause() + ` LIMIT $4`
+
+	args := append([]any{userID, cursor.Ms, cursor.UID, limit}, filterArgs...)
+	rows, err := s.DB.Query(ctx, query, args...)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list comments")
 		return nil, err
@@ -299,6 +472,7 @@ func (s *CommentService) ListComments(ctx context.Context, userID string, cursor
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
@@ -306,16 +480,24 @@ func (s *CommentService) ListComments(ctx context.Context, userID string, cursor
 		var ms int64
 		var uid string
 		var version int
+		var createdBy, updatedBy *string
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version, &createdBy, &updatedBy); err != nil {
 			logger.Error().Err(err).Msg("failed to scan comment row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode comment payload")
+			return nil, err
+		}
+
 		item := RESTItem{
 			UID:       uid,
 			Version:   version,
 			UpdatedAt: syncx.RFC3339(ms),
+			CreatedBy: createdBy,
+			UpdatedBy: updatedBy,
 			Payload:   payload,
 		}
 
@@ -324,6 +506,9 @@ func (s *CommentService) ListComments(ctx context.Context, userID string, cursor
 			item.DeletedAt = &deletedAt
 		}
 
+		if !budget.admit(item) {
+			break
+		}
 		items = append(items, item)
 		lastMs, lastUID = ms, uid
 	}
@@ -337,46 +522,70 @@ func (s *CommentService) ListComments(ctx context.Context, userID string, cursor
 	var nextCursor *string
 	if len(items) > 0 {
 		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
+		encoded := syncx.EncodeCursor(opts.cursorAt(lastMs, uid))
 		nextCursor = &encoded
 	}
 
 	return &RESTListResponse{
-		Items:      items,
-		NextCursor: nextCursor,
+		Items:       items,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
 // ApplyCommentMutation creates or updates a comment via REST
 // Handles optimistic locking, monotonic timestamps, and soft deletes
 func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
-	logger := log.With().Logger()
-
-	// Start transaction
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to begin transaction")
+		log.Error().Err(err).Msg("failed to begin transaction")
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	item, err := s.ApplyCommentMutationTx(ctx, tx, userID, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to commit mutation")
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ApplyCommentMutationTx is ApplyCommentMutation within an existing transaction
+// The caller is responsible for committing or rolling back the transaction
+func (s *CommentService) ApplyCommentMutationTx(ctx context.Context, tx pgx.Tx, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	logger := log.With().Logger()
+
+	// Reject malformed fields up front as a *syncx.FieldError (REST maps it to 422)
+	if !opts.SetDeleted {
+		if err := s.Cfg.validatePayloadFields("comment", payload); err != nil {
+			return nil, err
+		}
+	}
+
 	// Extract UID or generate new one
 	var commentUID uuid.UUID
 	if uidStr, ok := syncx.GetString(payload, "uid"); ok {
 		commentUID, _ = uuid.Parse(uidStr)
 	}
 	if commentUID == uuid.Nil {
-		commentUID = uuid.New()
+		commentUID = s.Cfg.NewUID()
 		payload["uid"] = commentUID.String()
 	}
 
 	// Fetch existing comment to determine timestamp
 	var existingMs int64
 	var existingVersion int
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		SELECT updated_at_ms, version
 		FROM comment
 		WHERE owner_id = $1 AND uid = $2
+		FOR UPDATE
 	`, userID, commentUID).Scan(&existingMs, &existingVersion)
 
 	if err != nil && err != pgx.ErrNoRows {
@@ -386,6 +595,15 @@ func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string
 
 	isNew := err == pgx.ErrNoRows
 
+	// Reject new items whose UID is not the configured version (REST maps it to 422)
+	if isNew {
+		if err := s.Cfg.checkNewUIDVersion(commentUID); err != nil {
+			return nil, err
+		}
+		// Fields the client left out start from the entity template (see Config.DefaultPayloads)
+		s.Cfg.applyDefaultPayload("comment", payload)
+	}
+
 	// Optimistic locking check
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
@@ -401,16 +619,29 @@ func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string
 	if opts.ForceTimestampMs != nil {
 		timestampMs = *opts.ForceTimestampMs
 	} else if isNew {
-		timestampMs = syncx.NowMs()
+		timestampMs = s.Clock.NowMs()
 	} else {
-		timestampMs = syncx.EnsureMonotonicTimestamp(existingMs)
+		timestampMs = syncx.EnsureMonotonicTimestampAt(s.Clock.NowMs(), existingMs)
+	}
+
+	// Surface a unique field conflict as a typed error (REST maps it to 409)
+	if _, err := s.Cfg.checkUniqueKey(ctx, tx, "comment", userID, commentUID, payload, opts.SetDeleted); err != nil {
+		return nil, err
 	}
 
 	// Build sync-compliant payload
 	mutatedPayload := syncx.BuildServerMutation(payload, timestampMs, opts.SetDeleted)
 
+	// Forced mutations win LWW whatever their timestamp (see MutationOpts.ForceWrite)
+	if opts.ForceWrite {
+		ctx = WithForceWrite(ctx)
+	}
+
 	// Call existing push logic
-	ack := s.PushCommentItem(ctx, tx, userID, mutatedPayload)
+	ack := s.PushCommentItem(withReplacePayload(ctx), tx, userID, mutatedPayload)
+	if ack.err != nil {
+		return nil, ack.err
+	}
 	if ack.Error != "" {
 		return nil, &MutationError{Message: ack.Error}
 	}
@@ -432,8 +663,10 @@ func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string
 		syncBlock["version"] = ack.Version
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		logger.Error().Err(err).Msg("failed to commit mutation")
+	// Record who made this change (createdBy is write-once, updatedBy tracks the latest mutation)
+	createdBy, updatedBy, err := stampAttribution(ctx, tx, "comment", userID, commentUID, isNew, true)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record attribution")
 		return nil, err
 	}
 
@@ -449,6 +682,8 @@ func (s *CommentService) ApplyCommentMutation(ctx context.Context, userID string
 		Version:   ack.Version,
 		UpdatedAt: ack.UpdatedAt,
 		DeletedAt: deletedAt,
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   mutatedPayload,
 	}, nil
 }
@@ -0,0 +1,57 @@
+package syncservice
+
+import "github.com/erauner12/toolbridge-api/internal/payloadcrypt"
+
+// Config holds the sync settings of the entity services: how payloads are validated,
+// stored, paged and logged. Every service built by NewXService starts from
+// DefaultConfig; the server builds one Config from its environment and gives the same
+// pointer to all services, so they agree on storage format and cache.
+// A Config must not be modified once it serves requests.
+type Config struct {
+	PayloadCipher      *payloadcrypt.Cipher          // Encrypts payload_json at rest (nil = plaintext storage)
+	AbsentFields       AbsentFieldMode               // How sync pushes treat omitted payload fields
+	ReservedKeys       ReservedKeyMode               // How REST writes treat ReservedPayloadKeys
+	ChatMessageRoles   []string                      // Allowlist for chat_message.role
+	RedactedLogKeys    []string                      // Payload keys masked in logs (case-insensitive)
+	PayloadLogging     bool                          // Log payloads at all (false = placeholder only)
+	DefaultPayloads    map[string]map[string]any     // Create templates per entity table
+	FieldFormats       map[string]map[string]string  // Field format rules per entity table
+	ArrayLimits        map[string]map[string]int     // Array size caps per entity table
+	TextLimits         map[string]map[string]int     // Text length caps per entity table
+	UniqueFields       map[string]string             // Payload field unique among a user's live items, per table
+	AppendOnlyEntities map[string]bool               // Tables whose items can be created and deleted but not edited
+	ExportColumns      map[string][]string           // Exportable payload fields per table (absent = no export)
+	PageLimits         map[string]PageLimit          // Page sizes per table (absent = DefaultPageLimit)
+	MaxPageBytes       int                           // Serialized size cap of one pull or list page (0 = unlimited)
+	MaxPayloadBytes    int64                         // Payload bytes of one user's live items (0 = unlimited)
+	RequiredUIDVersion int                           // UUID version new items must use (0 = any)
+	MaxCreateAgeDays   int                           // How far behind the clock a pushed create may be (0 = unchecked)
+	CreateAgeMode      CreateAgeMode                 // What happens to older creates
+	SeqIDs             bool                          // Stamp new tasks with per-user sequential display IDs
+	ItemCache          *ItemCache                    // Cache in front of single-item reads (nil = disabled)
+	Transforms         map[string][]PayloadTransform // Payload transforms per table (see RegisterPayloadTransform)
+}
+
+// DefaultConfig returns the built-in settings: plaintext storage, merging pushes,
+// the Default* validation rules and page sizes, no quota and no item cache
+func DefaultConfig() *Config {
+	return &Config{
+		AbsentFields:       AbsentFieldsKeep,
+		ReservedKeys:       ReservedKeysStrip,
+		ChatMessageRoles:   DefaultChatMessageRoles,
+		RedactedLogKeys:    DefaultRedactedLogKeys,
+		PayloadLogging:     true,
+		DefaultPayloads:    DefaultPayloads,
+		FieldFormats:       DefaultFieldFormats,
+		ArrayLimits:        DefaultArrayLimits,
+		TextLimits:         DefaultTextLimits,
+		UniqueFields:       map[string]string{},
+		AppendOnlyEntities: map[string]bool{},
+		ExportColumns:      DefaultExportColumns,
+		PageLimits:         DefaultPageLimits,
+		CreateAgeMode:      CreateAgeReject,
+		Transforms: map[string][]PayloadTransform{
+			"note": {RESTSyncFields},
+		},
+	}
+}
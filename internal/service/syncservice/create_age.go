@@ -0,0 +1,71 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+	"github.com/rs/zerolog/log"
+)
+
+// CreateAgeMode is what a sync push does with a new item whose updatedTs is older
+// than the max create age
+type CreateAgeMode string
+
+const (
+	CreateAgeReject CreateAgeMode = "reject" // refuse the item with a push ack error
+	CreateAgeFlag   CreateAgeMode = "flag"   // store it and log a warning
+)
+
+// ParseCreateAgeMode parses the stale-create mode from config ("" = reject)
+func ParseCreateAgeMode(v string) (CreateAgeMode, error) {
+	switch CreateAgeMode(v) {
+	case "", CreateAgeReject:
+		return CreateAgeReject, nil
+	case CreateAgeFlag:
+		return CreateAgeFlag, nil
+	}
+	return "", fmt.Errorf("unknown create age mode %q (want reject or flag)", v)
+}
+
+const dayMs = 24 * 60 * 60 * 1000
+
+// checkCreateAge catches a push creating an item with an implausibly old updatedTs,
+// typically a replayed or badly imported event: such an item loses every later LWW
+// comparison against writes stamped with the real time of the edit.
+//
+// Only creates are checked. Updates to existing items are left to LWW, and tombstones
+// pass so a client can always flush deletes it recorded long ago. The row is only
+// looked up when the timestamp is too old.
+func (c *Config) checkCreateAge(ctx context.Context, tx pgx.Tx, table, userID string, ext syncx.Extracted, nowMs int64) error {
+	if c.MaxCreateAgeDays <= 0 || ext.DeletedAtMs != nil || nowMs-ext.UpdatedAtMs <= int64(c.MaxCreateAgeDays)*dayMs {
+		return nil
+	}
+
+	var exists bool
+	err := tx.QueryRow(ctx,
+		fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE owner_id = $1 AND uid = $2)`, table),
+		userID, ext.UID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	if c.CreateAgeMode == CreateAgeFlag {
+		log.Warn().
+			Str("table", table).
+			Str("userId", userID).
+			Str("uid", ext.UID.String()).
+			Str("updatedTs", syncx.RFC3339(ext.UpdatedAtMs)).
+			Int64("ageDays", (nowMs-ext.UpdatedAtMs)/dayMs).
+			Msg("sync_stale_create")
+		return nil
+	}
+	return &syncx.FieldError{
+		Field:  "updatedTs",
+		Reason: fmt.Sprintf("new items must be updated within the last %d days, got %s", c.MaxCreateAgeDays, syncx.RFC3339(ext.UpdatedAtMs)),
+	}
+}
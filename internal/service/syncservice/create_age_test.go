@@ -0,0 +1,84 @@
+package syncservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestParseCreateAgeMode(t *testing.T) {
+	for v, want := range map[string]CreateAgeMode{"": CreateAgeReject, "reject": CreateAgeReject, "flag": CreateAgeFlag} {
+		if got, err := ParseCreateAgeMode(v); err != nil || got != want {
+			t.Errorf("ParseCreateAgeMode(%q) = %q, %v; want %q", v, got, err, want)
+		}
+	}
+	if _, err := ParseCreateAgeMode("drop"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+// TestCreateAge_Integration checks old timestamps are refused on create only, and
+// accepted with a warning in flag mode
+func TestCreateAge_Integration(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	svc := NewNoteService(pool)
+	now := int64(1_700_000_000_000)
+	svc.Clock = syncx.NewFakeClock(now)
+
+	svc.Cfg.MaxCreateAgeDays = 30
+
+	old := lwwWrite{ms: now - 31*dayMs, title: "replayed"}
+
+	// A create stamped 31 days ago is refused and nothing is stored
+	uid := svc.Cfg.NewUID()
+	err := pushNote(ctx, svc, userID, uid, old)
+	if err == nil {
+		t.Fatal("Expected a create 31 days in the past to be rejected")
+	}
+	if _, err := readNote(ctx, pool, userID, uid); err == nil {
+		t.Error("Rejected create was stored")
+	}
+
+	// Within the window it is accepted
+	if err := pushNote(ctx, svc, userID, uid, lwwWrite{ms: now - 29*dayMs, title: "recent"}); err != nil {
+		t.Fatalf("Create within the window failed: %v", err)
+	}
+
+	// Updates of existing items are left to LWW
+	if err := pushNote(ctx, svc, userID, uid, old); err != nil {
+		t.Errorf("Update with an old timestamp should not be rejected: %v", err)
+	}
+
+	// Tombstones for unknown items always pass
+	tomb := old.item(svc.Cfg.NewUID())
+	tomb["sync"] = map[string]any{"version": 1, "isDeleted": true, "deletedAt": syncx.RFC3339(old.ms)}
+	tx, err := db.Begin(ctx, pool)
+	if err != nil {
+		t.Fatalf("Begin failed: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	if ack := svc.PushNoteItem(ctx, tx, userID, tomb); ack.Error != "" {
+		t.Errorf("Old tombstone should pass, got %q", ack.Error)
+	}
+
+	// Flag mode stores the item
+	svc.Cfg.CreateAgeMode = CreateAgeFlag
+	flagged := svc.Cfg.NewUID()
+	if err := pushNote(ctx, svc, userID, flagged, old); err != nil {
+		t.Fatalf("Flag mode should accept the create: %v", err)
+	}
+	if _, err := readNote(ctx, pool, userID, flagged); err != nil {
+		t.Errorf("Flagged create was not stored: %v", err)
+	}
+}
+
+func TestCreateAge_Unchecked(t *testing.T) {
+	// With no max age nothing is looked up, so a nil transaction is never touched
+	ext := syncx.Extracted{UpdatedAtMs: 0}
+	if err := DefaultConfig().checkCreateAge(context.Background(), nil, "note", "u", ext, 1_700_000_000_000); err != nil {
+		t.Errorf("Expected no check without a max age, got %v", err)
+	}
+}
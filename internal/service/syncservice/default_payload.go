@@ -0,0 +1,61 @@
+package syncservice
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultPayloads is the built-in per-entity template merged under new items' payloads
+// Tasks start open and not done, chats unarchived, so lists filtering on those fields
+// see items created by clients that leave them unset.
+var DefaultPayloads = map[string]map[string]any{
+	"task": {"status": "open", "done": false},
+	"chat": {"archived": false},
+}
+
+// ParseDefaultPayloads parses create templates from config: a JSON object mapping entity
+// tables to the fields their new items default to, e.g. {"task":{"status":"open"}}.
+// "{}" disables templates.
+func ParseDefaultPayloads(v string) (map[string]map[string]any, error) {
+	var templates map[string]map[string]any
+	if err := json.Unmarshal([]byte(v), &templates); err != nil {
+		return nil, fmt.Errorf("default payloads must be a JSON object of entity templates: %w", err)
+	}
+	for table := range templates {
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in default payloads", table)
+		}
+	}
+	return templates, nil
+}
+
+// applyDefaultPayload fills fields a new item's payload leaves out from the table's template
+// Client values win, including an explicit null. Only REST creates apply it: sync pushes
+// carry the client's full local copy, which the server must not rewrite.
+func (c *Config) applyDefaultPayload(table string, payload map[string]any) {
+	for k, v := range c.DefaultPayloads[table] {
+		if _, ok := payload[k]; !ok {
+			payload[k] = cloneJSONValue(v)
+		}
+	}
+}
+
+// cloneJSONValue deep-copies a decoded JSON value so items never share template maps or slices
+func cloneJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = cloneJSONValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneJSONValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,46 @@
+package syncservice
+
+import "testing"
+
+func TestApplyDefaultPayload(t *testing.T) {
+	templates, err := ParseDefaultPayloads(`{"task":{"status":"open","done":false,"tags":["inbox"]}}`)
+	if err != nil {
+		t.Fatalf("ParseDefaultPayloads failed: %v", err)
+	}
+	cfg := &Config{DefaultPayloads: templates}
+
+	payload := map[string]any{"title": "Buy milk", "status": "in_progress", "done": nil}
+	cfg.applyDefaultPayload("task", payload)
+	if payload["status"] != "in_progress" || payload["done"] != nil {
+		t.Errorf("Expected client values (including null) to win, got %v", payload)
+	}
+	tags, ok := payload["tags"].([]any)
+	if !ok || len(tags) != 1 || tags[0] != "inbox" {
+		t.Fatalf("Expected template tags, got %v", payload["tags"])
+	}
+
+	// Items never share the template's slices
+	tags[0] = "changed"
+	other := map[string]any{}
+	cfg.applyDefaultPayload("task", other)
+	if other["tags"].([]any)[0] != "inbox" {
+		t.Errorf("Expected template to be copied, got %v", other["tags"])
+	}
+
+	note := map[string]any{"title": "Note"}
+	cfg.applyDefaultPayload("note", note)
+	if len(note) != 1 {
+		t.Errorf("Expected entities without a template untouched, got %v", note)
+	}
+}
+
+func TestParseDefaultPayloads(t *testing.T) {
+	for _, v := range []string{`{"widget":{"a":1}}`, `{"task":"open"}`, `not json`} {
+		if _, err := ParseDefaultPayloads(v); err == nil {
+			t.Errorf("ParseDefaultPayloads(%q) expected error", v)
+		}
+	}
+	if templates, err := ParseDefaultPayloads(`{}`); err != nil || len(templates) != 0 {
+		t.Errorf("Expected {} to disable templates, got %v %v", templates, err)
+	}
+}
@@ -0,0 +1,96 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WipeEntityTx hard-deletes every row of one entity table for a user, drops its purge
+// markers, and records the wipe so clients reset just that entity. The epoch is untouched.
+// wiped_at_ms always moves forward so clients can detect a second wipe, and pushes of
+// items updated before it are refused (see checkEntityWipe).
+// Returns the number of deleted rows and the wipe timestamp.
+// Cached items of the table are dropped from cache (nil = no cache).
+func WipeEntityTx(ctx context.Context, tx pgx.Tx, cache *ItemCache, table, userID string, nowMs int64) (int, int64, error) {
+	cache.InvalidateTable(tx, table)
+
+	var deleted int
+	err := tx.QueryRow(ctx, `
+		WITH del AS (
+			DELETE FROM `+table+` WHERE owner_id = $1 RETURNING 1
+		)
+		SELECT COUNT(*) FROM del
+	`, userID).Scan(&deleted)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM purge_marker WHERE owner_id = $1 AND entity = $2`,
+		userID, table); err != nil {
+		return 0, 0, err
+	}
+
+	var wipedAtMs int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO entity_wipe (owner_id, entity, wiped_at_ms)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (owner_id, entity) DO UPDATE
+			SET wiped_at_ms = GREATEST(EXCLUDED.wiped_at_ms, entity_wipe.wiped_at_ms + 1)
+		RETURNING wiped_at_ms
+	`, userID, table, nowMs).Scan(&wipedAtMs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return deleted, wipedAtMs, nil
+}
+
+// checkEntityWipe refuses a pushed item last updated at or before the latest wipe of its
+// entity. The wipe removed the rows without bumping the epoch, so an offline client that
+// pushes before it pulls (and sees wipedAt) would otherwise re-insert every wiped item:
+// LWW has no stored row left to lose against.
+func checkEntityWipe(ctx context.Context, tx pgx.Tx, table, userID string, ext syncx.Extracted) error {
+	var wipedAtMs int64
+	err := tx.QueryRow(ctx,
+		`SELECT wiped_at_ms FROM entity_wipe WHERE owner_id = $1 AND entity = $2`,
+		userID, table).Scan(&wipedAtMs)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if ext.UpdatedAtMs > wipedAtMs {
+		return nil
+	}
+	return &syncx.FieldError{
+		Field:  "updatedTs",
+		Reason: fmt.Sprintf("%s items were wiped at %s; pull to reset before pushing older items", table, syncx.RFC3339(wipedAtMs)),
+	}
+}
+
+// EntityWipes returns the latest wipe timestamp (RFC3339) per entity table for a user
+func EntityWipes(ctx context.Context, db *pgxpool.Pool, userID string) (map[string]string, error) {
+	rows, err := db.Query(ctx,
+		`SELECT entity, wiped_at_ms FROM entity_wipe WHERE owner_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	wipes := make(map[string]string)
+	for rows.Next() {
+		var entity string
+		var ms int64
+		if err := rows.Scan(&entity, &ms); err != nil {
+			return nil, err
+		}
+		wipes[entity] = syncx.RFC3339(ms)
+	}
+	return wipes, rows.Err()
+}
@@ -0,0 +1,51 @@
+package syncservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// ExportMetaColumns lead every CSV export row; they come from the item, not its payload
+var ExportMetaColumns = []string{"uid", "version", "updatedAt", "deletedAt"}
+
+// DefaultExportColumns is the built-in per-entity list of payload fields a CSV export
+// may contain, in column order. Entities without an entry have no export.
+var DefaultExportColumns = map[string][]string{
+	"task": {"title", "description", "status", "priority", "done", "dueDate", "taskListUid", "tags"},
+}
+
+// ParseExportColumns parses export columns from config: a JSON object mapping entity
+// tables to payload fields, e.g. {"task":["title","status","dueDate"]}. "{}" disables
+// every export.
+func ParseExportColumns(v string) (map[string][]string, error) {
+	var columns map[string][]string
+	if err := json.Unmarshal([]byte(v), &columns); err != nil {
+		return nil, fmt.Errorf("export columns must be a JSON object of entity field lists: %w", err)
+	}
+	for table, fields := range columns {
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in export columns", table)
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s: export columns must not be empty (leave the entity out instead)", table)
+		}
+		for i, field := range fields {
+			if field == "" {
+				return nil, fmt.Errorf("%s: empty export column name", table)
+			}
+			if slices.Contains(ExportMetaColumns, field) {
+				return nil, fmt.Errorf("%s: %q is always exported and cannot be listed", table, field)
+			}
+			if slices.Contains(fields[:i], field) {
+				return nil, fmt.Errorf("%s: duplicate export column %q", table, field)
+			}
+		}
+	}
+	return columns, nil
+}
+
+// ExportColumnsFor returns the exportable payload fields of an entity table (nil = no export)
+func (c *Config) ExportColumnsFor(table string) []string {
+	return c.ExportColumns[table]
+}
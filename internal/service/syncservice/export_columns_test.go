@@ -0,0 +1,29 @@
+package syncservice
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseExportColumns(t *testing.T) {
+	got, err := ParseExportColumns(`{"task":["title","status"],"note":["title"]}`)
+	if err != nil {
+		t.Fatalf("ParseExportColumns() error = %v", err)
+	}
+	if !slices.Equal(got["task"], []string{"title", "status"}) || !slices.Equal(got["note"], []string{"title"}) {
+		t.Errorf("ParseExportColumns() = %v", got)
+	}
+
+	for _, bad := range []string{
+		`["title"]`,
+		`{"tasks":["title"]}`,
+		`{"task":[]}`,
+		`{"task":[""]}`,
+		`{"task":["uid"]}`,
+		`{"task":["title","title"]}`,
+	} {
+		if _, err := ParseExportColumns(bad); err == nil {
+			t.Errorf("ParseExportColumns(%s): expected an error", bad)
+		}
+	}
+}
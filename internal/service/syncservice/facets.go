@@ -0,0 +1,64 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FacetCount is the number of items sharing one value of the faceted field
+// Value is nil for items where the field is absent or null.
+type FacetCount struct {
+	Value *string `json:"value"`
+	Count int64   `json:"count"`
+}
+
+// FacetResponse is the response for GET /v1/<entity>/facets
+type FacetResponse struct {
+	By     string       `json:"by"`
+	Counts []FacetCount `json:"counts"`
+}
+
+// ParseFacetField validates a ?by= param for an entity table
+// Facets use the same allowlist as filters (QueryableFields): low-cardinality fields keep
+// the groups few, and fields encrypted at rest are refused rather than counted as null.
+func (c *Config) ParseFacetField(table, by string) (string, error) {
+	if by == "" {
+		return "", fmt.Errorf("by is required (allowed: %s)", strings.Join(c.QueryableFields(table), ", "))
+	}
+	if err := c.checkQueryableField(table, by, "faceted"); err != nil {
+		return "", err
+	}
+	return by, nil
+}
+
+// CountFacets counts a user's items grouped by a payload field (see ParseFacetField) in
+// one query. opts narrows the items counted with the same deletion and ?where= filters
+// as a REST list. Counts are ordered by count descending, then value.
+func CountFacets(ctx context.Context, db *pgxpool.Pool, table, userID, field string, opts ListOpts) (*FacetResponse, error) {
+	filter, filterArgs := opts.whereClause(3)
+	query := `
+		SELECT payload_json->>$2::text AS value, COUNT(*)
+		FROM ` + table + `
+		WHERE owner_id = $1` + opts.deletedClause() + filter + `
+		GROUP BY value
+		ORDER BY COUNT(*) DESC, value NULLS LAST`
+
+	rows, err := db.Query(ctx, query, append([]any{userID, field}, filterArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("count %s facets: %w", table, err)
+	}
+	defer rows.Close()
+
+	resp := &FacetResponse{By: field, Counts: []FacetCount{}}
+	for rows.Next() {
+		var c FacetCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			return nil, fmt.Errorf("scan %s facet: %w", table, err)
+		}
+		resp.Counts = append(resp.Counts, c)
+	}
+	return resp, rows.Err()
+}
@@ -0,0 +1,36 @@
+package syncservice
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFacetField(t *testing.T) {
+	cfg := DefaultConfig()
+	if got, err := cfg.ParseFacetField("task", "status"); err != nil || got != "status" {
+		t.Errorf("ParseFacetField(status) = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "title"} {
+		if _, err := cfg.ParseFacetField("task", bad); err == nil {
+			t.Errorf("ParseFacetField(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestParseFacetField_Encrypted(t *testing.T) {
+	cfg := encryptedConfig(t)
+
+	// Relationship fields stay plaintext and can still be faceted
+	if _, err := cfg.ParseFacetField("comment", "parentType"); err != nil {
+		t.Errorf("plaintext field: %v", err)
+	}
+
+	// Encrypted fields would all count as null, so they are refused
+	_, err := cfg.ParseFacetField("task", "status")
+	if err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Errorf("encrypted field: error = %v, want an encrypted-at-rest error", err)
+	}
+	if _, err := cfg.ParseFacetField("task", ""); err == nil || strings.Contains(err.Error(), "status") {
+		t.Errorf("missing by: error = %v, want one listing only plaintext fields", err)
+	}
+}
@@ -0,0 +1,117 @@
+package syncservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// DefaultFieldFormats is the built-in per-entity map of payload fields to formats
+// Formats are "url" (absolute URL with scheme and host), "email" (bare address)
+// and "enum:a,b,c" (one of the listed values).
+var DefaultFieldFormats = map[string]map[string]string{
+	"note":    {"sourceUrl": "url"},
+	"comment": {"authorEmail": "email"},
+}
+
+// ParseFieldFormats parses format rules from config: a JSON object mapping entity tables
+// to field formats, e.g. {"task":{"priority":"enum:low,medium,high"}}. "{}" disables them.
+func ParseFieldFormats(v string) (map[string]map[string]string, error) {
+	var formats map[string]map[string]string
+	if err := json.Unmarshal([]byte(v), &formats); err != nil {
+		return nil, fmt.Errorf("field formats must be a JSON object of entity field formats: %w", err)
+	}
+	for table, fields := range formats {
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in field formats", table)
+		}
+		for field, format := range fields {
+			if err := checkFieldFormat(format); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", table, field, err)
+			}
+		}
+	}
+	return formats, nil
+}
+
+// checkFieldFormat rejects a format this server cannot validate
+func checkFieldFormat(format string) error {
+	switch {
+	case format == "url", format == "email":
+		return nil
+	case strings.HasPrefix(format, "enum:"):
+		if len(enumValues(format)) == 0 {
+			return fmt.Errorf("enum format %q lists no values", format)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q (want url, email or enum:a,b,c)", format)
+	}
+}
+
+func enumValues(format string) []string {
+	var values []string
+	for _, v := range strings.Split(strings.TrimPrefix(format, "enum:"), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// validateFieldFormats checks an item's fields against the table's format rules
+// Returns a *syncx.FieldError naming the field and expected format (push ack error,
+// REST 422). Absent or null fields are accepted so partial writes and clears pass.
+func (c *Config) validateFieldFormats(table string, item map[string]any) error {
+	rules := c.FieldFormats[table]
+	fields := make([]string, 0, len(rules))
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields) // report the same field first on every attempt
+
+	for _, field := range fields {
+		v, ok := item[field]
+		if !ok || v == nil {
+			continue
+		}
+		if err := validateFieldFormat(rules[field], v); err != "" {
+			return &syncx.FieldError{Field: field, Reason: err}
+		}
+	}
+	return nil
+}
+
+// validateFieldFormat returns why v does not match format, or "" if it does
+func validateFieldFormat(format string, v any) string {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Sprintf("must be a string (%s), got %T", format, v)
+	}
+	switch {
+	case format == "url":
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Sprintf("must be an absolute URL, got %q", s)
+		}
+	case format == "email":
+		addr, err := mail.ParseAddress(s)
+		if err != nil || addr.Address != s {
+			return fmt.Sprintf("must be an email address, got %q", s)
+		}
+	case strings.HasPrefix(format, "enum:"):
+		values := enumValues(format)
+		for _, allowed := range values {
+			if s == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(values, ", "), s)
+	}
+	return ""
+}
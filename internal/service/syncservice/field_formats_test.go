@@ -0,0 +1,72 @@
+package syncservice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestValidateFieldFormats(t *testing.T) {
+	cfg := &Config{FieldFormats: map[string]map[string]string{
+		"note": {"sourceUrl": "url", "contact": "email", "priority": "enum:low, high"},
+	}}
+
+	tests := []struct {
+		name      string
+		item      map[string]any
+		wantField string
+		wantIn    string
+	}{
+		{name: "valid fields", item: map[string]any{"sourceUrl": "https://example.com/a?b=c", "contact": "a@example.com", "priority": "low"}},
+		{name: "absent and null fields", item: map[string]any{"sourceUrl": nil}},
+		{name: "relative url", item: map[string]any{"sourceUrl": "/just/a/path"}, wantField: "sourceUrl", wantIn: "absolute URL"},
+		{name: "empty url", item: map[string]any{"sourceUrl": ""}, wantField: "sourceUrl", wantIn: "absolute URL"},
+		{name: "url wrong type", item: map[string]any{"sourceUrl": 42.0}, wantField: "sourceUrl", wantIn: "must be a string (url)"},
+		{name: "email with display name", item: map[string]any{"contact": "Ann <a@example.com>"}, wantField: "contact", wantIn: "email address"},
+		{name: "not an email", item: map[string]any{"contact": "nobody"}, wantField: "contact", wantIn: "email address"},
+		{name: "enum value not listed", item: map[string]any{"priority": "urgent"}, wantField: "priority", wantIn: "one of low, high"},
+		{name: "first field in name order wins", item: map[string]any{"sourceUrl": "x", "contact": "y"}, wantField: "contact"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cfg.validateFieldFormats("note", tt.item)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("validateFieldFormats() error = %v, want nil", err)
+				}
+				return
+			}
+			var fieldErr *syncx.FieldError
+			if !errors.As(err, &fieldErr) {
+				t.Fatalf("validateFieldFormats() error = %v, want *FieldError", err)
+			}
+			if fieldErr.Field != tt.wantField || !strings.Contains(fieldErr.Reason, tt.wantIn) {
+				t.Errorf("validateFieldFormats() = %v, want field %s with %q", err, tt.wantField, tt.wantIn)
+			}
+		})
+	}
+
+	// Entities without rules accept anything
+	if err := cfg.validateFieldFormats("task", map[string]any{"sourceUrl": "x"}); err != nil {
+		t.Errorf("Expected no rules for task, got %v", err)
+	}
+}
+
+func TestParseFieldFormats(t *testing.T) {
+	formats, err := ParseFieldFormats(`{"task":{"priority":"enum:low,high"},"comment":{"authorEmail":"email"}}`)
+	if err != nil {
+		t.Fatalf("ParseFieldFormats() error = %v", err)
+	}
+	if formats["task"]["priority"] != "enum:low,high" {
+		t.Errorf("Unexpected formats: %v", formats)
+	}
+
+	for _, v := range []string{`[]`, `{"widget":{"a":"url"}}`, `{"note":{"a":"phone"}}`, `{"note":{"a":"enum:"}}`} {
+		if _, err := ParseFieldFormats(v); err == nil {
+			t.Errorf("ParseFieldFormats(%s) expected error", v)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5"
+)
+
+type forceWriteKey struct{}
+
+// WithForceWrite marks pushes made with ctx as forced: each item replaces the stored row
+// whatever its timestamp (see advanceForcedWrite). Meant for controlled data migrations;
+// the HTTP and gRPC layers only set it for tokens holding the force-write scope.
+func WithForceWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceWriteKey{}, true)
+}
+
+// IsForceWrite reports whether pushes made with ctx are forced
+func IsForceWrite(ctx context.Context) bool {
+	force, _ := ctx.Value(forceWriteKey{}).(bool)
+	return force
+}
+
+// advanceForcedWrite moves a forced item's timestamp just past the stored row's, so the
+// ordinary LWW upsert applies it and bumps the version. The timestamp is moved rather
+// than the guard skipped so the stored row stays monotonic: pull cursors and other
+// clients' LWW comparisons then see the forced write as the newest one.
+func advanceForcedWrite(ctx context.Context, tx pgx.Tx, table, userID string, ext *syncx.Extracted, item map[string]any) error {
+	if !IsForceWrite(ctx) {
+		return nil
+	}
+	var storedMs int64
+	err := tx.QueryRow(ctx,
+		`SELECT updated_at_ms FROM `+table+` WHERE owner_id = $1 AND uid = $2 FOR UPDATE`,
+		userID, ext.UID).Scan(&storedMs)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("probe forced write: %w", err)
+	}
+	if ext.UpdatedAtMs > storedMs {
+		return nil
+	}
+	ext.UpdatedAtMs = storedMs + 1
+	item["updatedTs"] = syncx.RFC3339(ext.UpdatedAtMs)
+	return nil
+}
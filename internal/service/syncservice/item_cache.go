@@ -0,0 +1,184 @@
+package syncservice
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ItemCacheCfg configures the in-memory cache in front of single-item reads (GetNote, GetTask, ...)
+type ItemCacheCfg struct {
+	Size int           // max cached items (0 = cache disabled)
+	TTL  time.Duration // how long an entry is served before it is read again
+}
+
+// ItemCacheStats are the cache counters since startup
+type ItemCacheStats struct {
+	Enabled   bool   `json:"enabled"`
+	Size      int    `json:"size"`
+	Capacity  int    `json:"capacity"`
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
+
+// itemCacheKey identifies one item of one user
+type itemCacheKey struct {
+	table  string
+	userID string
+	uid    uuid.UUID
+}
+
+type itemCacheEntry struct {
+	key       itemCacheKey
+	item      RESTItem
+	expiresAt time.Time
+}
+
+// ItemCache is an LRU of RESTItems with a TTL, shared by the services of one Config
+// A nil *ItemCache is a disabled cache: every read misses and nothing is stored.
+//
+// Writers invalidate an item twice: inside their transaction and again once it commits
+// (see invalidateItem). Every invalidation bumps gen, and a read only fills the
+// cache if gen did not move while it queried, so a read racing a write can never cache
+// the pre-write value past that write's commit.
+type ItemCache struct {
+	mu      sync.Mutex
+	cfg     ItemCacheCfg
+	entries map[itemCacheKey]*list.Element
+	order   *list.List // front = most recently used
+	gen     atomic.Uint64
+
+	hits, misses, evictions atomic.Uint64
+}
+
+// NewItemCache creates a single-item read cache (nil when Size is 0, i.e. disabled)
+func NewItemCache(cfg ItemCacheCfg) *ItemCache {
+	if cfg.Size <= 0 {
+		return nil
+	}
+	return &ItemCache{cfg: cfg, entries: make(map[itemCacheKey]*list.Element), order: list.New()}
+}
+
+// Metrics reports the cache counters
+func (c *ItemCache) Metrics() ItemCacheStats {
+	if c == nil {
+		return ItemCacheStats{}
+	}
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+	return ItemCacheStats{
+		Enabled:   true,
+		Size:      size,
+		Capacity:  c.cfg.Size,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// get returns a copy of a live cached item, plus the generation to pass to put after a miss
+func (c *ItemCache) get(table, userID string, uid uuid.UUID) (*RESTItem, uint64, bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	gen := c.gen.Load()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[itemCacheKey{table, userID, uid}]
+	if !ok || time.Now().After(el.Value.(*itemCacheEntry).expiresAt) {
+		if ok {
+			c.remove(el)
+		}
+		c.misses.Add(1)
+		return nil, gen, false
+	}
+	c.order.MoveToFront(el)
+	c.hits.Add(1)
+	return copyRESTItem(&el.Value.(*itemCacheEntry).item), gen, true
+}
+
+// put caches a copy of item read at generation gen, unless an invalidation happened since
+func (c *ItemCache) put(gen uint64, table, userID string, uid uuid.UUID, item *RESTItem) {
+	if c == nil || item == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen.Load() != gen {
+		return
+	}
+	key := itemCacheKey{table, userID, uid}
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.order.PushFront(&itemCacheEntry{key: key, item: *copyRESTItem(item), expiresAt: time.Now().Add(c.cfg.TTL)})
+	for c.order.Len() > c.cfg.Size {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// invalidateItem drops one item now and again once tx commits
+// Call it from every write path, in the transaction that writes the row (nil tx for
+// autocommit writes).
+func (c *ItemCache) invalidateItem(tx pgx.Tx, table, userID string, uid uuid.UUID) {
+	if c == nil {
+		return
+	}
+	key := itemCacheKey{table, userID, uid}
+	drop := func() { c.invalidate(&key, "") }
+	drop()
+	db.AfterCommit(tx, drop)
+}
+
+// InvalidateTable drops every cached item of a table ("" = all tables) now and again
+// once tx commits, for bulk writes such as wipes, owner merges and cascades
+func (c *ItemCache) InvalidateTable(tx pgx.Tx, table string) {
+	if c == nil {
+		return
+	}
+	drop := func() { c.invalidate(nil, table) }
+	drop()
+	db.AfterCommit(tx, drop)
+}
+
+// invalidate bumps the generation and drops the entry for key or, with a nil key, every
+// entry of table ("" = all)
+func (c *ItemCache) invalidate(key *itemCacheKey, table string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen.Add(1)
+	if key != nil {
+		if el, ok := c.entries[*key]; ok {
+			c.remove(el)
+		}
+		return
+	}
+	for k, el := range c.entries {
+		if table == "" || k.table == table {
+			c.remove(el)
+		}
+	}
+}
+
+// remove unlinks an entry; c.mu must be held
+func (c *ItemCache) remove(el *list.Element) {
+	delete(c.entries, el.Value.(*itemCacheEntry).key)
+	c.order.Remove(el)
+}
+
+// copyRESTItem copies an item deeply enough that callers may modify its payload
+func copyRESTItem(item *RESTItem) *RESTItem {
+	out := *item
+	out.Payload = cloneJSONValue(item.Payload).(map[string]any)
+	return &out
+}
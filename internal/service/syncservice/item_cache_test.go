@@ -0,0 +1,96 @@
+package syncservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestItemCache(t *testing.T) {
+	cache := NewItemCache(ItemCacheCfg{Size: 2, TTL: time.Minute})
+
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	item := func(title string) *RESTItem {
+		return &RESTItem{UID: "x", Version: 1, Payload: map[string]any{"title": title, "tags": []any{"t"}}}
+	}
+	fill := func(table string, uid uuid.UUID, it *RESTItem) {
+		_, gen, ok := cache.get(table, "user-1", uid)
+		if ok {
+			t.Fatalf("Unexpected hit for %s", uid)
+		}
+		cache.put(gen, table, "user-1", uid, it)
+	}
+
+	fill("note", a, item("A"))
+	got, _, ok := cache.get("note", "user-1", a)
+	if !ok || got.Payload["title"] != "A" {
+		t.Fatalf("Expected hit for A, got %v %v", got, ok)
+	}
+
+	// Callers get copies: mutating one doesn't touch the cache
+	got.Payload["title"] = "changed"
+	got.Payload["tags"].([]any)[0] = "changed"
+	if again, _, _ := cache.get("note", "user-1", a); again.Payload["title"] != "A" || again.Payload["tags"].([]any)[0] != "t" {
+		t.Errorf("Cached item was modified through a returned copy: %v", again.Payload)
+	}
+
+	// Keys are per user and table
+	if _, _, ok := cache.get("note", "user-2", a); ok {
+		t.Error("Expected miss for another user")
+	}
+	if _, _, ok := cache.get("task", "user-1", a); ok {
+		t.Error("Expected miss for another table")
+	}
+
+	// LRU: A was used last, so B is evicted when C arrives
+	fill("note", b, item("B"))
+	cache.get("note", "user-1", a)
+	fill("note", c, item("C"))
+	if _, _, ok := cache.get("note", "user-1", b); ok {
+		t.Error("Expected B to be evicted")
+	}
+	if _, _, ok := cache.get("note", "user-1", a); !ok {
+		t.Error("Expected A to survive eviction")
+	}
+
+	// Invalidation drops the item, and a read that started before it can't refill the cache
+	_, staleGen, _ := cache.get("note", "user-1", b)
+	cache.invalidateItem(nil, "note", "user-1", a)
+	if _, _, ok := cache.get("note", "user-1", a); ok {
+		t.Error("Expected A to be invalidated")
+	}
+	cache.put(staleGen, "note", "user-1", b, item("stale B"))
+	if _, _, ok := cache.get("note", "user-1", b); ok {
+		t.Error("A read racing an invalidation must not be cached")
+	}
+
+	// Bulk invalidation by table
+	cache.InvalidateTable(nil, "note")
+	if _, _, ok := cache.get("note", "user-1", c); ok {
+		t.Error("Expected table invalidation to drop C")
+	}
+
+	stats := cache.Metrics()
+	if !stats.Enabled || stats.Capacity != 2 || stats.Hits == 0 || stats.Misses == 0 || stats.Evictions != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestItemCache_TTL(t *testing.T) {
+	cache := NewItemCache(ItemCacheCfg{Size: 10, TTL: time.Millisecond})
+
+	uid := uuid.New()
+	_, gen, _ := cache.get("note", "user-1", uid)
+	cache.put(gen, "note", "user-1", uid, &RESTItem{Payload: map[string]any{}})
+	time.Sleep(5 * time.Millisecond)
+	if _, _, ok := cache.get("note", "user-1", uid); ok {
+		t.Error("Expected expired entry to miss")
+	}
+
+	// Disabled cache never hits
+	cache = NewItemCache(ItemCacheCfg{})
+	if _, _, ok := cache.get("note", "user-1", uid); ok || cache.Metrics().Enabled {
+		t.Error("Expected disabled cache")
+	}
+}
@@ -0,0 +1,84 @@
+package syncservice
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/payloadcrypt"
+)
+
+// FilterableFields lists the payload keys each entity table can be filtered on in REST
+// list requests (?where=key:value). Kept to low-cardinality status/relationship fields
+// so filters stay cheap alongside the owner-scoped cursor scan.
+var FilterableFields = map[string][]string{
+	"note":               {"status", "priority"},
+	"task":               {"status", "priority", "done", "taskListUid"},
+	"comment":            {"status", "parentType", "parentUid"},
+	"chat":               {"status"},
+	"chat_message":       {"chatUid", "role"},
+	"task_list":          {"status", "categoryUid"},
+	"task_list_category": {"status"},
+}
+
+// FieldFilter matches items whose payload field equals Value (compared as text)
+type FieldFilter struct {
+	Key   string
+	Value string
+}
+
+// QueryableFields returns the FilterableFields of a table that SQL can currently read.
+// With encryption at rest only payloadcrypt.PlaintextFields stay readable, so the rest
+// are left out: a filter or facet on them would silently match nothing.
+func (c *Config) QueryableFields(table string) []string {
+	if c.PayloadCipher == nil {
+		return FilterableFields[table]
+	}
+	return slices.DeleteFunc(slices.Clone(FilterableFields[table]), func(f string) bool {
+		return !slices.Contains(payloadcrypt.PlaintextFields, f)
+	})
+}
+
+// checkQueryableField rejects a field outside QueryableFields; verb names the query
+// ("filtered", "faceted") in the error
+func (c *Config) checkQueryableField(table, field, verb string) error {
+	allowed := c.QueryableFields(table)
+	if slices.Contains(allowed, field) {
+		return nil
+	}
+	if slices.Contains(FilterableFields[table], field) {
+		return fmt.Errorf("field %q is encrypted at rest and cannot be %s (allowed: %s)", field, verb, strings.Join(allowed, ", "))
+	}
+	return fmt.Errorf("field %q cannot be %s (allowed: %s)", field, verb, strings.Join(allowed, ", "))
+}
+
+// ParseWhereFilters parses repeated ?where=key:value params for an entity table
+// Keys must be in QueryableFields; the value is everything after the first colon.
+func (c *Config) ParseWhereFilters(table string, raw []string) ([]FieldFilter, error) {
+	var filters []FieldFilter
+	for _, w := range raw {
+		key, value, ok := strings.Cut(w, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid where filter %q (want key:value)", w)
+		}
+		if err := c.checkQueryableField(table, key, "filtered"); err != nil {
+			return nil, err
+		}
+		filters = append(filters, FieldFilter{Key: key, Value: value})
+	}
+	return filters, nil
+}
+
+// whereClause returns the SQL predicates (with leading AND) for the field filters and
+// their args, numbered from argStart. Keys and values are both bound as parameters.
+func (o ListOpts) whereClause(argStart int) (string, []any) {
+	var b strings.Builder
+	args := make([]any, 0, 2*len(o.Where))
+	for _, f := range o.Where {
+		n := argStart + len(args)
+		b.WriteString(` AND payload_json->>$` + strconv.Itoa(n) + `::text = $` + strconv.Itoa(n+1) + `::text`)
+		args = append(args, f.Key, f.Value)
+	}
+	return b.String(), args
+}
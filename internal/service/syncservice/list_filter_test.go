@@ -0,0 +1,62 @@
+package syncservice
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/payloadcrypt"
+)
+
+// encryptedConfig returns the default settings with encryption at rest enabled
+func encryptedConfig(t *testing.T) *Config {
+	t.Helper()
+	c, err := payloadcrypt.NewCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("NewCipher: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.PayloadCipher = c
+	return cfg
+}
+
+func TestParseWhereFilters(t *testing.T) {
+	cfg := DefaultConfig()
+	got, err := cfg.ParseWhereFilters("task", []string{"status:open", "taskListUid:a:b"})
+	if err != nil {
+		t.Fatalf("ParseWhereFilters() error = %v", err)
+	}
+	want := []FieldFilter{{Key: "status", Value: "open"}, {Key: "taskListUid", Value: "a:b"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("ParseWhereFilters() = %+v, want %+v", got, want)
+	}
+
+	for _, bad := range []string{"status", ":open", "title:x"} {
+		if _, err := cfg.ParseWhereFilters("task", []string{bad}); err == nil {
+			t.Errorf("ParseWhereFilters(%q): expected an error", bad)
+		}
+	}
+}
+
+func TestParseWhereFilters_Encrypted(t *testing.T) {
+	cfg := encryptedConfig(t)
+
+	if got := cfg.QueryableFields("task"); !slices.Equal(got, []string{"taskListUid"}) {
+		t.Errorf("QueryableFields(task) = %v, want [taskListUid]", got)
+	}
+	if !slices.Contains(FilterableFields["task"], "status") {
+		t.Fatal("QueryableFields modified FilterableFields")
+	}
+
+	// Relationship fields stay plaintext and can still be filtered
+	if _, err := cfg.ParseWhereFilters("comment", []string{"parentType:note", "parentUid:x"}); err != nil {
+		t.Errorf("plaintext fields: %v", err)
+	}
+
+	// Fields only stored encrypted would match nothing, so they are refused
+	_, err := cfg.ParseWhereFilters("task", []string{"status:open"})
+	if err == nil || !strings.Contains(err.Error(), "encrypted") {
+		t.Errorf("encrypted field: error = %v, want an encrypted-at-rest error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package syncservice
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultRedactedLogKeys are the payload keys masked in logs when none are configured
+var DefaultRedactedLogKeys = []string{"content", "token", "password", "secret"}
+
+// redactedValue replaces the value of a redacted key in logs
+const redactedValue = "[REDACTED]"
+
+// omittedPayload replaces a whole payload in logs when payload logging is disabled
+const omittedPayload = "[payload omitted]"
+
+// ParseRedactedLogKeys parses a comma-separated list of payload keys to mask in logs
+// An empty list is allowed (nothing masked).
+func ParseRedactedLogKeys(v string) []string {
+	var keys []string
+	for _, key := range strings.Split(v, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// LogPayload returns a payload that is safe to log: a copy with redacted keys masked at
+// any depth, or a placeholder when payload logging is disabled. The input is not modified.
+// Use it wherever a payload is passed to the logger, e.g. Interface("item", s.Cfg.LogPayload(item)).
+func (c *Config) LogPayload(payload map[string]any) any {
+	if !c.PayloadLogging {
+		return omittedPayload
+	}
+	return c.redactValue(payload)
+}
+
+// redacted reports whether a payload key is masked in logs (matched case-insensitively)
+func (c *Config) redacted(key string) bool {
+	return slices.ContainsFunc(c.RedactedLogKeys, func(k string) bool { return strings.EqualFold(k, key) })
+}
+
+func (c *Config) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, val := range v {
+			if c.redacted(k) {
+				out[k] = redactedValue
+			} else {
+				out[k] = c.redactValue(val)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, val := range v {
+			out[i] = c.redactValue(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,41 @@
+package syncservice
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogPayload(t *testing.T) {
+	cfg := DefaultConfig()
+
+	payload := map[string]any{
+		"uid":     "n1",
+		"content": "my diary",
+		"meta":    map[string]any{"Token": "abc", "kind": "x"},
+		"items":   []any{map[string]any{"content": "nested"}},
+	}
+
+	got := cfg.LogPayload(payload)
+	want := map[string]any{
+		"uid":     "n1",
+		"content": "[REDACTED]",
+		"meta":    map[string]any{"Token": "[REDACTED]", "kind": "x"},
+		"items":   []any{map[string]any{"content": "[REDACTED]"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LogPayload() = %v, want %v", got, want)
+	}
+	if payload["content"] != "my diary" {
+		t.Errorf("LogPayload modified its input: %v", payload)
+	}
+
+	cfg.RedactedLogKeys = ParseRedactedLogKeys(" kind , ")
+	if got := cfg.LogPayload(payload).(map[string]any); got["content"] != "my diary" || got["meta"].(map[string]any)["kind"] != "[REDACTED]" {
+		t.Errorf("Expected only configured keys to be masked, got %v", got)
+	}
+
+	cfg.PayloadLogging = false
+	if got := cfg.LogPayload(payload); got != "[payload omitted]" {
+		t.Errorf("Expected payload to be omitted, got %v", got)
+	}
+}
@@ -0,0 +1,44 @@
+package syncservice
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+)
+
+// lwwTieKey returns the tie-breaker for two writes of one item with the same updated_at_ms
+//
+// LWW alone lets whichever of them commits first win, so concurrent writers could
+// converge on either payload. The upserts first let a delete beat an edit (a hash
+// comparison would resurrect a tombstone about half the time); between writes with
+// the same deleted state the larger key wins, regardless of arrival order. It digests the
+// plaintext payload (keys sorted by encoding/json) without sync.version, which clients
+// and the server rewrite freely, so a duplicate push has the same key and stays a no-op.
+// With encryption at rest the digest is an HMAC under the payload key (see
+// payloadcrypt.Cipher.MAC): a plain hash stored next to the ciphertext would let anyone
+// with database access confirm a guessed payload. Rows written before tie keys existed
+// keep first-writer-wins on ties (a NULL key never compares greater).
+func (c *Config) lwwTieKey(item map[string]any) []byte {
+	keyed := item
+	if syncBlock, ok := item["sync"].(map[string]any); ok {
+		keyed = make(map[string]any, len(item))
+		for k, v := range item {
+			keyed[k] = v
+		}
+		stripped := make(map[string]any, len(syncBlock))
+		for k, v := range syncBlock {
+			if k != "version" {
+				stripped[k] = v
+			}
+		}
+		keyed["sync"] = stripped
+	}
+	raw, err := json.Marshal(keyed)
+	if err != nil {
+		return nil
+	}
+	if c.PayloadCipher != nil {
+		return c.PayloadCipher.MAC(raw)
+	}
+	sum := sha256.Sum256(raw)
+	return sum[:]
+}
@@ -0,0 +1,345 @@
+package syncservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestLWWTieKey(t *testing.T) {
+	a := map[string]any{"uid": "u", "title": "a", "sync": map[string]any{"version": 1, "isDeleted": false}}
+	sameButVersion := map[string]any{"uid": "u", "title": "a", "sync": map[string]any{"version": 7, "isDeleted": false}}
+	deleted := map[string]any{"uid": "u", "title": "a", "sync": map[string]any{"version": 1, "isDeleted": true}}
+	other := map[string]any{"uid": "u", "title": "b", "sync": map[string]any{"version": 1, "isDeleted": false}}
+	cfg := DefaultConfig()
+
+	if !bytes.Equal(cfg.lwwTieKey(a), cfg.lwwTieKey(sameButVersion)) {
+		t.Error("sync.version must not affect the tie key (duplicate pushes would bump the version)")
+	}
+	if bytes.Equal(cfg.lwwTieKey(a), cfg.lwwTieKey(deleted)) {
+		t.Error("a delete and an edit with the same content need different keys")
+	}
+	if bytes.Equal(cfg.lwwTieKey(a), cfg.lwwTieKey(other)) {
+		t.Error("different payloads need different keys")
+	}
+	if a["sync"].(map[string]any)["version"] != 1 {
+		t.Error("lwwTieKey must not modify the item")
+	}
+}
+
+func TestLWWTieKey_Encrypted(t *testing.T) {
+	item := map[string]any{"uid": "u", "title": "secret", "sync": map[string]any{"version": 1}}
+	plain := DefaultConfig().lwwTieKey(item)
+
+	cfg := encryptedConfig(t)
+	keyed := cfg.lwwTieKey(item)
+	if bytes.Equal(plain, keyed) {
+		t.Error("with encryption at rest the tie key must not be a plain hash of the payload")
+	}
+	if !bytes.Equal(keyed, cfg.lwwTieKey(item)) {
+		t.Error("keyed tie keys must stay deterministic so duplicate pushes are no-ops")
+	}
+}
+
+// lwwTestDB opens TEST_DATABASE_URL and returns the pool plus a fresh user ID
+func lwwTestDB(t *testing.T) (*pgxpool.Pool, string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration tests")
+	}
+	pool, err := db.Open(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	var userID string
+	if err := pool.QueryRow(context.Background(),
+		`INSERT INTO app_user (sub) VALUES ($1) RETURNING id`, "lww-"+uuid.NewString(),
+	).Scan(&userID); err != nil {
+		t.Fatalf("Failed to create test user: %v", err)
+	}
+	return pool, userID
+}
+
+// lwwWrite is one push of the harness: a full note payload at a client timestamp
+type lwwWrite struct {
+	ms      int64
+	title   string
+	deleted bool // push a tombstone deleted at ms
+}
+
+func (w lwwWrite) item(uid uuid.UUID) map[string]any {
+	sync := map[string]any{"version": 1}
+	if w.deleted {
+		sync["isDeleted"] = true
+		sync["deletedAt"] = syncx.RFC3339(w.ms)
+	}
+	return map[string]any{
+		"uid":       uid.String(),
+		"title":     w.title,
+		"updatedTs": syncx.RFC3339(w.ms),
+		"sync":      sync,
+	}
+}
+
+// pushNote pushes one write in its own transaction
+func pushNote(ctx context.Context, svc *NoteService, userID string, uid uuid.UUID, w lwwWrite) error {
+	tx, err := db.Begin(ctx, svc.DB)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if ack := svc.PushNoteItem(ctx, tx, userID, w.item(uid)); ack.Error != "" {
+		return errors.New(ack.Error)
+	}
+	return tx.Commit(ctx)
+}
+
+// storedNote is the LWW-relevant state of a stored note
+type storedNote struct {
+	ms      int64
+	version int
+	title   string
+	deleted bool
+}
+
+func readNote(ctx context.Context, pool *pgxpool.Pool, userID string, uid uuid.UUID) (storedNote, error) {
+	var n storedNote
+	err := pool.QueryRow(ctx,
+		`SELECT updated_at_ms, version, payload_json->>'title', deleted_at_ms IS NOT NULL FROM note WHERE owner_id = $1 AND uid = $2`,
+		userID, uid).Scan(&n.ms, &n.version, &n.title, &n.deleted)
+	return n, err
+}
+
+// TestLWWConcurrentPushes_Integration fires many pushes with overlapping timestamps at one
+// UID and checks they converge on the same winner as any sequential order would
+func TestLWWConcurrentPushes_Integration(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	svc := NewNoteService(pool)
+
+	const base = int64(1_700_000_000_000)
+	var writes []lwwWrite
+	for i := 0; i < 48; i++ {
+		writes = append(writes, lwwWrite{ms: base + int64(i%6), title: fmt.Sprintf("write %02d", i)}) // 8 writes per timestamp
+	}
+
+	// Reference outcomes: the same writes applied one at a time, forwards and backwards
+	sequential := func(order []lwwWrite) storedNote {
+		uid := uuid.New()
+		for _, w := range order {
+			if err := pushNote(ctx, svc, userID, uid, w); err != nil {
+				t.Fatalf("Sequential push failed: %v", err)
+			}
+		}
+		n, err := readNote(ctx, pool, userID, uid)
+		if err != nil {
+			t.Fatalf("Failed to read note: %v", err)
+		}
+		return n
+	}
+	reversed := make([]lwwWrite, len(writes))
+	for i, w := range writes {
+		reversed[len(writes)-1-i] = w
+	}
+	forward, backward := sequential(writes), sequential(reversed)
+	if forward.title != backward.title {
+		t.Fatalf("Tie at %d resolved by arrival order: %q forwards, %q backwards", forward.ms, forward.title, backward.title)
+	}
+	if forward.ms != base+5 {
+		t.Fatalf("Expected the highest timestamp %d to win, got %d", base+5, forward.ms)
+	}
+
+	for round := 0; round < 5; round++ {
+		uid := uuid.New()
+
+		// Watch the version while the writers run: it must never go backward
+		stop := make(chan struct{})
+		watchErr := make(chan error, 1)
+		go func() {
+			last := 0
+			for {
+				select {
+				case <-stop:
+					watchErr <- nil
+					return
+				default:
+				}
+				n, err := readNote(ctx, pool, userID, uid)
+				if err != nil {
+					continue // not created yet
+				}
+				if n.version < last {
+					watchErr <- fmt.Errorf("version went backward: %d -> %d", last, n.version)
+					return
+				}
+				last = n.version
+			}
+		}()
+
+		var wg sync.WaitGroup
+		errs := make(chan error, len(writes))
+		for _, w := range writes {
+			wg.Add(1)
+			go func(w lwwWrite) {
+				defer wg.Done()
+				if err := pushNote(ctx, svc, userID, uid, w); err != nil {
+					errs <- err
+				}
+			}(w)
+		}
+		wg.Wait()
+		close(stop)
+		close(errs)
+		for err := range errs {
+			t.Errorf("Round %d: concurrent push failed: %v", round, err)
+		}
+		if err := <-watchErr; err != nil {
+			t.Errorf("Round %d: %v", round, err)
+		}
+
+		got, err := readNote(ctx, pool, userID, uid)
+		if err != nil {
+			t.Fatalf("Round %d: failed to read note: %v", round, err)
+		}
+		if got.ms != forward.ms || got.title != forward.title {
+			t.Errorf("Round %d: converged on %q at %d, want %q at %d", round, got.title, got.ms, forward.title, forward.ms)
+		}
+	}
+}
+
+// TestLWWDeleteWinsTie_Integration checks a tombstone and an edit with the same timestamp
+// resolve to the tombstone in either arrival order, whatever their tie keys
+func TestLWWDeleteWinsTie_Integration(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	svc := NewNoteService(pool)
+
+	const ms = int64(1_700_000_000_000)
+	base := lwwWrite{ms: ms - 1000, title: "base"}
+
+	// Several edit titles so both tie key orderings against the tombstone come up
+	for i := 0; i < 8; i++ {
+		edit := lwwWrite{ms: ms, title: fmt.Sprintf("edit %d", i)}
+		tomb := lwwWrite{ms: ms, title: "base", deleted: true}
+		for _, order := range [][]lwwWrite{{edit, tomb}, {tomb, edit}} {
+			uid := uuid.New()
+			for _, w := range append([]lwwWrite{base}, order...) {
+				if err := pushNote(ctx, svc, userID, uid, w); err != nil {
+					t.Fatalf("Push failed: %v", err)
+				}
+			}
+			got, err := readNote(ctx, pool, userID, uid)
+			if err != nil {
+				t.Fatalf("Failed to read note: %v", err)
+			}
+			if !got.deleted {
+				t.Errorf("%q then %q at the same timestamp: tombstone lost to an edit", order[0].title, order[1].title)
+			}
+		}
+	}
+}
+
+// TestLWWConcurrentIfMatch_Integration checks that REST mutations expecting the same version
+// are serialized: exactly one wins, the rest get a version mismatch instead of a lost update
+func TestLWWConcurrentIfMatch_Integration(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	svc := NewNoteService(pool)
+
+	created, err := svc.ApplyNoteMutation(ctx, userID, map[string]any{"title": "start"}, MutationOpts{})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	const writers = 16
+	var wg sync.WaitGroup
+	results := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := svc.ApplyNoteMutation(ctx, userID,
+				map[string]any{"uid": created.UID, "title": fmt.Sprintf("edit %d", i)},
+				MutationOpts{EnforceVersion: true, ExpectedVersion: created.Version})
+			results <- err
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+
+	wins := 0
+	for err := range results {
+		var mismatch *VersionMismatchError
+		switch {
+		case err == nil:
+			wins++
+		case errors.As(err, &mismatch):
+		default:
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+	if wins != 1 {
+		t.Errorf("Expected exactly one writer to win version %d, got %d", created.Version, wins)
+	}
+
+	got, err := readNote(ctx, pool, userID, uuid.MustParse(created.UID))
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if got.version != created.Version+1 {
+		t.Errorf("Expected version %d after one winning edit, got %d", created.Version+1, got.version)
+	}
+}
+
+// TestForceWrite_Integration checks a forced push replaces a newer stored row and
+// leaves the stored timestamp moving forward
+func TestForceWrite_Integration(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	svc := NewNoteService(pool)
+	uid := uuid.New()
+
+	const base = int64(1_700_000_000_000)
+	if err := pushNote(ctx, svc, userID, uid, lwwWrite{ms: base, title: "newer"}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	before, err := readNote(ctx, pool, userID, uid)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+
+	// Unforced, the older write loses
+	if err := pushNote(ctx, svc, userID, uid, lwwWrite{ms: base - 1000, title: "older"}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+	if got, _ := readNote(ctx, pool, userID, uid); got.title != "newer" {
+		t.Fatalf("Expected the older write to lose, got %q", got.title)
+	}
+
+	// Forced, it wins and is stamped just after the stored row
+	if err := pushNote(WithForceWrite(ctx), svc, userID, uid, lwwWrite{ms: base - 1000, title: "forced"}); err != nil {
+		t.Fatalf("Forced push failed: %v", err)
+	}
+	got, err := readNote(ctx, pool, userID, uid)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if got.title != "forced" || got.ms != base+1 || got.version != before.version+1 {
+		t.Errorf("Forced write: got %q at %d v%d, want %q at %d v%d", got.title, got.ms, got.version, "forced", base+1, before.version+1)
+	}
+}
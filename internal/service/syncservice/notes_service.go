@@ -2,8 +2,8 @@ package syncservice
 
 import (
 	"context"
-	"encoding/json"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -18,23 +18,33 @@ type PushAck struct {
 	UpdatedAt string `json:"updatedAt"`
 	Error     string `json:"error,omitempty"`
 	Applied   bool   `json:"applied,omitempty"`
+
+	err   error  // typed cause of Error for REST mutations (e.g. *QuotaExceededError)
+	seqID *int64 // task display ID for REST mutations (see Config.SeqIDs)
 }
 
 // PullResponse represents the response from a pull operation
 type PullResponse struct {
 	Upserts    []map[string]any `json:"upserts"`
 	Deletes    []map[string]any `json:"deletes"`
+	Inserts    []string         `json:"inserts"` // UIDs of upserts never updated since creation (server version 1)
 	NextCursor *string          `json:"nextCursor,omitempty"`
+
+	// ByteLimited is set when the page stopped before limit rows to stay under the byte cap
+	// (see SetMaxPageBytes); keep pulling from NextCursor
+	ByteLimited bool `json:"byteLimited,omitempty"`
 }
 
 // NoteService encapsulates business logic for note sync operations
 type NoteService struct {
-	DB *pgxpool.Pool
+	DB    *pgxpool.Pool
+	Clock syncx.Clock // time source for server-assigned timestamps (RealClock in production)
+	Cfg   *Config     // sync settings, shared by all services of a server (DefaultConfig unless set)
 }
 
 // NewNoteService creates a new NoteService
 func NewNoteService(db *pgxpool.Pool) *NoteService {
-	return &NoteService{DB: db}
+	return &NoteService{DB: db, Clock: syncx.RealClock{}, Cfg: DefaultConfig()}
 }
 
 // PushNoteItem handles the push logic for a single note item within a transaction
@@ -45,12 +55,105 @@ func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string
 	// Extract sync metadata from client JSON
 	ext, err := syncx.ExtractCommon(item)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Interface("item", s.Cfg.LogPayload(item)).Msg("failed to extract sync metadata")
+		return PushAck{Error: err.Error(), err: err}
 	}
 
+	// New items must use the configured UID version (see Config.RequiredUIDVersion)
+	if err := s.Cfg.checkPushedUIDVersion(ctx, tx, "note", userID, ext.UID); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// New items may not be stamped implausibly far in the past (see Config.MaxCreateAgeDays)
+	if err := s.Cfg.checkCreateAge(ctx, tx, "note", userID, ext, s.Clock.NowMs()); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Items last edited before a wipe of their entity stay wiped (see WipeEntityTx)
+	if err := checkEntityWipe(ctx, tx, "note", userID, ext); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// A forced push replaces the stored row regardless of timestamps (see WithForceWrite)
+	if err := advanceForcedWrite(ctx, tx, "note", userID, &ext, item); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to prepare forced write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Declarative field rules (see Config.validatePayloadFields); tombstones skip
+	// them so items stored before a rule was added can still be deleted
+	if ext.DeletedAtMs == nil {
+		if err := s.Cfg.validatePayloadFields("note", item); err != nil {
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     err.Error(),
+			}
+		}
+	}
+
+	// Omitted fields keep their stored values, explicit nulls clear them (see AbsentFieldMode)
+	item, err = s.Cfg.mergeStoredPayload(ctx, tx, "note", userID, ext.UID, item)
+	if err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to merge stored payload")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to merge stored payload",
+		}
+	}
+
+	// Scheduled deletion (see SweepScheduledDeletes); read after the merge so an omitted
+	// deleteAfter keeps the stored schedule
+	deleteAfterMs, err := syncx.ExtractDeleteAfter(item)
+	if err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// The configured unique field (see Config.UniqueFields) must not be held by another live item
+	uniqueValue, err := s.Cfg.checkUniqueKey(ctx, tx, "note", userID, ext.UID, item, ext.DeletedAtMs != nil)
+	if err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Computed and normalized fields (see RegisterPayloadTransform)
+	s.Cfg.applyPayloadTransforms(ctx, "note", item, ext)
+
 	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
+	payloadJSON, err := s.Cfg.encodePayload(item)
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
 		return PushAck{
@@ -61,24 +164,68 @@ func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string
 		}
 	}
 
+	// Deterministic winner between different writes with the same timestamp
+	tieKey := s.Cfg.lwwTieKey(item)
+
+	// Existing items of an append-only entity can only be deleted (see Config.AppendOnlyEntities)
+	if err := s.Cfg.checkAppendOnly(ctx, tx, "note", userID, ext.UID, tieKey, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Growth past the per-user storage cap is rejected (see Config.MaxPayloadBytes)
+	if err := s.Cfg.checkPayloadQuota(ctx, tx, "note", userID, ext.UID, payloadJSON, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Remember the pre-session state the first time this session writes the item (undo)
+	if err := captureSessionBaseline(ctx, tx, "note", userID, ext.UID); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to capture session baseline")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Cached reads of this item must not outlive the write (see ItemCache.invalidateItem)
+	s.Cfg.ItemCache.invalidateItem(tx, "note", userID, ext.UID)
+
 	// Insert or update with LWW conflict resolution
-	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
-	// If same timestamp arrives twice, version doesn't increment
+	// Key invariant: a duplicate push (same timestamp and content) matches neither branch of
+	// the WHERE clause, so it is a no-op and the version doesn't increment
 	tag, err := tx.Exec(ctx, `
-		INSERT INTO note (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
+		INSERT INTO note (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, delete_after_ms, unique_key, tie_key)
+		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8, $9)
 		ON CONFLICT (owner_id, uid) DO UPDATE SET
-			payload_json   = EXCLUDED.payload_json,
-			updated_at_ms  = EXCLUDED.updated_at_ms,
-			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			-- Bump version only on strictly newer update (not >=, just >)
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > note.updated_at_ms
-				THEN note.version + 1
-				ELSE note.version
-			END
+			payload_json    = EXCLUDED.payload_json,
+			unique_key      = EXCLUDED.unique_key,
+			tie_key         = EXCLUDED.tie_key,
+			updated_at_ms   = EXCLUDED.updated_at_ms,
+			deleted_at_ms   = EXCLUDED.deleted_at_ms,
+			delete_after_ms = EXCLUDED.delete_after_ms,
+			version         = note.version + 1
+		-- Strictly newer wins; on equal timestamps a delete beats an edit, and between two
+		-- edits (or two deletes) the larger tie key wins (see lwwTieKey)
 		WHERE EXCLUDED.updated_at_ms > note.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON)
+			OR (EXCLUDED.updated_at_ms = note.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) > (note.deleted_at_ms IS NOT NULL))
+			OR (EXCLUDED.updated_at_ms = note.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) = (note.deleted_at_ms IS NOT NULL)
+				AND EXCLUDED.tie_key > note.tie_key)
+	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, deleteAfterMs, uniqueValue, tieKey)
 
 	applied := false
 	if err == nil {
@@ -110,6 +257,16 @@ func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string
 		}
 	}
 
+	if err := recordSessionWrite(ctx, tx, "note", userID, ext.UID, ext.UpdatedAtMs, serverMs); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record session write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
 	// Success - return server-authoritative values
 	return PushAck{
 		UID:       ext.UID.String(),
@@ -122,17 +279,30 @@ func (s *NoteService) PushNoteItem(ctx context.Context, tx pgx.Tx, userID string
 // PullNotes handles the pull logic for notes
 // Returns upserts, deletes, and an optional next cursor for pagination
 func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	return s.PullNotesRange(ctx, userID, cursor, syncx.MaxCursor, limit)
+}
+
+// PullNotesRange pulls notes strictly after cursor and at or before to
+func (s *NoteService) PullNotesRange(ctx context.Context, userID string, cursor, to syncx.Cursor, limit int) (*PullResponse, error) {
 	logger := log.With().Logger()
 
 	// Query notes ordered by (updated_at_ms, uid) for deterministic pagination
+	// Purge markers are merged into the same ordering so purged tombstones still propagate
 	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version, false AS purged
 		FROM note
 		WHERE owner_id = $1
 		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		  AND (updated_at_ms, uid) <= ($5, $6::uuid)
+		UNION ALL
+		SELECT NULL::jsonb, purged_at_ms, purged_at_ms, uid, 0, true
+		FROM purge_marker
+		WHERE owner_id = $1 AND entity = 'note'
+		  AND (purged_at_ms, uid) > ($2, $3::uuid)
+		  AND (purged_at_ms, uid) <= ($5, $6::uuid)
 		ORDER BY updated_at_ms, uid
 		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	`, userID, cursor.Ms, cursor.UID, limit, to.Ms, to.UID)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query notes")
@@ -140,20 +310,137 @@ func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx
 	}
 	defer rows.Close()
 
-	upserts := make([]map[string]any, 0, limit)
-	deletes := make([]map[string]any, 0)
-	var lastMs int64
-	var lastUID string
+	budget := s.Cfg.newPageBudget()
+	upserts, deletes, inserts, lastMs, lastUID, err := s.scanNotePullRows(rows, limit, budget)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate next cursor if we returned any results
+	var nextCursor *string
+	if len(upserts)+len(deletes) > 0 {
+		uid, _ := uuid.Parse(lastUID)
+		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
+		nextCursor = &encoded
+	}
+
+	return &PullResponse{
+		Upserts:     upserts,
+		Deletes:     deletes,
+		Inserts:     inserts,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
+	}, nil
+}
+
+// PullByUIDResponse is the response for a pull of specific UIDs
+// Missing lists requested UIDs the user has no row or purge marker for.
+type PullByUIDResponse struct {
+	Upserts []map[string]any `json:"upserts"`
+	Deletes []map[string]any `json:"deletes"`
+	Inserts []string         `json:"inserts"` // see PullResponse.Inserts
+	Missing []string         `json:"missing"`
+}
+
+// PullNotesByUID returns the current state of specific notes in one query
+// Uses the same row shape as PullNotes so tombstones and purge markers come back as deletes.
+func (s *NoteService) PullNotesByUID(ctx context.Context, userID string, uids []uuid.UUID) (*PullByUIDResponse, error) {
+	logger := log.With().Logger()
+
+	uidStrs := make([]string, len(uids))
+	for i, uid := range uids {
+		uidStrs[i] = uid.String()
+	}
+
+	rows, err := s.DB.Query(ctx, `
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version, false AS purged
+		FROM note
+		WHERE owner_id = $1 AND uid = ANY($2::uuid[])
+		UNION ALL
+		SELECT NULL::jsonb, purged_at_ms, purged_at_ms, uid, 0, true
+		FROM purge_marker
+		WHERE owner_id = $1 AND entity = 'note' AND uid = ANY($2::uuid[])
+		ORDER BY updated_at_ms, uid
+	`, userID, uidStrs)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to query notes by uid")
+		return nil, err
+	}
+	defer rows.Close()
+
+	upserts, deletes, inserts, _, _, err := s.scanNotePullRows(rows, len(uids), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(upserts)+len(deletes))
+	for _, d := range deletes {
+		found[d["uid"].(string)] = true
+	}
+	for _, u := range upserts {
+		if uid, ok := u["uid"].(string); ok {
+			found[uid] = true
+		}
+	}
+	missing := make([]string, 0)
+	for _, uid := range uidStrs {
+		if !found[uid] {
+			missing = append(missing, uid)
+		}
+	}
+
+	return &PullByUIDResponse{
+		Upserts: upserts,
+		Deletes: deletes,
+		Inserts: inserts,
+		Missing: missing,
+	}, nil
+}
+
+// scanNotePullRows converts pull query rows (payload, deleted_at_ms, ms, uid, version, purged)
+// into upserts, delete markers and the UIDs of version-1 upserts, returning the position of
+// the last row. It stops early once budget is full (nil = no byte cap).
+func (s *NoteService) scanNotePullRows(rows pgx.Rows, sizeHint int, budget *pageBudget) (upserts, deletes []map[string]any, inserts []string, lastMs int64, lastUID string, err error) {
+	logger := log.With().Logger()
+
+	upserts = make([]map[string]any, 0, sizeHint)
+	deletes = make([]map[string]any, 0)
+	inserts = make([]string, 0)
 
 	for rows.Next() {
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
+		var version int
+		var purged bool
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version, &purged); err != nil {
 			logger.Error().Err(err).Msg("failed to scan note row")
-			return nil, err
+			return nil, nil, nil, 0, "", err
+		}
+
+		if purged {
+			// Purge marker - row is gone, clients must drop their local copy
+			if !budget.admitPullRow(nil, true) {
+				break
+			}
+			deletes = append(deletes, map[string]any{
+				"uid":       uid,
+				"deletedAt": syncx.RFC3339(*deletedAtMs),
+				"purged":    true,
+			})
+			lastMs, lastUID = ms, uid
+			continue
+		}
+
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode note payload")
+			return nil, nil, nil, 0, "", err
+		}
+
+		if !budget.admitPullRow(payload, deletedAtMs != nil) {
+			break
 		}
 
 		if deletedAtMs != nil {
@@ -165,6 +452,9 @@ func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx
 		} else {
 			// Active note - return full payload
 			upserts = append(upserts, payload)
+			if version == 1 {
+				inserts = append(inserts, uid)
+			}
 		}
 
 		lastMs, lastUID = ms, uid
@@ -172,22 +462,10 @@ func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx
 
 	if err := rows.Err(); err != nil {
 		logger.Error().Err(err).Msg("row iteration error")
-		return nil, err
-	}
-
-	// Generate next cursor if we returned any results
-	var nextCursor *string
-	if len(upserts)+len(deletes) > 0 {
-		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
-		nextCursor = &encoded
+		return nil, nil, nil, 0, "", err
 	}
 
-	return &PullResponse{
-		Upserts:    upserts,
-		Deletes:    deletes,
-		NextCursor: nextCursor,
-	}, nil
+	return upserts, deletes, inserts, lastMs, lastUID, nil
 }
 
 // REST-specific methods
@@ -197,16 +475,22 @@ func (s *NoteService) PullNotes(ctx context.Context, userID string, cursor syncx
 func (s *NoteService) GetNote(ctx context.Context, userID string, uid uuid.UUID) (*RESTItem, error) {
 	logger := log.With().Logger()
 
+	cached, gen, ok := s.Cfg.ItemCache.get("note", userID, uid)
+	if ok {
+		return cached, nil
+	}
+
 	var payload map[string]any
 	var version int
 	var updatedAtMs int64
 	var deletedAtMs *int64
+	var createdBy, updatedBy *string
 
 	err := s.DB.QueryRow(ctx, `
-		SELECT payload_json, version, updated_at_ms, deleted_at_ms
+		SELECT payload_json, version, updated_at_ms, deleted_at_ms, created_by, updated_by
 		FROM note
 		WHERE owner_id = $1 AND uid = $2
-	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs)
+	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs, &createdBy, &updatedBy)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -216,11 +500,18 @@ func (s *NoteService) GetNote(ctx context.Context, userID string, uid uuid.UUID)
 		return nil, err
 	}
 
+	if payload, err = s.Cfg.decodePayload(payload); err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to decode note payload")
+		return nil, err
+	}
+
 	// Always return the item (even if deleted) - handler will decide 410 vs 200
 	item := &RESTItem{
 		UID:       uid.String(),
 		Version:   version,
 		UpdatedAt: syncx.RFC3339(updatedAtMs),
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   payload,
 	}
 
@@ -229,26 +520,101 @@ func (s *NoteService) GetNote(ctx context.Context, userID string, uid uuid.UUID)
 		item.DeletedAt = &deletedAt
 	}
 
+	s.Cfg.ItemCache.put(gen, "note", userID, uid, item)
 	return item, nil
 }
 
+// PurgeNote permanently deletes a soft-deleted note and records a purge marker for pull
+// Returns ErrNotDeleted if the note is still active, ErrAlreadyPurged if it was purged before,
+// and ErrNotFound if it never existed. On success returns the purge timestamp.
+func (s *NoteService) PurgeNote(ctx context.Context, userID string, uid uuid.UUID) (int64, error) {
+	logger := log.With().Logger()
+
+	tx, err := db.Begin(ctx, s.DB)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to begin transaction")
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the row so a concurrent push can't revive it between check and delete
+	var updatedAtMs int64
+	var deletedAtMs *int64
+	err = tx.QueryRow(ctx, `
+		SELECT updated_at_ms, deleted_at_ms
+		FROM note
+		WHERE owner_id = $1 AND uid = $2
+		FOR UPDATE
+	`, userID, uid).Scan(&updatedAtMs, &deletedAtMs)
+
+	if err == pgx.ErrNoRows {
+		var purged bool
+		if err := tx.QueryRow(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM purge_marker
+				WHERE owner_id = $1 AND entity = 'note' AND uid = $2
+			)
+		`, userID, uid).Scan(&purged); err != nil {
+			logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to probe purge marker")
+			return 0, err
+		}
+		if purged {
+			return 0, ErrAlreadyPurged
+		}
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to probe note for purge")
+		return 0, err
+	}
+	if deletedAtMs == nil {
+		return 0, ErrNotDeleted
+	}
+
+	// Marker must sort after the tombstone so clients past it still see the purge
+	purgedAtMs := syncx.EnsureMonotonicTimestampAt(s.Clock.NowMs(), updatedAtMs)
+
+	s.Cfg.ItemCache.invalidateItem(tx, "note", userID, uid)
+	if _, err := tx.Exec(ctx, `DELETE FROM note WHERE owner_id = $1 AND uid = $2`, userID, uid); err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to purge note")
+		return 0, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO purge_marker (owner_id, entity, uid, purged_at_ms)
+		VALUES ($1, 'note', $2, $3)
+		ON CONFLICT (owner_id, entity, uid) DO UPDATE SET purged_at_ms = EXCLUDED.purged_at_ms
+	`, userID, uid, purgedAtMs); err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to record purge marker")
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		logger.Error().Err(err).Msg("failed to commit purge")
+		return 0, err
+	}
+
+	return purgedAtMs, nil
+}
+
 // ListNotes returns paginated notes for REST endpoints
-func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx.Cursor, limit int, includeDeleted bool) (*RESTListResponse, error) {
+func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts ListOpts) (*RESTListResponse, error) {
 	logger := log.With().Logger()
 
-	// Build query based on includeDeleted
+	// Build query based on deletion filter
 	query := `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version, created_by, updated_by
 		FROM note
 		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
 	`
-	if !includeDeleted {
-		query += ` AND deleted_at_ms IS NULL`
-	}
-	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
-
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	query += opts.positionClause()
+	query += opts.deletedClause()
+	filter, filterArgs := opts.whereClause(5)
+	query += filter
+	query += opts.orderClause() + ` LIMIT $4`
+
+	args := append([]any{userID, cursor.Ms, cursor.UID, limit}, filterArgs...)
+	rows, err := s.DB.Query(ctx, query, args...)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list notes")
 		return nil, err
@@ -258,6 +624,7 @@ func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
@@ -265,16 +632,24 @@ func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx
 		var ms int64
 		var uid string
 		var version int
+		var createdBy, updatedBy *string
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version, &createdBy, &updatedBy); err != nil {
 			logger.Error().Err(err).Msg("failed to scan note row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode note payload")
+			return nil, err
+		}
+
 		item := RESTItem{
 			UID:       uid,
 			Version:   version,
 			UpdatedAt: syncx.RFC3339(ms),
+			CreatedBy: createdBy,
+			UpdatedBy: updatedBy,
 			Payload:   payload,
 		}
 
@@ -283,6 +658,9 @@ func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx
 			item.DeletedAt = &deletedAt
 		}
 
+		if !budget.admit(item) {
+			break
+		}
 		items = append(items, item)
 		lastMs, lastUID = ms, uid
 	}
@@ -296,46 +674,70 @@ func (s *NoteService) ListNotes(ctx context.Context, userID string, cursor syncx
 	var nextCursor *string
 	if len(items) > 0 {
 		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
+		encoded := syncx.EncodeCursor(opts.cursorAt(lastMs, uid))
 		nextCursor = &encoded
 	}
 
 	return &RESTListResponse{
-		Items:      items,
-		NextCursor: nextCursor,
+		Items:       items,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
 // ApplyNoteMutation creates or updates a note via REST
 // Handles optimistic locking, monotonic timestamps, and soft deletes
 func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
-	logger := log.With().Logger()
-
-	// Start transaction
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to begin transaction")
+		log.Error().Err(err).Msg("failed to begin transaction")
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	item, err := s.ApplyNoteMutationTx(ctx, tx, userID, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to commit mutation")
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ApplyNoteMutationTx is ApplyNoteMutation within an existing transaction
+// The caller is responsible for committing or rolling back the transaction
+func (s *NoteService) ApplyNoteMutationTx(ctx context.Context, tx pgx.Tx, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	logger := log.With().Logger()
+
+	// Reject malformed fields up front as a *syncx.FieldError (REST maps it to 422)
+	if !opts.SetDeleted {
+		if err := s.Cfg.validatePayloadFields("note", payload); err != nil {
+			return nil, err
+		}
+	}
+
 	// Extract UID or generate new one
 	var noteUID uuid.UUID
 	if uidStr, ok := syncx.GetString(payload, "uid"); ok {
 		noteUID, _ = uuid.Parse(uidStr)
 	}
 	if noteUID == uuid.Nil {
-		noteUID = uuid.New()
+		noteUID = s.Cfg.NewUID()
 		payload["uid"] = noteUID.String()
 	}
 
 	// Fetch existing note to determine timestamp
 	var existingMs int64
 	var existingVersion int
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		SELECT updated_at_ms, version
 		FROM note
 		WHERE owner_id = $1 AND uid = $2
+		FOR UPDATE
 	`, userID, noteUID).Scan(&existingMs, &existingVersion)
 
 	if err != nil && err != pgx.ErrNoRows {
@@ -345,6 +747,15 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 
 	isNew := err == pgx.ErrNoRows
 
+	// Reject new items whose UID is not the configured version (REST maps it to 422)
+	if isNew {
+		if err := s.Cfg.checkNewUIDVersion(noteUID); err != nil {
+			return nil, err
+		}
+		// Fields the client left out start from the entity template (see Config.DefaultPayloads)
+		s.Cfg.applyDefaultPayload("note", payload)
+	}
+
 	// Optimistic locking check
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
@@ -360,16 +771,29 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 	if opts.ForceTimestampMs != nil {
 		timestampMs = *opts.ForceTimestampMs
 	} else if isNew {
-		timestampMs = syncx.NowMs()
+		timestampMs = s.Clock.NowMs()
 	} else {
-		timestampMs = syncx.EnsureMonotonicTimestamp(existingMs)
+		timestampMs = syncx.EnsureMonotonicTimestampAt(s.Clock.NowMs(), existingMs)
+	}
+
+	// Surface a unique field conflict as a typed error (REST maps it to 409)
+	if _, err := s.Cfg.checkUniqueKey(ctx, tx, "note", userID, noteUID, payload, opts.SetDeleted); err != nil {
+		return nil, err
 	}
 
 	// Build sync-compliant payload
 	mutatedPayload := syncx.BuildServerMutation(payload, timestampMs, opts.SetDeleted)
 
+	// Forced mutations win LWW whatever their timestamp (see MutationOpts.ForceWrite)
+	if opts.ForceWrite {
+		ctx = WithForceWrite(ctx)
+	}
+
 	// Call existing push logic
-	ack := s.PushNoteItem(ctx, tx, userID, mutatedPayload)
+	ack := s.PushNoteItem(withReplacePayload(ctx), tx, userID, mutatedPayload)
+	if ack.err != nil {
+		return nil, ack.err
+	}
 	if ack.Error != "" {
 		return nil, &MutationError{Message: ack.Error}
 	}
@@ -380,32 +804,21 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 	upsertApplied := ack.Applied
 	var deletedAtMs *int64 // Declared here for use in !upsertApplied path later
 
-	// Normalize sync metadata in payload for client compatibility
-	// Flutter clients expect flat sync fields (version, isDirty, isDeleted, remoteUpdatedAt, updateTime)
-	// to match the nested sync block that BuildServerMutation created.
+	// Re-run payload transforms with the authoritative server version so version-derived
+	// fields (e.g. the flat sync fields from RESTSyncFields) match the stored row.
 
 	// Only write the normalized payload when our upsert actually won (timestamps matched)
 	// Otherwise we risk overwriting a newer concurrent write with stale content.
 	if upsertApplied {
-		// Update nested sync.version to match authoritative server version
-		if syncBlock, ok := mutatedPayload["sync"].(map[string]any); ok {
-			syncBlock["version"] = ack.Version
-		}
-
-		// Normalize flat sync fields to match nested sync and server state
-		mutatedPayload["version"] = ack.Version
-		mutatedPayload["isDirty"] = 0 // REST mutations are already synced (use 0/1 for client compatibility)
-		if opts.SetDeleted {
-			mutatedPayload["isDeleted"] = 1
-		} else {
-			mutatedPayload["isDeleted"] = 0
-		}
-		mutatedPayload["remoteUpdatedAt"] = ack.UpdatedAt
-		mutatedPayload["updateTime"] = ack.UpdatedAt
-		mutatedPayload["lastSyncedAt"] = ack.UpdatedAt
+		s.Cfg.runPayloadTransforms("note", mutatedPayload, WriteInfo{
+			Deleted:   opts.SetDeleted,
+			Version:   ack.Version,
+			UpdatedAt: ack.UpdatedAt,
+			REST:      true,
+		})
 
 		// Persist normalized payload to database
-		payloadJSON, err := json.Marshal(mutatedPayload)
+		payloadJSON, err := s.Cfg.encodePayload(mutatedPayload)
 		if err != nil {
 			logger.Error().Err(err).Msg("failed to marshal normalized payload")
 			return nil, err
@@ -435,11 +848,17 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 			logger.Error().Err(err).Msg("failed to reload payload after concurrent write")
 			return nil, err
 		}
+		if currentPayload, err = s.Cfg.decodePayload(currentPayload); err != nil {
+			logger.Error().Err(err).Str("uid", noteUID.String()).Msg("failed to decode note payload")
+			return nil, err
+		}
 		mutatedPayload = currentPayload
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		logger.Error().Err(err).Msg("failed to commit mutation")
+	// Record who made this change (createdBy is write-once, updatedBy tracks the latest mutation)
+	createdBy, updatedBy, err := stampAttribution(ctx, tx, "note", userID, noteUID, isNew, upsertApplied)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record attribution")
 		return nil, err
 	}
 
@@ -470,6 +889,8 @@ func (s *NoteService) ApplyNoteMutation(ctx context.Context, userID string, payl
 		Version:   ack.Version,
 		UpdatedAt: ack.UpdatedAt,
 		DeletedAt: deletedAt,
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   mutatedPayload,
 	}, nil
 }
@@ -0,0 +1,93 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CounterChange is a cached counter before and after a recompute
+type CounterChange struct {
+	Before int64 `json:"before"`
+	After  int64 `json:"after"`
+}
+
+// CounterRecompute is the result of RecomputeOwnerCountersTx
+type CounterRecompute struct {
+	PayloadBytes CounterChange    `json:"payloadBytes"` // owner_usage.payload_bytes (see Config.MaxPayloadBytes)
+	TaskSeq      CounterChange    `json:"taskSeq"`      // owner_state.task_seq (see Config.SeqIDs)
+	LiveItems    map[string]int64 `json:"liveItems"`    // table -> items not deleted
+	LiveBytes    map[string]int64 `json:"liveBytes"`    // table -> payload bytes of those items
+}
+
+// RecomputeOwnerCountersTx recounts a user's live items and payload bytes from the
+// entity tables and rewrites the counters cached in owner_usage and owner_state
+//
+// The owner_usage row is locked first. The payload_bytes triggers update that row on
+// every write, so a concurrent write either commits before the recount (and is
+// counted) or waits and applies its delta on top of the rewritten total.
+//
+// task_seq is only ever raised to the highest stamped display ID: numbers of purged
+// tasks stay retired, so lowering it could hand one out twice.
+func RecomputeOwnerCountersTx(ctx context.Context, tx pgx.Tx, userID string, tables []string) (CounterRecompute, error) {
+	res := CounterRecompute{
+		LiveItems: make(map[string]int64, len(tables)),
+		LiveBytes: make(map[string]int64, len(tables)),
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO owner_usage (owner_id) VALUES ($1) ON CONFLICT (owner_id) DO NOTHING`,
+		userID); err != nil {
+		return res, fmt.Errorf("create owner usage: %w", err)
+	}
+	if err := tx.QueryRow(ctx,
+		`SELECT payload_bytes FROM owner_usage WHERE owner_id = $1 FOR UPDATE`,
+		userID).Scan(&res.PayloadBytes.Before); err != nil {
+		return res, fmt.Errorf("lock owner usage: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO owner_state (owner_id) VALUES ($1) ON CONFLICT (owner_id) DO NOTHING`,
+		userID); err != nil {
+		return res, fmt.Errorf("create owner state: %w", err)
+	}
+	if err := tx.QueryRow(ctx,
+		`SELECT task_seq FROM owner_state WHERE owner_id = $1 FOR UPDATE`,
+		userID).Scan(&res.TaskSeq.Before); err != nil {
+		return res, fmt.Errorf("lock owner state: %w", err)
+	}
+
+	for _, table := range tables {
+		var items, bytes int64
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*), COALESCE(SUM(octet_length(payload_json::text)), 0)
+			FROM `+table+`
+			WHERE owner_id = $1::uuid AND deleted_at_ms IS NULL
+		`, userID).Scan(&items, &bytes); err != nil {
+			return res, fmt.Errorf("count %s: %w", table, err)
+		}
+		res.LiveItems[table] = items
+		res.LiveBytes[table] = bytes
+		res.PayloadBytes.After += bytes
+	}
+
+	var maxSeq int64
+	if err := tx.QueryRow(ctx,
+		`SELECT COALESCE(MAX(seq_id), 0) FROM task WHERE owner_id = $1::uuid`,
+		userID).Scan(&maxSeq); err != nil {
+		return res, fmt.Errorf("read highest task seq id: %w", err)
+	}
+	res.TaskSeq.After = max(res.TaskSeq.Before, maxSeq)
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE owner_usage SET payload_bytes = $2 WHERE owner_id = $1`,
+		userID, res.PayloadBytes.After); err != nil {
+		return res, fmt.Errorf("rewrite owner counters: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE owner_state SET task_seq = $2 WHERE owner_id = $1`,
+		userID, res.TaskSeq.After); err != nil {
+		return res, fmt.Errorf("rewrite owner counters: %w", err)
+	}
+	return res, nil
+}
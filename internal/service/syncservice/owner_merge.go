@@ -0,0 +1,90 @@
+package syncservice
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MergeResult reports how one entity table was merged between owners
+type MergeResult struct {
+	Moved   int `json:"moved"`   // rows reassigned from the old owner
+	Dropped int `json:"dropped"` // colliding rows that lost LWW and were discarded
+}
+
+// MergeOwnerTx reassigns every row of an entity table from one owner to another
+// within the caller's transaction. UID collisions are resolved by LWW on
+// updated_at_ms; on a tie the destination row is kept.
+// Cached items of the table are dropped from cache (nil = no cache).
+func MergeOwnerTx(ctx context.Context, tx pgx.Tx, cache *ItemCache, table, fromUserID, toUserID string) (MergeResult, error) {
+	var res MergeResult
+	cache.InvalidateTable(tx, table)
+
+	// Old owner's copy is newer: discard the destination copy
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM `+table+` AS dst
+		USING `+table+` AS src
+		WHERE dst.owner_id = $2 AND src.owner_id = $1
+		  AND dst.uid = src.uid
+		  AND src.updated_at_ms > dst.updated_at_ms
+	`, fromUserID, toUserID)
+	if err != nil {
+		return res, err
+	}
+	res.Dropped += int(tag.RowsAffected())
+
+	// Remaining collisions: destination wins, discard the old owner's copy
+	tag, err = tx.Exec(ctx, `
+		DELETE FROM `+table+` AS src
+		USING `+table+` AS dst
+		WHERE src.owner_id = $1 AND dst.owner_id = $2
+		  AND src.uid = dst.uid
+	`, fromUserID, toUserID)
+	if err != nil {
+		return res, err
+	}
+	res.Dropped += int(tag.RowsAffected())
+
+	// Unique field values (see Config.UniqueFields) live under both owners stay with the
+	// destination; the old owner's copy keeps its payload but no longer claims the value
+	_, err = tx.Exec(ctx, `
+		UPDATE `+table+` AS src SET unique_key = NULL
+		FROM `+table+` AS dst
+		WHERE src.owner_id = $1 AND dst.owner_id = $2
+		  AND src.unique_key = dst.unique_key
+		  AND src.deleted_at_ms IS NULL AND dst.deleted_at_ms IS NULL
+	`, fromUserID, toUserID)
+	if err != nil {
+		return res, err
+	}
+
+	// Sequential display IDs (see Config.SeqIDs) are per owner, so moved tasks are renumbered
+	if table == "task" {
+		if res.Moved, err = mergeTaskSeqIDsTx(ctx, tx, fromUserID, toUserID); err != nil {
+			return res, err
+		}
+	}
+
+	tag, err = tx.Exec(ctx, `UPDATE `+table+` SET owner_id = $2 WHERE owner_id = $1`, fromUserID, toUserID)
+	if err != nil {
+		return res, err
+	}
+	res.Moved += int(tag.RowsAffected())
+
+	return res, nil
+}
+
+// MergePurgeMarkersTx moves purge markers between owners, keeping the later marker on collision
+func MergePurgeMarkersTx(ctx context.Context, tx pgx.Tx, fromUserID, toUserID string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO purge_marker (owner_id, entity, uid, purged_at_ms)
+		SELECT $2, entity, uid, purged_at_ms FROM purge_marker WHERE owner_id = $1
+		ON CONFLICT (owner_id, entity, uid) DO UPDATE
+			SET purged_at_ms = GREATEST(purge_marker.purged_at_ms, EXCLUDED.purged_at_ms)
+	`, fromUserID, toUserID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `DELETE FROM purge_marker WHERE owner_id = $1`, fromUserID)
+	return err
+}
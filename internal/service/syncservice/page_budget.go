@@ -0,0 +1,63 @@
+package syncservice
+
+import "encoding/json"
+
+// tombstoneBytes approximates one serialized delete marker ({"uid":...,"deletedAt":...})
+const tombstoneBytes = 96
+
+// pageBudget tracks the serialized size of a page while it is assembled
+// A page stops before the first item that would overflow the budget, even if fewer than
+// limit rows were read, and its cursor resumes at that item. The first item of a page is
+// always returned, however large, so pagination can't stall.
+// A nil budget admits everything (pulls by UID, which have no cursor to resume from).
+type pageBudget struct {
+	max   int
+	used  int
+	items int
+	full  bool // an item was turned away
+}
+
+// newPageBudget starts a page under the MaxPageBytes cap
+func (c *Config) newPageBudget() *pageBudget {
+	return &pageBudget{max: c.MaxPageBytes}
+}
+
+// admit reports whether v fits in the page, counting its encoded size if so
+func (b *pageBudget) admit(v any) bool {
+	if b == nil || b.max <= 0 {
+		return true
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		// The response encoder will report it; don't let sizing turn it away
+		return b.take(0)
+	}
+	return b.take(len(data))
+}
+
+// admitPullRow is admit for one pull row: the payload of an upsert or a delete marker
+func (b *pageBudget) admitPullRow(payload map[string]any, deleted bool) bool {
+	if b == nil || b.max <= 0 {
+		return true
+	}
+	if deleted {
+		return b.take(tombstoneBytes)
+	}
+	return b.admit(payload)
+}
+
+// take counts size bytes (plus a separator) unless that would overflow a non-empty page
+func (b *pageBudget) take(size int) bool {
+	if b.items > 0 && b.used+size+1 > b.max {
+		b.full = true
+		return false
+	}
+	b.used += size + 1
+	b.items++
+	return true
+}
+
+// byteLimited reports whether the page stopped short because of the byte cap
+func (b *pageBudget) byteLimited() bool {
+	return b != nil && b.full
+}
@@ -0,0 +1,60 @@
+package syncservice
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPageBudget(t *testing.T) {
+	cfg := DefaultConfig()
+	payload := func(n int) map[string]any {
+		return map[string]any{"content": strings.Repeat("x", n)}
+	}
+
+	// Unlimited by default; a nil budget (pull by UID) admits everything too
+	b := cfg.newPageBudget()
+	for i := 0; i < 100; i++ {
+		if !b.admitPullRow(payload(1000), false) {
+			t.Fatalf("Unlimited budget turned away item %d", i)
+		}
+	}
+	var nilBudget *pageBudget
+	if !nilBudget.admit(payload(1000)) || nilBudget.byteLimited() {
+		t.Error("nil budget should admit everything")
+	}
+
+	// ~520 bytes per item: two fit under 1200, the third ends the page
+	cfg.MaxPageBytes = 1200
+	b = cfg.newPageBudget()
+	if !b.admitPullRow(payload(500), false) || !b.admitPullRow(payload(500), false) {
+		t.Fatal("Expected the first two items to fit")
+	}
+	if b.byteLimited() {
+		t.Error("byteLimited set before anything was turned away")
+	}
+	if b.admitPullRow(payload(500), false) {
+		t.Error("Expected the third item to overflow the budget")
+	}
+	if !b.byteLimited() {
+		t.Error("Expected byteLimited after an item was turned away")
+	}
+
+	// The first item is always admitted so an oversized item can't stall pagination
+	b = cfg.newPageBudget()
+	if !b.admit(payload(5000)) {
+		t.Error("Expected the first item to be admitted regardless of size")
+	}
+	if b.admitPullRow(nil, true) {
+		t.Error("Expected nothing more once an oversized item filled the page")
+	}
+
+	// Delete markers are small: many fit where few payloads do
+	b = cfg.newPageBudget()
+	n := 0
+	for b.admitPullRow(nil, true) {
+		n++
+	}
+	if n != 1200/(tombstoneBytes+1) {
+		t.Errorf("Expected %d tombstones per page, got %d", 1200/(tombstoneBytes+1), n)
+	}
+}
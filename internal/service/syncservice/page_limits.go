@@ -0,0 +1,72 @@
+package syncservice
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PageLimit is the page size of one entity's pulls and REST lists: Default when the
+// client sends no limit, and Max as the most a client can ask for
+type PageLimit struct {
+	Default int `json:"default"`
+	Max     int `json:"max"`
+}
+
+// DefaultPageLimit applies to entities without their own entry
+var DefaultPageLimit = PageLimit{Default: 500, Max: 1000}
+
+// DefaultPageLimits is the built-in per-entity page size. Chat messages are small and
+// many, and clients render them a screen at a time, so their default page is shorter.
+var DefaultPageLimits = map[string]PageLimit{
+	"chat_message": {Default: 100, Max: 1000},
+}
+
+// ParsePageLimits parses page sizes from config: a JSON object mapping entity tables to
+// {"default", "max"}, e.g. {"chat_message":{"default":50,"max":200}}. An omitted default
+// is DefaultPageLimit.Default (lowered to max if needed); an omitted max is DefaultPageLimit.Max.
+// Entities not listed use DefaultPageLimit; "{}" drops the built-in entries.
+func ParsePageLimits(v string) (map[string]PageLimit, error) {
+	var limits map[string]PageLimit
+	if err := json.Unmarshal([]byte(v), &limits); err != nil {
+		return nil, fmt.Errorf("page limits must be a JSON object of entity page sizes: %w", err)
+	}
+	for table, l := range limits {
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in page limits", table)
+		}
+		if l.Default < 0 || l.Max < 0 {
+			return nil, fmt.Errorf("%s: page limits must be positive, got default %d max %d", table, l.Default, l.Max)
+		}
+		if l.Max == 0 {
+			l.Max = DefaultPageLimit.Max
+		}
+		if l.Default == 0 {
+			l.Default = min(DefaultPageLimit.Default, l.Max)
+		}
+		if l.Default > l.Max {
+			return nil, fmt.Errorf("%s: default page limit %d exceeds max %d", table, l.Default, l.Max)
+		}
+		limits[table] = l
+	}
+	return limits, nil
+}
+
+// PageLimitFor returns the page size of an entity table
+func (c *Config) PageLimitFor(table string) PageLimit {
+	if l, ok := c.PageLimits[table]; ok {
+		return l
+	}
+	return DefaultPageLimit
+}
+
+// Resolve returns the page size for a requested limit: Default for none (<= 0), and Max
+// for one over it, with clamped reporting that the request was cut down
+func (l PageLimit) Resolve(requested int) (limit int, clamped bool) {
+	switch {
+	case requested <= 0:
+		return l.Default, false
+	case requested > l.Max:
+		return l.Max, true
+	}
+	return requested, false
+}
@@ -0,0 +1,63 @@
+package syncservice
+
+import "testing"
+
+func TestParsePageLimits(t *testing.T) {
+	limits, err := ParsePageLimits(`{"chat_message":{"default":50,"max":200},"note":{"max":300},"task":{"max":100},"chat":{"default":20}}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := map[string]PageLimit{
+		"chat_message": {Default: 50, Max: 200},
+		"note":         {Default: 300, Max: 300}, // default lowered to max
+		"task":         {Default: 100, Max: 100},
+		"chat":         {Default: 20, Max: 1000},
+	}
+	for table, w := range want {
+		if limits[table] != w {
+			t.Errorf("%s: got %+v, want %+v", table, limits[table], w)
+		}
+	}
+
+	for _, bad := range []string{
+		`[]`,
+		`{"widget":{"max":10}}`,
+		`{"note":{"default":-1}}`,
+		`{"note":{"default":500,"max":100}}`,
+	} {
+		if _, err := ParsePageLimits(bad); err == nil {
+			t.Errorf("ParsePageLimits(%s): expected an error", bad)
+		}
+	}
+}
+
+func TestPageLimitResolve(t *testing.T) {
+	l := PageLimit{Default: 100, Max: 1000}
+	tests := []struct {
+		requested   int
+		want        int
+		wantClamped bool
+	}{
+		{0, 100, false},
+		{-5, 100, false},
+		{50, 50, false},
+		{1000, 1000, false},
+		{1001, 1000, true},
+	}
+	for _, tt := range tests {
+		if got, clamped := l.Resolve(tt.requested); got != tt.want || clamped != tt.wantClamped {
+			t.Errorf("Resolve(%d) = %d, %v; want %d, %v", tt.requested, got, clamped, tt.want, tt.wantClamped)
+		}
+	}
+}
+
+func TestPageLimitFor(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.PageLimitFor("chat_message"); got.Default != 100 {
+		t.Errorf("Expected the built-in chat message default of 100, got %+v", got)
+	}
+	cfg.PageLimits = map[string]PageLimit{}
+	if got := cfg.PageLimitFor("chat_message"); got != DefaultPageLimit {
+		t.Errorf("Expected DefaultPageLimit without an entry, got %+v", got)
+	}
+}
@@ -0,0 +1,28 @@
+package syncservice
+
+import (
+	"encoding/json"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// encodePayload serializes a payload for storage in payload_json,
+// sealing it when encryption at rest is enabled
+func (c *Config) encodePayload(payload map[string]any) ([]byte, error) {
+	if c.PayloadCipher == nil {
+		return json.Marshal(payload)
+	}
+	return c.PayloadCipher.Seal(payload)
+}
+
+// decodePayload returns the client-visible payload for a stored payload_json value
+// Plaintext rows pass through unchanged, so encryption can be enabled without a migration.
+// Timestamps are served in UTC even for rows written before pushes were normalized.
+func (c *Config) decodePayload(stored map[string]any) (map[string]any, error) {
+	payload, err := c.PayloadCipher.Open(stored)
+	if err != nil {
+		return nil, err
+	}
+	syncx.NormalizeTimestamps(payload)
+	return payload, nil
+}
@@ -0,0 +1,82 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// AbsentFieldMode controls how a sync push treats payload fields the client omits
+type AbsentFieldMode string
+
+const (
+	// AbsentFieldsKeep merges pushes onto the stored payload: an omitted field keeps
+	// its stored value and an explicit null clears it (same as REST PATCH). This is the default.
+	AbsentFieldsKeep AbsentFieldMode = "keep"
+	// AbsentFieldsClear stores the pushed payload as-is, dropping omitted fields
+	// Legacy opt-out for clients that always push the complete payload and rely on
+	// omission to remove a field.
+	AbsentFieldsClear AbsentFieldMode = "clear"
+)
+
+// ParseAbsentFieldMode parses a mode name from config
+func ParseAbsentFieldMode(v string) (AbsentFieldMode, error) {
+	switch AbsentFieldMode(v) {
+	case AbsentFieldsKeep, AbsentFieldsClear:
+		return AbsentFieldMode(v), nil
+	default:
+		return "", fmt.Errorf("unknown absent field mode %q (want %q or %q)", v, AbsentFieldsKeep, AbsentFieldsClear)
+	}
+}
+
+// pushMetadataKeys are the fields syncx.ExtractCommon reads the sync columns from
+// They are extracted from the pushed item before the merge, so the stored payload takes
+// them from the push alone: a stored sync.isDeleted must not outlive an un-delete that
+// omits the sync block while deleted_at_ms is cleared.
+var pushMetadataKeys = []string{"sync", "updatedTs", "updatedAt", "updateTime"}
+
+type replacePayloadKey struct{}
+
+// withReplacePayload marks a push as carrying the complete payload
+// REST mutations use it: PUT replaces the document and PATCH has already merged.
+func withReplacePayload(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replacePayloadKey{}, true)
+}
+
+// mergeStoredPayload returns the payload to store for a pushed item
+// Under AbsentFieldsKeep the item is merged onto the current row (see syncx.MergePayload),
+// except for the sync metadata (see pushMetadataKeys); new rows, REST mutations and
+// AbsentFieldsClear store the item unchanged.
+// The row is locked so the merge base can't change before the upsert.
+func (c *Config) mergeStoredPayload(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID, item map[string]any) (map[string]any, error) {
+	if c.AbsentFields != AbsentFieldsKeep {
+		return item, nil
+	}
+	if replace, _ := ctx.Value(replacePayloadKey{}).(bool); replace {
+		return item, nil
+	}
+
+	var stored map[string]any
+	err := tx.QueryRow(ctx,
+		fmt.Sprintf(`SELECT payload_json FROM %s WHERE owner_id = $1 AND uid = $2 FOR UPDATE`, table),
+		userID, uid).Scan(&stored)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return item, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err = c.decodePayload(stored)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range pushMetadataKeys {
+		delete(stored, key)
+	}
+	return syncx.MergePayload(stored, item), nil
+}
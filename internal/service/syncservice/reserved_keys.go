@@ -0,0 +1,52 @@
+package syncservice
+
+import (
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// ReservedPayloadKeys are top-level payload keys that look like sync metadata
+// A client-sent value would shadow the server's sync block on read, so REST writes
+// do not accept them (see ReservedKeyMode). uid and sync are always server-controlled.
+var ReservedPayloadKeys = []string{"version", "updatedAt", "deletedAt", "createdAt", "isDeleted", "isDirty"}
+
+// ReservedKeyMode controls how REST writes treat ReservedPayloadKeys in client payloads
+type ReservedKeyMode string
+
+const (
+	// ReservedKeysStrip drops reserved keys from the payload before it is stored
+	ReservedKeysStrip ReservedKeyMode = "strip"
+	// ReservedKeysReject fails the write with a *syncx.FieldError (REST 422)
+	ReservedKeysReject ReservedKeyMode = "reject"
+	// ReservedKeysAllow stores reserved keys as sent (legacy behavior)
+	ReservedKeysAllow ReservedKeyMode = "allow"
+)
+
+// ParseReservedKeyMode parses a mode name from config
+func ParseReservedKeyMode(v string) (ReservedKeyMode, error) {
+	switch ReservedKeyMode(v) {
+	case ReservedKeysStrip, ReservedKeysReject, ReservedKeysAllow:
+		return ReservedKeyMode(v), nil
+	default:
+		return "", fmt.Errorf("unknown reserved key mode %q (want %q, %q or %q)", v, ReservedKeysStrip, ReservedKeysReject, ReservedKeysAllow)
+	}
+}
+
+// CheckReservedKeys applies the reserved key mode to a client payload (REST create, PUT
+// or PATCH body): strips the keys, or returns a *syncx.FieldError for the first one
+// present. Sync pushes are not checked: clients send their own flat sync fields there.
+func (c *Config) CheckReservedKeys(payload map[string]any) error {
+	for _, key := range ReservedPayloadKeys {
+		if _, ok := payload[key]; !ok {
+			continue
+		}
+		switch c.ReservedKeys {
+		case ReservedKeysStrip:
+			delete(payload, key)
+		case ReservedKeysReject:
+			return &syncx.FieldError{Field: key, Reason: "is reserved for sync metadata"}
+		}
+	}
+	return nil
+}
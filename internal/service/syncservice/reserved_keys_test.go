@@ -0,0 +1,51 @@
+package syncservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestCheckReservedKeys(t *testing.T) {
+	payload := func() map[string]any {
+		return map[string]any{"title": "a", "version": 9, "isDirty": 1, "createdAt": "2025-01-01T00:00:00Z"}
+	}
+
+	cfg := DefaultConfig()
+
+	// Default: reserved keys are dropped, everything else is kept
+	p := payload()
+	if err := cfg.CheckReservedKeys(p); err != nil {
+		t.Fatalf("strip mode returned %v", err)
+	}
+	if len(p) != 1 || p["title"] != "a" {
+		t.Errorf("Expected only title to remain, got %v", p)
+	}
+
+	cfg.ReservedKeys = ReservedKeysReject
+	var fieldErr *syncx.FieldError
+	if err := cfg.CheckReservedKeys(payload()); !errors.As(err, &fieldErr) || fieldErr.Field != "version" {
+		t.Errorf("Expected the first reserved key (version) to be rejected, got %v", err)
+	}
+	if err := cfg.CheckReservedKeys(map[string]any{"title": "a"}); err != nil {
+		t.Errorf("Payload without reserved keys should pass, got %v", err)
+	}
+
+	cfg.ReservedKeys = ReservedKeysAllow
+	p = payload()
+	if err := cfg.CheckReservedKeys(p); err != nil || len(p) != 4 {
+		t.Errorf("allow mode should leave the payload as sent, got %v %v", p, err)
+	}
+}
+
+func TestParseReservedKeyMode(t *testing.T) {
+	for _, v := range []string{"strip", "reject", "allow"} {
+		if m, err := ParseReservedKeyMode(v); err != nil || string(m) != v {
+			t.Errorf("ParseReservedKeyMode(%q) = %q, %v", v, m, err)
+		}
+	}
+	if _, err := ParseReservedKeyMode("drop"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
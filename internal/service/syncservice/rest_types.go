@@ -1,6 +1,12 @@
 package syncservice
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+)
 
 // RESTItem represents a single entity with sync metadata exposed
 type RESTItem struct {
@@ -8,13 +14,62 @@ type RESTItem struct {
 	Version   int            `json:"version"`
 	UpdatedAt string         `json:"updatedAt"`
 	DeletedAt *string        `json:"deletedAt,omitempty"`
+	CreatedBy *string        `json:"createdBy,omitempty"` // actor that created the item via REST (write-once)
+	UpdatedBy *string        `json:"updatedBy,omitempty"` // actor of the latest applied REST mutation
+	SeqID     *int64         `json:"seqId,omitempty"`     // task display ID (see Config.SeqIDs)
 	Payload   map[string]any `json:"payload"`
 }
 
 // RESTListResponse represents paginated list response
 type RESTListResponse struct {
-	Items      []RESTItem `json:"items"`
-	NextCursor *string    `json:"nextCursor,omitempty"`
+	Items       []RESTItem `json:"items"`
+	NextCursor  *string    `json:"nextCursor,omitempty"`
+	ByteLimited bool       `json:"byteLimited,omitempty"` // page cut short by the byte cap (see SetMaxPageBytes)
+}
+
+// ListOpts configures REST list filtering
+type ListOpts struct {
+	IncludeDeleted bool          // Include tombstones alongside active items
+	DeletedOnly    bool          // Return only tombstones (implies IncludeDeleted)
+	Where          []FieldFilter // Payload field equality filters, AND-combined (see ParseWhereFilters)
+	OrderByUID     bool          // Order by UID instead of (updated_at_ms, uid): creation order for UUIDv7
+}
+
+// positionClause returns the SQL predicate (with leading AND) for the list cursor ($2, $3)
+// UID-ordered cursors carry Ms=0; $2 stays bound so the argument list is the same.
+func (o ListOpts) positionClause() string {
+	if o.OrderByUID {
+		return ` AND $2::bigint = 0 AND uid > $3::uuid`
+	}
+	return ` AND (updated_at_ms, uid) > ($2, $3::uuid)`
+}
+
+// orderClause returns the ORDER BY for the list ordering
+func (o ListOpts) orderClause() string {
+	if o.OrderByUID {
+		return ` ORDER BY uid`
+	}
+	return ` ORDER BY updated_at_ms, uid`
+}
+
+// cursorAt returns the list cursor positioned after a row
+func (o ListOpts) cursorAt(ms int64, uid uuid.UUID) syncx.Cursor {
+	if o.OrderByUID {
+		return syncx.Cursor{UID: uid}
+	}
+	return syncx.Cursor{Ms: ms, UID: uid}
+}
+
+// deletedClause returns the SQL predicate (with leading AND) for the deletion filter
+func (o ListOpts) deletedClause() string {
+	switch {
+	case o.DeletedOnly:
+		return ` AND deleted_at_ms IS NOT NULL`
+	case o.IncludeDeleted:
+		return ""
+	default:
+		return ` AND deleted_at_ms IS NULL`
+	}
 }
 
 // MutationOpts configures REST mutation behavior
@@ -23,8 +78,16 @@ type MutationOpts struct {
 	ExpectedVersion  int    // Expected version for optimistic locking
 	ForceTimestampMs *int64 // Override timestamp (for testing)
 	SetDeleted       bool   // Mark as deleted
+	ForceWrite       bool   // Win LWW even against a newer stored row (migrations; see WithForceWrite)
 }
 
+// Purge errors (see NoteService.PurgeNote)
+var (
+	ErrNotFound      = errors.New("item not found")
+	ErrNotDeleted    = errors.New("item is not deleted")
+	ErrAlreadyPurged = errors.New("item already purged")
+)
+
 // VersionMismatchError indicates optimistic locking failure
 type VersionMismatchError struct {
 	Expected int
@@ -0,0 +1,128 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// scheduledDeleteActor attributes sweeper deletions in updated_by
+const scheduledDeleteActor = "system:scheduled-delete"
+
+// dueNote is a live note whose deleteAfter has passed
+type dueNote struct {
+	ownerID       string
+	uid           string
+	version       int
+	deleteAfterMs int64
+	payload       map[string]any
+}
+
+// SweepScheduledDeletes soft-deletes up to limit live notes whose deleteAfter has passed
+// Each note goes through ApplyNoteMutation with the version read here, so the delete gets
+// a monotonic timestamp like any REST delete and loses to a concurrent edit: a note changed
+// since the scan (e.g. its schedule was cleared) is skipped and re-checked next sweep.
+// Due notes are read in (delete_after_ms, owner_id, uid) pages, so notes that keep failing
+// (e.g. an undecodable payload) are stepped over rather than filling every sweep's batch.
+func (s *NoteService) SweepScheduledDeletes(ctx context.Context, limit int) (int, error) {
+	logger := log.With().Logger()
+	nowMs := s.Clock.NowMs()
+
+	ctx = WithActor(ctx, scheduledDeleteActor)
+	deleted := 0
+	after := dueNote{deleteAfterMs: math.MinInt64, ownerID: uuid.Nil.String(), uid: uuid.Nil.String()}
+	for deleted < limit {
+		due, err := s.dueNotes(ctx, nowMs, after, limit)
+		if err != nil {
+			return deleted, err
+		}
+
+		for _, n := range due {
+			if deleted >= limit {
+				break
+			}
+			after = n
+
+			payload, err := s.Cfg.decodePayload(n.payload)
+			if err != nil {
+				logger.Error().Err(err).Str("uid", n.uid).Msg("failed to decode note for scheduled delete")
+				continue
+			}
+
+			_, err = s.ApplyNoteMutation(ctx, n.ownerID, payload, MutationOpts{
+				SetDeleted:      true,
+				EnforceVersion:  true,
+				ExpectedVersion: n.version,
+			})
+			var mismatch *VersionMismatchError
+			if errors.As(err, &mismatch) {
+				logger.Debug().Str("uid", n.uid).Msg("note changed since scan, skipping scheduled delete")
+				continue
+			}
+			if err != nil {
+				logger.Error().Err(err).Str("uid", n.uid).Msg("scheduled delete failed")
+				continue
+			}
+			deleted++
+		}
+
+		if len(due) < limit {
+			break
+		}
+	}
+
+	return deleted, nil
+}
+
+// dueNotes reads up to limit live notes due for deletion at nowMs, after the
+// (delete_after_ms, owner_id, uid) keyset position of after
+func (s *NoteService) dueNotes(ctx context.Context, nowMs int64, after dueNote, limit int) ([]dueNote, error) {
+	rows, err := s.DB.Query(ctx, `
+		SELECT owner_id, uid, version, delete_after_ms, payload_json
+		FROM note
+		WHERE delete_after_ms <= $1 AND deleted_at_ms IS NULL
+		  AND (delete_after_ms, owner_id, uid) > ($2, $3::uuid, $4::uuid)
+		ORDER BY delete_after_ms, owner_id, uid
+		LIMIT $5
+	`, nowMs, after.deleteAfterMs, after.ownerID, after.uid, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []dueNote
+	for rows.Next() {
+		var n dueNote
+		if err := rows.Scan(&n.ownerID, &n.uid, &n.version, &n.deleteAfterMs, &n.payload); err != nil {
+			return nil, err
+		}
+		due = append(due, n)
+	}
+	return due, rows.Err()
+}
+
+// RunScheduledDeleteSweeper calls SweepScheduledDeletes every interval until ctx is cancelled
+func (s *NoteService) RunScheduledDeleteSweeper(ctx context.Context, interval time.Duration) {
+	logger := log.With().Str("worker", "scheduled_delete").Logger()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := s.SweepScheduledDeletes(ctx, 500)
+			if err != nil {
+				logger.Error().Err(err).Msg("scheduled delete sweep failed")
+			} else if n > 0 {
+				logger.Info().Int("deleted", n).Msg("scheduled delete sweep completed")
+			}
+		}
+	}
+}
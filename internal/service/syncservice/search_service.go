@@ -0,0 +1,150 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/payloadcrypt"
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// searchableEntity maps a public entity type name to its backing table
+type searchableEntity struct {
+	EntityType string
+	Table      string
+}
+
+// searchableEntities lists entity types covered by full-text search (in result tie-break order)
+var searchableEntities = []searchableEntity{
+	{EntityType: "notes", Table: "note"},
+	{EntityType: "tasks", Table: "task"},
+	{EntityType: "comments", Table: "comment"},
+	{EntityType: "chats", Table: "chat"},
+	{EntityType: "chat_messages", Table: "chat_message"},
+	{EntityType: "task_lists", Table: "task_list"},
+	{EntityType: "task_list_categories", Table: "task_list_category"},
+}
+
+// SearchResult is a single ranked search hit
+type SearchResult struct {
+	EntityType string         `json:"entityType"`
+	UID        string         `json:"uid"`
+	Version    int            `json:"version"`
+	UpdatedAt  string         `json:"updatedAt"`
+	Rank       float64        `json:"rank"`
+	Payload    map[string]any `json:"payload"`
+}
+
+// SearchResponse is the response for a search query
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+
+	// EncryptedFieldsSkipped is set when payloads are encrypted at rest: only the
+	// plaintext fields of encrypted items (payloadcrypt.PlaintextFields) were searched
+	EncryptedFieldsSkipped bool `json:"encryptedFieldsSkipped,omitempty"`
+}
+
+// UnknownEntityTypeError indicates a search filter referenced an unsupported entity type
+type UnknownEntityTypeError struct {
+	EntityType string
+}
+
+func (e *UnknownEntityTypeError) Error() string {
+	return fmt.Sprintf("unknown entity type: %s", e.EntityType)
+}
+
+// SearchService provides keyword search across all entity types
+type SearchService struct {
+	DB  *pgxpool.Pool
+	Cfg *Config // sync settings, shared by all services of a server (DefaultConfig unless set)
+}
+
+// NewSearchService creates a new SearchService
+func NewSearchService(db *pgxpool.Pool) *SearchService {
+	return &SearchService{DB: db, Cfg: DefaultConfig()}
+}
+
+// Search runs a full-text query over string values in payload_json for active (non-deleted) items.
+// Payloads encrypted at rest only expose their plaintext sync/relationship fields, so their content
+// is not searchable; the response says so (EncryptedFieldsSkipped) and the envelope is not indexed.
+// entityTypes restricts the search to the given types (empty = all types).
+// Results are ordered by relevance (ts_rank), then most recently updated first.
+func (s *SearchService) Search(ctx context.Context, userID string, query string, entityTypes []string, limit int) (*SearchResponse, error) {
+	logger := log.With().Logger()
+
+	selected := searchableEntities
+	if len(entityTypes) > 0 {
+		selected = make([]searchableEntity, 0, len(entityTypes))
+		for _, et := range entityTypes {
+			found := false
+			for _, se := range searchableEntities {
+				if se.EntityType == et {
+					selected = append(selected, se)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, &UnknownEntityTypeError{EntityType: et}
+			}
+		}
+	}
+
+	// Only string values are indexed (jsonb_to_tsvector with '["string"]'),
+	// so field names like "title" don't match every row. The encryption envelope is left
+	// out: its base64 ciphertext would only produce noise matches.
+	// Entity type and table names come from searchableEntities (never user input).
+	document := fmt.Sprintf(`jsonb_to_tsvector('simple', payload_json - '%s', '["string"]')`, payloadcrypt.EnvelopeField)
+	parts := make([]string, 0, len(selected))
+	for _, se := range selected {
+		parts = append(parts, fmt.Sprintf(`
+			SELECT '%s' AS entity_type, uid::text, version, updated_at_ms, payload_json,
+			       ts_rank(%s, plainto_tsquery('simple', $2)) AS rank
+			FROM %s
+			WHERE owner_id = $1
+			  AND deleted_at_ms IS NULL
+			  AND %s @@ plainto_tsquery('simple', $2)
+		`, se.EntityType, document, se.Table, document))
+	}
+	sql := strings.Join(parts, " UNION ALL ") + ` ORDER BY rank DESC, updated_at_ms DESC LIMIT $3`
+
+	rows, err := s.DB.Query(ctx, sql, userID, query, limit)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to run search query")
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]SearchResult, 0, limit)
+	for rows.Next() {
+		var r SearchResult
+		var ms int64
+		var rank float32
+		if err := rows.Scan(&r.EntityType, &r.UID, &r.Version, &ms, &r.Payload, &rank); err != nil {
+			logger.Error().Err(err).Msg("failed to scan search row")
+			return nil, err
+		}
+		if r.Payload, err = s.Cfg.decodePayload(r.Payload); err != nil {
+			logger.Error().Err(err).Str("uid", r.UID).Msg("failed to decode search result payload")
+			return nil, err
+		}
+		r.UpdatedAt = syncx.RFC3339(ms)
+		r.Rank = float64(rank)
+		results = append(results, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Error().Err(err).Msg("row iteration error")
+		return nil, err
+	}
+
+	return &SearchResponse{
+		Query:                  query,
+		Results:                results,
+		EncryptedFieldsSkipped: s.Cfg.PayloadCipher != nil,
+	}, nil
+}
@@ -0,0 +1,52 @@
+package syncservice
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearch_Encrypted(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	cfg := encryptedConfig(t)
+
+	// A note written before encryption was enabled stays plaintext and searchable
+	plainSvc := NewNoteService(pool)
+	if _, err := plainSvc.ApplyNoteMutation(ctx, userID, map[string]any{"title": "quarterly plaintext"}, MutationOpts{}); err != nil {
+		t.Fatalf("create plaintext note: %v", err)
+	}
+	sealedSvc := NewNoteService(pool)
+	sealedSvc.Cfg = cfg
+	if _, err := sealedSvc.ApplyNoteMutation(ctx, userID, map[string]any{"title": "quarterly sealed"}, MutationOpts{}); err != nil {
+		t.Fatalf("create encrypted note: %v", err)
+	}
+
+	search := &SearchService{DB: pool, Cfg: cfg}
+	resp, err := search.Search(ctx, userID, "quarterly", nil, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if !resp.EncryptedFieldsSkipped {
+		t.Error("expected the response to flag that encrypted fields were not searched")
+	}
+	if len(resp.Results) != 1 || resp.Results[0].Payload["title"] != "quarterly plaintext" {
+		t.Errorf("expected only the plaintext note, got %+v", resp.Results)
+	}
+
+	// The envelope is not indexed: its key ID would otherwise match every encrypted item
+	resp, err = search.Search(ctx, userID, cfg.PayloadCipher.KeyID(), nil, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if len(resp.Results) != 0 {
+		t.Errorf("expected no matches on the encryption envelope, got %+v", resp.Results)
+	}
+
+	plainResp, err := (&SearchService{DB: pool, Cfg: DefaultConfig()}).Search(ctx, userID, "quarterly", nil, 10)
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if plainResp.EncryptedFieldsSkipped {
+		t.Error("without encryption at rest nothing is skipped")
+	}
+}
@@ -0,0 +1,79 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// stampSeqID gives a task that has none the user's next display ID (migration 0017) and
+// returns it. Only called when Config.SeqIDs is on.
+//
+// The increment is an upsert on owner_state, which holds the row lock until the
+// transaction ends: concurrent creates of one user queue behind it and take distinct,
+// increasing IDs. A create that rolls back leaves a gap.
+func stampSeqID(ctx context.Context, tx pgx.Tx, userID string, uid uuid.UUID) (*int64, error) {
+	var next int64
+	if err := tx.QueryRow(ctx, `
+		INSERT INTO owner_state (owner_id, task_seq) VALUES ($1, 1)
+		ON CONFLICT (owner_id) DO UPDATE SET task_seq = owner_state.task_seq + 1
+		RETURNING task_seq
+	`, userID).Scan(&next); err != nil {
+		return nil, fmt.Errorf("advance task sequence: %w", err)
+	}
+
+	var seqID int64
+	err := tx.QueryRow(ctx, `
+		UPDATE task SET seq_id = $3
+		WHERE owner_id = $1 AND uid = $2 AND seq_id IS NULL
+		RETURNING seq_id
+	`, userID, uid, next).Scan(&seqID)
+	if err == pgx.ErrNoRows {
+		// Stamped by a concurrent duplicate push; keep its ID
+		err = tx.QueryRow(ctx, `SELECT seq_id FROM task WHERE owner_id = $1 AND uid = $2`, userID, uid).Scan(&seqID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stamp task seq id: %w", err)
+	}
+	return &seqID, nil
+}
+
+// mergeTaskSeqIDsTx moves the old owner's numbered tasks to the new owner, renumbering
+// them after the new owner's current sequence in their original order so no ID is
+// held twice. Tasks without an ID are left for MergeOwnerTx to move. Returns the
+// number of tasks moved.
+func mergeTaskSeqIDsTx(ctx context.Context, tx pgx.Tx, fromUserID, toUserID string) (int, error) {
+	tag, err := tx.Exec(ctx, `
+		WITH moved AS (
+			SELECT uid, row_number() OVER (ORDER BY seq_id) AS n, count(*) OVER () AS total
+			FROM task WHERE owner_id = $1::uuid AND seq_id IS NOT NULL
+		), counter AS (
+			INSERT INTO owner_state (owner_id, task_seq)
+			SELECT $2::text, total FROM moved LIMIT 1
+			ON CONFLICT (owner_id) DO UPDATE SET task_seq = owner_state.task_seq + EXCLUDED.task_seq
+			RETURNING task_seq
+		)
+		UPDATE task SET owner_id = $2::text::uuid, seq_id = counter.task_seq - moved.total + moved.n
+		FROM moved, counter
+		WHERE task.owner_id = $1::uuid AND task.uid = moved.uid
+	`, fromUserID, toUserID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// GetTaskBySeq retrieves a task by its sequential display ID (nil if no task has it)
+func (s *TaskService) GetTaskBySeq(ctx context.Context, userID string, seqID int64) (*RESTItem, error) {
+	var uid uuid.UUID
+	err := s.DB.QueryRow(ctx, `SELECT uid FROM task WHERE owner_id = $1 AND seq_id = $2`, userID, seqID).Scan(&uid)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTask(ctx, userID, uid)
+}
@@ -0,0 +1,77 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestSeqIDsConcurrentCreates_Integration creates many tasks of one user at once and checks
+// they get distinct display IDs 1..n, readable back by GetTaskBySeq
+func TestSeqIDsConcurrentCreates_Integration(t *testing.T) {
+	pool, userID := lwwTestDB(t)
+	ctx := context.Background()
+	svc := NewTaskService(pool)
+
+	svc.Cfg.SeqIDs = true
+
+	const creates = 32
+	var wg sync.WaitGroup
+	results := make(chan *RESTItem, creates)
+	errs := make(chan error, creates)
+	for i := 0; i < creates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			item, err := svc.ApplyTaskMutation(ctx, userID, map[string]any{"title": fmt.Sprintf("task %d", i)}, MutationOpts{})
+			if err != nil {
+				errs <- err
+				return
+			}
+			results <- item
+		}(i)
+	}
+	wg.Wait()
+	close(results)
+	close(errs)
+	for err := range errs {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	seen := map[int64]string{}
+	for item := range results {
+		if item.SeqID == nil {
+			t.Fatalf("Task %s created without a seq id", item.UID)
+		}
+		if other, dup := seen[*item.SeqID]; dup {
+			t.Fatalf("Seq id %d given to both %s and %s", *item.SeqID, other, item.UID)
+		}
+		seen[*item.SeqID] = item.UID
+	}
+	for n := int64(1); n <= creates; n++ {
+		if _, ok := seen[n]; !ok {
+			t.Errorf("Seq id %d was not assigned", n)
+		}
+	}
+
+	// Updates keep the ID
+	updated, err := svc.ApplyTaskMutation(ctx, userID, map[string]any{"uid": seen[5], "title": "renamed"}, MutationOpts{})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.SeqID == nil || *updated.SeqID != 5 {
+		t.Errorf("Update changed the seq id: %v", updated.SeqID)
+	}
+
+	got, err := svc.GetTaskBySeq(ctx, userID, 5)
+	if err != nil {
+		t.Fatalf("GetTaskBySeq failed: %v", err)
+	}
+	if got == nil || got.UID != seen[5] {
+		t.Errorf("GetTaskBySeq(5) = %v, want %s", got, seen[5])
+	}
+	if got, _ := svc.GetTaskBySeq(ctx, userID, creates+1); got != nil {
+		t.Errorf("GetTaskBySeq(%d) found %s, want none", creates+1, got.UID)
+	}
+}
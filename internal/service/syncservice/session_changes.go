@@ -0,0 +1,147 @@
+package syncservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+type sessionKey struct{}
+
+// WithSession attaches the sync session whose writes should be tracked for undo
+// Writes made with a session record the item's pre-session state in session_change
+// (see UndoSession). An empty session ID disables tracking.
+func WithSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+func sessionFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionKey{}).(string)
+	return sessionID
+}
+
+// captureSessionBaseline saves the item's current row (or its absence) the first time
+// the context session writes it, before the upsert. Later writes in the same session
+// keep the original baseline.
+func captureSessionBaseline(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID) error {
+	sessionID := sessionFromContext(ctx)
+	if sessionID == "" {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO session_change (session_id, owner_id, entity, uid, existed, prev_payload, prev_deleted_at_ms)
+		SELECT $1, $2, $3, $4, t.uid IS NOT NULL, t.payload_json, t.deleted_at_ms
+		FROM (SELECT 1) AS one
+		LEFT JOIN `+table+` AS t ON t.owner_id = $2 AND t.uid = $4
+		ON CONFLICT (session_id, entity, uid) DO NOTHING
+	`, sessionID, userID, table, uid)
+	return err
+}
+
+// recordSessionWrite notes the timestamp of the session's write once the upsert is done.
+// Only writes that won LWW are recorded; undo refuses to revert an item whose current
+// timestamp differs (someone else wrote it since).
+func recordSessionWrite(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID, pushedMs, serverMs int64) error {
+	sessionID := sessionFromContext(ctx)
+	if sessionID == "" || pushedMs != serverMs {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, `
+		UPDATE session_change SET last_ms = $5
+		WHERE session_id = $1 AND owner_id = $2 AND entity = $3 AND uid = $4
+	`, sessionID, userID, table, uid, serverMs)
+	return err
+}
+
+// SessionChange is an item written during a session, with its pre-session state
+type SessionChange struct {
+	Entity          string // table name
+	UID             uuid.UUID
+	Existed         bool           // false = the session created the item
+	PrevPayload     map[string]any // client-visible payload before the session (nil if !Existed)
+	PrevDeletedAtMs *int64
+	LastMs          *int64 // timestamp of the session's latest applied write (nil = none applied)
+}
+
+// SessionChanges returns the items a session wrote for a user, oldest first
+// Previous payloads are decoded with cfg, which must match the services that stored them.
+func SessionChanges(ctx context.Context, db *pgxpool.Pool, cfg *Config, userID, sessionID string) ([]SessionChange, error) {
+	rows, err := db.Query(ctx, `
+		SELECT entity, uid, existed, prev_payload, prev_deleted_at_ms, last_ms
+		FROM session_change
+		WHERE owner_id = $1 AND session_id = $2
+		ORDER BY created_at, entity, uid
+	`, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []SessionChange
+	for rows.Next() {
+		var c SessionChange
+		var prev map[string]any
+		if err := rows.Scan(&c.Entity, &c.UID, &c.Existed, &prev, &c.PrevDeletedAtMs, &c.LastMs); err != nil {
+			return nil, err
+		}
+		if prev != nil {
+			if c.PrevPayload, err = cfg.decodePayload(prev); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// CurrentRowStateTx locks an item and returns its current timestamp and version
+// (ErrNotFound if absent)
+func CurrentRowStateTx(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID) (updatedAtMs int64, version int, err error) {
+	err = tx.QueryRow(ctx,
+		`SELECT updated_at_ms, version FROM `+table+` WHERE owner_id = $1 AND uid = $2 FOR UPDATE`,
+		userID, uid).Scan(&updatedAtMs, &version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, ErrNotFound
+	}
+	return updatedAtMs, version, err
+}
+
+// ForgetSessionChangeTx drops one item from a session's change log once undo has dealt
+// with it. Items undo could not revert keep their baseline so the undo can be retried.
+func ForgetSessionChangeTx(ctx context.Context, tx pgx.Tx, userID, sessionID, table string, uid uuid.UUID) error {
+	_, err := tx.Exec(ctx,
+		`DELETE FROM session_change WHERE owner_id = $1 AND session_id = $2 AND entity = $3 AND uid = $4`,
+		userID, sessionID, table, uid)
+	return err
+}
+
+// RunSessionChangePruner deletes change logs older than maxAge every interval until ctx is done
+func RunSessionChangePruner(ctx context.Context, db *pgxpool.Pool, interval, maxAge time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tag, err := db.Exec(ctx,
+				`DELETE FROM session_change WHERE created_at < NOW() - $1::interval`,
+				maxAge.String())
+			if err != nil {
+				log.Error().Err(err).Msg("session change pruning failed")
+				continue
+			}
+			if n := tag.RowsAffected(); n > 0 {
+				log.Info().Int64("deleted", n).Msg("pruned expired session change logs")
+			}
+		}
+	}
+}
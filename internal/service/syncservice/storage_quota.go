@@ -0,0 +1,67 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// QuotaExceededError reports a write that would grow a user's stored payload bytes past
+// the cap. Writes that keep or shrink a user's size (including deletes) always pass.
+type QuotaExceededError struct {
+	Used  int64 // payload bytes stored before the write
+	Limit int64
+	Grow  int64 // bytes the write would add
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("storage quota exceeded: %d of %d payload bytes used, write needs %d more", e.Used, e.Limit, e.Grow)
+}
+
+// checkPayloadQuota returns a *QuotaExceededError if storing payloadJSON as a live item
+// would take the user past MaxPayloadBytes
+//
+// Sizes are measured the way owner_usage.payload_bytes counts them (migrations 0015 and
+// 0020). The user's owner_usage row is locked before it is read; the payload_bytes
+// trigger would lock it at the write anyway, so taking the lock earlier only makes
+// concurrent writers of one user check against each other's committed totals.
+func (c *Config) checkPayloadQuota(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID, payloadJSON []byte, deleted bool) error {
+	if c.MaxPayloadBytes <= 0 || deleted {
+		return nil
+	}
+	var used, grow int64
+	err := tx.QueryRow(ctx, `
+		INSERT INTO owner_usage (owner_id) VALUES ($1)
+		ON CONFLICT (owner_id) DO UPDATE SET payload_bytes = owner_usage.payload_bytes
+		RETURNING payload_bytes
+	`, userID).Scan(&used)
+	if err != nil {
+		return fmt.Errorf("lock storage usage: %w", err)
+	}
+	err = tx.QueryRow(ctx, `
+		SELECT octet_length($3::jsonb::text) - COALESCE((
+			SELECT octet_length(payload_json::text) FROM `+table+`
+			WHERE owner_id = $1::uuid AND uid = $2 AND deleted_at_ms IS NULL
+		), 0)
+	`, userID, uid, payloadJSON).Scan(&grow)
+	if err != nil {
+		return fmt.Errorf("check storage quota: %w", err)
+	}
+	if grow > 0 && used+grow > c.MaxPayloadBytes {
+		return &QuotaExceededError{Used: used, Limit: c.MaxPayloadBytes, Grow: grow}
+	}
+	return nil
+}
+
+// PayloadBytes returns the payload bytes of a user's live items
+func PayloadBytes(ctx context.Context, db *pgxpool.Pool, userID string) (int64, error) {
+	var n int64
+	err := db.QueryRow(ctx, `SELECT payload_bytes FROM owner_usage WHERE owner_id = $1`, userID).Scan(&n)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	return n, err
+}
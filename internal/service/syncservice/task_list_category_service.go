@@ -2,8 +2,8 @@ package syncservice
 
 import (
 	"context"
-	"encoding/json"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -13,12 +13,14 @@ import (
 
 // TaskListCategoryService encapsulates business logic for task list category sync operations
 type TaskListCategoryService struct {
-	DB *pgxpool.Pool
+	DB    *pgxpool.Pool
+	Clock syncx.Clock // time source for server-assigned timestamps (RealClock in production)
+	Cfg   *Config     // sync settings, shared by all services of a server (DefaultConfig unless set)
 }
 
 // NewTaskListCategoryService creates a new TaskListCategoryService
 func NewTaskListCategoryService(db *pgxpool.Pool) *TaskListCategoryService {
-	return &TaskListCategoryService{DB: db}
+	return &TaskListCategoryService{DB: db, Clock: syncx.RealClock{}, Cfg: DefaultConfig()}
 }
 
 // PushTaskListCategoryItem handles the push logic for a single category item within a transaction
@@ -27,11 +29,91 @@ func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context,
 
 	ext, err := syncx.ExtractCommon(item)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Interface("item", s.Cfg.LogPayload(item)).Msg("failed to extract sync metadata")
+		return PushAck{Error: err.Error(), err: err}
 	}
 
-	payloadJSON, err := json.Marshal(item)
+	// New items must use the configured UID version (see Config.RequiredUIDVersion)
+	if err := s.Cfg.checkPushedUIDVersion(ctx, tx, "task_list_category", userID, ext.UID); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// New items may not be stamped implausibly far in the past (see Config.MaxCreateAgeDays)
+	if err := s.Cfg.checkCreateAge(ctx, tx, "task_list_category", userID, ext, s.Clock.NowMs()); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Items last edited before a wipe of their entity stay wiped (see WipeEntityTx)
+	if err := checkEntityWipe(ctx, tx, "task_list_category", userID, ext); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// A forced push replaces the stored row regardless of timestamps (see WithForceWrite)
+	if err := advanceForcedWrite(ctx, tx, "task_list_category", userID, &ext, item); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to prepare forced write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Declarative field rules (see Config.validatePayloadFields); tombstones skip
+	// them so items stored before a rule was added can still be deleted
+	if ext.DeletedAtMs == nil {
+		if err := s.Cfg.validatePayloadFields("task_list_category", item); err != nil {
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     err.Error(),
+			}
+		}
+	}
+
+	// Omitted fields keep their stored values, explicit nulls clear them (see AbsentFieldMode)
+	item, err = s.Cfg.mergeStoredPayload(ctx, tx, "task_list_category", userID, ext.UID, item)
+	if err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to merge stored payload")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to merge stored payload",
+		}
+	}
+
+	// The configured unique field (see Config.UniqueFields) must not be held by another live item
+	uniqueValue, err := s.Cfg.checkUniqueKey(ctx, tx, "task_list_category", userID, ext.UID, item, ext.DeletedAtMs != nil)
+	if err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Computed and normalized fields (see RegisterPayloadTransform)
+	s.Cfg.applyPayloadTransforms(ctx, "task_list_category", item, ext)
+
+	payloadJSON, err := s.Cfg.encodePayload(item)
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
 		return PushAck{
@@ -42,20 +124,64 @@ func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context,
 		}
 	}
 
+	// Deterministic winner between different writes with the same timestamp
+	tieKey := s.Cfg.lwwTieKey(item)
+
+	// Existing items of an append-only entity can only be deleted (see Config.AppendOnlyEntities)
+	if err := s.Cfg.checkAppendOnly(ctx, tx, "task_list_category", userID, ext.UID, tieKey, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Growth past the per-user storage cap is rejected (see Config.MaxPayloadBytes)
+	if err := s.Cfg.checkPayloadQuota(ctx, tx, "task_list_category", userID, ext.UID, payloadJSON, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Remember the pre-session state the first time this session writes the item (undo)
+	if err := captureSessionBaseline(ctx, tx, "task_list_category", userID, ext.UID); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to capture session baseline")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Cached reads of this item must not outlive the write (see ItemCache.invalidateItem)
+	s.Cfg.ItemCache.invalidateItem(tx, "task_list_category", userID, ext.UID)
+
 	_, err = tx.Exec(ctx, `
-		INSERT INTO task_list_category (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
+		INSERT INTO task_list_category (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, unique_key, tie_key)
+		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8)
 		ON CONFLICT (owner_id, uid) DO UPDATE SET
 			payload_json   = EXCLUDED.payload_json,
+			unique_key     = EXCLUDED.unique_key,
+			tie_key        = EXCLUDED.tie_key,
 			updated_at_ms  = EXCLUDED.updated_at_ms,
 			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > task_list_category.updated_at_ms
-				THEN task_list_category.version + 1
-				ELSE task_list_category.version
-			END
+			version        = task_list_category.version + 1
+		-- Strictly newer wins; on equal timestamps a delete beats an edit, and between two
+		-- edits (or two deletes) the larger tie key wins (see lwwTieKey)
 		WHERE EXCLUDED.updated_at_ms > task_list_category.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON)
+			OR (EXCLUDED.updated_at_ms = task_list_category.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) > (task_list_category.deleted_at_ms IS NOT NULL))
+			OR (EXCLUDED.updated_at_ms = task_list_category.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) = (task_list_category.deleted_at_ms IS NOT NULL)
+				AND EXCLUDED.tie_key > task_list_category.tie_key)
+	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, uniqueValue, tieKey)
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert task_list_category")
@@ -81,6 +207,16 @@ func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context,
 		}
 	}
 
+	if err := recordSessionWrite(ctx, tx, "task_list_category", userID, ext.UID, ext.UpdatedAtMs, serverMs); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record session write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
 	return PushAck{
 		UID:       ext.UID.String(),
 		Version:   serverVersion,
@@ -90,16 +226,22 @@ func (s *TaskListCategoryService) PushTaskListCategoryItem(ctx context.Context,
 
 // PullTaskListCategories handles the pull logic for task list categories
 func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	return s.PullTaskListCategoriesRange(ctx, userID, cursor, syncx.MaxCursor, limit)
+}
+
+// PullTaskListCategoriesRange pulls task list categories strictly after cursor and at or before to
+func (s *TaskListCategoryService) PullTaskListCategoriesRange(ctx context.Context, userID string, cursor, to syncx.Cursor, limit int) (*PullResponse, error) {
 	logger := log.With().Logger()
 
 	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
 		FROM task_list_category
 		WHERE owner_id = $1
 		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		  AND (updated_at_ms, uid) <= ($5, $6::uuid)
 		ORDER BY updated_at_ms, uid
 		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	`, userID, cursor.Ms, cursor.UID, limit, to.Ms, to.UID)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query task_list_categories")
@@ -109,20 +251,32 @@ func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, us
 
 	upserts := make([]map[string]any, 0, limit)
 	deletes := make([]map[string]any, 0)
+	inserts := make([]string, 0)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
+		var version int
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
 			logger.Error().Err(err).Msg("failed to scan task_list_category row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode task list category payload")
+			return nil, err
+		}
+
+		if !budget.admitPullRow(payload, deletedAtMs != nil) {
+			break
+		}
+
 		if deletedAtMs != nil {
 			deletes = append(deletes, map[string]any{
 				"uid":       uid,
@@ -130,6 +284,9 @@ func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, us
 			})
 		} else {
 			upserts = append(upserts, payload)
+			if version == 1 {
+				inserts = append(inserts, uid)
+			}
 		}
 
 		lastMs, lastUID = ms, uid
@@ -148,9 +305,11 @@ func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, us
 	}
 
 	return &PullResponse{
-		Upserts:    upserts,
-		Deletes:    deletes,
-		NextCursor: nextCursor,
+		Upserts:     upserts,
+		Deletes:     deletes,
+		Inserts:     inserts,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
@@ -158,16 +317,22 @@ func (s *TaskListCategoryService) PullTaskListCategories(ctx context.Context, us
 func (s *TaskListCategoryService) GetTaskListCategory(ctx context.Context, userID string, uid uuid.UUID) (*RESTItem, error) {
 	logger := log.With().Logger()
 
+	cached, gen, ok := s.Cfg.ItemCache.get("task_list_category", userID, uid)
+	if ok {
+		return cached, nil
+	}
+
 	var payload map[string]any
 	var version int
 	var updatedAtMs int64
 	var deletedAtMs *int64
+	var createdBy, updatedBy *string
 
 	err := s.DB.QueryRow(ctx, `
-		SELECT payload_json, version, updated_at_ms, deleted_at_ms
+		SELECT payload_json, version, updated_at_ms, deleted_at_ms, created_by, updated_by
 		FROM task_list_category
 		WHERE owner_id = $1 AND uid = $2
-	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs)
+	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs, &createdBy, &updatedBy)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -177,10 +342,17 @@ func (s *TaskListCategoryService) GetTaskListCategory(ctx context.Context, userI
 		return nil, err
 	}
 
+	if payload, err = s.Cfg.decodePayload(payload); err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to decode task list category payload")
+		return nil, err
+	}
+
 	item := &RESTItem{
 		UID:       uid.String(),
 		Version:   version,
 		UpdatedAt: syncx.RFC3339(updatedAtMs),
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   payload,
 	}
 
@@ -189,25 +361,27 @@ func (s *TaskListCategoryService) GetTaskListCategory(ctx context.Context, userI
 		item.DeletedAt = &deletedAt
 	}
 
+	s.Cfg.ItemCache.put(gen, "task_list_category", userID, uid, item)
 	return item, nil
 }
 
 // ListTaskListCategories returns paginated categories for REST endpoints
-func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, userID string, cursor syncx.Cursor, limit int, includeDeleted bool) (*RESTListResponse, error) {
+func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts ListOpts) (*RESTListResponse, error) {
 	logger := log.With().Logger()
 
 	query := `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version, created_by, updated_by
 		FROM task_list_category
 		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
 	`
-	if !includeDeleted {
-		query += ` AND deleted_at_ms IS NULL`
-	}
-	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
-
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	query += opts.positionClause()
+	query += opts.deletedClause()
+	filter, filterArgs := opts.whereClause(5)
+	query += filter
+	query += opts.orderClause() + ` LIMIT $4`
+
+	args := append([]any{userID, cursor.Ms, cursor.UID, limit}, filterArgs...)
+	rows, err := s.DB.Query(ctx, query, args...)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list task_list_categories")
 		return nil, err
@@ -217,6 +391,7 @@ func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, us
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
@@ -224,16 +399,24 @@ func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, us
 		var ms int64
 		var uid string
 		var version int
+		var createdBy, updatedBy *string
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version, &createdBy, &updatedBy); err != nil {
 			logger.Error().Err(err).Msg("failed to scan task_list_category row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode task list category payload")
+			return nil, err
+		}
+
 		item := RESTItem{
 			UID:       uid,
 			Version:   version,
 			UpdatedAt: syncx.RFC3339(ms),
+			CreatedBy: createdBy,
+			UpdatedBy: updatedBy,
 			Payload:   payload,
 		}
 
@@ -242,6 +425,9 @@ func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, us
 			item.DeletedAt = &deletedAt
 		}
 
+		if !budget.admit(item) {
+			break
+		}
 		items = append(items, item)
 		lastMs, lastUID = ms, uid
 	}
@@ -254,42 +440,67 @@ func (s *TaskListCategoryService) ListTaskListCategories(ctx context.Context, us
 	var nextCursor *string
 	if len(items) > 0 {
 		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
+		encoded := syncx.EncodeCursor(opts.cursorAt(lastMs, uid))
 		nextCursor = &encoded
 	}
 
 	return &RESTListResponse{
-		Items:      items,
-		NextCursor: nextCursor,
+		Items:       items,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
 // ApplyTaskListCategoryMutation creates or updates a category via REST
 func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
-	logger := log.With().Logger()
-
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
-		logger.Error().Err(err).Msg("failed to begin transaction")
+		log.Error().Err(err).Msg("failed to begin transaction")
 		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	item, err := s.ApplyTaskListCategoryMutationTx(ctx, tx, userID, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Error().Err(err).Msg("failed to commit mutation")
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// ApplyTaskListCategoryMutationTx is ApplyTaskListCategoryMutation within an existing transaction
+// The caller is responsible for committing or rolling back the transaction
+func (s *TaskListCategoryService) ApplyTaskListCategoryMutationTx(ctx context.Context, tx pgx.Tx, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
+	logger := log.With().Logger()
+
+	// Reject malformed fields up front as a *syncx.FieldError (REST maps it to 422)
+	if !opts.SetDeleted {
+		if err := s.Cfg.validatePayloadFields("task_list_category", payload); err != nil {
+			return nil, err
+		}
+	}
+
 	var categoryUID uuid.UUID
 	if uidStr, ok := syncx.GetString(payload, "uid"); ok {
 		categoryUID, _ = uuid.Parse(uidStr)
 	}
 	if categoryUID == uuid.Nil {
-		categoryUID = uuid.New()
+		categoryUID = s.Cfg.NewUID()
 		payload["uid"] = categoryUID.String()
 	}
 
 	var existingMs int64
 	var existingVersion int
-	err = tx.QueryRow(ctx, `
+	err := tx.QueryRow(ctx, `
 		SELECT updated_at_ms, version
 		FROM task_list_category
 		WHERE owner_id = $1 AND uid = $2
+		FOR UPDATE
 	`, userID, categoryUID).Scan(&existingMs, &existingVersion)
 
 	if err != nil && err != pgx.ErrNoRows {
@@ -299,6 +510,15 @@ func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Cont
 
 	isNew := err == pgx.ErrNoRows
 
+	// Reject new items whose UID is not the configured version (REST maps it to 422)
+	if isNew {
+		if err := s.Cfg.checkNewUIDVersion(categoryUID); err != nil {
+			return nil, err
+		}
+		// Fields the client left out start from the entity template (see Config.DefaultPayloads)
+		s.Cfg.applyDefaultPayload("task_list_category", payload)
+	}
+
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
 			return nil, &VersionMismatchError{
@@ -312,14 +532,27 @@ func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Cont
 	if opts.ForceTimestampMs != nil {
 		timestampMs = *opts.ForceTimestampMs
 	} else if isNew {
-		timestampMs = syncx.NowMs()
+		timestampMs = s.Clock.NowMs()
 	} else {
-		timestampMs = syncx.EnsureMonotonicTimestamp(existingMs)
+		timestampMs = syncx.EnsureMonotonicTimestampAt(s.Clock.NowMs(), existingMs)
+	}
+
+	// Surface a unique field conflict as a typed error (REST maps it to 409)
+	if _, err := s.Cfg.checkUniqueKey(ctx, tx, "task_list_category", userID, categoryUID, payload, opts.SetDeleted); err != nil {
+		return nil, err
 	}
 
 	mutatedPayload := syncx.BuildServerMutation(payload, timestampMs, opts.SetDeleted)
 
-	ack := s.PushTaskListCategoryItem(ctx, tx, userID, mutatedPayload)
+	// Forced mutations win LWW whatever their timestamp (see MutationOpts.ForceWrite)
+	if opts.ForceWrite {
+		ctx = WithForceWrite(ctx)
+	}
+
+	ack := s.PushTaskListCategoryItem(withReplacePayload(ctx), tx, userID, mutatedPayload)
+	if ack.err != nil {
+		return nil, ack.err
+	}
 	if ack.Error != "" {
 		return nil, &MutationError{Message: ack.Error}
 	}
@@ -338,8 +571,10 @@ func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Cont
 		syncBlock["version"] = ack.Version
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		logger.Error().Err(err).Msg("failed to commit mutation")
+	// Record who made this change (createdBy is write-once, updatedBy tracks the latest mutation)
+	createdBy, updatedBy, err := stampAttribution(ctx, tx, "task_list_category", userID, categoryUID, isNew, true)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record attribution")
 		return nil, err
 	}
 
@@ -354,6 +589,8 @@ func (s *TaskListCategoryService) ApplyTaskListCategoryMutation(ctx context.Cont
 		Version:   ack.Version,
 		UpdatedAt: ack.UpdatedAt,
 		DeletedAt: deletedAt,
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   mutatedPayload,
 	}, nil
 }
@@ -2,8 +2,8 @@ package syncservice
 
 import (
 	"context"
-	"encoding/json"
 
+	"github.com/erauner12/toolbridge-api/internal/db"
 	"github.com/erauner12/toolbridge-api/internal/syncx"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -14,12 +14,14 @@ import (
 
 // TaskListService encapsulates business logic for task list sync operations
 type TaskListService struct {
-	DB *pgxpool.Pool
+	DB    *pgxpool.Pool
+	Clock syncx.Clock // time source for server-assigned timestamps (RealClock in production)
+	Cfg   *Config     // sync settings, shared by all services of a server (DefaultConfig unless set)
 }
 
 // NewTaskListService creates a new TaskListService
 func NewTaskListService(db *pgxpool.Pool) *TaskListService {
-	return &TaskListService{DB: db}
+	return &TaskListService{DB: db, Clock: syncx.RealClock{}, Cfg: DefaultConfig()}
 }
 
 // PushTaskListItem handles the push logic for a single task list item within a transaction
@@ -30,12 +32,92 @@ func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userI
 	// Extract sync metadata from client JSON
 	ext, err := syncx.ExtractCommon(item)
 	if err != nil {
-		logger.Warn().Err(err).Interface("item", item).Msg("failed to extract sync metadata")
-		return PushAck{Error: err.Error()}
+		logger.Warn().Err(err).Interface("item", s.Cfg.LogPayload(item)).Msg("failed to extract sync metadata")
+		return PushAck{Error: err.Error(), err: err}
 	}
 
+	// New items must use the configured UID version (see Config.RequiredUIDVersion)
+	if err := s.Cfg.checkPushedUIDVersion(ctx, tx, "task_list", userID, ext.UID); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// New items may not be stamped implausibly far in the past (see Config.MaxCreateAgeDays)
+	if err := s.Cfg.checkCreateAge(ctx, tx, "task_list", userID, ext, s.Clock.NowMs()); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Items last edited before a wipe of their entity stay wiped (see WipeEntityTx)
+	if err := checkEntityWipe(ctx, tx, "task_list", userID, ext); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// A forced push replaces the stored row regardless of timestamps (see WithForceWrite)
+	if err := advanceForcedWrite(ctx, tx, "task_list", userID, &ext, item); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to prepare forced write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Declarative field rules (see Config.validatePayloadFields); tombstones skip
+	// them so items stored before a rule was added can still be deleted
+	if ext.DeletedAtMs == nil {
+		if err := s.Cfg.validatePayloadFields("task_list", item); err != nil {
+			return PushAck{
+				UID:       ext.UID.String(),
+				Version:   ext.Version,
+				UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+				Error:     err.Error(),
+			}
+		}
+	}
+
+	// Omitted fields keep their stored values, explicit nulls clear them (see AbsentFieldMode)
+	item, err = s.Cfg.mergeStoredPayload(ctx, tx, "task_list", userID, ext.UID, item)
+	if err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to merge stored payload")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     "failed to merge stored payload",
+		}
+	}
+
+	// The configured unique field (see Config.UniqueFields) must not be held by another live item
+	uniqueValue, err := s.Cfg.checkUniqueKey(ctx, tx, "task_list", userID, ext.UID, item, ext.DeletedAtMs != nil)
+	if err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Computed and normalized fields (see RegisterPayloadTransform)
+	s.Cfg.applyPayloadTransforms(ctx, "task_list", item, ext)
+
 	// Serialize payload back to JSON for storage
-	payloadJSON, err := json.Marshal(item)
+	payloadJSON, err := s.Cfg.encodePayload(item)
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to marshal payload")
 		return PushAck{
@@ -46,22 +128,67 @@ func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userI
 		}
 	}
 
+	// Deterministic winner between different writes with the same timestamp
+	tieKey := s.Cfg.lwwTieKey(item)
+
+	// Existing items of an append-only entity can only be deleted (see Config.AppendOnlyEntities)
+	if err := s.Cfg.checkAppendOnly(ctx, tx, "task_list", userID, ext.UID, tieKey, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Growth past the per-user storage cap is rejected (see Config.MaxPayloadBytes)
+	if err := s.Cfg.checkPayloadQuota(ctx, tx, "task_list", userID, ext.UID, payloadJSON, ext.DeletedAtMs != nil); err != nil {
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+			err:       err,
+		}
+	}
+
+	// Remember the pre-session state the first time this session writes the item (undo)
+	if err := captureSessionBaseline(ctx, tx, "task_list", userID, ext.UID); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to capture session baseline")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
+	// Cached reads of this item must not outlive the write (see ItemCache.invalidateItem)
+	s.Cfg.ItemCache.invalidateItem(tx, "task_list", userID, ext.UID)
+
 	// Insert or update with LWW conflict resolution
-	// Key invariant: WHERE clause uses strict > (not >=) to make duplicate pushes idempotent
+	// Key invariant: a duplicate push (same timestamp and content) matches neither branch of
+	// the WHERE clause, so it is a no-op and the version doesn't increment
 	_, err = tx.Exec(ctx, `
-		INSERT INTO task_list (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json)
-		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6)
+		INSERT INTO task_list (uid, owner_id, updated_at_ms, deleted_at_ms, version, payload_json, unique_key, tie_key)
+		VALUES ($1, $2, $3, $4, GREATEST($5, 1), $6, $7, $8)
 		ON CONFLICT (owner_id, uid) DO UPDATE SET
 			payload_json   = EXCLUDED.payload_json,
+			unique_key     = EXCLUDED.unique_key,
+			tie_key        = EXCLUDED.tie_key,
 			updated_at_ms  = EXCLUDED.updated_at_ms,
 			deleted_at_ms  = EXCLUDED.deleted_at_ms,
-			version        = CASE
-				WHEN EXCLUDED.updated_at_ms > task_list.updated_at_ms
-				THEN task_list.version + 1
-				ELSE task_list.version
-			END
+			version        = task_list.version + 1
+		-- Strictly newer wins; on equal timestamps a delete beats an edit, and between two
+		-- edits (or two deletes) the larger tie key wins (see lwwTieKey)
 		WHERE EXCLUDED.updated_at_ms > task_list.updated_at_ms
-	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON)
+			OR (EXCLUDED.updated_at_ms = task_list.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) > (task_list.deleted_at_ms IS NOT NULL))
+			OR (EXCLUDED.updated_at_ms = task_list.updated_at_ms
+				AND (EXCLUDED.deleted_at_ms IS NOT NULL) = (task_list.deleted_at_ms IS NOT NULL)
+				AND EXCLUDED.tie_key > task_list.tie_key)
+	`, ext.UID, userID, ext.UpdatedAtMs, ext.DeletedAtMs, ext.Version, payloadJSON, uniqueValue, tieKey)
 
 	if err != nil {
 		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to upsert task_list")
@@ -88,6 +215,16 @@ func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userI
 		}
 	}
 
+	if err := recordSessionWrite(ctx, tx, "task_list", userID, ext.UID, ext.UpdatedAtMs, serverMs); err != nil {
+		logger.Error().Err(err).Str("uid", ext.UID.String()).Msg("failed to record session write")
+		return PushAck{
+			UID:       ext.UID.String(),
+			Version:   ext.Version,
+			UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+			Error:     err.Error(),
+		}
+	}
+
 	return PushAck{
 		UID:       ext.UID.String(),
 		Version:   serverVersion,
@@ -97,16 +234,22 @@ func (s *TaskListService) PushTaskListItem(ctx context.Context, tx pgx.Tx, userI
 
 // PullTaskLists handles the pull logic for task lists
 func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, cursor syncx.Cursor, limit int) (*PullResponse, error) {
+	return s.PullTaskListsRange(ctx, userID, cursor, syncx.MaxCursor, limit)
+}
+
+// PullTaskListsRange pulls task lists strictly after cursor and at or before to
+func (s *TaskListService) PullTaskListsRange(ctx context.Context, userID string, cursor, to syncx.Cursor, limit int) (*PullResponse, error) {
 	logger := log.With().Logger()
 
 	rows, err := s.DB.Query(ctx, `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
 		FROM task_list
 		WHERE owner_id = $1
 		  AND (updated_at_ms, uid) > ($2, $3::uuid)
+		  AND (updated_at_ms, uid) <= ($5, $6::uuid)
 		ORDER BY updated_at_ms, uid
 		LIMIT $4
-	`, userID, cursor.Ms, cursor.UID, limit)
+	`, userID, cursor.Ms, cursor.UID, limit, to.Ms, to.UID)
 
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to query task_lists")
@@ -116,20 +259,32 @@ func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, curs
 
 	upserts := make([]map[string]any, 0, limit)
 	deletes := make([]map[string]any, 0)
+	inserts := make([]string, 0)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
 		var deletedAtMs *int64
 		var ms int64
 		var uid string
+		var version int
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
 			logger.Error().Err(err).Msg("failed to scan task_list row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode task list payload")
+			return nil, err
+		}
+
+		if !budget.admitPullRow(payload, deletedAtMs != nil) {
+			break
+		}
+
 		if deletedAtMs != nil {
 			deletes = append(deletes, map[string]any{
 				"uid":       uid,
@@ -137,6 +292,9 @@ func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, curs
 			})
 		} else {
 			upserts = append(upserts, payload)
+			if version == 1 {
+				inserts = append(inserts, uid)
+			}
 		}
 
 		lastMs, lastUID = ms, uid
@@ -155,9 +313,11 @@ func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, curs
 	}
 
 	return &PullResponse{
-		Upserts:    upserts,
-		Deletes:    deletes,
-		NextCursor: nextCursor,
+		Upserts:     upserts,
+		Deletes:     deletes,
+		Inserts:     inserts,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
@@ -165,16 +325,22 @@ func (s *TaskListService) PullTaskLists(ctx context.Context, userID string, curs
 func (s *TaskListService) GetTaskList(ctx context.Context, userID string, uid uuid.UUID) (*RESTItem, error) {
 	logger := log.With().Logger()
 
+	cached, gen, ok := s.Cfg.ItemCache.get("task_list", userID, uid)
+	if ok {
+		return cached, nil
+	}
+
 	var payload map[string]any
 	var version int
 	var updatedAtMs int64
 	var deletedAtMs *int64
+	var createdBy, updatedBy *string
 
 	err := s.DB.QueryRow(ctx, `
-		SELECT payload_json, version, updated_at_ms, deleted_at_ms
+		SELECT payload_json, version, updated_at_ms, deleted_at_ms, created_by, updated_by
 		FROM task_list
 		WHERE owner_id = $1 AND uid = $2
-	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs)
+	`, userID, uid).Scan(&payload, &version, &updatedAtMs, &deletedAtMs, &createdBy, &updatedBy)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -184,10 +350,17 @@ func (s *TaskListService) GetTaskList(ctx context.Context, userID string, uid uu
 		return nil, err
 	}
 
+	if payload, err = s.Cfg.decodePayload(payload); err != nil {
+		logger.Error().Err(err).Str("uid", uid.String()).Msg("failed to decode task list payload")
+		return nil, err
+	}
+
 	item := &RESTItem{
 		UID:       uid.String(),
 		Version:   version,
 		UpdatedAt: syncx.RFC3339(updatedAtMs),
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   payload,
 	}
 
@@ -196,25 +369,27 @@ func (s *TaskListService) GetTaskList(ctx context.Context, userID string, uid uu
 		item.DeletedAt = &deletedAt
 	}
 
+	s.Cfg.ItemCache.put(gen, "task_list", userID, uid, item)
 	return item, nil
 }
 
 // ListTaskLists returns paginated task lists for REST endpoints
-func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, cursor syncx.Cursor, limit int, includeDeleted bool) (*RESTListResponse, error) {
+func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, cursor syncx.Cursor, limit int, opts ListOpts) (*RESTListResponse, error) {
 	logger := log.With().Logger()
 
 	query := `
-		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version
+		SELECT payload_json, deleted_at_ms, updated_at_ms, uid, version, created_by, updated_by
 		FROM task_list
 		WHERE owner_id = $1
-		  AND (updated_at_ms, uid) > ($2, $3::uuid)
 	`
-	if !includeDeleted {
-		query += ` AND deleted_at_ms IS NULL`
-	}
-	query += ` ORDER BY updated_at_ms, uid LIMIT $4`
-
-	rows, err := s.DB.Query(ctx, query, userID, cursor.Ms, cursor.UID, limit)
+	query += opts.positionClause()
+	query += opts.deletedClause()
+	filter, filterArgs := opts.whereClause(5)
+	query += filter
+	query += opts.orderClause() + ` LIMIT $4`
+
+	args := append([]any{userID, cursor.Ms, cursor.UID, limit}, filterArgs...)
+	rows, err := s.DB.Query(ctx, query, args...)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to list task_lists")
 		return nil, err
@@ -224,6 +399,7 @@ func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, curs
 	items := make([]RESTItem, 0, limit)
 	var lastMs int64
 	var lastUID string
+	budget := s.Cfg.newPageBudget()
 
 	for rows.Next() {
 		var payload map[string]any
@@ -231,16 +407,24 @@ func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, curs
 		var ms int64
 		var uid string
 		var version int
+		var createdBy, updatedBy *string
 
-		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version); err != nil {
+		if err := rows.Scan(&payload, &deletedAtMs, &ms, &uid, &version, &createdBy, &updatedBy); err != nil {
 			logger.Error().Err(err).Msg("failed to scan task_list row")
 			return nil, err
 		}
 
+		if payload, err = s.Cfg.decodePayload(payload); err != nil {
+			logger.Error().Err(err).Str("uid", uid).Msg("failed to decode task list payload")
+			return nil, err
+		}
+
 		item := RESTItem{
 			UID:       uid,
 			Version:   version,
 			UpdatedAt: syncx.RFC3339(ms),
+			CreatedBy: createdBy,
+			UpdatedBy: updatedBy,
 			Payload:   payload,
 		}
 
@@ -249,6 +433,9 @@ func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, curs
 			item.DeletedAt = &deletedAt
 		}
 
+		if !budget.admit(item) {
+			break
+		}
 		items = append(items, item)
 		lastMs, lastUID = ms, uid
 	}
@@ -261,19 +448,20 @@ func (s *TaskListService) ListTaskLists(ctx context.Context, userID string, curs
 	var nextCursor *string
 	if len(items) > 0 {
 		uid, _ := uuid.Parse(lastUID)
-		encoded := syncx.EncodeCursor(syncx.Cursor{Ms: lastMs, UID: uid})
+		encoded := syncx.EncodeCursor(opts.cursorAt(lastMs, uid))
 		nextCursor = &encoded
 	}
 
 	return &RESTListResponse{
-		Items:      items,
-		NextCursor: nextCursor,
+		Items:       items,
+		NextCursor:  nextCursor,
+		ByteLimited: budget.byteLimited(),
 	}, nil
 }
 
 // ApplyTaskListMutation creates or updates a task list via REST
 func (s *TaskListService) ApplyTaskListMutation(ctx context.Context, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
-	tx, err := s.DB.Begin(ctx)
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to begin transaction")
 		return nil, err
@@ -298,13 +486,20 @@ func (s *TaskListService) ApplyTaskListMutation(ctx context.Context, userID stri
 func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx, userID string, payload map[string]any, opts MutationOpts) (*RESTItem, error) {
 	logger := log.With().Logger()
 
+	// Reject malformed fields up front as a *syncx.FieldError (REST maps it to 422)
+	if !opts.SetDeleted {
+		if err := s.Cfg.validatePayloadFields("task_list", payload); err != nil {
+			return nil, err
+		}
+	}
+
 	// Extract UID or generate new one
 	var taskListUID uuid.UUID
 	if uidStr, ok := syncx.GetString(payload, "uid"); ok {
 		taskListUID, _ = uuid.Parse(uidStr)
 	}
 	if taskListUID == uuid.Nil {
-		taskListUID = uuid.New()
+		taskListUID = s.Cfg.NewUID()
 		payload["uid"] = taskListUID.String()
 	}
 
@@ -315,6 +510,7 @@ func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx
 		SELECT updated_at_ms, version
 		FROM task_list
 		WHERE owner_id = $1 AND uid = $2
+		FOR UPDATE
 	`, userID, taskListUID).Scan(&existingMs, &existingVersion)
 
 	if err != nil && err != pgx.ErrNoRows {
@@ -324,6 +520,15 @@ func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx
 
 	isNew := err == pgx.ErrNoRows
 
+	// Reject new items whose UID is not the configured version (REST maps it to 422)
+	if isNew {
+		if err := s.Cfg.checkNewUIDVersion(taskListUID); err != nil {
+			return nil, err
+		}
+		// Fields the client left out start from the entity template (see Config.DefaultPayloads)
+		s.Cfg.applyDefaultPayload("task_list", payload)
+	}
+
 	// Optimistic locking check
 	if !isNew && opts.EnforceVersion {
 		if existingVersion != opts.ExpectedVersion {
@@ -339,16 +544,29 @@ func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx
 	if opts.ForceTimestampMs != nil {
 		timestampMs = *opts.ForceTimestampMs
 	} else if isNew {
-		timestampMs = syncx.NowMs()
+		timestampMs = s.Clock.NowMs()
 	} else {
-		timestampMs = syncx.EnsureMonotonicTimestamp(existingMs)
+		timestampMs = syncx.EnsureMonotonicTimestampAt(s.Clock.NowMs(), existingMs)
+	}
+
+	// Surface a unique field conflict as a typed error (REST maps it to 409)
+	if _, err := s.Cfg.checkUniqueKey(ctx, tx, "task_list", userID, taskListUID, payload, opts.SetDeleted); err != nil {
+		return nil, err
 	}
 
 	// Build sync-compliant payload
 	mutatedPayload := syncx.BuildServerMutation(payload, timestampMs, opts.SetDeleted)
 
+	// Forced mutations win LWW whatever their timestamp (see MutationOpts.ForceWrite)
+	if opts.ForceWrite {
+		ctx = WithForceWrite(ctx)
+	}
+
 	// Call existing push logic
-	ack := s.PushTaskListItem(ctx, tx, userID, mutatedPayload)
+	ack := s.PushTaskListItem(withReplacePayload(ctx), tx, userID, mutatedPayload)
+	if ack.err != nil {
+		return nil, ack.err
+	}
 	if ack.Error != "" {
 		return nil, &MutationError{Message: ack.Error}
 	}
@@ -368,6 +586,13 @@ func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx
 		syncBlock["version"] = ack.Version
 	}
 
+	// Record who made this change (createdBy is write-once, updatedBy tracks the latest mutation)
+	createdBy, updatedBy, err := stampAttribution(ctx, tx, "task_list", userID, taskListUID, isNew, true)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to record attribution")
+		return nil, err
+	}
+
 	var deletedAt *string
 	if opts.SetDeleted {
 		ts := syncx.RFC3339(timestampMs)
@@ -379,6 +604,8 @@ func (s *TaskListService) ApplyTaskListMutationTx(ctx context.Context, tx pgx.Tx
 		Version:   ack.Version,
 		UpdatedAt: ack.UpdatedAt,
 		DeletedAt: deletedAt,
+		CreatedBy: createdBy,
+		UpdatedBy: updatedBy,
 		Payload:   mutatedPayload,
 	}, nil
 }
@@ -394,7 +621,7 @@ func (s *TaskListService) OrphanTasksInList(ctx context.Context, userID string,
 func (s *TaskListService) OrphanTasksInListTx(ctx context.Context, tx pgx.Tx, userID string, taskListUID uuid.UUID) (int64, error) {
 	logger := log.With().Logger()
 
-	nowMs := syncx.NowMs()
+	nowMs := s.Clock.NowMs()
 
 	// Update tasks that belong to this list:
 	// 1. Remove taskListUid from payload
@@ -442,6 +669,7 @@ func (s *TaskListService) OrphanTasksInListTx(ctx context.Context, tx pgx.Tx, us
 		logger.Error().Err(err).Str("taskListUid", taskListUID.String()).Msg("failed to orphan tasks")
 		return 0, err
 	}
+	s.Cfg.ItemCache.InvalidateTable(tx, "task")
 
 	return ct.RowsAffected(), nil
 }
@@ -454,8 +682,8 @@ type DeleteTaskListResult struct {
 
 // DeleteTaskListWithOrphan atomically orphans tasks and soft-deletes the task list
 // This ensures both operations succeed or fail together
-func (s *TaskListService) DeleteTaskListWithOrphan(ctx context.Context, userID string, taskListUID uuid.UUID, payload map[string]any) (*DeleteTaskListResult, error) {
-	tx, err := s.DB.Begin(ctx)
+func (s *TaskListService) DeleteTaskListWithOrphan(ctx context.Context, userID string, taskListUID uuid.UUID, payload map[string]any, opts MutationOpts) (*DeleteTaskListResult, error) {
+	tx, err := db.Begin(ctx, s.DB)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to begin transaction for task list deletion")
 		return nil, err
@@ -468,8 +696,9 @@ func (s *TaskListService) DeleteTaskListWithOrphan(ctx context.Context, userID s
 		return nil, err
 	}
 
-	// Soft delete the task list (within same transaction)
-	opts := MutationOpts{SetDeleted: true}
+	// Soft delete the task list (within same transaction); a failed precondition
+	// in opts rolls back the orphaning too
+	opts.SetDeleted = true
 	item, err := s.ApplyTaskListMutationTx(ctx, tx, userID, payload, opts)
 	if err != nil {
 		return nil, err
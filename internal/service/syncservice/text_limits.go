@@ -0,0 +1,62 @@
+package syncservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// DefaultTextLimits is the built-in per-entity map of free-text payload fields to their
+// maximum length in characters. The caps are generous enough for any hand-written text
+// but stop a single item from carrying megabytes.
+var DefaultTextLimits = map[string]map[string]int{
+	"note":         {"content": 1_000_000},
+	"comment":      {"content": 100_000},
+	"chat_message": {"content": 100_000},
+}
+
+// ParseTextLimits parses text length caps from config: a JSON object mapping entity tables
+// to field limits in characters, e.g. {"comment":{"content":5000}}. "{}" disables them.
+func ParseTextLimits(v string) (map[string]map[string]int, error) {
+	var limits map[string]map[string]int
+	if err := json.Unmarshal([]byte(v), &limits); err != nil {
+		return nil, fmt.Errorf("text limits must be a JSON object of entity field limits: %w", err)
+	}
+	for table, fields := range limits {
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in text limits", table)
+		}
+		for field, limit := range fields {
+			if limit <= 0 {
+				return nil, fmt.Errorf("%s.%s: limit must be positive, got %d", table, field, limit)
+			}
+		}
+	}
+	return limits, nil
+}
+
+// validateTextLimits checks an item's string fields against the table's length caps
+// Length is counted in characters (runes), not bytes, so the limit reads the same for
+// every script. Fields that are absent, null or not strings are left to other validation.
+func (c *Config) validateTextLimits(table string, item map[string]any) error {
+	rules := c.TextLimits[table]
+	fields := make([]string, 0, len(rules))
+	for field := range rules {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields) // report the same field first on every attempt
+
+	for _, field := range fields {
+		s, ok := item[field].(string)
+		if !ok {
+			continue
+		}
+		if limit, n := rules[field], utf8.RuneCountInString(s); n > limit {
+			return &syncx.FieldError{Field: field, Reason: fmt.Sprintf("must be at most %d characters, got %d", limit, n)}
+		}
+	}
+	return nil
+}
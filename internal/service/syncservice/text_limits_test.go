@@ -0,0 +1,61 @@
+package syncservice
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestValidateTextLimits(t *testing.T) {
+	cfg := DefaultConfig()
+
+	// Default: 100k characters of comment content, counted in runes
+	if err := cfg.validateTextLimits("comment", map[string]any{"content": strings.Repeat("é", 100_000)}); err != nil {
+		t.Errorf("100k two-byte characters should pass, got %v", err)
+	}
+	var fieldErr *syncx.FieldError
+	if err := cfg.validateTextLimits("comment", map[string]any{"content": strings.Repeat("a", 100_001)}); !errors.As(err, &fieldErr) || fieldErr.Field != "content" {
+		t.Errorf("100001 characters should fail on content, got %v", err)
+	}
+
+	// Absent, null and non-string values are not this check's concern
+	for _, item := range []map[string]any{{}, {"content": nil}, {"content": 42}} {
+		if err := cfg.validateTextLimits("comment", item); err != nil {
+			t.Errorf("validateTextLimits(%v) = %v, want nil", item, err)
+		}
+	}
+
+	// Configured limits replace the defaults and run with the other payload rules
+	cfg.TextLimits = map[string]map[string]int{"task": {"title": 5, "description": 3}}
+	if err := cfg.validateTextLimits("comment", map[string]any{"content": strings.Repeat("a", 200_000)}); err != nil {
+		t.Errorf("Expected comment caps to be replaced, got %v", err)
+	}
+	err := cfg.validatePayloadFields("task", map[string]any{"title": "too long", "description": "long"})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "description" {
+		t.Errorf("Expected description to be reported first, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "at most 3 characters") {
+		t.Errorf("Expected the limit in the error, got %v", err)
+	}
+}
+
+func TestParseTextLimits(t *testing.T) {
+	limits, err := ParseTextLimits(`{"comment":{"content":5000},"task":{"title":200}}`)
+	if err != nil {
+		t.Fatalf("ParseTextLimits() error = %v", err)
+	}
+	if limits["comment"]["content"] != 5000 || limits["task"]["title"] != 200 {
+		t.Errorf("ParseTextLimits() = %v", limits)
+	}
+	if limits, err := ParseTextLimits(`{}`); err != nil || len(limits) != 0 {
+		t.Errorf(`ParseTextLimits("{}") = %v, %v; want empty`, limits, err)
+	}
+
+	for _, v := range []string{`not json`, `{"widget":{"content":1}}`, `{"comment":{"content":-1}}`, `{"comment":{"content":"long"}}`} {
+		if _, err := ParseTextLimits(v); err == nil {
+			t.Errorf("ParseTextLimits(%q) expected error", v)
+		}
+	}
+}
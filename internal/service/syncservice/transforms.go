@@ -0,0 +1,87 @@
+package syncservice
+
+import (
+	"context"
+	"strings"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+// WriteInfo describes the write a payload transform is applied to
+type WriteInfo struct {
+	Deleted   bool   // the write is a tombstone
+	Version   int    // version being written (server-authoritative once the upsert has run)
+	UpdatedAt string // RFC3339 timestamp of the write
+	REST      bool   // REST mutation (the server is the writer, so the result is already synced)
+}
+
+// PayloadTransform adds or normalizes payload fields before a write is persisted.
+// Transforms run in the push path of every entity (sync push and REST alike), in
+// registration order, after the stored payload has been merged in. They must be
+// idempotent: REST writes apply them again once the server version is known.
+type PayloadTransform func(payload map[string]any, w WriteInfo)
+
+// RegisterPayloadTransform adds a transform for an entity table (e.g. "note")
+func (c *Config) RegisterPayloadTransform(table string, fn PayloadTransform) {
+	if c.Transforms == nil {
+		c.Transforms = map[string][]PayloadTransform{}
+	}
+	c.Transforms[table] = append(c.Transforms[table], fn)
+}
+
+// applyPayloadTransforms runs the table's transforms on a pushed item
+// Timestamps are normalized to UTC first, so transforms and storage see one form.
+func (c *Config) applyPayloadTransforms(ctx context.Context, table string, item map[string]any, ext syncx.Extracted) {
+	syncx.NormalizeTimestamps(item)
+	replace, _ := ctx.Value(replacePayloadKey{}).(bool)
+	c.runPayloadTransforms(table, item, WriteInfo{
+		Deleted:   ext.DeletedAtMs != nil,
+		Version:   ext.Version,
+		UpdatedAt: syncx.RFC3339(ext.UpdatedAtMs),
+		REST:      replace,
+	})
+}
+
+func (c *Config) runPayloadTransforms(table string, payload map[string]any, w WriteInfo) {
+	for _, fn := range c.Transforms[table] {
+		fn(payload, w)
+	}
+}
+
+// RESTSyncFields mirrors the nested sync block into the flat sync fields Flutter
+// clients read (version, isDirty, isDeleted, remoteUpdatedAt, updateTime, lastSyncedAt)
+// for REST writes. Flags use 0/1 for client compatibility. Sync pushes are left as sent.
+func RESTSyncFields(payload map[string]any, w WriteInfo) {
+	if !w.REST {
+		return
+	}
+
+	if syncBlock, ok := payload["sync"].(map[string]any); ok {
+		syncBlock["version"] = w.Version
+	}
+
+	payload["version"] = w.Version
+	payload["isDirty"] = 0
+	if w.Deleted {
+		payload["isDeleted"] = 1
+	} else {
+		payload["isDeleted"] = 0
+	}
+	payload["remoteUpdatedAt"] = w.UpdatedAt
+	payload["updateTime"] = w.UpdatedAt
+	payload["lastSyncedAt"] = w.UpdatedAt
+}
+
+// WordCount returns a transform that stores the number of words in a text field
+// as a computed field (e.g. WordCount("content", "wordCount") for notes).
+// The computed field is removed when the source field is absent or not a string.
+func WordCount(source, target string) PayloadTransform {
+	return func(payload map[string]any, _ WriteInfo) {
+		text, ok := payload[source].(string)
+		if !ok {
+			delete(payload, target)
+			return
+		}
+		payload[target] = len(strings.Fields(text))
+	}
+}
@@ -0,0 +1,53 @@
+package syncservice
+
+import "testing"
+
+func TestRESTSyncFields(t *testing.T) {
+	w := WriteInfo{Deleted: true, Version: 3, UpdatedAt: "2025-11-03T10:00:00Z", REST: true}
+
+	payload := map[string]any{"sync": map[string]any{"version": 1}, "isDirty": 1}
+	RESTSyncFields(payload, w)
+
+	if payload["version"] != 3 || payload["sync"].(map[string]any)["version"] != 3 {
+		t.Errorf("Expected flat and nested version 3, got %v", payload)
+	}
+	if payload["isDirty"] != 0 || payload["isDeleted"] != 1 {
+		t.Errorf("Expected isDirty=0 isDeleted=1, got %v %v", payload["isDirty"], payload["isDeleted"])
+	}
+	for _, field := range []string{"remoteUpdatedAt", "updateTime", "lastSyncedAt"} {
+		if payload[field] != w.UpdatedAt {
+			t.Errorf("%s = %v, want %s", field, payload[field], w.UpdatedAt)
+		}
+	}
+
+	// Sync pushes keep the client's flat fields
+	pushed := map[string]any{"isDirty": 1}
+	RESTSyncFields(pushed, WriteInfo{Version: 3})
+	if pushed["isDirty"] != 1 || pushed["version"] != nil {
+		t.Errorf("Expected sync push payload untouched, got %v", pushed)
+	}
+}
+
+func TestWordCount(t *testing.T) {
+	fn := WordCount("content", "wordCount")
+
+	tests := []struct {
+		name    string
+		payload map[string]any
+		want    any
+	}{
+		{"words", map[string]any{"content": "  hello\tsync\nworld "}, 3},
+		{"empty", map[string]any{"content": ""}, 0},
+		{"absent clears stale count", map[string]any{"wordCount": 7}, nil},
+		{"non-string", map[string]any{"content": 42, "wordCount": 7}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn(tt.payload, WriteInfo{})
+			if got := tt.payload["wordCount"]; got != tt.want {
+				t.Errorf("wordCount = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package syncservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ParseUIDVersion parses the required UID version from config
+// "" or "any" accepts every UUID version (0); "4" and "7" require that version for new items.
+// Existing items keep their UIDs, so a deployment can switch to v7 without migrating data.
+func ParseUIDVersion(v string) (int, error) {
+	switch v {
+	case "", "any":
+		return 0, nil
+	case "4", "v4":
+		return 4, nil
+	case "7", "v7":
+		return 7, nil
+	default:
+		return 0, fmt.Errorf("unknown UID version %q (want any, 4 or 7)", v)
+	}
+}
+
+// NewUID generates a UID for a server-created item in the required version
+// (v7 when required, v4 otherwise)
+func (c *Config) NewUID() uuid.UUID {
+	if c.RequiredUIDVersion == 7 {
+		return uuid.Must(uuid.NewV7())
+	}
+	return uuid.New()
+}
+
+// checkNewUIDVersion rejects a UID for a new item that is not the required version
+func (c *Config) checkNewUIDVersion(uid uuid.UUID) error {
+	if c.RequiredUIDVersion == 0 || int(uid.Version()) == c.RequiredUIDVersion {
+		return nil
+	}
+	return &syncx.FieldError{
+		Field:  "uid",
+		Reason: fmt.Sprintf("new items must use UUIDv%d, got v%d", c.RequiredUIDVersion, uid.Version()),
+	}
+}
+
+// checkPushedUIDVersion is checkNewUIDVersion for a sync push, which may create or update
+// Only looks the row up when the UID has the wrong version: updates to existing items are
+// always accepted, only creates are rejected.
+func (c *Config) checkPushedUIDVersion(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID) error {
+	versionErr := c.checkNewUIDVersion(uid)
+	if versionErr == nil {
+		return nil
+	}
+
+	var exists bool
+	err := tx.QueryRow(ctx,
+		fmt.Sprintf(`SELECT EXISTS(SELECT 1 FROM %s WHERE owner_id = $1 AND uid = $2)`, table),
+		userID, uid).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return versionErr
+}
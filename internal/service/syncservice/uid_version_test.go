@@ -0,0 +1,51 @@
+package syncservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+)
+
+func TestUIDVersion(t *testing.T) {
+	v4 := uuid.New()
+	v7 := uuid.Must(uuid.NewV7())
+
+	tests := []struct {
+		name      string
+		config    string
+		uid       uuid.UUID
+		wantErr   bool
+		newUIDVer uuid.Version
+	}{
+		{"any accepts v4", "", v4, false, 4},
+		{"any accepts v7", "any", v7, false, 4},
+		{"v7 rejects v4", "7", v4, true, 7},
+		{"v7 accepts v7", "v7", v7, false, 7},
+		{"v4 rejects v7", "4", v7, true, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := ParseUIDVersion(tt.config)
+			if err != nil {
+				t.Fatalf("ParseUIDVersion(%q) error = %v", tt.config, err)
+			}
+			cfg := &Config{RequiredUIDVersion: version}
+
+			err = cfg.checkNewUIDVersion(tt.uid)
+			var fieldErr *syncx.FieldError
+			if tt.wantErr != errors.As(err, &fieldErr) {
+				t.Errorf("checkNewUIDVersion(v%d) = %v, wantErr %v", tt.uid.Version(), err, tt.wantErr)
+			}
+			if got := cfg.NewUID().Version(); got != tt.newUIDVer {
+				t.Errorf("NewUID() version = %d, want %d", got, tt.newUIDVer)
+			}
+		})
+	}
+
+	if _, err := ParseUIDVersion("6"); err == nil {
+		t.Error("Expected ParseUIDVersion to reject unsupported versions")
+	}
+}
@@ -0,0 +1,89 @@
+package syncservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ParseUniqueFields parses unique fields from config: a JSON object mapping entity
+// tables to the one payload field whose value must be unique among a user's live items,
+// e.g. {"note":"externalId"} for integrations keyed by an external system's IDs
+func ParseUniqueFields(v string) (map[string]string, error) {
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(v), &fields); err != nil {
+		return nil, fmt.Errorf("unique fields must be a JSON object of entity fields: %w", err)
+	}
+	for table, field := range fields {
+		if _, ok := FilterableFields[table]; !ok {
+			return nil, fmt.Errorf("unknown entity %q in unique fields", table)
+		}
+		if field == "" {
+			return nil, fmt.Errorf("empty unique field for entity %q", table)
+		}
+	}
+	return fields, nil
+}
+
+// UniqueConflictError reports a write whose unique field value is held by another live item
+type UniqueConflictError struct {
+	Field string
+	Value string
+	UID   string // The live item already holding the value
+}
+
+func (e *UniqueConflictError) Error() string {
+	return fmt.Sprintf("%s %q is already used by %s", e.Field, e.Value, e.UID)
+}
+
+// uniqueKey returns the value of the table's unique field in item (nil = none)
+// Strings and numbers are accepted; an absent, null or empty value has no key.
+func (c *Config) uniqueKey(table string, item map[string]any) (*string, error) {
+	field, ok := c.UniqueFields[table]
+	if !ok {
+		return nil, nil
+	}
+	var key string
+	switch v := item[field].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		key = v
+	case float64:
+		key = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, &syncx.FieldError{Field: field, Reason: fmt.Sprintf("must be a string or number, got %T", v)}
+	}
+	if key == "" {
+		return nil, nil
+	}
+	return &key, nil
+}
+
+// checkUniqueKey returns the unique key of a live item about to be written, or a
+// *UniqueConflictError if another live item of the user already holds it.
+// Tombstones are never checked: deleted items don't block reuse.
+func (c *Config) checkUniqueKey(ctx context.Context, tx pgx.Tx, table, userID string, uid uuid.UUID, item map[string]any, deleted bool) (*string, error) {
+	key, err := c.uniqueKey(table, item)
+	if err != nil || key == nil || deleted {
+		return key, err
+	}
+
+	var holder uuid.UUID
+	err = tx.QueryRow(ctx,
+		fmt.Sprintf(`SELECT uid FROM %s WHERE owner_id = $1 AND unique_key = $2 AND deleted_at_ms IS NULL AND uid <> $3`, table),
+		userID, *key, uid).Scan(&holder)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return key, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nil, &UniqueConflictError{Field: c.UniqueFields[table], Value: *key, UID: holder.String()}
+}
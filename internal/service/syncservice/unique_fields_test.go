@@ -0,0 +1,63 @@
+package syncservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+)
+
+func TestUniqueKey(t *testing.T) {
+	cfg := &Config{UniqueFields: map[string]string{"note": "externalId"}}
+
+	tests := []struct {
+		name string
+		item map[string]any
+		want string // "" = no key
+	}{
+		{name: "string value", item: map[string]any{"externalId": "ext-1"}, want: "ext-1"},
+		{name: "number value", item: map[string]any{"externalId": 42.0}, want: "42"},
+		{name: "absent", item: map[string]any{}},
+		{name: "null", item: map[string]any{"externalId": nil}},
+		{name: "empty string", item: map[string]any{"externalId": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := cfg.uniqueKey("note", tt.item)
+			if err != nil {
+				t.Fatalf("uniqueKey() error = %v", err)
+			}
+			got := ""
+			if key != nil {
+				got = *key
+			}
+			if got != tt.want {
+				t.Errorf("uniqueKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	var fieldErr *syncx.FieldError
+	if _, err := cfg.uniqueKey("note", map[string]any{"externalId": []any{"a"}}); !errors.As(err, &fieldErr) || fieldErr.Field != "externalId" {
+		t.Errorf("Expected FieldError for a non-scalar value, got %v", err)
+	}
+	if key, err := cfg.uniqueKey("task", map[string]any{"externalId": "ext-1"}); key != nil || err != nil {
+		t.Errorf("Expected no key for an entity without a unique field, got %v, %v", key, err)
+	}
+}
+
+func TestParseUniqueFields(t *testing.T) {
+	fields, err := ParseUniqueFields(`{"note":"externalId","task":"sourceId"}`)
+	if err != nil {
+		t.Fatalf("ParseUniqueFields() error = %v", err)
+	}
+	if fields["note"] != "externalId" || fields["task"] != "sourceId" {
+		t.Errorf("Unexpected fields: %v", fields)
+	}
+
+	for _, v := range []string{`["externalId"]`, `{"widget":"externalId"}`, `{"note":""}`} {
+		if _, err := ParseUniqueFields(v); err == nil {
+			t.Errorf("ParseUniqueFields(%s) expected error", v)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package syncservice
+
+import (
+	"context"
+
+	"github.com/erauner12/toolbridge-api/internal/syncx"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PullWatermark returns the newest pull position (updated_at_ms, uid) for a user's
+// entity table, including purge markers and the latest per-entity wipe. A pull whose cursor is at or past the
+// watermark is guaranteed to return nothing. Returns a zero cursor if the user has no rows.
+// Each lookup is a backward scan of the (owner_id, updated_at_ms) index, so it's
+// cheaper than running the pull query.
+func PullWatermark(ctx context.Context, db *pgxpool.Pool, table, userID string) (syncx.Cursor, error) {
+	var watermark syncx.Cursor
+
+	queries := []struct {
+		sql  string
+		args []any
+	}{
+		{`SELECT updated_at_ms, uid FROM ` + table + `
+			WHERE owner_id = $1
+			ORDER BY updated_at_ms DESC, uid DESC
+			LIMIT 1`, []any{userID}},
+		{`SELECT purged_at_ms, uid FROM purge_marker
+			WHERE owner_id = $1 AND entity = $2
+			ORDER BY purged_at_ms DESC, uid DESC
+			LIMIT 1`, []any{userID, table}},
+		// A wipe leaves no rows behind; its marker keeps older cursors from looking up to date
+		{`SELECT wiped_at_ms, '00000000-0000-0000-0000-000000000000'::uuid FROM entity_wipe
+			WHERE owner_id = $1 AND entity = $2`, []any{userID, table}},
+	}
+
+	for _, q := range queries {
+		var ms int64
+		var uid uuid.UUID
+		err := db.QueryRow(ctx, q.sql, q.args...).Scan(&ms, &uid)
+		if err == pgx.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return syncx.Cursor{}, err
+		}
+		if c := (syncx.Cursor{Ms: ms, UID: uid}); syncx.CompareCursors(c, watermark) > 0 {
+			watermark = c
+		}
+	}
+
+	return watermark, nil
+}
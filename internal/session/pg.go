@@ -0,0 +1,157 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+)
+
+// StoreBackend selects where sessions are kept
+type StoreBackend string
+
+const (
+	// StoreMemory keeps sessions in this process (single replica)
+	StoreMemory StoreBackend = "memory"
+	// StorePostgres keeps sessions in the sync_session table, shared by every replica
+	StorePostgres StoreBackend = "postgres"
+)
+
+// ParseStoreBackend parses a session store name from config
+func ParseStoreBackend(v string) (StoreBackend, error) {
+	switch StoreBackend(v) {
+	case StoreMemory, StorePostgres:
+		return StoreBackend(v), nil
+	default:
+		return "", fmt.Errorf("unknown session store %q (want %q or %q)", v, StoreMemory, StorePostgres)
+	}
+}
+
+// pgQueryTimeout bounds each session query; Store methods are called without a request context
+const pgQueryTimeout = 5 * time.Second
+
+// UsePostgres moves the store's sessions into Postgres (sync_session), so a session begun
+// on one replica is immediately valid on every replica sharing the database. Nothing is
+// cached in memory: every lookup reads the table. Call once at startup.
+func (s *Store) UsePostgres(pool *pgxpool.Pool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.db = pool
+}
+
+// pool returns the Postgres pool, or nil when sessions are kept in memory
+// Postgres-backed methods run without holding s.mu so replicas' queries never queue on it.
+func (s *Store) pool() *pgxpool.Pool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.db
+}
+
+// createSessionPG is CreateSession against sync_session
+// A per-user advisory lock serializes concurrent creates across replicas so the cap holds.
+func (s *Store) createSessionPG(userID string, epoch int) (Session, error) {
+	maxPerUser, policy := s.UserSessionLimit()
+	ctx, cancel := context.WithTimeout(context.Background(), pgQueryTimeout)
+	defer cancel()
+
+	now := time.Now().UTC()
+	session := Session{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.ttl),
+		Epoch:     epoch,
+	}
+
+	tx, err := db.Begin(ctx, s.pool())
+	if err != nil {
+		return Session{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('sync_session:' || $1))`, userID); err != nil {
+		return Session{}, err
+	}
+
+	// Expired sessions don't count toward the cap
+	if _, err := tx.Exec(ctx, `DELETE FROM sync_session WHERE owner_id = $1 AND expires_at <= $2`, userID, now); err != nil {
+		return Session{}, err
+	}
+
+	if maxPerUser > 0 {
+		var active int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM sync_session WHERE owner_id = $1`, userID).Scan(&active); err != nil {
+			return Session{}, err
+		}
+		if excess := active - maxPerUser + 1; excess > 0 {
+			if policy == LimitPolicyReject {
+				return Session{}, ErrSessionLimitReached
+			}
+			// Evict oldest sessions first
+			if _, err := tx.Exec(ctx, `
+				DELETE FROM sync_session WHERE id IN (
+					SELECT id FROM sync_session WHERE owner_id = $1
+					ORDER BY created_at LIMIT $2
+				)
+			`, userID, excess); err != nil {
+				return Session{}, err
+			}
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO sync_session (id, owner_id, epoch, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, session.ID, userID, epoch, session.CreatedAt, session.ExpiresAt); err != nil {
+		return Session{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Session{}, err
+	}
+	return session, nil
+}
+
+// getSessionPG is GetSession against sync_session
+// A failed lookup is logged and treated as no session: the client begins a new one.
+func (s *Store) getSessionPG(sessionID string) (Session, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), pgQueryTimeout)
+	defer cancel()
+
+	var sess Session
+	err := db.Retry(ctx, db.DefaultRetry, func() error {
+		return s.pool().QueryRow(ctx, `
+			SELECT id, owner_id, epoch, created_at, expires_at
+			FROM sync_session
+			WHERE id = $1 AND expires_at > $2
+		`, sessionID, time.Now().UTC()).Scan(&sess.ID, &sess.UserID, &sess.Epoch, &sess.CreatedAt, &sess.ExpiresAt)
+	})
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Error().Err(err).Str("sessionId", sessionID).Msg("failed to load sync session")
+		}
+		return Session{}, false
+	}
+	sess.CreatedAt, sess.ExpiresAt = sess.CreatedAt.UTC(), sess.ExpiresAt.UTC()
+	return sess, true
+}
+
+// execCountPG runs a session DELETE/COUNT statement and returns its row count
+func (s *Store) execCountPG(sql string, args ...any) int {
+	ctx, cancel := context.WithTimeout(context.Background(), pgQueryTimeout)
+	defer cancel()
+
+	var n int
+	if err := s.pool().QueryRow(ctx, sql, args...).Scan(&n); err != nil {
+		log.Error().Err(err).Msg("sync session query failed")
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,127 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/erauner12/toolbridge-api/internal/db"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// getTestDB connects to TEST_DATABASE_URL (skips when unset) and clears sync_session
+func getTestDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration tests")
+	}
+
+	pool, err := db.Open(context.Background(), dbURL)
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+
+	if _, err := pool.Exec(context.Background(), "DELETE FROM sync_session"); err != nil {
+		t.Fatalf("Failed to clean sync_session table: %v", err)
+	}
+
+	return pool
+}
+
+// newPGTestStore is a Postgres-backed store, standing in for one server replica
+func newPGTestStore(pool *pgxpool.Pool, max int, policy LimitPolicy) *Store {
+	s := newTestStore(max, policy)
+	s.UsePostgres(pool)
+	return s
+}
+
+func TestPostgresStore_SharedAcrossReplicas_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	replicaA := newPGTestStore(pool, 0, LimitPolicyEvictOldest)
+	replicaB := newPGTestStore(pool, 0, LimitPolicyEvictOldest)
+
+	created, err := replicaA.CreateSession("user-1", 3)
+	if err != nil {
+		t.Fatalf("CreateSession on replica A failed: %v", err)
+	}
+
+	got, ok := replicaB.GetSession(created.ID)
+	if !ok {
+		t.Fatal("Session created on replica A not found on replica B")
+	}
+	if got.UserID != "user-1" || got.Epoch != 3 {
+		t.Errorf("Replica B sees %+v, want user-1 at epoch 3", got)
+	}
+	if !got.ExpiresAt.Truncate(time.Millisecond).Equal(created.ExpiresAt.Truncate(time.Millisecond)) {
+		t.Errorf("ExpiresAt = %v, want %v", got.ExpiresAt, created.ExpiresAt)
+	}
+	if n := replicaB.CountUserSessions("user-1"); n != 1 {
+		t.Errorf("Replica B counts %d sessions, want 1", n)
+	}
+
+	// Ending the session on B invalidates it on A
+	if !replicaB.DeleteSession(created.ID) {
+		t.Fatal("DeleteSession on replica B returned false")
+	}
+	if _, ok := replicaA.GetSession(created.ID); ok {
+		t.Error("Session deleted on replica B still valid on replica A")
+	}
+
+	// A wipe on one replica ends the user's sessions everywhere
+	for i := 0; i < 2; i++ {
+		if _, err := replicaA.CreateSession("user-1", 4); err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+	}
+	if n := replicaB.DeleteUserSessions("user-1"); n != 2 {
+		t.Errorf("DeleteUserSessions removed %d sessions, want 2", n)
+	}
+	if n := replicaA.CountUserSessions("user-1"); n != 0 {
+		t.Errorf("Replica A still counts %d sessions after wipe", n)
+	}
+}
+
+func TestPostgresStore_LimitAcrossReplicas_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	pool := getTestDB(t)
+	defer pool.Close()
+
+	replicaA := newPGTestStore(pool, 2, LimitPolicyReject)
+	replicaB := newPGTestStore(pool, 2, LimitPolicyReject)
+
+	if _, err := replicaA.CreateSession("user-1", 1); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := replicaB.CreateSession("user-1", 1); err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := replicaA.CreateSession("user-1", 1); !errors.Is(err, ErrSessionLimitReached) {
+		t.Errorf("Expected ErrSessionLimitReached across replicas, got %v", err)
+	}
+
+	// Evicting on one replica removes the oldest session for both
+	replicaB.SetUserSessionLimit(2, LimitPolicyEvictOldest)
+	newest, err := replicaB.CreateSession("user-1", 1)
+	if err != nil {
+		t.Fatalf("CreateSession with eviction failed: %v", err)
+	}
+	if n := replicaA.CountUserSessions("user-1"); n != 2 {
+		t.Errorf("Expected 2 sessions after eviction, got %d", n)
+	}
+	if _, ok := replicaA.GetSession(newest.ID); !ok {
+		t.Error("Newest session missing on replica A")
+	}
+}
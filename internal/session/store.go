@@ -1,10 +1,14 @@
 package session
 
 import (
+	"errors"
+	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // Session represents an active sync session
@@ -16,17 +20,51 @@ type Session struct {
 	Epoch     int       `json:"epoch"` // Tenant epoch for wipe/reset coordination
 }
 
+// ResyncRequiredCode is the error code returned (HTTP 409 body "code", gRPC ErrorInfo
+// reason) when a request's X-Sync-Epoch is not the user's current epoch. The client
+// must stop paging, drop its local copy and begin a new session.
+const ResyncRequiredCode = "resyncRequired"
+
+// LimitPolicy controls what CreateSession does when a user is at the session cap
+type LimitPolicy string
+
+const (
+	// LimitPolicyEvictOldest ends the user's oldest session(s) to make room
+	LimitPolicyEvictOldest LimitPolicy = "evict_oldest"
+	// LimitPolicyReject refuses to create the new session
+	LimitPolicyReject LimitPolicy = "reject"
+)
+
+// ParseLimitPolicy parses a policy name from config
+func ParseLimitPolicy(v string) (LimitPolicy, error) {
+	switch LimitPolicy(v) {
+	case LimitPolicyEvictOldest, LimitPolicyReject:
+		return LimitPolicy(v), nil
+	default:
+		return "", fmt.Errorf("unknown session limit policy %q (want %q or %q)", v, LimitPolicyEvictOldest, LimitPolicyReject)
+	}
+}
+
+// ErrSessionLimitReached is returned by CreateSession when the user is at the
+// session cap and the policy is LimitPolicyReject
+var ErrSessionLimitReached = errors.New("too many active sessions")
+
 // Store manages active sync sessions
+// Sessions are kept in memory by default; UsePostgres shares them across replicas.
 type Store struct {
-	mu       sync.RWMutex
-	sessions map[string]Session // key: sessionId
-	ttl      time.Duration
+	mu          sync.RWMutex
+	sessions    map[string]Session // key: sessionId
+	ttl         time.Duration
+	maxPerUser  int // 0 = unlimited
+	limitPolicy LimitPolicy
+	db          *pgxpool.Pool // non-nil = sessions live in Postgres (see UsePostgres)
 }
 
-// Global session store (in-memory)
+// Global session store (in-memory unless UsePostgres is called)
 var globalStore = &Store{
-	sessions: make(map[string]Session),
-	ttl:      30 * time.Minute, // Sessions expire after 30 minutes
+	sessions:    make(map[string]Session),
+	ttl:         30 * time.Minute, // Sessions expire after 30 minutes
+	limitPolicy: LimitPolicyEvictOldest,
 }
 
 // GetStore returns the singleton session store
@@ -34,11 +72,44 @@ func GetStore() *Store {
 	return globalStore
 }
 
+// SetUserSessionLimit caps concurrent active sessions per user (0 = unlimited)
+// and sets what happens when a user at the cap begins another session
+func (s *Store) SetUserSessionLimit(max int, policy LimitPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.maxPerUser = max
+	s.limitPolicy = policy
+}
+
+// UserSessionLimit returns the per-user session cap (0 = unlimited) and policy
+func (s *Store) UserSessionLimit() (int, LimitPolicy) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.maxPerUser, s.limitPolicy
+}
+
 // CreateSession generates a new session ID for the user
-func (s *Store) CreateSession(userID string, epoch int) Session {
+// When the user is at the per-user cap, the oldest sessions are evicted or
+// ErrSessionLimitReached is returned, depending on the limit policy.
+func (s *Store) CreateSession(userID string, epoch int) (Session, error) {
+	if s.pool() != nil {
+		return s.createSessionPG(userID, epoch)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Clean up expired sessions first so they don't count toward the cap
+	s.cleanupExpiredLocked()
+
+	if s.maxPerUser > 0 {
+		if err := s.enforceUserLimitLocked(userID); err != nil {
+			return Session{}, err
+		}
+	}
+
 	session := Session{
 		ID:        uuid.New().String(),
 		UserID:    userID,
@@ -49,14 +120,43 @@ func (s *Store) CreateSession(userID string, epoch int) Session {
 
 	s.sessions[session.ID] = session
 
-	// Clean up expired sessions opportunistically
-	s.cleanupExpiredLocked()
+	return session, nil
+}
 
-	return session
+// enforceUserLimitLocked makes room for one more session for the user
+// (caller must hold write lock and have removed expired sessions)
+func (s *Store) enforceUserLimitLocked(userID string) error {
+	userSessions := make([]Session, 0)
+	for _, sess := range s.sessions {
+		if sess.UserID == userID {
+			userSessions = append(userSessions, sess)
+		}
+	}
+
+	excess := len(userSessions) - s.maxPerUser + 1
+	if excess <= 0 {
+		return nil
+	}
+	if s.limitPolicy == LimitPolicyReject {
+		return ErrSessionLimitReached
+	}
+
+	// Evict oldest sessions first
+	sort.Slice(userSessions, func(i, j int) bool {
+		return userSessions[i].CreatedAt.Before(userSessions[j].CreatedAt)
+	})
+	for _, sess := range userSessions[:excess] {
+		delete(s.sessions, sess.ID)
+	}
+	return nil
 }
 
 // GetSession retrieves a session by ID
 func (s *Store) GetSession(sessionID string) (Session, bool) {
+	if s.pool() != nil {
+		return s.getSessionPG(sessionID)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -75,6 +175,10 @@ func (s *Store) GetSession(sessionID string) (Session, bool) {
 
 // DeleteSession removes a session
 func (s *Store) DeleteSession(sessionID string) bool {
+	if s.pool() != nil {
+		return s.execCountPG(`WITH del AS (DELETE FROM sync_session WHERE id = $1 RETURNING 1) SELECT COUNT(*) FROM del`, sessionID) > 0
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -90,6 +194,10 @@ func (s *Store) DeleteSession(sessionID string) bool {
 // Returns the number of sessions deleted.
 // Used when wiping account data to invalidate all device sessions.
 func (s *Store) DeleteUserSessions(userID string) int {
+	if s.pool() != nil {
+		return s.execCountPG(`WITH del AS (DELETE FROM sync_session WHERE owner_id = $1 RETURNING 1) SELECT COUNT(*) FROM del`, userID)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -103,6 +211,26 @@ func (s *Store) DeleteUserSessions(userID string) int {
 	return count
 }
 
+// CountUserSessions returns the number of unexpired sessions for a given user.
+// Used by admin diagnostics to inspect a user's sync activity.
+func (s *Store) CountUserSessions(userID string) int {
+	if s.pool() != nil {
+		return s.execCountPG(`SELECT COUNT(*) FROM sync_session WHERE owner_id = $1 AND expires_at > $2`, userID, time.Now().UTC())
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now().UTC()
+	count := 0
+	for _, sess := range s.sessions {
+		if sess.UserID == userID && !now.After(sess.ExpiresAt) {
+			count++
+		}
+	}
+	return count
+}
+
 // cleanupExpiredLocked removes expired sessions (caller must hold write lock)
 func (s *Store) cleanupExpiredLocked() {
 	now := time.Now().UTC()
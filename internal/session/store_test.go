@@ -0,0 +1,84 @@
+package session
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestStore(max int, policy LimitPolicy) *Store {
+	s := &Store{
+		sessions: make(map[string]Session),
+		ttl:      30 * time.Minute,
+	}
+	s.SetUserSessionLimit(max, policy)
+	return s
+}
+
+func TestCreateSession_Unlimited(t *testing.T) {
+	s := newTestStore(0, LimitPolicyReject)
+
+	for i := 0; i < 10; i++ {
+		if _, err := s.CreateSession("user-1", 1); err != nil {
+			t.Fatalf("Unexpected error creating session %d: %v", i, err)
+		}
+	}
+	if got := s.CountUserSessions("user-1"); got != 10 {
+		t.Errorf("Expected 10 sessions, got %d", got)
+	}
+}
+
+func TestCreateSession_RejectAtLimit(t *testing.T) {
+	s := newTestStore(2, LimitPolicyReject)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.CreateSession("user-1", 1); err != nil {
+			t.Fatalf("Unexpected error creating session %d: %v", i, err)
+		}
+	}
+
+	if _, err := s.CreateSession("user-1", 1); !errors.Is(err, ErrSessionLimitReached) {
+		t.Errorf("Expected ErrSessionLimitReached, got %v", err)
+	}
+
+	// Other users are unaffected
+	if _, err := s.CreateSession("user-2", 1); err != nil {
+		t.Errorf("Expected other user's session to be created, got %v", err)
+	}
+}
+
+func TestCreateSession_EvictOldestAtLimit(t *testing.T) {
+	s := newTestStore(2, LimitPolicyEvictOldest)
+
+	first, _ := s.CreateSession("user-1", 1)
+	time.Sleep(time.Millisecond)
+	second, _ := s.CreateSession("user-1", 1)
+	time.Sleep(time.Millisecond)
+	third, err := s.CreateSession("user-1", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, ok := s.GetSession(first.ID); ok {
+		t.Error("Expected oldest session to be evicted")
+	}
+	for _, sess := range []Session{second, third} {
+		if _, ok := s.GetSession(sess.ID); !ok {
+			t.Errorf("Expected session %s to remain active", sess.ID)
+		}
+	}
+	if got := s.CountUserSessions("user-1"); got != 2 {
+		t.Errorf("Expected 2 sessions, got %d", got)
+	}
+}
+
+func TestParseLimitPolicy(t *testing.T) {
+	for _, v := range []string{"evict_oldest", "reject"} {
+		if _, err := ParseLimitPolicy(v); err != nil {
+			t.Errorf("Expected %q to parse, got %v", v, err)
+		}
+	}
+	if _, err := ParseLimitPolicy("lru"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
@@ -0,0 +1,52 @@
+package syncx
+
+import (
+	"sync"
+)
+
+// Clock supplies the current time in Unix milliseconds
+// Services take a Clock so tests can control time deterministically
+// instead of threading ForceTimestampMs through every call.
+type Clock interface {
+	NowMs() int64
+}
+
+// RealClock is the production Clock backed by the system time
+type RealClock struct{}
+
+// NowMs returns current Unix milliseconds timestamp (UTC)
+func (RealClock) NowMs() int64 {
+	return NowMs()
+}
+
+// FakeClock is a manually controlled Clock for tests
+type FakeClock struct {
+	mu sync.Mutex
+	ms int64
+}
+
+// NewFakeClock creates a FakeClock starting at the given Unix milliseconds
+func NewFakeClock(startMs int64) *FakeClock {
+	return &FakeClock{ms: startMs}
+}
+
+// NowMs returns the fake clock's current time
+func (c *FakeClock) NowMs() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ms
+}
+
+// Set moves the fake clock to the given Unix milliseconds
+func (c *FakeClock) Set(ms int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ms = ms
+}
+
+// Advance moves the fake clock forward by deltaMs milliseconds
+func (c *FakeClock) Advance(deltaMs int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ms += deltaMs
+}
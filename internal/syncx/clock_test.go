@@ -0,0 +1,41 @@
+package syncx
+
+import "testing"
+
+func TestFakeClock(t *testing.T) {
+	c := NewFakeClock(1000)
+	if got := c.NowMs(); got != 1000 {
+		t.Errorf("NowMs() = %d, want 1000", got)
+	}
+
+	c.Advance(250)
+	if got := c.NowMs(); got != 1250 {
+		t.Errorf("after Advance(250), NowMs() = %d, want 1250", got)
+	}
+
+	c.Set(500)
+	if got := c.NowMs(); got != 500 {
+		t.Errorf("after Set(500), NowMs() = %d, want 500", got)
+	}
+}
+
+func TestEnsureMonotonicTimestampAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		nowMs  int64
+		prevMs int64
+		want   int64
+	}{
+		{"clock ahead of previous", 2000, 1000, 2000},
+		{"clock equal to previous", 1000, 1000, 1001},
+		{"clock behind previous (skew)", 900, 1000, 1001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EnsureMonotonicTimestampAt(tt.nowMs, tt.prevMs); got != tt.want {
+				t.Errorf("EnsureMonotonicTimestampAt(%d, %d) = %d, want %d", tt.nowMs, tt.prevMs, got, tt.want)
+			}
+		})
+	}
+}
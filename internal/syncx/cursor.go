@@ -1,8 +1,11 @@
 package syncx
 
 import (
+	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -11,51 +14,88 @@ import (
 )
 
 // Cursor represents a position in the sync stream
-// Format: base64("<updated_at_ms>|<uuid>")
+// Format: base64(<version byte> "<updated_at_ms>|<uuid>")
 // Ensures lexicographically ordered, deterministic pagination
 type Cursor struct {
 	Ms  int64     // Unix milliseconds timestamp
 	UID uuid.UUID // Entity UUID (for deterministic ordering within same timestamp)
 }
 
+// MaxCursor is past every real position; an upper bound of MaxCursor leaves a range open-ended
+var MaxCursor = Cursor{Ms: math.MaxInt64, UID: uuid.Max}
+
+// cursorVersion is the format version byte written by EncodeCursor
+// Bump it when the cursor layout changes so old servers reject new cursors (and vice
+// versa) instead of misreading them.
+const cursorVersion byte = 1
+
+// ErrInvalidCursor is returned by DecodeCursor for a cursor this server cannot read
+// Clients must not treat it as "start over": restarting from the beginning would
+// silently re-download everything.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // EncodeCursor creates a base64-encoded cursor string
 // Returns empty string for zero-value cursor
 func EncodeCursor(c Cursor) string {
 	if c.Ms == 0 && c.UID == uuid.Nil {
 		return ""
 	}
-	raw := fmt.Sprintf("%d|%s", c.Ms, c.UID.String())
-	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	raw := append([]byte{cursorVersion}, fmt.Sprintf("%d|%s", c.Ms, c.UID.String())...)
+	return base64.RawURLEncoding.EncodeToString(raw)
 }
 
 // DecodeCursor parses a cursor string
-// Returns zero-value cursor and false if invalid or empty
-func DecodeCursor(s string) (Cursor, bool) {
+// An empty string is the zero cursor (start of the stream). Malformed cursors and unknown
+// format versions return an error wrapping ErrInvalidCursor. Unversioned cursors issued
+// before the version byte was added ("<ms>|<uuid>") are still accepted.
+func DecodeCursor(s string) (Cursor, error) {
 	if s == "" {
-		return Cursor{}, false
+		return Cursor{}, nil
 	}
 
 	b, err := base64.RawURLEncoding.DecodeString(s)
-	if err != nil {
-		return Cursor{}, false
+	if err != nil || len(b) == 0 {
+		return Cursor{}, fmt.Errorf("%w: not base64url", ErrInvalidCursor)
 	}
 
-	parts := strings.Split(string(b), "|")
-	if len(parts) != 2 {
-		return Cursor{}, false
+	switch {
+	case b[0] == cursorVersion:
+		b = b[1:]
+	case (b[0] >= '0' && b[0] <= '9') || b[0] == '-':
+		// Legacy unversioned cursor
+	default:
+		return Cursor{}, fmt.Errorf("%w: unknown cursor version %d", ErrInvalidCursor, b[0])
 	}
 
-	ms, err := strconv.ParseInt(parts[0], 10, 64)
+	msStr, uidStr, ok := strings.Cut(string(b), "|")
+	if !ok {
+		return Cursor{}, fmt.Errorf("%w: malformed position", ErrInvalidCursor)
+	}
+
+	ms, err := strconv.ParseInt(msStr, 10, 64)
 	if err != nil {
-		return Cursor{}, false
+		return Cursor{}, fmt.Errorf("%w: malformed timestamp", ErrInvalidCursor)
 	}
 
-	id, err := uuid.Parse(parts[1])
+	id, err := uuid.Parse(uidStr)
 	if err != nil {
-		return Cursor{}, false
+		return Cursor{}, fmt.Errorf("%w: malformed uid", ErrInvalidCursor)
 	}
 
-	return Cursor{Ms: ms, UID: id}, true
+	return Cursor{Ms: ms, UID: id}, nil
+}
+
+// CompareCursors orders cursors the way pull queries do: by (Ms, UID)
+// UIDs compare bytewise, matching Postgres uuid ordering.
+// Returns -1 if a < b, 0 if equal, +1 if a > b.
+func CompareCursors(a, b Cursor) int {
+	switch {
+	case a.Ms < b.Ms:
+		return -1
+	case a.Ms > b.Ms:
+		return 1
+	}
+	return bytes.Compare(a.UID[:], b.UID[:])
 }
 
 // RFC3339 converts Unix milliseconds to RFC3339 timestamp string
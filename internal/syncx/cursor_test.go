@@ -1,6 +1,7 @@
 package syncx
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/google/uuid"
@@ -18,7 +19,7 @@ func TestEncodeCursor(t *testing.T) {
 				Ms:  1730635200000,
 				UID: uuid.MustParse("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"),
 			},
-			expected: "MTczMDYzNTIwMDAwMHxjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmY",
+			expected: "ATE3MzA2MzUyMDAwMDB8YzFkOWI3ZGMtYTFiMi00YzNkLTllOGYtN2E2YjVjNGQzZTJm",
 		},
 		{
 			name: "zero timestamp",
@@ -26,7 +27,7 @@ func TestEncodeCursor(t *testing.T) {
 				Ms:  0,
 				UID: uuid.MustParse("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"),
 			},
-			expected: "MHxjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmY",
+			expected: "ATB8YzFkOWI3ZGMtYTFiMi00YzNkLTllOGYtN2E2YjVjNGQzZTJm",
 		},
 		{
 			name:     "zero value cursor",
@@ -55,16 +56,30 @@ func TestDecodeCursor(t *testing.T) {
 	}{
 		{
 			name:      "valid cursor",
+			encoded:   "ATE3MzA2MzUyMDAwMDB8YzFkOWI3ZGMtYTFiMi00YzNkLTllOGYtN2E2YjVjNGQzZTJm",
+			wantMs:    1730635200000,
+			wantUID:   uuid.MustParse("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"),
+			wantValid: true,
+		},
+		{
+			name:      "legacy unversioned cursor",
 			encoded:   "MTczMDYzNTIwMDAwMHxjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmY",
 			wantMs:    1730635200000,
 			wantUID:   uuid.MustParse("c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"),
 			wantValid: true,
 		},
 		{
-			name:      "empty string",
+			name:      "empty string is the zero cursor",
 			encoded:   "",
 			wantMs:    0,
 			wantUID:   uuid.Nil,
+			wantValid: true,
+		},
+		{
+			name:      "unknown version",
+			encoded:   "AjEyM3xjMWQ5YjdkYy1hMWIyLTRjM2QtOWU4Zi03YTZiNWM0ZDNlMmY", // version 2
+			wantMs:    0,
+			wantUID:   uuid.Nil,
 			wantValid: false,
 		},
 		{
@@ -99,9 +114,13 @@ func TestDecodeCursor(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, valid := DecodeCursor(tt.encoded)
+			got, err := DecodeCursor(tt.encoded)
+			valid := err == nil
 			if valid != tt.wantValid {
-				t.Errorf("DecodeCursor() valid = %v, want %v", valid, tt.wantValid)
+				t.Errorf("DecodeCursor() error = %v, want valid %v", err, tt.wantValid)
+			}
+			if !valid && !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor() error = %v, want ErrInvalidCursor", err)
 			}
 			if valid {
 				if got.Ms != tt.wantMs {
@@ -122,10 +141,10 @@ func TestCursorRoundTrip(t *testing.T) {
 	}
 
 	encoded := EncodeCursor(original)
-	decoded, valid := DecodeCursor(encoded)
+	decoded, err := DecodeCursor(encoded)
 
-	if !valid {
-		t.Fatal("DecodeCursor() failed for valid cursor")
+	if err != nil {
+		t.Fatalf("DecodeCursor() failed for valid cursor: %v", err)
 	}
 	if decoded.Ms != original.Ms {
 		t.Errorf("Round trip Ms = %v, want %v", decoded.Ms, original.Ms)
@@ -135,6 +154,30 @@ func TestCursorRoundTrip(t *testing.T) {
 	}
 }
 
+func TestCompareCursors(t *testing.T) {
+	low := uuid.MustParse("00000000-0000-4000-8000-000000000001")
+	high := uuid.MustParse("ffffffff-0000-4000-8000-000000000001")
+
+	tests := []struct {
+		name string
+		a, b Cursor
+		want int
+	}{
+		{"earlier ms", Cursor{Ms: 1, UID: high}, Cursor{Ms: 2, UID: low}, -1},
+		{"later ms", Cursor{Ms: 3, UID: low}, Cursor{Ms: 2, UID: high}, 1},
+		{"same ms, lower uid", Cursor{Ms: 2, UID: low}, Cursor{Ms: 2, UID: high}, -1},
+		{"equal", Cursor{Ms: 2, UID: high}, Cursor{Ms: 2, UID: high}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareCursors(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareCursors() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRFC3339(t *testing.T) {
 	tests := []struct {
 		name string
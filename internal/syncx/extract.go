@@ -61,7 +61,8 @@ func ParseUUID(s string) (uuid.UUID, bool) {
 }
 
 // ParseTimeToMs converts various time formats to Unix milliseconds
-// Accepts: RFC3339, numeric milliseconds (as string), empty (returns 0)
+// Accepts: RFC3339 with any offset, ISO 8601 without an offset (read in the default
+// location, see SetDefaultLocation), numeric milliseconds (as string), empty (returns 0)
 func ParseTimeToMs(s string) (int64, bool) {
 	if s == "" {
 		return 0, false
@@ -74,6 +75,11 @@ func ParseTimeToMs(s string) (int64, bool) {
 	if t, err := time.Parse(time.RFC3339, s); err == nil {
 		return t.UTC().UnixMilli(), true
 	}
+	for _, layout := range localLayouts {
+		if t, err := time.ParseInLocation(layout, s, defaultLocation); err == nil {
+			return t.UTC().UnixMilli(), true
+		}
+	}
 
 	// Try numeric milliseconds
 	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
@@ -88,6 +94,11 @@ func ParseTimeToMs(s string) (int64, bool) {
 func ExtractCommon(item map[string]any) (Extracted, error) {
 	var out Extracted
 
+	// 0. Reject malformed UUID/timestamp fields up front with a field-specific error
+	if err := ValidatePayloadFormats(item); err != nil {
+		return out, err
+	}
+
 	// 1. Extract UID (required)
 	uidStr, _ := GetString(item, "uid")
 	id, ok := ParseUUID(uidStr)
@@ -153,6 +164,20 @@ func ExtractCommon(item map[string]any) (Extracted, error) {
 	return out, nil
 }
 
+// ExtractDeleteAfter returns the scheduled deletion time from a payload's "deleteAfter" field
+// Returns nil when the field is absent, null or empty (no deletion scheduled).
+func ExtractDeleteAfter(item map[string]any) (*int64, error) {
+	s, present, err := stringField(item, "deleteAfter", "an RFC3339 timestamp string")
+	if err != nil || !present {
+		return nil, err
+	}
+	ms, ok := ParseTimeToMs(s)
+	if !ok {
+		return nil, &FieldError{Field: "deleteAfter", Reason: fmt.Sprintf("%q is not an RFC3339 timestamp", s)}
+	}
+	return &ms, nil
+}
+
 // ExtractComment adds comment-specific fields (parentType, parentUid)
 func ExtractComment(item map[string]any) (Extracted, error) {
 	ext, err := ExtractCommon(item)
@@ -239,7 +264,12 @@ func BuildServerMutation(payload map[string]any, timestampMs int64, setDeleted b
 // EnsureMonotonicTimestamp returns a timestamp that is strictly greater than the provided previous timestamp
 // Used to maintain strict ordering of updates in REST operations
 func EnsureMonotonicTimestamp(prevMs int64) int64 {
-	nowMs := NowMs()
+	return EnsureMonotonicTimestampAt(NowMs(), prevMs)
+}
+
+// EnsureMonotonicTimestampAt is EnsureMonotonicTimestamp with an explicit current time
+// (used by services with an injected Clock)
+func EnsureMonotonicTimestampAt(nowMs, prevMs int64) int64 {
 	if nowMs <= prevMs {
 		return prevMs + 1
 	}
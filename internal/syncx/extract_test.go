@@ -330,3 +330,33 @@ func TestParseTimeToMs(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractDeleteAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    map[string]any
+		want    *int64
+		wantErr bool
+	}{
+		{name: "absent", item: map[string]any{}},
+		{name: "null", item: map[string]any{"deleteAfter": nil}},
+		{name: "empty", item: map[string]any{"deleteAfter": ""}},
+		{name: "rfc3339", item: map[string]any{"deleteAfter": "2025-11-10T10:00:00Z"}, want: ptrInt64(1762768800000)},
+		{name: "invalid", item: map[string]any{"deleteAfter": "next week"}, wantErr: true},
+		{name: "wrong type", item: map[string]any{"deleteAfter": true}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractDeleteAfter(tt.item)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExtractDeleteAfter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ExtractDeleteAfter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptrInt64(v int64) *int64 { return &v }
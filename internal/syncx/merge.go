@@ -0,0 +1,16 @@
+package syncx
+
+// MergePayload applies an incoming payload on top of a stored one with merge-patch
+// field semantics: keys absent from incoming keep their stored value, an explicit
+// null clears the field (kept as null so every client sees the clear), and any
+// other value replaces it. Merging is shallow, like the REST PATCH handlers.
+func MergePayload(stored, incoming map[string]any) map[string]any {
+	merged := make(map[string]any, len(stored)+len(incoming))
+	for k, v := range stored {
+		merged[k] = v
+	}
+	for k, v := range incoming {
+		merged[k] = v
+	}
+	return merged
+}
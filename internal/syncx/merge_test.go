@@ -0,0 +1,23 @@
+package syncx
+
+import "testing"
+
+func TestMergePayload(t *testing.T) {
+	stored := map[string]any{"uid": "u1", "title": "Old", "color": "red", "pinned": true}
+	incoming := map[string]any{"uid": "u1", "title": "New", "color": nil}
+
+	merged := MergePayload(stored, incoming)
+
+	if merged["title"] != "New" {
+		t.Errorf("title = %v, want New (present fields replace)", merged["title"])
+	}
+	if merged["pinned"] != true {
+		t.Errorf("pinned = %v, want true (absent fields are kept)", merged["pinned"])
+	}
+	if color, ok := merged["color"]; !ok || color != nil {
+		t.Errorf("color = %v (present=%v), want explicit null (null clears)", color, ok)
+	}
+	if stored["title"] != "Old" || stored["color"] != "red" {
+		t.Errorf("stored payload was mutated: %v", stored)
+	}
+}
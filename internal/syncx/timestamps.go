@@ -0,0 +1,42 @@
+package syncx
+
+import "time"
+
+// defaultLocation is the zone assumed for timestamps sent without a UTC offset
+var defaultLocation = time.UTC
+
+// SetDefaultLocation sets the zone offset-less timestamps (e.g. "2025-11-03T10:00:00")
+// are read in (default UTC). Parsing reads it without locking, so call it before serving.
+func SetDefaultLocation(loc *time.Location) {
+	defaultLocation = loc
+}
+
+// localLayouts are ISO 8601 forms without an offset, read in defaultLocation
+// (fractional seconds are accepted after the seconds field)
+var localLayouts = []string{"2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+// normalizedTimestampFields are the payload fields NormalizeTimestamps rewrites
+var normalizedTimestampFields = []string{"updatedTs", "updatedAt", "updateTime", "deleteAfter"}
+
+// NormalizeTimestamps rewrites a payload's timestamp fields (and sync.deletedAt) to UTC
+// RFC3339 at millisecond precision, the resolution LWW compares at. The same instant sent
+// with different offsets is then stored and served identically. Values that don't parse
+// are left alone (ValidatePayloadFormats reports the ones that must be valid).
+func NormalizeTimestamps(payload map[string]any) {
+	for _, field := range normalizedTimestampFields {
+		normalizeTimestampField(payload, field)
+	}
+	if sync, ok := payload["sync"].(map[string]any); ok {
+		normalizeTimestampField(sync, "deletedAt")
+	}
+}
+
+func normalizeTimestampField(m map[string]any, field string) {
+	s, ok := m[field].(string)
+	if !ok {
+		return
+	}
+	if ms, ok := ParseTimeToMs(s); ok {
+		m[field] = RFC3339(ms)
+	}
+}
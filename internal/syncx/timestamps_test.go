@@ -0,0 +1,89 @@
+package syncx
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseTimeToMs_EquivalentOffsets checks that one instant written with different
+// offsets parses to the same epoch-ms, so LWW treats the writes as simultaneous
+func TestParseTimeToMs_EquivalentOffsets(t *testing.T) {
+	inputs := []string{
+		"2025-11-03T10:00:00.250Z",
+		"2025-11-03T10:00:00.250+00:00",
+		"2025-11-03T15:30:00.250+05:30",
+		"2025-11-03T05:00:00.250-05:00",
+		"2025-11-03T10:00:00.250",          // no offset: default location (UTC)
+		"2025-11-03T10:00:00.250999+00:00", // sub-millisecond digits are truncated
+		"1762164000250",
+	}
+
+	want, ok := ParseTimeToMs(inputs[0])
+	if !ok {
+		t.Fatalf("Failed to parse %q", inputs[0])
+	}
+	for _, in := range inputs[1:] {
+		got, ok := ParseTimeToMs(in)
+		if !ok {
+			t.Errorf("Failed to parse %q", in)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseTimeToMs(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestParseTimeToMs_DefaultLocation(t *testing.T) {
+	defer SetDefaultLocation(time.UTC)
+	SetDefaultLocation(time.FixedZone("UTC+2", 2*60*60))
+
+	got, ok := ParseTimeToMs("2025-11-03T12:00:00")
+	want, _ := ParseTimeToMs("2025-11-03T10:00:00Z")
+	if !ok || got != want {
+		t.Errorf("Offset-less time in UTC+2 = %d (ok=%v), want %d", got, ok, want)
+	}
+
+	// Explicit offsets ignore the default location
+	got, _ = ParseTimeToMs("2025-11-03T10:00:00Z")
+	if got != want {
+		t.Errorf("Explicit Z should not be shifted, got %d want %d", got, want)
+	}
+}
+
+func TestNormalizeTimestamps(t *testing.T) {
+	payload := map[string]any{
+		"updatedTs":   "2025-11-03T15:30:00+05:30",
+		"updateTime":  "2025-11-03T05:00:00.5-05:00",
+		"deleteAfter": "1762164000000",
+		"updatedAt":   "yesterday", // unparseable: left as sent
+		"title":       "2025-11-03T15:30:00+05:30",
+		"sync":        map[string]any{"deletedAt": "2025-11-03T11:00:00+01:00"},
+	}
+	NormalizeTimestamps(payload)
+
+	checks := map[string]any{
+		"updatedTs":   "2025-11-03T10:00:00Z",
+		"updateTime":  "2025-11-03T10:00:00.5Z",
+		"deleteAfter": "2025-11-03T10:00:00Z",
+		"updatedAt":   "yesterday",
+		"title":       "2025-11-03T15:30:00+05:30", // only timestamp fields are touched
+	}
+	for field, want := range checks {
+		if payload[field] != want {
+			t.Errorf("%s = %v, want %v", field, payload[field], want)
+		}
+	}
+	if got := payload["sync"].(map[string]any)["deletedAt"]; got != "2025-11-03T10:00:00Z" {
+		t.Errorf("sync.deletedAt = %v, want 2025-11-03T10:00:00Z", got)
+	}
+
+	// Equivalent instants normalize to identical payload values
+	a := map[string]any{"updatedTs": "2025-11-03T10:00:00Z"}
+	b := map[string]any{"updatedTs": "2025-11-03T12:00:00+02:00"}
+	NormalizeTimestamps(a)
+	NormalizeTimestamps(b)
+	if a["updatedTs"] != b["updatedTs"] {
+		t.Errorf("Equivalent instants normalized differently: %v vs %v", a["updatedTs"], b["updatedTs"])
+	}
+}
@@ -0,0 +1,66 @@
+package syncx
+
+import "fmt"
+
+// uuidFields are payload fields that must hold a UUID string when present
+var uuidFields = []string{"uid", "parentUid", "chatUid"}
+
+// timestampFields are payload fields that must hold an RFC3339 (or epoch-ms) string when present
+var timestampFields = []string{"updatedTs", "deleteAfter"}
+
+// FieldError reports a payload field with a malformed value
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Reason)
+}
+
+// ValidatePayloadFormats checks the format of identity and timestamp fields before extraction
+// so malformed values are rejected with an error naming the field instead of being
+// silently dropped or replaced with server time. Absent, null, or empty fields are left
+// to the entity-specific extractors, which decide whether they are required.
+func ValidatePayloadFormats(item map[string]any) error {
+	for _, field := range uuidFields {
+		s, present, err := stringField(item, field, "a UUID string")
+		if err != nil {
+			return err
+		}
+		if !present {
+			continue
+		}
+		if _, ok := ParseUUID(s); !ok {
+			return &FieldError{Field: field, Reason: fmt.Sprintf("%q is not a UUID", s)}
+		}
+	}
+
+	for _, field := range timestampFields {
+		s, present, err := stringField(item, field, "an RFC3339 timestamp string")
+		if err != nil {
+			return err
+		}
+		if !present {
+			continue
+		}
+		if _, ok := ParseTimeToMs(s); !ok {
+			return &FieldError{Field: field, Reason: fmt.Sprintf("%q is not an RFC3339 timestamp", s)}
+		}
+	}
+
+	return nil
+}
+
+// stringField returns a non-empty string field, or a FieldError if the value has the wrong type
+func stringField(item map[string]any, field, want string) (string, bool, error) {
+	v, ok := item[field]
+	if !ok || v == nil {
+		return "", false, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", false, &FieldError{Field: field, Reason: fmt.Sprintf("must be %s, got %T", want, v)}
+	}
+	return s, s != "", nil
+}
@@ -0,0 +1,93 @@
+package syncx
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidatePayloadFormats(t *testing.T) {
+	const validUID = "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f"
+
+	tests := []struct {
+		name      string
+		item      map[string]any
+		wantField string // empty = valid
+	}{
+		{
+			name: "valid payload",
+			item: map[string]any{
+				"uid":       validUID,
+				"parentUid": "a1b2c3d4-e5f6-7890-abcd-ef1234567890",
+				"updatedTs": "2025-11-03T10:00:00Z",
+			},
+		},
+		{
+			name: "numeric ms updatedTs",
+			item: map[string]any{"uid": validUID, "updatedTs": "1762164000000"},
+		},
+		{
+			name: "null and empty optional fields",
+			item: map[string]any{"uid": validUID, "parentUid": nil, "chatUid": ""},
+		},
+		{
+			name:      "invalid uid",
+			item:      map[string]any{"uid": "note-1"},
+			wantField: "uid",
+		},
+		{
+			name:      "non-string uid",
+			item:      map[string]any{"uid": float64(42)},
+			wantField: "uid",
+		},
+		{
+			name:      "invalid parentUid",
+			item:      map[string]any{"uid": validUID, "parentUid": "not-a-uuid"},
+			wantField: "parentUid",
+		},
+		{
+			name:      "invalid chatUid",
+			item:      map[string]any{"uid": validUID, "chatUid": "chat-1"},
+			wantField: "chatUid",
+		},
+		{
+			name:      "invalid updatedTs",
+			item:      map[string]any{"uid": validUID, "updatedTs": "yesterday"},
+			wantField: "updatedTs",
+		},
+		{
+			name:      "non-string updatedTs",
+			item:      map[string]any{"uid": validUID, "updatedTs": float64(1762164000000)},
+			wantField: "updatedTs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePayloadFormats(tt.item)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("ValidatePayloadFormats() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var fe *FieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("ValidatePayloadFormats() error = %v, want *FieldError", err)
+			}
+			if fe.Field != tt.wantField {
+				t.Errorf("Field = %q, want %q (error: %v)", fe.Field, tt.wantField, err)
+			}
+		})
+	}
+}
+
+func TestExtractCommon_RejectsMalformedUpdatedTs(t *testing.T) {
+	_, err := ExtractCommon(map[string]any{
+		"uid":       "c1d9b7dc-a1b2-4c3d-9e8f-7a6b5c4d3e2f",
+		"updatedTs": "not-a-time",
+	})
+	if err == nil || err.Error() != `invalid updatedTs: "not-a-time" is not an RFC3339 timestamp` {
+		t.Errorf("ExtractCommon() error = %v", err)
+	}
+}
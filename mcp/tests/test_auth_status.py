@@ -0,0 +1,133 @@
+"""
+Unit tests for the auth_status MCP tool.
+
+Tests the authenticated, expired-token and missing-token responses with the
+FastMCP access token and auth caches stubbed.
+"""
+
+import time
+from types import SimpleNamespace
+
+import pytest
+
+from toolbridge_mcp.tools import auth_status as auth_status_module
+from toolbridge_mcp.utils import requests as req
+
+# The @mcp.tool() decorator wraps the function in a FunctionTool;
+# .fn is the original coroutine function.
+auth_status = auth_status_module.auth_status.fn
+
+
+def _token(sub="user_a", exp=None, scopes=("openid", "email")):
+    """Build an access token like the one FastMCP hands to tools."""
+    return SimpleNamespace(
+        claims={"sub": sub, "email": f"{sub}@example.com"},
+        client_id="client_123",
+        scopes=list(scopes),
+        expires_at=exp,
+    )
+
+
+@pytest.fixture(autouse=True)
+def clean_caches():
+    """Ensure each test starts and ends with empty auth caches."""
+    req.clear_auth_caches()
+    yield
+    req.clear_auth_caches()
+
+
+@pytest.fixture
+def access_token(monkeypatch):
+    """Return a setter for the token get_access_token() yields."""
+
+    def set_token(token):
+        monkeypatch.setattr(auth_status_module, "get_access_token", lambda: token)
+
+    return set_token
+
+
+class TestAuthStatus:
+    """Tests for auth_status tool."""
+
+    @pytest.mark.asyncio
+    async def test_authenticated(self, access_token):
+        """Test that a valid token reports subject, expiry, scopes and cache state."""
+        exp = int(time.time()) + 3600
+        access_token(_token(exp=exp))
+        req._tenant_cache["user_a"] = "tenant_1"
+        req._jwt_cache["user_a"] = "backend-jwt"
+
+        status = await auth_status()
+
+        assert status.authenticated is True
+        assert status.subject == "user_a"
+        assert status.email == "user_a@example.com"
+        assert status.client_id == "client_123"
+        assert status.scopes == ["openid", "email"]
+        assert status.expires_at is not None
+        assert 0 < status.expires_in_seconds <= 3600
+        assert status.tenant_id == "tenant_1"
+        assert status.backend_jwt_cached is True
+        assert status.hint is None
+
+    @pytest.mark.asyncio
+    async def test_authenticated_without_cached_tenant(self, access_token):
+        """Test that a fresh login reports nothing cached yet."""
+        access_token(_token(exp=int(time.time()) + 3600))
+
+        status = await auth_status()
+
+        assert status.authenticated is True
+        assert status.tenant_id is None
+        assert status.backend_jwt_cached is False
+
+    @pytest.mark.asyncio
+    async def test_expired_token(self, access_token):
+        """Test that an expired token is reported as unauthenticated with a re-login hint."""
+        access_token(_token(exp=int(time.time()) - 60))
+
+        status = await auth_status()
+
+        assert status.authenticated is False
+        assert status.subject == "user_a"
+        assert status.expires_in_seconds <= -60
+        assert "expired" in status.hint
+
+    @pytest.mark.asyncio
+    async def test_expiry_falls_back_to_exp_claim(self, access_token):
+        """Test that the exp claim is used when the token has no expires_at."""
+        token = _token()
+        token.claims["exp"] = int(time.time()) - 60
+        access_token(token)
+
+        status = await auth_status()
+
+        assert status.authenticated is False
+        assert "expired" in status.hint
+
+    @pytest.mark.asyncio
+    async def test_missing_token(self, access_token):
+        """Test that no access token reports unauthenticated with a login hint."""
+        access_token(None)
+
+        status = await auth_status()
+
+        assert status.authenticated is False
+        assert status.subject is None
+        assert status.scopes == []
+        assert status.backend_jwt_cached is False
+        assert "No access token" in status.hint
+
+    @pytest.mark.asyncio
+    async def test_missing_request_context(self, monkeypatch):
+        """Test that get_access_token failing outside a request counts as no token."""
+
+        def no_context():
+            raise RuntimeError("no active HTTP request")
+
+        monkeypatch.setattr(auth_status_module, "get_access_token", no_context)
+
+        status = await auth_status()
+
+        assert status.authenticated is False
+        assert "No access token" in status.hint
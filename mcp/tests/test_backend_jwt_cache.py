@@ -0,0 +1,104 @@
+"""
+Unit tests for per-user backend JWT caching and proactive refresh.
+
+Tests get_fresh_backend_jwt and clear_auth_caches with token exchange mocked.
+"""
+
+import asyncio
+import time
+
+import pytest
+from jose import jwt
+
+from toolbridge_mcp.utils import requests as req
+
+
+def _make_jwt(sub: str, exp: int) -> str:
+    """Build a backend JWT with the given subject and expiry."""
+    return jwt.encode({"sub": sub, "exp": exp}, "test-secret", algorithm="HS256")
+
+
+@pytest.fixture(autouse=True)
+def clean_caches():
+    """Ensure each test starts and ends with empty auth caches."""
+    req.clear_auth_caches()
+    req._jwt_refresh_locks.clear()
+    yield
+    req.clear_auth_caches()
+    req._jwt_refresh_locks.clear()
+
+
+@pytest.fixture
+def exchange_calls(monkeypatch):
+    """Mock token exchange; each call returns a new JWT valid for one hour."""
+    calls = []
+
+    async def fake_exchange(client):
+        calls.append(client)
+        await asyncio.sleep(0.01)
+        return _make_jwt(f"user-{len(calls)}", int(time.time()) + 3600)
+
+    monkeypatch.setattr(req, "exchange_for_backend_jwt", fake_exchange)
+    return calls
+
+
+class TestGetFreshBackendJwt:
+    """Tests for get_fresh_backend_jwt function."""
+
+    @pytest.mark.asyncio
+    async def test_first_call_exchanges_and_caches(self, exchange_calls):
+        """Test that a cache miss exchanges once and later calls reuse the token."""
+        first = await req.get_fresh_backend_jwt(None, "user_a")
+        second = await req.get_fresh_backend_jwt(None, "user_a")
+
+        assert first == second
+        assert len(exchange_calls) == 1
+        assert req.get_cached_backend_jwt("user_a") == first
+
+    @pytest.mark.asyncio
+    async def test_refreshes_before_expiry(self, exchange_calls):
+        """Test that a token inside the refresh skew window is re-exchanged."""
+        req._jwt_cache["user_a"] = _make_jwt("user_a", int(time.time()) + 5)
+        req._jwt_expiry["user_a"] = int(time.time()) + 5
+
+        token = await req.get_fresh_backend_jwt(None, "user_a")
+
+        assert len(exchange_calls) == 1
+        assert req._jwt_expiry["user_a"] > int(time.time()) + 60
+        assert req.get_cached_backend_jwt("user_a") == token
+
+    @pytest.mark.asyncio
+    async def test_concurrent_refresh_is_single_flight(self, exchange_calls):
+        """Test that concurrent callers for one user share a single exchange."""
+        tokens = await asyncio.gather(
+            *(req.get_fresh_backend_jwt(None, "user_a") for _ in range(5))
+        )
+
+        assert len(exchange_calls) == 1
+        assert len(set(tokens)) == 1
+
+
+class TestClearAuthCaches:
+    """Tests for clear_auth_caches function."""
+
+    def test_clear_single_user(self):
+        """Test that clearing one user leaves other users' state intact."""
+        req._jwt_cache.update({"user_a": "a", "user_b": "b"})
+        req._tenant_cache.update({"user_a": "t1", "user_b": "t2"})
+
+        req.clear_auth_caches("user_a")
+
+        assert req.get_cached_backend_jwt("user_a") is None
+        assert req.get_cached_tenant_id("user_a") is None
+        assert req.get_cached_backend_jwt("user_b") == "b"
+        assert req.get_cached_tenant_id("user_b") == "t2"
+
+    def test_clear_all_users(self):
+        """Test that clearing without a user drops all cached state."""
+        req._jwt_cache.update({"user_a": "a", "user_b": "b"})
+        req._jwt_expiry.update({"user_a": 1, "user_b": 2})
+
+        req.clear_auth_caches()
+
+        assert req._jwt_cache == {}
+        assert req._jwt_expiry == {}
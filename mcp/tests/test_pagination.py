@@ -0,0 +1,39 @@
+"""
+Unit tests for MCP list tool pagination helpers.
+"""
+
+from toolbridge_mcp.utils.pagination import (
+    DEFAULT_LIST_LIMIT,
+    MAX_LIST_LIMIT,
+    build_list_params,
+)
+
+
+class TestBuildListParams:
+    """Tests for build_list_params function."""
+
+    def test_defaults_use_model_friendly_limit(self):
+        """Test that defaults only send the default limit."""
+        assert build_list_params() == {"limit": DEFAULT_LIST_LIMIT}
+
+    def test_cursor_is_passed_through(self):
+        """Test that a cursor from a previous page is forwarded."""
+        params = build_list_params(limit=10, cursor="abc")
+        assert params == {"limit": 10, "cursor": "abc"}
+
+    def test_limit_is_clamped(self):
+        """Test that limit is clamped to 1..MAX_LIST_LIMIT."""
+        assert build_list_params(limit=5000)["limit"] == MAX_LIST_LIMIT
+        assert build_list_params(limit=0)["limit"] == 1
+
+    def test_include_deleted(self):
+        """Test that include_deleted maps to includeDeleted."""
+        params = build_list_params(include_deleted=True)
+        assert params["includeDeleted"] == "true"
+        assert "deletedOnly" not in params
+
+    def test_deleted_only_takes_precedence(self):
+        """Test that deleted_only maps to deletedOnly and supersedes include_deleted."""
+        params = build_list_params(include_deleted=True, deleted_only=True)
+        assert params["deletedOnly"] == "true"
+        assert "includeDeleted" not in params
@@ -0,0 +1,133 @@
+"""
+Unit tests for Go API retry-with-backoff.
+
+Tests parse_retry_after, backoff_delay, and send_with_retry's policy for
+idempotent vs non-idempotent requests.
+"""
+
+import httpx
+import pytest
+
+from toolbridge_mcp.utils import retry
+from toolbridge_mcp.utils.retry import (
+    UpstreamUnavailableError,
+    backoff_delay,
+    parse_retry_after,
+    send_with_retry,
+)
+
+
+@pytest.fixture(autouse=True)
+def no_sleep(monkeypatch):
+    """Record backoff delays instead of sleeping."""
+    delays = []
+
+    async def fake_sleep(delay):
+        delays.append(delay)
+
+    monkeypatch.setattr(retry.asyncio, "sleep", fake_sleep)
+    return delays
+
+
+def _sender(*outcomes):
+    """Build a send() that returns responses / raises errors in order."""
+    calls = []
+    request = httpx.Request("GET", "http://test/v1/notes")
+
+    async def send():
+        outcome = outcomes[len(calls)]
+        calls.append(outcome)
+        if isinstance(outcome, Exception):
+            raise outcome
+        status, headers = outcome if isinstance(outcome, tuple) else (outcome, {})
+        return httpx.Response(status, headers=headers, request=request)
+
+    return send, calls
+
+
+class TestParseRetryAfter:
+    """Tests for parse_retry_after function."""
+
+    def test_seconds(self):
+        assert parse_retry_after("5") == 5.0
+
+    def test_missing_or_invalid(self):
+        assert parse_retry_after(None) is None
+        assert parse_retry_after("soon") is None
+
+    def test_http_date_in_past_is_zero(self):
+        assert parse_retry_after("Wed, 21 Oct 2015 07:28:00 GMT") == 0.0
+
+
+class TestBackoffDelay:
+    """Tests for backoff_delay function."""
+
+    def test_retry_after_takes_precedence(self):
+        assert backoff_delay(0, retry_after=2.0) == 2.0
+
+    def test_delay_is_capped(self):
+        for attempt in range(10):
+            assert 0 <= backoff_delay(attempt) <= retry.settings.http_retry_max_delay_seconds
+        assert backoff_delay(0, retry_after=3600) == retry.settings.http_retry_max_delay_seconds
+
+
+class TestSendWithRetry:
+    """Tests for send_with_retry function."""
+
+    @pytest.mark.asyncio
+    async def test_get_retries_5xx_then_succeeds(self):
+        send, calls = _sender(503, 502, 200)
+        response = await send_with_retry(send, "GET", "/v1/notes")
+        assert response.status_code == 200
+        assert len(calls) == 3
+
+    @pytest.mark.asyncio
+    async def test_get_retries_500(self):
+        send, calls = _sender(500, 200)
+        response = await send_with_retry(send, "GET", "/v1/notes")
+        assert response.status_code == 200
+        assert len(calls) == 2
+
+    @pytest.mark.asyncio
+    async def test_honors_retry_after_on_429(self, no_sleep):
+        send, calls = _sender((429, {"Retry-After": "2"}), 200)
+        response = await send_with_retry(send, "POST", "/v1/notes")
+        assert response.status_code == 200
+        assert no_sleep == [2.0]
+
+    @pytest.mark.asyncio
+    async def test_write_does_not_retry_ambiguous_5xx(self):
+        for status in (500, 503):
+            send, calls = _sender(status)
+            response = await send_with_retry(send, "POST", "/v1/notes")
+            assert response.status_code == status
+            assert len(calls) == 1
+
+    @pytest.mark.asyncio
+    async def test_write_retries_connect_error(self):
+        send, calls = _sender(httpx.ConnectError("refused"), 201)
+        response = await send_with_retry(send, "POST", "/v1/notes")
+        assert response.status_code == 201
+        assert len(calls) == 2
+
+    @pytest.mark.asyncio
+    async def test_write_does_not_retry_read_timeout(self):
+        send, calls = _sender(httpx.ReadTimeout("timed out"))
+        with pytest.raises(httpx.ReadTimeout):
+            await send_with_retry(send, "PATCH", "/v1/notes/abc")
+        assert len(calls) == 1
+
+    @pytest.mark.asyncio
+    async def test_client_errors_are_not_retried(self):
+        send, calls = _sender(404)
+        response = await send_with_retry(send, "GET", "/v1/notes/abc")
+        assert response.status_code == 404
+        assert len(calls) == 1
+
+    @pytest.mark.asyncio
+    async def test_exhausted_retries_raise_clear_error(self):
+        attempts = retry.settings.http_max_retries + 1
+        send, calls = _sender(*([503] * attempts))
+        with pytest.raises(UpstreamUnavailableError, match="503"):
+            await send_with_retry(send, "GET", "/v1/notes")
+        assert len(calls) == attempts
@@ -0,0 +1,68 @@
+"""
+Unit tests for per-deployment tool allow/deny policy.
+"""
+
+import pytest
+
+from toolbridge_mcp.tool_policy import ToolPolicy, is_read_only_tool, parse_tool_list
+
+
+class TestParseToolList:
+    """Tests for parse_tool_list function."""
+
+    def test_unset_returns_none(self):
+        assert parse_tool_list(None) is None
+        assert parse_tool_list("") is None
+        assert parse_tool_list(" , ") is None
+
+    def test_splits_and_trims(self):
+        assert parse_tool_list("list_notes, get_note ,search") == frozenset(
+            {"list_notes", "get_note", "search"}
+        )
+
+
+class TestIsReadOnlyTool:
+    """Tests for is_read_only_tool function."""
+
+    @pytest.mark.parametrize(
+        "name", ["list_notes", "get_task", "show_note_ui", "search", "auth_status"]
+    )
+    def test_read_tools(self, name):
+        assert is_read_only_tool(name)
+
+    @pytest.mark.parametrize(
+        "name", ["create_note", "delete_task", "patch_chat", "apply_note_edit", "archive_task_ui"]
+    )
+    def test_write_tools(self, name):
+        assert not is_read_only_tool(name)
+
+
+class TestToolPolicy:
+    """Tests for ToolPolicy.is_allowed."""
+
+    def test_default_allows_everything(self):
+        policy = ToolPolicy()
+        assert not policy.restricted
+        assert policy.is_allowed("delete_note")
+
+    def test_allowlist_only_exposes_listed_tools(self):
+        policy = ToolPolicy(allowlist=frozenset({"list_notes", "create_note"}))
+        assert policy.is_allowed("list_notes")
+        assert policy.is_allowed("create_note")
+        assert not policy.is_allowed("get_note")
+
+    def test_denylist_wins_over_allowlist(self):
+        policy = ToolPolicy(
+            allowlist=frozenset({"list_notes", "delete_note"}),
+            denylist=frozenset({"delete_note"}),
+        )
+        assert policy.is_allowed("list_notes")
+        assert not policy.is_allowed("delete_note")
+
+    def test_read_only_blocks_writes(self):
+        policy = ToolPolicy(read_only=True)
+        assert policy.restricted
+        assert policy.is_allowed("list_tasks")
+        assert policy.is_allowed("search")
+        assert not policy.is_allowed("create_task")
+        assert not policy.is_allowed("delete_note_ui")
@@ -221,6 +221,29 @@ def _unsafe_extract_user_id_for_logging(backend_jwt: str) -> str:
         return "unknown"
 
 
+def _unsafe_extract_expiry_for_caching(backend_jwt: str) -> Optional[int]:
+    """
+    WARNING: Cache-bookkeeping helper - DO NOT use for authorization decisions.
+
+    Extracts the exp claim (unix seconds) from a backend JWT WITHOUT VALIDATION,
+    so callers can refresh a cached token before the backend starts rejecting it.
+    The backend still validates the token on every request.
+
+    Args:
+        backend_jwt: Backend JWT token string (trusted source only)
+
+    Returns:
+        Expiry as unix seconds, or None if the token has no readable exp claim
+    """
+    try:
+        claims = jwt.get_unverified_claims(backend_jwt)
+        exp = claims.get("exp")
+        return int(exp) if exp is not None else None
+    except Exception as e:
+        logger.warning(f"Failed to read backend JWT expiry: {e}")
+        return None
+
+
 # Backwards compatibility alias - prefer using the explicit unsafe name
 # TODO: Remove this alias after updating all call sites
 def extract_user_id_from_backend_jwt(backend_jwt: str) -> str:
@@ -38,12 +38,32 @@ class Settings(BaseSettings):
     # Private key for signing backend JWTs if not using backend /token-exchange endpoint
     jwt_signing_key: str | None = None
 
+    # Backend JWT caching
+    # Cached backend JWTs are proactively re-exchanged this many seconds before
+    # they expire, so long-running sessions never send an expired token
+    backend_jwt_refresh_skew_seconds: int = 60
+
+    # Go API retry policy (see utils/retry.py)
+    # Rate-limited (429) and transient upstream failures are retried with
+    # exponential backoff + jitter; writes only retry when provably not applied
+    http_max_retries: int = 3
+    http_retry_base_delay_seconds: float = 0.5
+    http_retry_max_delay_seconds: float = 8.0
+
     # UI Configuration
     # HTML MIME type for UI resources:
     # - "text/html" (default): Works with all MCP-UI hosts (ToolBridge, Nanobot, Goose)
     # - "text/html+skybridge": Required for ChatGPT Apps SDK
     ui_html_mime_type: str = "text/html"
 
+    # Tool exposure policy (see tool_policy.py)
+    # Comma-separated tool names. Denylist always wins; if an allowlist is set,
+    # only those tools are exposed; tools_read_only exposes only read tools.
+    # Defaults expose every tool.
+    tool_allowlist: str | None = None
+    tool_denylist: str | None = None
+    tools_read_only: bool = False
+
     # Logging
     log_level: str = "INFO"
 
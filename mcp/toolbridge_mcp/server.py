@@ -72,12 +72,25 @@ from toolbridge_mcp.tools import tasks  # noqa: F401, E402
 from toolbridge_mcp.tools import comments  # noqa: F401, E402
 from toolbridge_mcp.tools import chats  # noqa: F401, E402
 from toolbridge_mcp.tools import chat_messages  # noqa: F401, E402
+from toolbridge_mcp.tools import search  # noqa: F401, E402
+from toolbridge_mcp.tools import auth_status  # noqa: F401, E402
 
 # Import MCP-UI enabled tools (return both text and UIResource)
 from toolbridge_mcp.tools import notes_ui  # noqa: F401, E402
 from toolbridge_mcp.tools import tasks_ui  # noqa: F401, E402
 
-logger.info("✓ ToolBridge MCP server initialized with 47 tools (40 data + 7 UI)")
+logger.info("✓ ToolBridge MCP server initialized with 49 tools (42 data + 7 UI)")
+
+# Apply per-deployment tool allow/deny policy (filters tools/list, enforced on tools/call)
+from toolbridge_mcp.tool_policy import ToolPolicy, ToolPolicyMiddleware  # noqa: E402
+
+tool_policy = ToolPolicy.from_settings()
+if tool_policy.restricted:
+    mcp.add_middleware(ToolPolicyMiddleware(tool_policy))
+    logger.info(
+        f"✓ Tool policy active: allowlist={sorted(tool_policy.allowlist) if tool_policy.allowlist else None}, "
+        f"denylist={sorted(tool_policy.denylist)}, read_only={tool_policy.read_only}"
+    )
 
 # Note: health_check tool is provided by FastMCP by default
 # No need to register a custom one to avoid "Tool already exists" warnings
@@ -0,0 +1,100 @@
+"""
+Per-deployment tool allow/deny policy.
+
+Some deployments only want to expose read tools to the model. The policy is
+configured via settings (see config.py) and enforced by ToolPolicyMiddleware:
+- tools/list: disabled tools are filtered out of the listing
+- tools/call: disabled tools are rejected even if the model calls them by name
+
+Resolution order:
+1. tool_denylist: listed tools are always disabled
+2. tool_allowlist: if set, only listed tools are enabled
+3. tools_read_only: if true, only read tools (see is_read_only_tool) are enabled
+"""
+
+from typing import FrozenSet, Optional
+
+from fastmcp.exceptions import ToolError
+from fastmcp.server.middleware import Middleware, MiddlewareContext
+from loguru import logger
+
+from toolbridge_mcp.config import settings
+
+
+# Tools that never modify data, by name prefix or exact name
+READ_ONLY_TOOL_PREFIXES = ("list_", "get_", "show_")
+READ_ONLY_TOOLS = frozenset({"search", "auth_status", "health_check"})
+
+
+def is_read_only_tool(name: str) -> bool:
+    """Return True if the tool only reads data."""
+    return name in READ_ONLY_TOOLS or name.startswith(READ_ONLY_TOOL_PREFIXES)
+
+
+def parse_tool_list(value: Optional[str]) -> Optional[FrozenSet[str]]:
+    """
+    Parse a comma-separated tool list from config.
+
+    Returns:
+        Set of tool names, or None if the value is unset/empty
+    """
+    if not value:
+        return None
+    names = frozenset(n.strip() for n in value.split(",") if n.strip())
+    return names or None
+
+
+class ToolPolicy:
+    """Decides which tools are exposed to the model."""
+
+    def __init__(
+        self,
+        allowlist: Optional[FrozenSet[str]] = None,
+        denylist: Optional[FrozenSet[str]] = None,
+        read_only: bool = False,
+    ):
+        self.allowlist = allowlist
+        self.denylist = denylist or frozenset()
+        self.read_only = read_only
+
+    @classmethod
+    def from_settings(cls) -> "ToolPolicy":
+        """Build the policy from TOOLBRIDGE_TOOL_* settings."""
+        return cls(
+            allowlist=parse_tool_list(settings.tool_allowlist),
+            denylist=parse_tool_list(settings.tool_denylist),
+            read_only=settings.tools_read_only,
+        )
+
+    @property
+    def restricted(self) -> bool:
+        """Return True if the policy disables any tools."""
+        return self.allowlist is not None or bool(self.denylist) or self.read_only
+
+    def is_allowed(self, name: str) -> bool:
+        """Return True if the named tool is enabled."""
+        if name in self.denylist:
+            return False
+        if self.allowlist is not None:
+            return name in self.allowlist
+        if self.read_only:
+            return is_read_only_tool(name)
+        return True
+
+
+class ToolPolicyMiddleware(Middleware):
+    """Filters tools/list and rejects tools/call for tools disabled by policy."""
+
+    def __init__(self, policy: ToolPolicy):
+        self.policy = policy
+
+    async def on_list_tools(self, context: MiddlewareContext, call_next):
+        tools = await call_next(context)
+        return [tool for tool in tools if self.policy.is_allowed(tool.name)]
+
+    async def on_call_tool(self, context: MiddlewareContext, call_next):
+        name = context.message.name
+        if not self.policy.is_allowed(name):
+            logger.warning(f"Rejected call to disabled tool: {name}")
+            raise ToolError(f"Tool '{name}' is not enabled on this server")
+        return await call_next(context)
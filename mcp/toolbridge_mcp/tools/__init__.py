@@ -7,4 +7,6 @@ Each module exposes tools for a specific entity type:
 - comments: Comment management
 - chats: Chat management
 - chat_messages: Chat message management
+- search: Keyword search across all entity types
+- auth_status: Current authentication state (subject, expiry, scopes)
 """
@@ -0,0 +1,98 @@
+"""
+MCP tool for inspecting the current authentication state.
+
+Reports who the MCP server thinks the caller is, when their OAuth token
+expires, and which scopes it carries, plus whether a tenant and backend JWT
+are already cached. Useful for diagnosing "why am I being asked to log in
+again" without digging through server logs.
+
+Note: this server authenticates via WorkOS AuthKit OAuth 2.1 + PKCE, which
+is driven by the MCP client. Re-login is triggered by the client when the
+server rejects an expired token; there is no server-side device code flow
+to start from here.
+"""
+
+from datetime import datetime, timezone
+from typing import List, Optional
+
+from pydantic import BaseModel, Field
+from fastmcp.server.dependencies import get_access_token
+from loguru import logger
+
+from toolbridge_mcp.utils.requests import get_cached_backend_jwt, get_cached_tenant_id
+from toolbridge_mcp.mcp_instance import mcp
+
+
+class AuthStatus(BaseModel):
+    """Authentication state for the current MCP caller."""
+
+    authenticated: bool
+    subject: Optional[str] = None
+    email: Optional[str] = None
+    client_id: Optional[str] = Field(default=None, alias="clientId")
+    scopes: List[str] = Field(default_factory=list)
+    expires_at: Optional[str] = Field(default=None, alias="expiresAt")
+    expires_in_seconds: Optional[int] = Field(default=None, alias="expiresInSeconds")
+    tenant_id: Optional[str] = Field(default=None, alias="tenantId")
+    backend_jwt_cached: bool = Field(default=False, alias="backendJwtCached")
+    hint: Optional[str] = None
+
+    class Config:
+        populate_by_name = True
+
+
+@mcp.tool()
+async def auth_status() -> AuthStatus:
+    """
+    Report the current authentication state.
+
+    Returns the authenticated subject, OAuth token expiry, and scopes for the
+    caller, plus whether the tenant and backend JWT are cached. If the token
+    is missing or expired, the hint explains how to re-authenticate.
+
+    Returns:
+        AuthStatus describing the caller's current auth state
+
+    Examples:
+        >>> await auth_status()
+    """
+    try:
+        token = get_access_token()
+    except Exception:
+        token = None
+
+    if token is None:
+        logger.info("Auth status requested without an access token")
+        return AuthStatus(
+            authenticated=False,
+            hint="No access token. Reconnect the MCP server in your client to start the OAuth login flow.",
+        )
+
+    claims = token.claims or {}
+    subject = claims.get("sub")
+
+    expires_at = token.expires_at or claims.get("exp")
+    expires_at_iso: Optional[str] = None
+    expires_in: Optional[int] = None
+    if expires_at:
+        expires_at_iso = datetime.fromtimestamp(int(expires_at), tz=timezone.utc).isoformat()
+        expires_in = int(expires_at) - int(datetime.now(tz=timezone.utc).timestamp())
+
+    hint = None
+    if expires_in is not None and expires_in <= 0:
+        hint = "Access token has expired. Reconnect the MCP server in your client to log in again."
+
+    logger.info(f"Auth status requested: subject={subject}, expires_in={expires_in}")
+
+    return AuthStatus(
+        authenticated=expires_in is None or expires_in > 0,
+        subject=subject,
+        email=claims.get("email"),
+        client_id=token.client_id,
+        scopes=list(token.scopes or []),
+        expires_at=expires_at_iso,
+        expires_in_seconds=expires_in,
+        tenant_id=get_cached_tenant_id(subject) if subject else None,
+        backend_jwt_cached=bool(subject and get_cached_backend_jwt(subject)),
+        hint=hint,
+    )
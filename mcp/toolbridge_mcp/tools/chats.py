@@ -13,6 +13,7 @@ from loguru import logger
 
 from toolbridge_mcp.async_client import get_client
 from toolbridge_mcp.utils.requests import call_get, call_post, call_put, call_patch, call_delete
+from toolbridge_mcp.utils.pagination import DEFAULT_LIST_LIMIT, MAX_LIST_LIMIT, build_list_params
 from toolbridge_mcp.mcp_instance import mcp
 
 
@@ -48,23 +49,26 @@ class ChatsListResponse(BaseModel):
 @mcp.tool()
 async def list_chats(
     limit: Annotated[
-        int, Field(ge=1, le=1000, description="Maximum number of chats to return")
-    ] = 100,
+        int, Field(ge=1, le=MAX_LIST_LIMIT, description="Maximum number of chats to return")
+    ] = DEFAULT_LIST_LIMIT,
     cursor: Annotated[
         Optional[str], Field(description="Pagination cursor from previous response")
     ] = None,
     include_deleted: Annotated[bool, Field(description="Include soft-deleted chats")] = False,
+    deleted_only: Annotated[bool, Field(description="Return only soft-deleted chats")] = False,
 ) -> ChatsListResponse:
     """
     List chats with cursor-based pagination.
 
     Returns chats for the authenticated tenant. Supports filtering and pagination.
-    Use the next_cursor from the response to fetch additional pages.
+    Results are paged (25 per call by default). When next_cursor is set,
+    more items exist: pass it back as cursor to fetch the next page.
 
     Args:
-        limit: Maximum number of chats to return (1-1000, default 100)
+        limit: Maximum number of chats to return (1-200, default 25)
         cursor: Optional pagination cursor from previous response
         include_deleted: Whether to include soft-deleted chats (default False)
+        deleted_only: Whether to return only soft-deleted chats (default False)
 
     Returns:
         ChatsListResponse containing items and optional next_cursor
@@ -80,14 +84,11 @@ async def list_chats(
         >>> await list_chats(include_deleted=True)
     """
     async with get_client() as client:
-        params = {"limit": limit}
-        if cursor:
-            params["cursor"] = cursor
-        if include_deleted:
-            params["includeDeleted"] = "true"
+        params = build_list_params(limit, cursor, include_deleted, deleted_only)
 
         logger.info(
-            f"Listing chats: limit={limit}, cursor={cursor}, include_deleted={include_deleted}"
+            f"Listing chats: limit={limit}, cursor={cursor}, include_deleted={include_deleted}, "
+            f"deleted_only={deleted_only}"
         )
         response = await call_get(client, "/v1/chats", params=params)
         data = response.json()
@@ -14,6 +14,7 @@ from loguru import logger
 
 from toolbridge_mcp.async_client import get_client
 from toolbridge_mcp.utils.requests import call_get, call_post, call_put, call_patch, call_delete
+from toolbridge_mcp.utils.pagination import DEFAULT_LIST_LIMIT, MAX_LIST_LIMIT, build_list_params
 from toolbridge_mcp.mcp_instance import mcp
 
 
@@ -49,24 +50,27 @@ class NotesListResponse(BaseModel):
 @mcp.tool()
 async def list_notes(
     limit: Annotated[
-        int, Field(ge=1, le=1000, description="Maximum number of notes to return")
-    ] = 100,
+        int, Field(ge=1, le=MAX_LIST_LIMIT, description="Maximum number of notes to return")
+    ] = DEFAULT_LIST_LIMIT,
     cursor: Annotated[
         Optional[str], Field(description="Pagination cursor from previous response")
     ] = None,
     include_deleted: Annotated[bool, Field(description="Include soft-deleted notes")] = False,
+    deleted_only: Annotated[bool, Field(description="Return only soft-deleted notes")] = False,
 ) -> NotesListResponse:
     """
     List notes with cursor-based pagination (per-user).
 
     Returns notes for the authenticated user's tenant. Each user can only see
     their own tenant's notes. OAuth authentication enforced by FastMCP.
-    Use the next_cursor from the response to fetch additional pages.
+    Results are paged (25 per call by default). When next_cursor is set,
+    more items exist: pass it back as cursor to fetch the next page.
 
     Args:
-        limit: Maximum number of notes to return (1-1000, default 100)
+        limit: Maximum number of notes to return (1-200, default 25)
         cursor: Optional pagination cursor from previous response
         include_deleted: Whether to include soft-deleted notes (default False)
+        deleted_only: Whether to return only soft-deleted notes (default False)
 
     Returns:
         NotesListResponse containing items and optional next_cursor
@@ -93,15 +97,12 @@ async def list_notes(
         tenant_id = "unknown"
     
     async with get_client() as client:
-        params = {"limit": limit}
-        if cursor:
-            params["cursor"] = cursor
-        if include_deleted:
-            params["includeDeleted"] = "true"
+        params = build_list_params(limit, cursor, include_deleted, deleted_only)
 
         logger.info(
             f"Listing notes for user={user_id}, tenant={tenant_id}: "
-            f"limit={limit}, cursor={cursor}, include_deleted={include_deleted}"
+            f"limit={limit}, cursor={cursor}, include_deleted={include_deleted}, "
+            f"deleted_only={deleted_only}"
         )
         response = await call_get(client, "/v1/notes", params=params)
         data = response.json()
@@ -0,0 +1,119 @@
+"""
+MCP tool for keyword search across ToolBridge entities.
+
+Provides a single `search` tool that calls the Go API's /v1/search endpoint,
+returning ranked results across notes, tasks, comments, chats, and more.
+"""
+
+from typing import Annotated, List, Optional, Any, Dict, Union
+
+from pydantic import BaseModel, Field
+from loguru import logger
+
+from toolbridge_mcp.async_client import get_client
+from toolbridge_mcp.utils.requests import call_get
+from toolbridge_mcp.mcp_instance import mcp
+
+
+# Entity types accepted by the Go API search endpoint
+SEARCHABLE_ENTITY_TYPES = [
+    "notes",
+    "tasks",
+    "comments",
+    "chats",
+    "chat_messages",
+    "task_lists",
+    "task_list_categories",
+]
+
+
+# Pydantic models matching Go API responses
+
+
+class SearchResult(BaseModel):
+    """Single ranked search hit."""
+
+    entity_type: str = Field(alias="entityType")
+    uid: str
+    version: int
+    updated_at: str = Field(alias="updatedAt")
+    rank: float
+    payload: Dict[str, Any]
+
+    class Config:
+        populate_by_name = True
+
+
+class SearchResponse(BaseModel):
+    """Ranked search results for a query."""
+
+    query: str
+    results: List[SearchResult]
+
+    class Config:
+        populate_by_name = True
+
+
+# MCP Tool Definitions
+
+
+@mcp.tool()
+async def search(
+    query: Annotated[str, Field(min_length=1, description="Keywords to search for")],
+    entity_types: Annotated[
+        Optional[Union[List[str], str]],
+        Field(
+            description=(
+                "Optional entity types to restrict the search to "
+                f"(any of: {', '.join(SEARCHABLE_ENTITY_TYPES)}; list or comma-separated string)"
+            )
+        ),
+    ] = None,
+    limit: Annotated[
+        int, Field(ge=1, le=100, description="Maximum number of results to return")
+    ] = 20,
+) -> SearchResponse:
+    """
+    Search notes, tasks, and other entities by keyword.
+
+    Runs a full-text search over the text fields of the authenticated user's
+    active (non-deleted) items and returns results ranked by relevance.
+    Each result includes its entity_type and uid so it can be cited or
+    fetched in full with the matching get_* tool.
+
+    Args:
+        query: Keywords to search for
+        entity_types: Optional entity types to search (default: all types)
+        limit: Maximum number of results (1-100, default 20)
+
+    Returns:
+        SearchResponse containing ranked results
+
+    Examples:
+        # Search everything
+        >>> await search(query="quarterly planning")
+
+        # Search only notes and tasks
+        >>> await search(query="invoice", entity_types=["notes", "tasks"])
+    """
+    if isinstance(entity_types, str):
+        entity_types = [t.strip() for t in entity_types.split(",") if t.strip()]
+
+    if entity_types:
+        unknown = [t for t in entity_types if t not in SEARCHABLE_ENTITY_TYPES]
+        if unknown:
+            raise ValueError(
+                f"Unknown entity types: {unknown}. "
+                f"Valid types: {', '.join(SEARCHABLE_ENTITY_TYPES)}"
+            )
+
+    async with get_client() as client:
+        params: Dict[str, Any] = {"q": query, "limit": limit}
+        if entity_types:
+            params["types"] = ",".join(entity_types)
+
+        logger.info(f"Searching: query={query!r}, entity_types={entity_types}, limit={limit}")
+        response = await call_get(client, "/v1/search", params=params)
+        data = response.json()
+
+        return SearchResponse(**data)
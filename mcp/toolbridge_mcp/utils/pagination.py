@@ -0,0 +1,44 @@
+"""
+Pagination helpers shared by the MCP list tools.
+
+List tools map directly onto the Go API's cursor pagination
+(?limit=&cursor=&includeDeleted=&deletedOnly=). Defaults are deliberately
+smaller than the REST defaults so a single tool result doesn't flood the
+model's context; the model pages through larger sets using next_cursor.
+"""
+
+from typing import Any, Dict, Optional
+
+# Default page size for MCP list tools (REST default is 500)
+DEFAULT_LIST_LIMIT = 25
+
+# Upper bound for a single MCP list tool call (REST allows up to 1000)
+MAX_LIST_LIMIT = 200
+
+
+def build_list_params(
+    limit: int = DEFAULT_LIST_LIMIT,
+    cursor: Optional[str] = None,
+    include_deleted: bool = False,
+    deleted_only: bool = False,
+) -> Dict[str, Any]:
+    """
+    Build query parameters for a REST list endpoint.
+
+    Args:
+        limit: Page size (clamped to 1..MAX_LIST_LIMIT)
+        cursor: Opaque cursor from a previous response's next_cursor
+        include_deleted: Include soft-deleted items alongside active ones
+        deleted_only: Return only soft-deleted items (implies include_deleted)
+
+    Returns:
+        Dict of query parameters for call_get
+    """
+    params: Dict[str, Any] = {"limit": max(1, min(limit, MAX_LIST_LIMIT))}
+    if cursor:
+        params["cursor"] = cursor
+    if deleted_only:
+        params["deletedOnly"] = "true"
+    elif include_deleted:
+        params["includeDeleted"] = "true"
+    return params
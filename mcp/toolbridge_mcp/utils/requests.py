@@ -11,11 +11,20 @@ WorkOS AuthKit OAuth 2.1 Flow:
 Session management: Each request creates a fresh sync session before calling
 the API. Sessions are NOT cached or reused to avoid stale session issues.
 
+Backend JWT caching: Exchanged backend JWTs are cached per-user and proactively
+re-exchanged shortly before they expire (see backend_jwt_refresh_skew_seconds),
+with a per-user lock so concurrent tool calls trigger at most one exchange.
+
+Retries: Requests go through send_with_retry, which retries rate limiting and
+transient upstream failures with backoff (see utils/retry.py).
+
 Tenant resolution: Supports two modes:
 - Single-tenant mode: TENANT_ID env var set → uses hardcoded tenant (smoke testing)
 - Multi-tenant mode: TENANT_ID not set → dynamically resolves via /v1/auth/tenant (primary mode)
 """
 
+import asyncio
+import time
 from typing import Any, Dict, Optional
 
 import httpx
@@ -23,12 +32,14 @@ from fastmcp.server.dependencies import get_access_token
 from loguru import logger
 
 from toolbridge_mcp.auth import (
+    _unsafe_extract_expiry_for_caching,
     exchange_for_backend_jwt,
     extract_user_id_from_backend_jwt,
     resolve_tenant,
     TenantResolutionError,
 )
 from toolbridge_mcp.config import settings
+from toolbridge_mcp.utils.retry import send_with_retry
 from toolbridge_mcp.utils.session import create_session
 
 
@@ -45,6 +56,12 @@ _tenant_cache: Dict[str, str] = {}
 # Prevents double token exchange per request (ensure_tenant_resolved + get_backend_auth_header)
 _jwt_cache: Dict[str, str] = {}
 
+# Per-user backend JWT expiry (unix seconds), read from the cached JWT's exp claim
+_jwt_expiry: Dict[str, int] = {}
+
+# Per-user locks so concurrent tool calls share a single token exchange
+_jwt_refresh_locks: Dict[str, asyncio.Lock] = {}
+
 
 def get_cached_tenant_id(user_id: str) -> Optional[str]:
     """Get cached tenant ID for specific user."""
@@ -56,6 +73,75 @@ def get_cached_backend_jwt(user_id: str) -> Optional[str]:
     return _jwt_cache.get(user_id)
 
 
+def clear_auth_caches(user_id: Optional[str] = None) -> None:
+    """
+    Drop cached tenant and backend JWT state.
+
+    Args:
+        user_id: Clear only this user's state; clears all users when None
+    """
+    if user_id is None:
+        _tenant_cache.clear()
+        _jwt_cache.clear()
+        _jwt_expiry.clear()
+        logger.info("Cleared auth caches for all users")
+        return
+
+    _tenant_cache.pop(user_id, None)
+    _jwt_cache.pop(user_id, None)
+    _jwt_expiry.pop(user_id, None)
+    logger.info(f"Cleared auth caches for user {user_id}")
+
+
+def _backend_jwt_needs_refresh(user_id: str) -> bool:
+    """Return True if the user's cached backend JWT is missing or about to expire."""
+    if user_id not in _jwt_cache:
+        return True
+    expires_at = _jwt_expiry.get(user_id)
+    if expires_at is None:
+        # No readable exp claim - trust the token until the backend rejects it
+        return False
+    return time.time() >= expires_at - settings.backend_jwt_refresh_skew_seconds
+
+
+async def get_fresh_backend_jwt(client: httpx.AsyncClient, user_id: str) -> str:
+    """
+    Return a cached backend JWT for the user, re-exchanging it if near expiry.
+
+    Concurrent callers for the same user wait on a shared lock, so only the
+    first one performs the token exchange and the rest reuse its result.
+
+    Args:
+        client: httpx client for token exchange requests
+        user_id: User subject from the MCP OAuth token
+
+    Returns:
+        Backend JWT token string
+    """
+    if not _backend_jwt_needs_refresh(user_id):
+        return _jwt_cache[user_id]
+
+    lock = _jwt_refresh_locks.setdefault(user_id, asyncio.Lock())
+    async with lock:
+        # Another caller may have refreshed while we waited
+        if not _backend_jwt_needs_refresh(user_id):
+            return _jwt_cache[user_id]
+
+        refreshing = user_id in _jwt_cache
+        backend_jwt = await exchange_for_backend_jwt(client)
+        _jwt_cache[user_id] = backend_jwt
+
+        expires_at = _unsafe_extract_expiry_for_caching(backend_jwt)
+        if expires_at is not None:
+            _jwt_expiry[user_id] = expires_at
+        else:
+            _jwt_expiry.pop(user_id, None)
+
+        if refreshing:
+            logger.debug(f"Refreshed backend JWT for user {user_id} (expires_at={expires_at})")
+        return backend_jwt
+
+
 async def ensure_tenant_resolved(client: httpx.AsyncClient) -> str:
     """
     Ensure tenant ID is resolved for the current user.
@@ -90,15 +176,13 @@ async def ensure_tenant_resolved(client: httpx.AsyncClient) -> str:
 
         # Check per-user caches first to avoid unnecessary network calls
         cached_tenant = _tenant_cache.get(user_id)
-        cached_jwt = _jwt_cache.get(user_id)
 
-        if cached_tenant and cached_jwt:
+        if cached_tenant and not _backend_jwt_needs_refresh(user_id):
             logger.debug(f"Using cached tenant and JWT for user {user_id}: {cached_tenant}")
             return cached_tenant
 
-        # Need to exchange for backend JWT (cache miss or first request)
-        backend_jwt = await exchange_for_backend_jwt(client)
-        _jwt_cache[user_id] = backend_jwt
+        # Need to exchange for backend JWT (cache miss, first request, or near expiry)
+        await get_fresh_backend_jwt(client, user_id)
 
         # Check if tenant was cached (JWT cache miss but tenant cache hit)
         if cached_tenant:
@@ -165,16 +249,14 @@ async def get_backend_auth_header(client: httpx.AsyncClient) -> str:
         mcp_token = get_access_token()
         current_user_id = mcp_token.claims.get("sub")
 
-        # Try to get cached JWT for THIS specific user (avoids double token exchange)
-        # ensure_tenant_resolved caches the JWT when it exchanges for user_id
-        if current_user_id and current_user_id in _jwt_cache:
-            cached_jwt = _jwt_cache[current_user_id]
-            logger.debug(f"Using cached backend JWT for user {current_user_id}")
-            return f"Bearer {cached_jwt}"
+        # Use cached JWT for THIS specific user (avoids double token exchange),
+        # re-exchanging if it is missing or about to expire
+        if current_user_id:
+            backend_jwt = await get_fresh_backend_jwt(client, current_user_id)
+            return f"Bearer {backend_jwt}"
 
-        # Fall back to token exchange if not cached
-        # (shouldn't happen if ensure_tenant_resolved was called first)
-        logger.debug(f"Exchanging MCP OAuth token for backend JWT (cache miss for user {current_user_id})")
+        # No subject to key the cache on - exchange without caching
+        logger.debug("Exchanging MCP OAuth token for backend JWT (no sub claim to cache under)")
         backend_jwt = await exchange_for_backend_jwt(client)
         return f"Bearer {backend_jwt}"
     except TokenExchangeError as e:
@@ -232,6 +314,7 @@ async def call_get(
 
     Raises:
         httpx.HTTPStatusError: If request fails
+        UpstreamUnavailableError: If the API is still unavailable after retries
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
     # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
@@ -246,7 +329,9 @@ async def call_get(
     }
 
     logger.debug(f"GET {path} params={params}")
-    response = await client.get(path, params=params, headers=headers)
+    response = await send_with_retry(
+        lambda: client.get(path, params=params, headers=headers), "GET", path
+    )
     response.raise_for_status()
     return response
 
@@ -271,6 +356,7 @@ async def call_post(
 
     Raises:
         httpx.HTTPStatusError: If request fails
+        UpstreamUnavailableError: If the API is still unavailable after retries
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
     # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
@@ -285,7 +371,9 @@ async def call_post(
     }
 
     logger.debug(f"POST {path}")
-    response = await client.post(path, json=json, headers=headers)
+    response = await send_with_retry(
+        lambda: client.post(path, json=json, headers=headers), "POST", path
+    )
     response.raise_for_status()
     return response
 
@@ -312,6 +400,7 @@ async def call_put(
 
     Raises:
         httpx.HTTPStatusError: If request fails
+        UpstreamUnavailableError: If the API is still unavailable after retries
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
     # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
@@ -329,7 +418,9 @@ async def call_put(
         headers["If-Match"] = str(if_match)
 
     logger.debug(f"PUT {path} if_match={if_match}")
-    response = await client.put(path, json=json, headers=headers)
+    response = await send_with_retry(
+        lambda: client.put(path, json=json, headers=headers), "PUT", path
+    )
     response.raise_for_status()
     return response
 
@@ -354,6 +445,7 @@ async def call_patch(
 
     Raises:
         httpx.HTTPStatusError: If request fails
+        UpstreamUnavailableError: If the API is still unavailable after retries
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
     # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
@@ -368,7 +460,9 @@ async def call_patch(
     }
 
     logger.debug(f"PATCH {path}")
-    response = await client.patch(path, json=json, headers=headers)
+    response = await send_with_retry(
+        lambda: client.patch(path, json=json, headers=headers), "PATCH", path
+    )
     response.raise_for_status()
     return response
 
@@ -391,6 +485,7 @@ async def call_delete(
 
     Raises:
         httpx.HTTPStatusError: If request fails
+        UpstreamUnavailableError: If the API is still unavailable after retries
         AuthorizationError: If Authorization header missing or tenant resolution fails
     """
     # Ensure tenant is resolved (single-tenant mode or dynamic resolution)
@@ -405,6 +500,8 @@ async def call_delete(
     }
 
     logger.debug(f"DELETE {path}")
-    response = await client.delete(path, headers=headers)
+    response = await send_with_retry(
+        lambda: client.delete(path, headers=headers), "DELETE", path
+    )
     response.raise_for_status()
     return response
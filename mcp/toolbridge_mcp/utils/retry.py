@@ -0,0 +1,150 @@
+"""
+Retry-with-backoff for Go API requests.
+
+Transient upstream failures (rate limiting, brief 5xx, dropped connections)
+are retried with bounded exponential backoff + full jitter so a single blip
+doesn't fail the whole tool call.
+
+Retry policy:
+- 429 Too Many Requests: retried for all methods (the request was rejected
+  before being processed), honoring Retry-After
+- 500/502/503/504: retried only for idempotent requests (GET); a write may
+  already have been applied, so the ambiguous failure is surfaced instead.
+  500 is included because the API reports transient database errors (e.g. an
+  exhausted connection pool) as 500
+- Connection errors (request never reached the server): retried for all methods
+- Read timeouts and other transport errors: retried only for idempotent requests
+
+After exhausting retries, UpstreamUnavailableError is raised with a message
+the model can act on (e.g. "try again later").
+"""
+
+import asyncio
+import random
+from email.utils import parsedate_to_datetime
+from datetime import datetime, timezone
+from typing import Awaitable, Callable, Optional
+
+import httpx
+from loguru import logger
+
+from toolbridge_mcp.config import settings
+
+
+# Statuses retried for idempotent requests
+RETRYABLE_STATUSES = {429, 500, 502, 503, 504}
+
+# Transport errors where the request provably never reached the server
+CONNECT_ERRORS = (httpx.ConnectError, httpx.ConnectTimeout, httpx.PoolTimeout)
+
+
+class UpstreamUnavailableError(Exception):
+    """Raised when the Go API is still failing after all retries."""
+    pass
+
+
+def parse_retry_after(value: Optional[str]) -> Optional[float]:
+    """
+    Parse a Retry-After header value into seconds.
+
+    Supports both delta-seconds ("5") and HTTP-date forms.
+
+    Returns:
+        Seconds to wait, or None if the header is missing or unparseable
+    """
+    if not value:
+        return None
+    value = value.strip()
+    try:
+        return max(0.0, float(value))
+    except ValueError:
+        pass
+    try:
+        when = parsedate_to_datetime(value)
+        return max(0.0, (when - datetime.now(timezone.utc)).total_seconds())
+    except (TypeError, ValueError):
+        return None
+
+
+def backoff_delay(attempt: int, retry_after: Optional[float] = None) -> float:
+    """
+    Compute the delay before the next attempt.
+
+    Uses exponential backoff with full jitter, capped at
+    http_retry_max_delay_seconds. A Retry-After hint from the server takes
+    precedence (also capped).
+
+    Args:
+        attempt: Zero-based index of the attempt that just failed
+        retry_after: Server-provided Retry-After in seconds, if any
+    """
+    max_delay = settings.http_retry_max_delay_seconds
+    if retry_after is not None:
+        return min(retry_after, max_delay)
+    ceiling = min(max_delay, settings.http_retry_base_delay_seconds * (2 ** attempt))
+    return random.uniform(0, ceiling)
+
+
+async def send_with_retry(
+    send: Callable[[], Awaitable[httpx.Response]],
+    method: str,
+    path: str,
+) -> httpx.Response:
+    """
+    Send a request, retrying transient failures according to the retry policy.
+
+    Args:
+        send: Zero-arg coroutine factory that performs the request
+        method: HTTP method (used to decide idempotency)
+        path: API path (for logging and error messages)
+
+    Returns:
+        The final HTTP response (not yet checked with raise_for_status)
+
+    Raises:
+        UpstreamUnavailableError: If the request still fails after all retries
+    """
+    idempotent = method.upper() == "GET"
+    max_retries = settings.http_max_retries
+
+    for attempt in range(max_retries + 1):
+        is_last = attempt == max_retries
+        try:
+            response = await send()
+        except httpx.TransportError as e:
+            retryable = idempotent or isinstance(e, CONNECT_ERRORS)
+            if not retryable:
+                raise
+            if is_last:
+                raise UpstreamUnavailableError(
+                    f"ToolBridge API unreachable for {method} {path} after "
+                    f"{attempt + 1} attempts ({type(e).__name__}). Try again later."
+                ) from e
+            delay = backoff_delay(attempt)
+            logger.warning(
+                f"{method} {path} failed ({type(e).__name__}), retrying in {delay:.2f}s "
+                f"(attempt {attempt + 1}/{max_retries})"
+            )
+            await asyncio.sleep(delay)
+            continue
+
+        status = response.status_code
+        retryable = status == 429 or (idempotent and status in RETRYABLE_STATUSES)
+        if not retryable:
+            return response
+        if is_last:
+            raise UpstreamUnavailableError(
+                f"ToolBridge API returned {status} for {method} {path} after "
+                f"{attempt + 1} attempts. "
+                + ("Rate limited; wait before retrying." if status == 429 else "Try again later.")
+            )
+
+        delay = backoff_delay(attempt, parse_retry_after(response.headers.get("Retry-After")))
+        logger.warning(
+            f"{method} {path} returned {status}, retrying in {delay:.2f}s "
+            f"(attempt {attempt + 1}/{max_retries})"
+        )
+        await asyncio.sleep(delay)
+
+    # Unreachable: the loop either returns or raises on the last attempt
+    raise UpstreamUnavailableError(f"ToolBridge API request failed for {method} {path}")
@@ -17,6 +17,7 @@ import httpx
 from loguru import logger
 
 from toolbridge_mcp.config import settings
+from toolbridge_mcp.utils.retry import send_with_retry
 
 
 class SessionError(Exception):
@@ -55,11 +56,15 @@ async def create_session(
 
         # The backend JWT contains the user identity (sub claim)
         # Go API JWT middleware will extract it automatically
-        response = await client.post(
+        response = await send_with_retry(
+            lambda: client.post(
+                "/v1/sync/sessions",
+                headers={
+                    "Authorization": auth_header,
+                },
+            ),
+            "POST",
             "/v1/sync/sessions",
-            headers={
-                "Authorization": auth_header,
-            },
         )
         response.raise_for_status()
 
@@ -0,0 +1,19 @@
+-- Purge markers for permanently deleted (purged) tombstones
+--
+-- Purging hard-deletes an entity row, so its tombstone no longer appears in pull.
+-- A purge marker takes its place in the delta stream so clients that haven't
+-- pulled the tombstone yet still drop the item.
+
+CREATE TABLE purge_marker (
+  owner_id       UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  entity         TEXT NOT NULL,              -- Entity table name (e.g., 'note')
+  uid            UUID NOT NULL,
+  purged_at_ms   BIGINT NOT NULL,            -- Unix milliseconds; ordered with updated_at_ms in pull
+  PRIMARY KEY (owner_id, entity, uid)
+);
+
+-- Cursor-based pagination alongside the entity table
+CREATE INDEX purge_marker_cursor_idx ON purge_marker (owner_id, entity, purged_at_ms, uid);
+
+COMMENT ON TABLE purge_marker IS 'Delta sync markers for hard-deleted tombstones';
+COMMENT ON COLUMN purge_marker.purged_at_ms IS 'Purge timestamp - strictly after the purged tombstone''s updated_at_ms';
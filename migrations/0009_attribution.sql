@@ -0,0 +1,13 @@
+-- Created-by / last-modified-by attribution for REST mutations
+--
+-- created_by is written once when a REST mutation creates the row.
+-- updated_by is overwritten by every applied REST mutation.
+-- Both hold the actor (JWT subject) and are NULL for rows written only via sync push.
+
+ALTER TABLE note               ADD COLUMN created_by TEXT, ADD COLUMN updated_by TEXT;
+ALTER TABLE task               ADD COLUMN created_by TEXT, ADD COLUMN updated_by TEXT;
+ALTER TABLE comment            ADD COLUMN created_by TEXT, ADD COLUMN updated_by TEXT;
+ALTER TABLE chat               ADD COLUMN created_by TEXT, ADD COLUMN updated_by TEXT;
+ALTER TABLE chat_message       ADD COLUMN created_by TEXT, ADD COLUMN updated_by TEXT;
+ALTER TABLE task_list          ADD COLUMN created_by TEXT, ADD COLUMN updated_by TEXT;
+ALTER TABLE task_list_category ADD COLUMN created_by TEXT, ADD COLUMN updated_by TEXT;
@@ -0,0 +1,13 @@
+-- Scheduled deletion for notes
+--
+-- delete_after_ms mirrors the note payload's optional "deleteAfter" timestamp.
+-- Notes stay active until then; a background sweeper soft-deletes due notes.
+-- Kept as a column so the sweeper can find due notes even when payloads are encrypted.
+
+ALTER TABLE note ADD COLUMN delete_after_ms BIGINT;
+
+-- Sweeper scan: only live notes with a schedule
+CREATE INDEX note_delete_after_idx ON note (delete_after_ms)
+  WHERE delete_after_ms IS NOT NULL AND deleted_at_ms IS NULL;
+
+COMMENT ON COLUMN note.delete_after_ms IS 'Scheduled soft-delete time (Unix ms) from payload deleteAfter; NULL = not scheduled';
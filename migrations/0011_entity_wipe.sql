@@ -0,0 +1,15 @@
+-- Per-entity wipe markers
+--
+-- A per-entity wipe hard-deletes one entity type for a user without bumping the
+-- account epoch. The marker tells clients to drop their local copy of just that
+-- type: pull responses for the entity carry wipedAt, and clients that see a
+-- new value reset that entity and pull it again from an empty cursor.
+
+CREATE TABLE entity_wipe (
+  owner_id      UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  entity        TEXT NOT NULL,              -- Entity table name (e.g., 'chat_message')
+  wiped_at_ms   BIGINT NOT NULL,            -- Unix milliseconds of the latest wipe
+  PRIMARY KEY (owner_id, entity)
+);
+
+COMMENT ON TABLE entity_wipe IS 'Latest per-entity wipe per user (clients reset that entity when it changes)';
@@ -0,0 +1,24 @@
+-- Session change log (session-scoped undo)
+--
+-- When SESSION_UNDO is enabled, the first write a sync session makes to an item
+-- records the item's pre-session state here. POST /v1/sync/sessions/{id}/undo
+-- restores that state, or deletes items the session created, as long as no
+-- other writer has changed the item since (last_ms still matches the row).
+
+CREATE TABLE session_change (
+  session_id          TEXT NOT NULL,              -- X-Sync-Session ID
+  owner_id            UUID NOT NULL REFERENCES app_user(id) ON DELETE CASCADE,
+  entity              TEXT NOT NULL,              -- Entity table name (e.g., 'note')
+  uid                 UUID NOT NULL,
+  existed             BOOLEAN NOT NULL,           -- FALSE = created in this session
+  prev_payload        JSONB,                      -- Stored payload before the session (NULL if created)
+  prev_deleted_at_ms  BIGINT,                     -- Tombstone before the session
+  last_ms             BIGINT,                     -- updated_at_ms of the session's latest applied write
+  created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (session_id, entity, uid)
+);
+
+CREATE INDEX session_change_owner_idx ON session_change(owner_id, session_id);
+CREATE INDEX session_change_created_idx ON session_change(created_at);
+
+COMMENT ON TABLE session_change IS 'Pre-session item state for session-scoped undo (pruned after SESSION_UNDO_RETENTION)';
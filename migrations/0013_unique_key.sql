@@ -0,0 +1,31 @@
+-- Configurable unique payload fields
+--
+-- unique_key mirrors the value of the entity's configured unique payload field
+-- (UNIQUE_FIELDS, e.g. note "externalId"). Kept as a column so uniqueness holds
+-- even when payloads are encrypted. Only live items are indexed: deleting an
+-- item frees its value for reuse.
+
+ALTER TABLE note ADD COLUMN unique_key TEXT;
+ALTER TABLE task ADD COLUMN unique_key TEXT;
+ALTER TABLE comment ADD COLUMN unique_key TEXT;
+ALTER TABLE chat ADD COLUMN unique_key TEXT;
+ALTER TABLE chat_message ADD COLUMN unique_key TEXT;
+ALTER TABLE task_list ADD COLUMN unique_key TEXT;
+ALTER TABLE task_list_category ADD COLUMN unique_key TEXT;
+
+CREATE UNIQUE INDEX note_unique_key_idx ON note (owner_id, unique_key)
+  WHERE unique_key IS NOT NULL AND deleted_at_ms IS NULL;
+CREATE UNIQUE INDEX task_unique_key_idx ON task (owner_id, unique_key)
+  WHERE unique_key IS NOT NULL AND deleted_at_ms IS NULL;
+CREATE UNIQUE INDEX comment_unique_key_idx ON comment (owner_id, unique_key)
+  WHERE unique_key IS NOT NULL AND deleted_at_ms IS NULL;
+CREATE UNIQUE INDEX chat_unique_key_idx ON chat (owner_id, unique_key)
+  WHERE unique_key IS NOT NULL AND deleted_at_ms IS NULL;
+CREATE UNIQUE INDEX chat_message_unique_key_idx ON chat_message (owner_id, unique_key)
+  WHERE unique_key IS NOT NULL AND deleted_at_ms IS NULL;
+CREATE UNIQUE INDEX task_list_unique_key_idx ON task_list (owner_id, unique_key)
+  WHERE unique_key IS NOT NULL AND deleted_at_ms IS NULL;
+CREATE UNIQUE INDEX task_list_category_unique_key_idx ON task_list_category (owner_id, unique_key)
+  WHERE unique_key IS NOT NULL AND deleted_at_ms IS NULL;
+
+COMMENT ON COLUMN note.unique_key IS 'Value of the configured unique payload field (UNIQUE_FIELDS); NULL = none';
@@ -0,0 +1,17 @@
+-- Shared sync sessions
+--
+-- With SESSION_STORE=postgres, sync sessions live here instead of in each server's
+-- memory, so a session begun on one replica is honored by every other replica.
+-- Expired rows are removed when their owner begins a new session.
+
+CREATE TABLE sync_session (
+  id          TEXT PRIMARY KEY,          -- X-Sync-Session ID
+  owner_id    TEXT NOT NULL,             -- Same owner key as owner_state
+  epoch       INT NOT NULL,              -- Epoch the session was begun at
+  created_at  TIMESTAMPTZ NOT NULL,
+  expires_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX sync_session_owner_idx ON sync_session(owner_id, created_at);
+
+COMMENT ON TABLE sync_session IS 'Active sync sessions shared by all replicas (SESSION_STORE=postgres)';
@@ -0,0 +1,81 @@
+-- Per-user storage accounting
+--
+-- owner_state.payload_bytes is the total size (octet_length of payload_json::text)
+-- of a user's live items across all entity tables. Tombstones do not count, so a
+-- delete gives its bytes back. A row trigger on every entity table keeps the
+-- counter current for every write path: pushes, REST mutations, purges, wipes,
+-- scheduled deletes and owner merges. MAX_PAYLOAD_BYTES caps it on write.
+
+ALTER TABLE owner_state ADD COLUMN payload_bytes BIGINT NOT NULL DEFAULT 0;
+
+-- Decrements never create owner_state rows (a missing row has nothing to give back)
+CREATE OR REPLACE FUNCTION add_payload_bytes(owner_key TEXT, delta BIGINT)
+RETURNS VOID AS $$
+BEGIN
+  IF delta > 0 THEN
+    INSERT INTO owner_state (owner_id, payload_bytes) VALUES (owner_key, delta)
+    ON CONFLICT (owner_id) DO UPDATE SET payload_bytes = owner_state.payload_bytes + EXCLUDED.payload_bytes;
+  ELSIF delta < 0 THEN
+    UPDATE owner_state SET payload_bytes = GREATEST(payload_bytes + delta, 0) WHERE owner_id = owner_key;
+  END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION track_payload_bytes()
+RETURNS TRIGGER AS $$
+DECLARE
+  old_bytes BIGINT := 0;
+  new_bytes BIGINT := 0;
+BEGIN
+  IF TG_OP <> 'INSERT' AND OLD.deleted_at_ms IS NULL THEN
+    old_bytes := octet_length(OLD.payload_json::text);
+  END IF;
+  IF TG_OP <> 'DELETE' AND NEW.deleted_at_ms IS NULL THEN
+    new_bytes := octet_length(NEW.payload_json::text);
+  END IF;
+
+  IF TG_OP = 'UPDATE' AND OLD.owner_id = NEW.owner_id THEN
+    PERFORM add_payload_bytes(NEW.owner_id::text, new_bytes - old_bytes);
+  ELSE
+    IF TG_OP <> 'INSERT' THEN
+      PERFORM add_payload_bytes(OLD.owner_id::text, -old_bytes);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+      PERFORM add_payload_bytes(NEW.owner_id::text, new_bytes);
+    END IF;
+  END IF;
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER note_payload_bytes AFTER INSERT OR UPDATE OR DELETE ON note
+  FOR EACH ROW EXECUTE FUNCTION track_payload_bytes();
+CREATE TRIGGER task_payload_bytes AFTER INSERT OR UPDATE OR DELETE ON task
+  FOR EACH ROW EXECUTE FUNCTION track_payload_bytes();
+CREATE TRIGGER comment_payload_bytes AFTER INSERT OR UPDATE OR DELETE ON comment
+  FOR EACH ROW EXECUTE FUNCTION track_payload_bytes();
+CREATE TRIGGER chat_payload_bytes AFTER INSERT OR UPDATE OR DELETE ON chat
+  FOR EACH ROW EXECUTE FUNCTION track_payload_bytes();
+CREATE TRIGGER chat_message_payload_bytes AFTER INSERT OR UPDATE OR DELETE ON chat_message
+  FOR EACH ROW EXECUTE FUNCTION track_payload_bytes();
+CREATE TRIGGER task_list_payload_bytes AFTER INSERT OR UPDATE OR DELETE ON task_list
+  FOR EACH ROW EXECUTE FUNCTION track_payload_bytes();
+CREATE TRIGGER task_list_category_payload_bytes AFTER INSERT OR UPDATE OR DELETE ON task_list_category
+  FOR EACH ROW EXECUTE FUNCTION track_payload_bytes();
+
+-- Backfill existing users
+INSERT INTO owner_state (owner_id, payload_bytes)
+SELECT owner_id::text, SUM(octet_length(payload_json::text))
+FROM (
+  SELECT owner_id, payload_json FROM note WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, payload_json FROM task WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, payload_json FROM comment WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, payload_json FROM chat WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, payload_json FROM chat_message WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, payload_json FROM task_list WHERE deleted_at_ms IS NULL
+  UNION ALL SELECT owner_id, payload_json FROM task_list_category WHERE deleted_at_ms IS NULL
+) live
+GROUP BY owner_id
+ON CONFLICT (owner_id) DO UPDATE SET payload_bytes = EXCLUDED.payload_bytes;
+
+COMMENT ON COLUMN owner_state.payload_bytes IS 'Total payload bytes of the user''s live items (kept by track_payload_bytes)';
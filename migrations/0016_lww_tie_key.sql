@@ -0,0 +1,17 @@
+-- Deterministic LWW tie-breaking
+--
+-- Two different writes of one item with the same updated_at_ms used to resolve to
+-- whichever committed first. tie_key (SHA-256 of the plaintext payload without
+-- sync.version) decides instead: the larger key wins regardless of arrival order.
+-- Identical writes have identical keys, so duplicate pushes stay no-ops. Existing
+-- rows keep a NULL key until their next write.
+
+ALTER TABLE note ADD COLUMN tie_key BYTEA;
+ALTER TABLE task ADD COLUMN tie_key BYTEA;
+ALTER TABLE comment ADD COLUMN tie_key BYTEA;
+ALTER TABLE chat ADD COLUMN tie_key BYTEA;
+ALTER TABLE chat_message ADD COLUMN tie_key BYTEA;
+ALTER TABLE task_list ADD COLUMN tie_key BYTEA;
+ALTER TABLE task_list_category ADD COLUMN tie_key BYTEA;
+
+COMMENT ON COLUMN note.tie_key IS 'LWW tie-breaker for equal updated_at_ms (larger wins; NULL = legacy row)';
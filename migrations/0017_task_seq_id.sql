@@ -0,0 +1,16 @@
+-- Sequential display IDs for tasks
+--
+-- When SEQ_IDS is on, a task created by a push or REST mutation is stamped with its
+-- owner's next seq_id (1, 2, 3, ...) in the creating transaction. The counter is
+-- owner_state.task_seq; incrementing it with an upsert row-locks it until commit,
+-- so concurrent creates of one user never share an ID. IDs are never reused: a
+-- rolled-back create or a purge leaves a gap. Tasks created while the setting was
+-- off keep a NULL seq_id.
+
+ALTER TABLE owner_state ADD COLUMN task_seq BIGINT NOT NULL DEFAULT 0;
+
+ALTER TABLE task ADD COLUMN seq_id BIGINT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS task_owner_seq_id_idx ON task (owner_id, seq_id) WHERE seq_id IS NOT NULL;
+
+COMMENT ON COLUMN task.seq_id IS 'Per-owner sequential display ID (NULL = created with SEQ_IDS off)';
@@ -0,0 +1,17 @@
+-- Drop plaintext tie keys of encrypted rows
+--
+-- tie_key used to be an unkeyed SHA-256 of the plaintext payload even when the
+-- payload was stored encrypted, so a guessed payload could be confirmed against it.
+-- With encryption on, tie keys are now an HMAC under the payload key. Existing
+-- sealed rows drop their old key; a NULL key falls back to first-writer-wins on
+-- timestamp ties until the item's next write stores a keyed one.
+
+UPDATE note SET tie_key = NULL WHERE payload_json ? '_enc';
+UPDATE task SET tie_key = NULL WHERE payload_json ? '_enc';
+UPDATE comment SET tie_key = NULL WHERE payload_json ? '_enc';
+UPDATE chat SET tie_key = NULL WHERE payload_json ? '_enc';
+UPDATE chat_message SET tie_key = NULL WHERE payload_json ? '_enc';
+UPDATE task_list SET tie_key = NULL WHERE payload_json ? '_enc';
+UPDATE task_list_category SET tie_key = NULL WHERE payload_json ? '_enc';
+
+COMMENT ON COLUMN note.tie_key IS 'LWW tie-breaker for equal updated_at_ms (larger wins; NULL = legacy row; HMAC when payloads are encrypted)';
@@ -0,0 +1,10 @@
+-- Keyset paging for the scheduled delete sweeper
+--
+-- The sweeper pages through due notes by (delete_after_ms, owner_id, uid), so notes
+-- that keep failing to delete no longer hold the head of every batch. The index
+-- covers the full sort key.
+
+DROP INDEX IF EXISTS note_delete_after_idx;
+
+CREATE INDEX note_delete_after_idx ON note (delete_after_ms, owner_id, uid)
+  WHERE delete_after_ms IS NOT NULL AND deleted_at_ms IS NULL;
@@ -0,0 +1,34 @@
+-- Storage accounting in its own table
+--
+-- The payload_bytes triggers (migration 0015) upserted owner_state on every entity
+-- write, so each write row-locked the row that session starts, epoch checks, wipes
+-- and task display IDs also write, until commit. The counter moves to owner_usage:
+-- a user's writes still apply their deltas to one row, but epoch handling no longer
+-- queues behind them. With MAX_PAYLOAD_BYTES set, the quota check locks the same row
+-- before reading it, so concurrent writes of one user cannot overshoot the cap.
+
+CREATE TABLE owner_usage (
+  owner_id       TEXT PRIMARY KEY,          -- Same owner key as owner_state
+  payload_bytes  BIGINT NOT NULL DEFAULT 0  -- Total payload bytes of the user's live items
+);
+
+INSERT INTO owner_usage (owner_id, payload_bytes)
+SELECT owner_id, payload_bytes FROM owner_state WHERE payload_bytes > 0;
+
+-- Decrements never create owner_usage rows (a missing row has nothing to give back)
+CREATE OR REPLACE FUNCTION add_payload_bytes(owner_key TEXT, delta BIGINT)
+RETURNS VOID AS $$
+BEGIN
+  IF delta > 0 THEN
+    INSERT INTO owner_usage (owner_id, payload_bytes) VALUES (owner_key, delta)
+    ON CONFLICT (owner_id) DO UPDATE SET payload_bytes = owner_usage.payload_bytes + EXCLUDED.payload_bytes;
+  ELSIF delta < 0 THEN
+    UPDATE owner_usage SET payload_bytes = GREATEST(payload_bytes + delta, 0) WHERE owner_id = owner_key;
+  END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE owner_state DROP COLUMN payload_bytes;
+
+COMMENT ON TABLE owner_usage IS 'Storage counters per user, kept by track_payload_bytes';
+COMMENT ON COLUMN owner_usage.payload_bytes IS 'Total payload bytes of the user''s live items (kept by track_payload_bytes)';